@@ -0,0 +1,142 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// accessCollectionPrefix prefixes the DocumentStore collection holding the access
+// records of one transaction; the transaction ID follows.
+const accessCollectionPrefix = "access-"
+
+// AccessRecord is evidence that certified data was fetched and decoded through the SDK.
+type AccessRecord struct {
+	TxID      string    `json:"txId"`            // The transaction whose data was read.
+	ClientID  string    `json:"clientId"`        // Who read it; see WithClientID and SetAccessLog.
+	Time      time.Time `json:"time"`            // When the data was decoded.
+	Operation string    `json:"operation"`       // The SDK method that read it, e.g. "GetTransactionData".
+	Bytes     int64     `json:"bytes"`           // The number of bytes of data decoded.
+	RequestID string    `json:"requestId"`       // The correlation ID of the read; see WithRequestID.
+	Label     string    `json:"label,omitempty"` // The reading account's label, if any; see SetLabel.
+}
+
+// clientIDKey is the context key under which the reading client's ID is stored.
+type clientIDKey struct{}
+
+// WithClientID returns a copy of ctx identifying the client on whose behalf data is
+// read, for the access log; see SetAccessLog. Services reading certificates for many
+// users set it per request.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - clientID: Identifies the reader, such as a user or service account name.
+//
+// Returns:
+//
+//	A derived context carrying the client ID.
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// SetAccessLog turns on the recording of every successful read of certified data by
+// GetTransactionData, as evidence of who accessed certified documents. Each record
+// names the client from WithClientID on the read's context, or clientID if the context
+// carries none, and is kept in docs, which may be shared by every account of a process.
+// When a record cannot be saved, the read returns an error even though the data was
+// written.
+//
+// Parameters:
+//   - docs: The document store holding the records; nil turns the log off.
+//   - clientID: The client ID recorded for reads whose context carries none.
+func (a *CEPAccount) SetAccessLog(docs storage.DocumentStore, clientID string) {
+	a.access.mu.Lock()
+	defer a.access.mu.Unlock()
+	a.access.docs = docs
+	a.access.client = clientID
+}
+
+// ListAccessLog returns the recorded reads of the data of txID, oldest first.
+//
+// Parameters:
+//   - txID: The transaction, with or without "0x" prefix.
+//
+// Returns:
+//
+//	The records, none if the data was never read, or an error if the access log is
+//	off or cannot be read.
+func (a *CEPAccount) ListAccessLog(txID string) ([]AccessRecord, error) {
+	a.access.mu.Lock()
+	docs := a.access.docs
+	a.access.mu.Unlock()
+	if docs == nil {
+		return nil, fmt.Errorf("access log is not enabled")
+	}
+
+	collection := accessCollection(txID)
+	ids, err := docs.IDs(collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access log: %w", err)
+	}
+	records := make([]AccessRecord, 0, len(ids))
+	for _, id := range ids {
+		var record AccessRecord
+		if err := docs.Load(collection, id, &record); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read access record %s: %w", id, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// accessCollection returns the collection holding the access records of txID.
+func accessCollection(txID string) string {
+	return accessCollectionPrefix + helpers.HexFix(txID)
+}
+
+// accessLog is the destination of an account's access records.
+type accessLog struct {
+	mu     sync.Mutex
+	docs   storage.DocumentStore
+	client string
+	seq    uint64
+}
+
+// recordAccess records that operation decoded n bytes of the data of txID, if the
+// access log is on.
+func (a *CEPAccount) recordAccess(ctx context.Context, operation, txID string, n int64) error {
+	label := a.view().label
+	a.access.mu.Lock()
+	docs := a.access.docs
+	record := AccessRecord{
+		TxID:      helpers.HexFix(txID),
+		ClientID:  a.access.client,
+		Time:      time.Now(),
+		Operation: operation,
+		Bytes:     n,
+		RequestID: RequestIDFromContext(ctx),
+		Label:     label,
+	}
+	a.access.seq++
+	// Zero-padded, so that IDs sort in time order; the sequence keeps them unique.
+	id := fmt.Sprintf("%020d-%010d", record.Time.UnixNano(), a.access.seq)
+	a.access.mu.Unlock()
+	if docs == nil {
+		return nil
+	}
+	if clientID, ok := ctx.Value(clientIDKey{}).(string); ok && clientID != "" {
+		record.ClientID = clientID
+	}
+	if err := docs.Save(accessCollection(txID), id, record); err != nil {
+		return fmt.Errorf("failed to record access to transaction %s: %w", record.TxID, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// failingDocs is a DocumentStore whose saves fail.
+type failingDocs struct{ storage.DocumentStore }
+
+func (failingDocs) Save(collection, id string, doc interface{}) error {
+	return errors.New("disk full")
+}
+
+func TestAccessLog(t *testing.T) {
+	envelope, _ := CanonicalJSON(map[string]string{"Action": certificateAction, "Data": helpers.StringToHex("contract")})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Result":200,"Response":{"Status":"Executed","Payload":"%s"}}`, helpers.StringToHex(string(envelope)))
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	if _, err := acc.ListAccessLog("0xabc"); err == nil {
+		t.Error("Expected an error while the access log is off")
+	}
+	// Reads are not recorded while the log is off.
+	if _, err := acc.GetTransactionData(t.Context(), "0xabc", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := storage.NewDocumentStore(storage.NewMemory())
+	acc.SetAccessLog(docs, "batch-job")
+	acc.SetLabel("reporting")
+	if _, err := acc.GetTransactionData(t.Context(), "0xABC", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithRequestID(WithClientID(t.Context(), "alice"), "req-1")
+	if _, err := acc.GetTransactionData(ctx, "abc", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := acc.ListAccessLog("0xabc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 recorded reads, got %+v", records)
+	}
+	if r := records[0]; r.ClientID != "batch-job" || r.TxID != helpers.HexFix("abc") || r.Bytes != 8 || r.Operation != "GetTransactionData" || r.RequestID == "" || r.Time.IsZero() {
+		t.Errorf("Expected the default client's read first, got %+v", r)
+	}
+	if r := records[1]; r.ClientID != "alice" || r.RequestID != "req-1" || r.Label != "reporting" || r.Time.Before(records[0].Time) {
+		t.Errorf("Expected alice's read second, got %+v", r)
+	}
+	if others, _ := acc.ListAccessLog("def"); len(others) != 0 {
+		t.Errorf("Expected no reads of another transaction, got %+v", others)
+	}
+
+	// Another account sharing the store sees the same evidence.
+	other := NewCEPAccount()
+	other.SetAccessLog(docs, "")
+	if shared, _ := other.ListAccessLog("abc"); len(shared) != 2 {
+		t.Errorf("Expected the records to be shared through the store, got %+v", shared)
+	}
+
+	acc.SetAccessLog(failingDocs{docs}, "batch-job")
+	if n, err := acc.GetTransactionData(t.Context(), "abc", io.Discard); err == nil || n != 8 {
+		t.Errorf("Expected the data to be read but the failure to record it reported, got %d, %v", n, err)
+	}
+}
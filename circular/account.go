@@ -0,0 +1,778 @@
+package circular
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// CEPAccount represents a client-side interface for interacting with the Circular Enterprise Protocol blockchain.
+// It encapsulates all necessary account information and provides methods for managing account state,
+// interacting with the Network Access Gateway (NAG), and performing blockchain operations such as
+// submitting certificates and querying transaction outcomes.
+//
+// All methods are safe for concurrent use. Submissions on the account's Blockchain are
+// serialized so that each uses the next nonce, while queries proceed in parallel. The
+// exported fields are not synchronized: set them before sharing the account between
+// goroutines, and read them afterwards through State.
+type CEPAccount struct {
+	Address     string      // The blockchain address of the account.
+	PublicKey   string      // The public key associated with the account.
+	Info        interface{} // General information or metadata about the account.
+	CodeVersion string      // The version of the client library being used.
+	LastError   string      // Stores the last encountered error message, aligning with Java API behavior.
+	NAGURL      string      // The URL of the Network Access Gateway (NAG) for the currently configured network.
+	NetworkNode string      // Identifier for the specific network node being used (e.g., "testnet", "mainnet").
+	Blockchain  string      // The identifier of the blockchain being interacted with.
+	LatestTxID  string      // The ID of the most recently submitted transaction by this account.
+	Nonce       int64       // A unique, incrementing number used to prevent transaction replay attacks.
+	IntervalSec int         // The polling interval in seconds for transaction outcome checks.
+	NetworkURL  string      // The base URL for discovering network access gateways.
+
+	lastErr     error               // The last error as a typed value; see LastErr.
+	permissions *AccountPermissions // Permissions cached by GetPermissions; nil until fetched.
+	schemas     *SchemaRegistry     // Optional schemas used to validate certificate data; see SetSchemaRegistry.
+	retryPolicy *RetryPolicy        // Retry behaviour for throttled NAG calls; nil disables retries.
+	pressure    backpressureState   // Throttling signals received from the gateway; see Backpressure.
+	concurrency concurrencyControl  // Adaptive limits on batch calls in flight; see SetAdaptiveConcurrency.
+	nodes       nodeRegistry        // The network's gateway nodes and their health; see ListNetworkNodes.
+	appName     string              // Application name sent to the gateway; see SetApplicationName.
+	userAgent   string              // User-Agent override; see SetUserAgent.
+	polling     pollingRecorder     // Aggregate outcome polling statistics; see PollingStats.
+	adaptive    *AdaptivePolling    // Adaptive outcome polling interval bounds; see SetAdaptivePolling.
+	receipts    ReceiptStore        // Records submitted transactions; see SetReceiptStore.
+	watchers    watcherRegistry     // In-flight outcome polls, cancelled by AbandonTransaction.
+	devMode     bool                // Top up and retry on devnet balance rejections; see SetDevMode.
+	nagPath     string              // Gateway path layout; see SetNetworkProfile.
+	nagProto    string              // Gateway protocol; see NetworkProfile.Protocol.
+	readToken   string              // Delegated read token presented by a ReadOnlyClient.
+	chains      chainRegistry       // Per-chain state for SubmitCertificateOn.
+	chainCheck  bool                // Validate chains before submitting; see SetStrictChains.
+	compat      versionChecker      // Gateway version compatibility; see SetVersionCheck.
+	logLevel    LogLevel            // Minimum level of log output; see SetLogLevel.
+	logger      Logger              // Receives log output instead of stdout; see SetLogger.
+	limiter     rateLimiter         // Spaces out NAG calls; see Reload.
+	shared      *RateLimiter        // Rate limit shared with other accounts; see SetRateLimiter.
+	notFound    time.Duration       // How long "Transaction Not Found" is retried; see SetNotFoundWindow.
+	nonces      nonceJournal        // Outstanding nonce reservations; see ReserveNonces.
+	quota       quotaTracker        // Quota usage enforced client-side; see SetQuotaAware.
+	guard       Guard               // Safety interlock on writes; see SetGuard.
+	policy      Policy              // Governance rules for submissions; see SetPolicy.
+	degrade     degradation         // NAG error rate and queue-only mode; see SetDegradationPolicy.
+	maint       maintenance         // Gateway maintenance windows; see SetMaintenanceWindows.
+	inflight    atomic.Int32        // Transactions being broadcast; see Lifecycle.
+	node        *NodeClient         // Node RPC endpoint used instead of a NAG; see SetNodeClient.
+	client      *http.Client        // HTTP client for gateway calls; nil uses httpClient. See SetHTTPOptions.
+	deadlines   Deadlines           // Default operation deadlines; see SetDeadlines.
+	clock       Clock               // Time source for polling and the queue; see SetClock.
+	label       string              // Workload label for logs, metrics and records; see SetLabel.
+	scanner     Scanner             // Inspects data before certification; see SetScanner.
+	signer      Signer              // The signer given to NewAccount; see Signer.
+	keys        KeyStore            // Persists keys switched to by RotateKey; see SetKeyStore.
+	maxResponse int64               // Response body size limit; see SetMaxResponseSize.
+	session     uint64              // Advanced by each new address or chain and by Close; see sameSubject.
+	results     resultCounter       // Result codes of failed gateway calls; see Stats.
+	dedup       dedupIndex          // Content hashes of certified data; see SetDedupStore.
+	nwatch      nonceWatch          // Nonces used locally, for WatchNonce.
+	nstore      nonceStore          // Where nonces are persisted; see SetNonceStore.
+	outcomes    outcomeCache        // Final outcomes of transactions; see SetOutcomeCache.
+	rechecks    recheckQueue        // Confirmed transactions awaiting a re-check; see WatchConfirmations.
+	events      eventJournal        // Journal of state changes; see SetEventJournal.
+	flights     flightGroup         // Nonce fetches in flight, shared by concurrent callers.
+	sla         slaTracker          // Recent confirmation samples; see SetSLATracking.
+	access      accessLog           // Records of certified data read; see SetAccessLog.
+	features    featureFlags        // The account's own settings of experimental features; see SetFeature.
+	archiveMu   sync.Mutex          // Serializes ExportArchive runs; see ExportArchive.
+
+	mu       sync.RWMutex // Guards the fields above that are not synchronized separately.
+	submitMu sync.Mutex   // Serializes nonce allocation on the account's Blockchain.
+}
+
+// NewCEPAccount is a factory function that creates and initializes a new CEPAccount instance.
+// It sets up the account with default values for the library version, network URLs,
+// blockchain, nonce, and transaction polling interval. This function should be used
+// to obtain a properly configured CEPAccount object before performing any operations.
+// See also NewAccount.
+//
+// Returns:
+//
+//	A pointer to a newly initialized CEPAccount struct.
+func NewCEPAccount() *CEPAccount {
+	return &CEPAccount{
+		CodeVersion: LibVersion,
+		NetworkURL:  NetworkDiscoveryURL(),
+		NAGURL:      DefaultNAGURL(),
+		Blockchain:  DefaultBlockchain(),
+		Nonce:       0,
+		IntervalSec: defaultIntervalSec,
+	}
+}
+
+// GetLastError retrieves the last error message that occurred during an operation
+// performed by the CEPAccount instance. This method is provided for compatibility
+// and consistent error reporting across different API implementations (e.g., Java).
+//
+// Returns:
+//
+//	A string containing the last error message. Returns an empty string if no error
+//	has occurred since the last operation or since the account was initialized.
+func (a *CEPAccount) GetLastError() string {
+	return a.view().LastError
+}
+
+// LastErr returns the last error encountered by the account as an error value.
+// Unlike GetLastError, the returned error preserves its type, so callers can use
+// errors.As to extract an *APIError carrying the HTTP status, NAG result code,
+// message and request ID of a failed gateway call.
+//
+// Returns:
+//
+//	The last error, or nil if no error has occurred.
+func (a *CEPAccount) LastErr() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastErr
+}
+
+// setError records err as the account's last error, keeping `LastError` in sync.
+func (a *CEPAccount) setError(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastErr = err
+	a.LastError = err.Error()
+}
+
+// Open initializes the CEPAccount with a specified blockchain address.
+// This method is a prerequisite for most other account operations.
+//
+// Parameters:
+//   - address: The blockchain address to associate with this account.
+//
+// Returns:
+//
+//	`true` if the address is successfully set, and `false` otherwise.
+//	If the address is empty, an error message is stored in `a.LastError`.
+func (a *CEPAccount) Open(address string) bool {
+	if address == "" {
+		a.setError(ErrInvalidAddress)
+		return false
+	}
+	a.mu.Lock()
+	if a.Address != "" && !sameHex(a.Address, address) {
+		// The nonces, latest transaction and permissions were those of the other address.
+		a.Nonce = 0
+		a.LatestTxID = ""
+		a.permissions = nil
+		a.chains.reset()
+	}
+	if !sameHex(a.Address, address) {
+		a.session++
+	}
+	a.Address = address
+	a.mu.Unlock()
+	a.recordEvent(AccountEvent{Type: EventOpen, Address: address})
+	return true
+}
+
+// Close securely clears all sensitive and operational data from the CEPAccount instance.
+// This includes the blockchain address, public key, network configurations,
+// and any cached transaction IDs or nonces. Before clearing them, Close stops the
+// account's outcome polls, which fail with ErrAccountClosed, and saves its nonces to
+// the nonce store, if one is set (see SetNonceStore).
+//
+// A closed account can be reopened: Open starts a new session from the cleared state,
+// in which the network, blockchain and signer must be set again. Results of operations
+// still running from the previous session are never applied to it. Closing an account
+// that nothing was set on since it was last closed does nothing, and records no event.
+//
+// Parameters:
+//   - opts: Optional behaviour, such as WithZeroizeKeys.
+//
+// Returns:
+//
+//	An error if the nonces could not be saved. The account is closed regardless.
+func (a *CEPAccount) Close(opts ...CloseOption) error {
+	var cfg closeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	a.mu.RLock()
+	cleared := a.cleared()
+	a.mu.RUnlock()
+	if cleared {
+		return nil
+	}
+	v := a.view()
+	a.watchers.cancelAll(ErrAccountClosed)
+	err := a.persistOnClose(v)
+	if zeroizer, ok := v.signer.(Zeroizer); ok && cfg.zeroize {
+		zeroizer.Zeroize()
+	}
+
+	a.mu.Lock()
+	a.Address = ""
+	a.PublicKey = ""
+	a.signer = nil
+	a.Info = nil
+	a.NAGURL = ""
+	a.NetworkNode = ""
+	a.Blockchain = ""
+	a.LatestTxID = ""
+	a.Nonce = 0
+	a.IntervalSec = defaultIntervalSec
+	a.permissions = nil
+	a.nagPath = ""
+	a.nagProto = ""
+	a.node = nil
+	a.chains.reset()
+	a.session++
+	a.mu.Unlock()
+	a.recordEvent(AccountEvent{Type: EventClose})
+	return err
+}
+
+// SetNetwork configures the CEPAccount to operate on a specific blockchain network.
+// It achieves this by querying a public endpoint to discover the appropriate
+// Network Access Gateway (NAG) URL for the given network identifier (e.g., "testnet", "mainnet").
+// The discovered NAG URL is then stored internally for subsequent API calls.
+//
+// Parameters:
+//   - network: A string identifier for the desired network (e.g., "devnet", "testnet", "mainnet").
+//
+// Returns:
+//
+//	The resolved NAG URL as a string if successful, or an empty string
+//	if there's an error during the network discovery process, with the error
+//	details stored in `a.LastError`.
+func (a *CEPAccount) SetNetwork(network string) string {
+	url, err := a.setNetwork(network)
+	if err != nil {
+		a.setError(err)
+		return ""
+	}
+	return url
+}
+
+// setNetwork is SetNetwork, returning the discovery error instead of recording it.
+func (a *CEPAccount) setNetwork(network string) (string, error) {
+	ctx, cancel := withDeadline(context.Background(), a.view().deadlines.withDefaults().Discovery)
+	defer cancel()
+	found, err := discoverNetwork(ctx, network)
+	if err != nil {
+		return "", fmt.Errorf("network discovery failed: %w", err)
+	}
+	url := found.URL
+
+	a.mu.Lock()
+	a.NAGURL = url
+	a.NetworkNode = network
+	a.nagPath = "" // Discovered gateways use the legacy layout and protocol.
+	a.nagProto = ""
+	a.node = nil
+	a.mu.Unlock()
+	a.compat.reset()
+	a.nodes.discovered(network, found.Nodes)
+	a.recordEvent(AccountEvent{Type: EventNetwork, NAGURL: url, NetworkNode: network})
+	return url, nil
+}
+
+// SetBlockchain explicitly sets the blockchain identifier for the CEPAccount.
+// This function allows overriding the default blockchain configured during initialization.
+//
+// Parameters:
+//   - chain: A valid blockchain address or identifier (e.g., a hexadecimal string)
+//     that the account will interact with for all subsequent operations.
+//
+// Each chain has one nonce at a time. On a change of chain, the account's Nonce becomes
+// the state of the previous chain, from which SubmitCertificateOn continues, and the
+// state tracked for the new chain, if any, becomes the Nonce; without one, the Nonce is
+// reset until UpdateAccount fetches it. SetBlockchain waits for submissions in flight
+// on the previous chain, so that the nonce handed over is the one after theirs.
+func (a *CEPAccount) SetBlockchain(chain string) {
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+	var adopted int64
+	old := a.view().Blockchain
+	if !sameHex(old, chain) {
+		adopted = a.handOverNonce(old, chain)
+	}
+	a.mu.Lock()
+	if !sameHex(old, chain) {
+		a.session++
+	}
+	a.Blockchain = chain
+	a.mu.Unlock()
+	a.recordEvent(AccountEvent{Type: EventBlockchain, Blockchain: chain})
+	if adopted > 0 {
+		a.recordEvent(AccountEvent{Type: EventNonce, Nonce: adopted, Reason: "blockchain"})
+	}
+}
+
+// UpdateAccount fetches the latest nonce for the account from the configured Network Access Gateway (NAG).
+// The nonce is a crucial component for preventing transaction replay attacks and ensuring
+// the sequential ordering of transactions from a given account. This method increments
+// the internal nonce value by one after a successful fetch, preparing it for the next transaction.
+// Concurrent calls are coalesced: while a fetch is in flight, further calls wait for it and
+// share its result instead of making requests of their own.
+//
+// Returns:
+//
+//	`true` if the nonce is successfully updated, and `false` otherwise.
+//	Any errors encountered during the network request or response parsing are stored in `a.LastError`.
+func (a *CEPAccount) UpdateAccount() bool {
+	v := a.view()
+	if v.Address == "" {
+		a.setError(ErrAccountNotOpen)
+		return false
+	}
+
+	// Concurrent refreshes of the same nonce share one request to the gateway.
+	shared, err := a.flights.do(context.Background(), "update\x00"+v.Address+"\x00"+v.Blockchain, func(ctx context.Context) error {
+		a.submitMu.Lock()
+		defer a.submitMu.Unlock()
+		nonce, err := a.fetchNonce(ctx, v.Blockchain)
+		if err != nil {
+			return err
+		}
+		return a.setNonce(v, nonce, "update")
+	})
+	if shared {
+		a.logf(LogDebug, "UpdateAccount: joined the nonce fetch already in flight\n")
+	}
+	if err != nil {
+		a.setError(err)
+		return false
+	}
+	return true
+}
+
+// fetchNonce retrieves the account's wallet nonce on chain from the NAG and returns the
+// nonce to use for the account's next transaction on that chain.
+func (a *CEPAccount) fetchNonce(ctx context.Context, chain string) (int64, error) {
+	return a.fetchNonceOf(ctx, a.view().Address, chain)
+}
+
+// fetchNonceOf is fetchNonce for the wallet of address.
+func (a *CEPAccount) fetchNonceOf(ctx context.Context, address, chain string) (int64, error) {
+	v := a.view()
+	ctx, cancel := withDeadline(ctx, v.deadlines.withDefaults().UpdateAccount)
+	defer cancel()
+	requestData := map[string]string{
+		"Address":    helpers.HexFix(address),
+		"Version":    v.CodeVersion,
+		"Blockchain": helpers.HexFix(chain),
+	}
+
+	resp, err := a.postNAG(ctx, "Circular_GetWalletNonce_", requestData)
+	if err != nil {
+		return 0, err
+	}
+
+	a.logf(LogDebug, "UpdateAccount: Parsed Response - Result: %d %s, Response: %s\n", resp.Result, resp.Result, string(resp.Response))
+
+	switch resp.Result {
+	case ResultOK:
+		// If Result is 200, Response should be a struct with Nonce
+		var nonceResponse struct {
+			Nonce int `json:"Nonce"`
+		}
+		if err := json.Unmarshal(resp.Response, &nonceResponse); err != nil {
+			return 0, fmt.Errorf("failed to decode nonce response: %w, body: %s", err, string(resp.Response))
+		}
+		return int64(nonceResponse.Nonce) + 1, nil
+	case ResultInvalidBlockchain:
+		return 0, resp.resultError("Rejected: Invalid Blockchain")
+	case ResultInsufficientBalance:
+		return 0, resp.resultError(a.insufficientBalanceMessage())
+	default:
+		// If Result is not 200, Response should be a string error message
+		if errMsg := resp.message(); errMsg != "" {
+			return 0, resp.resultError(fmt.Sprintf("failed to update account: %s", errMsg))
+		}
+		return 0, resp.resultError("failed to update account: unknown error response")
+	}
+}
+
+// SubmitCertificate creates a data certificate, signs it with the provided private key,
+// and then submits it to the blockchain via the configured Network Access Gateway (NAG).
+// This function encapsulates the entire process of preparing the certificate payload,
+// generating a unique transaction ID, signing the transaction, and sending it to the network.
+// It updates the account's `LatestTxID` upon successful submission and increments the nonce.
+//
+// Parameters:
+//   - pdata: The primary data content of the certificate to be submitted.
+//   - privateKeyHex: The private key of the account, in hexadecimal format, used for signing the transaction.
+//   - opts: Optional settings for this submission, such as WithRecipient.
+//
+// Returns:
+//
+//	This function does not explicitly return a value. Any errors during the process
+//	(e.g., account not open, signing failure, network issues, or non-200 response from the server)
+//	are captured and stored in `a.LastError`.
+func (a *CEPAccount) SubmitCertificate(pdata string, privateKeyHex string, opts ...SubmitOption) {
+	if a.view().Address == "" {
+		a.setError(fmt.Errorf("Account is not open"))
+		return
+	}
+
+	signer, err := NewPrivateKeySigner(privateKeyHex)
+	if err != nil {
+		a.setError(fmt.Errorf("failed to sign data: %w", err))
+		return
+	}
+
+	if _, err := a.submitCertificate(context.Background(), pdata, signer, opts...); err != nil {
+		a.setError(err)
+	}
+}
+
+// DefaultOutcomeTimeout is the default OutcomeTotal deadline, bounding how long
+// SubmitAndWait and WaitForOutcomes wait for an outcome when their context has no
+// deadline; see SetDeadlines.
+const DefaultOutcomeTimeout = 2 * time.Minute
+
+// SubmitResult describes a certificate submitted with SubmitAndWait.
+type SubmitResult struct {
+	TxID      string                 // The ID of the submitted transaction.
+	Duplicate bool                   // The data was already certified and TxID is the earlier transaction; see SetDedupStore.
+	Outcome   map[string]interface{} // The finalized transaction details; nil if waiting failed.
+	Stats     *OutcomeStats          // How the outcome was polled for.
+}
+
+// SubmitAndWait submits a certificate and then waits for the transaction to reach a
+// final state, polling at the account's IntervalSec (or its adaptive interval; see
+// SetAdaptivePolling). Waiting ends when ctx is done; if ctx has no deadline, the
+// account's Submit and OutcomeTotal deadlines apply to the two steps (see SetDeadlines).
+//
+// Parameters:
+//   - ctx: Controls cancellation of the submission and bounds the wait.
+//   - pdata: The data content of the certificate.
+//   - signer: Signs the transaction; it must hold the account's key.
+//   - opts: Optional settings for the submission, such as WithRecipient or WithTTL.
+//
+// Returns:
+//
+//	The submission result and nil on success. If the submission fails, nil and the
+//	error. If the submission succeeds but no outcome is obtained, the result (with its
+//	TxID set, so the caller can keep tracking it) and the error.
+func (a *CEPAccount) SubmitAndWait(ctx context.Context, pdata string, signer Signer, opts ...SubmitOption) (*SubmitResult, error) {
+	ctx = ensureRequestID(ctx)
+	txID, duplicate, err := a.certify(ctx, pdata, signer, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.awaitOutcome(ctx, txID, duplicate)
+}
+
+// awaitOutcome waits for the outcome of the submitted transaction txID, as the second
+// step of SubmitAndWait.
+func (a *CEPAccount) awaitOutcome(ctx context.Context, txID string, duplicate bool) (*SubmitResult, error) {
+	result := &SubmitResult{TxID: txID, Duplicate: duplicate, Stats: &OutcomeStats{}}
+	var err error
+	result.Outcome, err = a.waitForOutcome(ctx, txID, a.pollInterval(a.view().IntervalSec), result.Stats)
+	if err != nil {
+		return result, fmt.Errorf("transaction %s submitted but no outcome was obtained: %w", txID, err)
+	}
+	return result, nil
+}
+
+// submitCertificate builds the CP_CERTIFICATE payload for pdata, derives the transaction ID,
+// signs it with signer and broadcasts the resulting transaction to the NAG.
+// On success the account's `LatestTxID` is updated, the nonce is incremented and the
+// transaction ID is returned. Concurrent submissions are serialized in nonce order.
+func (a *CEPAccount) submitCertificate(ctx context.Context, pdata string, signer Signer, opts ...SubmitOption) (string, error) {
+	txID, _, err := a.certify(ctx, pdata, signer, opts...)
+	return txID, err
+}
+
+// certify is submitCertificate, additionally reporting whether the data had already been
+// certified, in which case the existing transaction ID is returned and the nonce is
+// left unchanged.
+func (a *CEPAccount) certify(ctx context.Context, pdata string, signer Signer, opts ...SubmitOption) (string, bool, error) {
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+	return a.certifyLocked(ctx, a.view(), pdata, signer, opts)
+}
+
+// certifyLocked is certify for the account state v; a.submitMu must be held.
+func (a *CEPAccount) certifyLocked(ctx context.Context, v accountView, pdata string, signer Signer, opts []SubmitOption) (string, bool, error) {
+	id, nonce, duplicate, err := a.sendCertificate(ctx, v.Blockchain, v.Nonce, pdata, signer, opts)
+	if err != nil && !queuedForLater(err) {
+		return "", false, err
+	}
+
+	a.applySubmission(v, id, nonce, !duplicate)
+	return id, duplicate, err
+}
+
+// sendCertificate builds, signs and broadcasts a CP_CERTIFICATE transaction for pdata on
+// chain with the given nonce, stamped by the account's Clock, and records its
+// receipt. It returns the transaction ID and the nonce used, but does not update any
+// nonce or latest transaction state; callers do so on success. If the data was already
+// certified (see SetDedupStore), nothing is sent and the earlier transaction's ID is
+// returned with duplicate set.
+func (a *CEPAccount) sendCertificate(ctx context.Context, chain string, nonce int64, pdata string, signer Signer, opts []SubmitOption) (id string, usedNonce int64, duplicate bool, err error) {
+	v := a.view()
+	if v.Address == "" {
+		return "", 0, false, ErrAccountNotOpen
+	}
+	ctx, cancel := withDeadline(ctx, v.deadlines.withDefaults().Submit)
+	defer cancel()
+	cfg, err := newSubmitConfig(v.Address, opts)
+	if err != nil {
+		return "", 0, false, err
+	}
+	if pdata, err = v.scan(pdata); err != nil {
+		return "", 0, false, err
+	}
+	if cfg.previousTx == "" {
+		if txID := a.lookupDuplicate(chain, pdata); txID != "" {
+			a.logf(LogInfo, "sendCertificate: data already certified by %s\n", txID)
+			return txID, nonce, true, nil
+		}
+	}
+	if err := a.checkPermissions(chain, certificateTxType); err != nil {
+		return "", 0, false, err
+	}
+	if v.chainCheck {
+		if err := a.validateChain(ctx, chain, certificateTxType); err != nil {
+			return "", 0, false, err
+		}
+	}
+	if v.schemas != nil && !cfg.skipSchema {
+		if err := v.schemas.Validate(certificateAction, pdata); err != nil {
+			return "", 0, false, err
+		}
+	}
+	submission, err := v.checkPolicy(chain, cfg.to, pdata)
+	if err != nil {
+		return "", 0, false, err
+	}
+	if err := a.quota.check(1, int64(len(pdata))); err != nil {
+		return "", 0, false, err
+	}
+
+	tx, err := buildCertificate(chain, v.Address, v.CodeVersion, nonce, v.timeSource().Now(), pdata, signer, cfg)
+	if err != nil {
+		return "", 0, false, err
+	}
+	err = a.dispatch(ctx, v, tx, cfg, int64(len(pdata)), submission)
+	if err != nil && !queuedForLater(err) {
+		return "", 0, false, err
+	}
+	a.recordCertified(chain, pdata, tx.ID)
+	return tx.ID, nonce, false, err
+}
+
+// dispatch broadcasts the signed transaction tx and records its receipt, or queues it
+// while the gateway is in a maintenance window or the account is degraded, then counts
+// the submission of size bytes of data against the account's quota and policy.
+//
+// Returns:
+//
+//	A *DeferredError if the gateway is in a maintenance window, with Queued set if tx
+//	was queued, or another error if tx was neither broadcast nor queued.
+func (a *CEPAccount) dispatch(ctx context.Context, v accountView, tx *Transaction, cfg *submitConfig, size int64, submission *Submission) error {
+	resumeAt, maintenance := a.maint.until(v.timeSource().Now())
+	queue := maintenance
+	if !queue {
+		var err error
+		if queue, err = a.queueOnly(ctx); err != nil {
+			return err
+		}
+	}
+	if queue {
+		if maintenance && v.receipts == nil {
+			return &DeferredError{ResumeAt: resumeAt}
+		}
+		if err := a.queueTransaction(tx, cfg.ttl, cfg.previousTx); err != nil {
+			return err
+		}
+	} else {
+		if err := a.broadcastTransaction(ctx, tx); err != nil {
+			return err
+		}
+		a.recordReceipt(tx, cfg.ttl, cfg.previousTx)
+	}
+	a.quota.consume(size)
+	v.policySubmitted(submission)
+	if maintenance {
+		return &DeferredError{TxID: tx.ID, ResumeAt: resumeAt, Queued: true}
+	}
+	return nil
+}
+
+// buildCertificate builds and signs the CP_CERTIFICATE transaction certifying pdata from
+// address on chain with the given nonce and timestamp at, as configured by cfg. The
+// client library version is recorded as version.
+func buildCertificate(chain, address, version string, nonce int64, at time.Time, pdata string, signer Signer, cfg *submitConfig) (*Transaction, error) {
+	payloadObject := map[string]string{
+		"Action": certificateAction,
+		"Data":   helpers.StringToHex(pdata),
+	}
+	if cfg.previousTx != "" {
+		payloadObject["PreviousTxID"] = helpers.HexFix(cfg.previousTx)
+	}
+	if cfg.hashData {
+		digest := sha256.Sum256([]byte(pdata))
+		payloadObject["SHA256"] = hex.EncodeToString(digest[:])
+	}
+	// The envelope is hashed into the transaction ID, so it must encode identically everywhere.
+	jsonStr, err := CanonicalJSON(payloadObject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	payload, err := EncodePayload(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+	return signTransaction(chain, address, version, nonce, at, certificateTxType, payload, signer, cfg)
+}
+
+// signTransaction builds and signs the transaction of type txType carrying payload from
+// address on chain with the given nonce and timestamp at, as configured by cfg. The
+// client library version is recorded as version.
+func signTransaction(chain, address, version string, nonce int64, at time.Time, txType string, payload Payload, signer Signer, cfg *submitConfig) (*Transaction, error) {
+	timestamp := at.UTC().Format(helpers.TimestampLayout)
+
+	nonceStr := fmt.Sprintf("%d", nonce)
+	id := ComputeTransactionID(chain, address, cfg.to, string(payload), nonceStr, timestamp)
+
+	signature, err := signer.Sign(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+
+	return &Transaction{
+		Blockchain: helpers.HexFix(chain),
+		From:       helpers.HexFix(address),
+		ID:         id,
+		Nonce:      nonceStr,
+		Payload:    string(payload),
+		Signature:  signature,
+		Timestamp:  timestamp,
+		To:         helpers.HexFix(cfg.to),
+		Type:       txType,
+		Version:    version,
+	}, nil
+}
+
+// GetTransaction retrieves the details of a specific transaction using its block ID and transaction ID.
+// This function acts as a convenience wrapper around `getTransactionByID`, specifically
+// searching for the transaction within the confines of a single, designated block.
+// It is useful when the exact block where a transaction was recorded is known.
+//
+// Parameters:
+//   - blockID: The identifier of the block where the transaction is expected to be found.
+//   - transactionID: The unique identifier of the transaction.
+//
+// Returns:
+//
+//	A map[string]interface{} containing the transaction details if found.
+//	Returns `nil` if the `blockID` is empty or invalid, or if the transaction cannot be retrieved.
+//	An error message is stored in `a.LastError` in case of failure.
+func (a *CEPAccount) GetTransaction(blockID string, transactionID string) map[string]interface{} {
+	if blockID == "" {
+		a.setError(fmt.Errorf("blockID cannot be empty"))
+		return nil
+	}
+	// This function is a convenience wrapper around getTransactionByID,
+	// searching within a single, specific block.
+	startBlock, err := strconv.ParseInt(blockID, 10, 64)
+	if err != nil {
+		a.setError(fmt.Errorf("invalid blockID: %w", err))
+		return nil
+	}
+	result, err := a.getTransactionByID(context.Background(), transactionID, startBlock, startBlock)
+	if err != nil {
+		a.setError(fmt.Errorf("failed to get transaction by ID: %w", err))
+		return nil
+	}
+	return result
+}
+
+// getTransactionByID retrieves the detailed information for a specific transaction by its ID.
+// It allows for searching within a specified range of blocks (`startBlock` to `endBlock`).
+// This function communicates with the configured Network Access Gateway (NAG) to fetch
+// transaction data.
+//
+// Parameters:
+//   - ctx: Controls cancellation and carries the correlation ID of the calling operation.
+//   - transactionID: The unique identifier of the transaction to retrieve.
+//   - startBlock: The starting block number for the search range.
+//   - endBlock: The ending block number for the search range.
+//
+// Returns:
+//
+//	A map containing the transaction details if successful.
+//	An error if the network is not set, the request data cannot be marshaled,
+//	the HTTP request fails, the network returns a non-OK status, or the response
+//	JSON cannot be decoded.
+func (a *CEPAccount) getTransactionByID(ctx context.Context, transactionID string, startBlock, endBlock int64) (map[string]interface{}, error) {
+	v := a.view()
+	if v.NAGURL == "" {
+		return nil, ErrNetworkNotSet
+	}
+
+	resp, err := a.postNAG(ctx, transactionByIDEndpoint, v.transactionQuery(transactionID, startBlock, endBlock))
+	if err != nil {
+		return nil, err
+	}
+	return a.decodeTransaction(resp)
+}
+
+// transactionByIDEndpoint is the NAG operation looking up a transaction by ID.
+const transactionByIDEndpoint = "Circular_GetTransactionbyID_"
+
+// transactionQuery returns the request looking up transactionID between the given blocks.
+func (v accountView) transactionQuery(transactionID string, startBlock, endBlock int64) map[string]string {
+	return map[string]string{
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"ID":         helpers.HexFix(transactionID),
+		"Start":      fmt.Sprintf("%d", startBlock),
+		"End":        fmt.Sprintf("%d", endBlock),
+		"Version":    v.CodeVersion,
+	}
+}
+
+// decodeTransaction decodes the full envelope of a transaction lookup.
+func (a *CEPAccount) decodeTransaction(resp *nagResponse) (map[string]interface{}, error) {
+	var transactionDetails map[string]interface{}
+	if err := json.Unmarshal(resp.body, &transactionDetails); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction JSON: %w, body: %s", err, string(resp.body))
+	}
+
+	a.logf(LogDebug, "getTransactionByID: Parsed Response: %v\n", transactionDetails)
+
+	return transactionDetails, nil
+}
+
+// GetTransactionOutcome polls the blockchain for the final status of a transaction
+// identified by `txID`. It repeatedly queries the Network Access Gateway (NAG)
+// until the transaction's status is no longer "Pending" or a specified timeout is reached.
+// The polling interval is determined by `intervalSec`.
+//
+// Parameters:
+//   - txID: The unique identifier of the transaction to monitor.
+//   - timeoutSec: The maximum time (in seconds) to wait for the transaction to finalize.
+//   - intervalSec: The delay (in seconds) between consecutive polling attempts.
+//
+// Returns:
+//
+//	A map[string]interface{} containing the finalized transaction details if successful.
+//	Returns `nil` if the timeout is exceeded or if any error occurs during polling,
+//	with the specific error message stored in `a.LastError`.
+func (a *CEPAccount) GetTransactionOutcome(txID string, timeoutSec int, intervalSec int) map[string]interface{} {
+	outcome, _ := a.GetTransactionOutcomeWithStats(txID, timeoutSec, intervalSec)
+	return outcome
+}
@@ -1,4 +1,4 @@
-package circular_enterprise_apis
+package circular
 
 import (
 	"fmt"
@@ -0,0 +1,30 @@
+package circular
+
+import "context"
+
+// Account is the part of the SDK most services build on: opening an account, choosing
+// a network, submitting certificates and reading their outcomes. *CEPAccount implements
+// it, and so does testsupport.FakeAccount, so that code written against Account can be
+// unit-tested without a gateway. Methods beyond it remain available on *CEPAccount.
+type Account interface {
+	// Open associates the account with an address; see CEPAccount.Open.
+	Open(address string) bool
+	// SetNetwork selects the network and its gateway; see CEPAccount.SetNetwork.
+	SetNetwork(network string) string
+	// UpdateAccount refreshes the nonce; see CEPAccount.UpdateAccount.
+	UpdateAccount() bool
+	// SubmitCertificate certifies pdata; see CEPAccount.SubmitCertificate.
+	SubmitCertificate(pdata string, privateKeyHex string, opts ...SubmitOption)
+	// GetTransaction looks up a transaction in a block; see CEPAccount.GetTransaction.
+	GetTransaction(blockID string, transactionID string) map[string]interface{}
+	// WaitForOutcome waits for a transaction to finalize; see CEPAccount.WaitForOutcome.
+	WaitForOutcome(ctx context.Context, txID string) (map[string]interface{}, error)
+	// State returns a snapshot of the account; see CEPAccount.State.
+	State() AccountState
+	// LastErr returns the last error; see CEPAccount.LastErr.
+	LastErr() error
+	// Close clears the account; see CEPAccount.Close.
+	Close(opts ...CloseOption) error
+}
+
+var _ Account = (*CEPAccount)(nil)
@@ -0,0 +1,285 @@
+package circular
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateAPI = flag.Bool("update-api", false, "rewrite the golden API files in api/ from the current sources")
+
+// apiPackages are the public packages whose exported API is recorded in api/, relative
+// to the module root.
+var apiPackages = []string{
+	"circular",
+	"circular/certtemplate",
+	"circular/conformance",
+	"circular/circulartest",
+	"circular/helpers",
+	"circular/jsonx",
+	"circular/keystore",
+	"circular/storage",
+	"circular/tenant",
+	"circular/testsupport",
+	"circular/webhook",
+	"pkg",
+	"pkg/certtemplate",
+	"pkg/utils",
+}
+
+// TestAPICompatibility fails when the exported API of a public package differs from its
+// golden file in api/, so that signature changes are made deliberately. After an
+// intended change, regenerate the files with
+//
+//	go test ./circular -run TestAPICompatibility -update-api
+//
+// and review the diff: removed or changed lines break downstream code and are only
+// acceptable in a new major version.
+func TestAPICompatibility(t *testing.T) {
+	for _, dir := range apiPackages {
+		t.Run(dir, func(t *testing.T) {
+			features, err := exportedAPI(filepath.Join("..", dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("..", "api", strings.ReplaceAll(dir, "/", "_")+".txt")
+			if *updateAPI {
+				if err := os.WriteFile(golden, []byte(strings.Join(features, "\n")+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			data, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read the recorded API: %v (record it with -update-api)", err)
+			}
+			removed, added := diffFeatures(strings.Split(strings.TrimSpace(string(data)), "\n"), features)
+			if len(removed) > 0 {
+				t.Errorf("incompatible API change; removed or changed:\n\t%s", strings.Join(removed, "\n\t"))
+			}
+			if len(added) > 0 {
+				t.Errorf("API added but not recorded in %s (run with -update-api):\n\t%s", golden, strings.Join(added, "\n\t"))
+			}
+		})
+	}
+}
+
+// diffFeatures returns the lines of want missing from got, and those of got missing
+// from want.
+func diffFeatures(want, got []string) (removed, added []string) {
+	inGot := make(map[string]bool, len(got))
+	for _, line := range got {
+		inGot[line] = true
+	}
+	inWant := make(map[string]bool, len(want))
+	for _, line := range want {
+		inWant[line] = true
+		if !inGot[line] {
+			removed = append(removed, line)
+		}
+	}
+	for _, line := range got {
+		if !inWant[line] {
+			added = append(added, line)
+		}
+	}
+	return removed, added
+}
+
+// exportedAPI lists the exported declarations of the package in dir, one per line and
+// sorted, in the style of the Go distribution's api files. Parameter names are omitted
+// since renaming them does not affect callers.
+func exportedAPI(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &apiWriter{fset: token.NewFileSet()}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(w.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			w.decl(decl)
+		}
+	}
+	sort.Strings(w.features)
+	return w.features, nil
+}
+
+// apiWriter collects the features of a package's exported declarations.
+type apiWriter struct {
+	fset     *token.FileSet
+	features []string
+}
+
+func (w *apiWriter) emit(feature string) {
+	w.features = append(w.features, feature)
+}
+
+// expr renders an expression on a single line.
+func (w *apiWriter) expr(e ast.Expr) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, w.fset, e)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// fields renders a parameter or result list by type, repeating the type once per name.
+func (w *apiWriter) fields(list *ast.FieldList) []string {
+	if list == nil {
+		return nil
+	}
+	var types []string
+	for _, field := range list.List {
+		n := max(len(field.Names), 1)
+		for range n {
+			types = append(types, w.expr(field.Type))
+		}
+	}
+	return types
+}
+
+// signature renders a function type without parameter names.
+func (w *apiWriter) signature(fn *ast.FuncType) string {
+	sig := "(" + strings.Join(w.fields(fn.Params), ", ") + ")"
+	results := w.fields(fn.Results)
+	switch len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+// typeParams renders a type parameter list such as "[T any]", or "" if there is none.
+func (w *apiWriter) typeParams(list *ast.FieldList) string {
+	if list == nil {
+		return ""
+	}
+	var params []string
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			params = append(params, name.Name+" "+w.expr(field.Type))
+		}
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+func (w *apiWriter) decl(decl ast.Decl) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		w.funcDecl(d)
+	case *ast.GenDecl:
+		var lastType ast.Expr // Constants without a type repeat the previous one.
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				w.typeSpec(s)
+			case *ast.ValueSpec:
+				if d.Tok == token.CONST && (s.Type != nil || len(s.Values) > 0) {
+					lastType = s.Type
+				}
+				w.valueSpec(d.Tok, s, lastType)
+			}
+		}
+	}
+}
+
+func (w *apiWriter) funcDecl(d *ast.FuncDecl) {
+	if !d.Name.IsExported() {
+		return
+	}
+	if d.Recv == nil {
+		w.emit("func " + d.Name.Name + w.typeParams(d.Type.TypeParams) + w.signature(d.Type))
+		return
+	}
+	recv := d.Recv.List[0].Type
+	pointer := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv, pointer = star.X, "*"
+	}
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	name, ok := recv.(*ast.Ident)
+	if !ok || !name.IsExported() {
+		return
+	}
+	w.emit("method (" + pointer + name.Name + ") " + d.Name.Name + w.signature(d.Type))
+}
+
+func (w *apiWriter) typeSpec(s *ast.TypeSpec) {
+	if !s.Name.IsExported() {
+		return
+	}
+	name := "type " + s.Name.Name + w.typeParams(s.TypeParams)
+	if s.Assign.IsValid() {
+		w.emit(name + " = " + w.expr(s.Type))
+		return
+	}
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		w.emit(name + " struct")
+		for _, field := range t.Fields.List {
+			if len(field.Names) == 0 {
+				if ast.IsExported(strings.TrimLeft(w.expr(field.Type), "*")) {
+					w.emit(name + " struct, embedded " + w.expr(field.Type))
+				}
+				continue
+			}
+			for _, fieldName := range field.Names {
+				if fieldName.IsExported() {
+					w.emit(name + " struct, " + fieldName.Name + " " + w.expr(field.Type))
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		w.emit(name + " interface")
+		for _, method := range t.Methods.List {
+			if len(method.Names) == 0 {
+				w.emit(name + " interface, embedded " + w.expr(method.Type))
+				continue
+			}
+			if fn, ok := method.Type.(*ast.FuncType); ok {
+				w.emit(name + " interface, " + method.Names[0].Name + w.signature(fn))
+			}
+		}
+	default:
+		w.emit(name + " " + w.expr(s.Type))
+	}
+}
+
+func (w *apiWriter) valueSpec(tok token.Token, s *ast.ValueSpec, lastType ast.Expr) {
+	typ := s.Type
+	if tok == token.CONST {
+		typ = lastType
+	}
+	for _, name := range s.Names {
+		if !name.IsExported() {
+			continue
+		}
+		feature := tok.String() + " " + name.Name
+		if typ != nil {
+			feature += " " + w.expr(typ)
+		}
+		w.emit(feature)
+	}
+}
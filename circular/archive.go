@@ -0,0 +1,184 @@
+package circular
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// Key prefixes of the archive written by ExportArchive.
+const (
+	archivePrefix      = "archive/"       // Partitioned NDJSON objects.
+	archiveIndexPrefix = "archive-index/" // One marker per archived transaction, naming its object.
+)
+
+// ArchiveRecord is one confirmed transaction in the archive written by ExportArchive,
+// encoded as one line of newline-delimited JSON. Together, the signed envelope and the
+// gateway's record of the transaction prove what was certified, by whom, and in which
+// block.
+type ArchiveRecord struct {
+	TxID        string                 `json:"txId"`              // The transaction ID, without "0x" prefix.
+	Blockchain  string                 `json:"blockchain"`        // The chain the transaction was submitted to.
+	Status      string                 `json:"status"`            // The on-chain status, e.g. "Executed".
+	SubmittedAt time.Time              `json:"submittedAt"`       // When the gateway accepted the transaction.
+	ArchivedAt  time.Time              `json:"archivedAt"`        // When the record was exported.
+	Transaction Transaction            `json:"transaction"`       // The signed envelope exactly as broadcast.
+	Outcome     map[string]interface{} `json:"outcome,omitempty"` // The gateway's record of the transaction, with its block; absent if the gateway no longer finds it.
+}
+
+// ArchiveExport summarizes one run of ExportArchive.
+type ArchiveExport struct {
+	Exported int      // The transactions archived by this run.
+	Objects  []string // The keys of the objects written, in ascending order.
+}
+
+// ExportArchive writes the account's finalized receipts not yet archived in kv to kv as
+// newline-delimited JSON, for long-term retention. Records are partitioned by chain and
+// UTC submission date under keys of the form
+// "archive/chain=<chain>/date=<YYYY-MM-DD>/part-<unix nanoseconds>.ndjson", a layout
+// understood by common query engines, and each transaction is marked under
+// "archive-index/<txID>" so later runs skip it. kv is typically an object storage
+// adapter, such as one for S3 or GCS, possibly within storage.Namespace; Parquet is not
+// produced.
+//
+// The outcome recorded is taken from the outcome cache or looked up from the gateway.
+// A transaction whose lookup fails is left for the next run. Objects are written before
+// the markers, so a run interrupted between the two may archive some transactions again.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the outcome lookups.
+//   - kv: The store receiving the archive.
+//
+// Returns:
+//
+//	What was exported, or an error if the receipts cannot be listed (see ReceiptLister)
+//	or kv fails, in which case the objects already written are kept.
+func (a *CEPAccount) ExportArchive(ctx context.Context, kv storage.KV) (*ArchiveExport, error) {
+	a.archiveMu.Lock()
+	defer a.archiveMu.Unlock()
+	ctx = ensureRequestID(ctx)
+
+	receipts, err := a.receiptsWithStatus(ReceiptFinalized)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	partitions := make(map[string][]*ArchiveRecord)
+	for _, receipt := range receipts {
+		txID := helpers.HexFix(receipt.Transaction.ID)
+		if _, err := kv.Get(archiveIndexPrefix + txID); err == nil {
+			continue
+		} else if !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("failed to read archive index: %w", err)
+		}
+		outcome, err := a.archiveOutcome(ctx, txID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			a.logf(LogWarn, "ExportArchive: skipping %s until the next run: %v\n", txID, err)
+			continue
+		}
+		record := &ArchiveRecord{
+			TxID:        txID,
+			Blockchain:  receipt.Transaction.Blockchain,
+			Status:      receipt.FinalStatus,
+			SubmittedAt: receipt.SubmittedAt,
+			ArchivedAt:  now,
+			Transaction: receipt.Transaction,
+			Outcome:     outcome,
+		}
+		partition := fmt.Sprintf("%schain=%s/date=%s/", archivePrefix, record.Blockchain, record.SubmittedAt.UTC().Format(time.DateOnly))
+		partitions[partition] = append(partitions[partition], record)
+	}
+
+	export := &ArchiveExport{}
+	for partition, records := range partitions {
+		var b bytes.Buffer
+		for _, record := range records {
+			line, err := json.Marshal(record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode archive record %s: %w", record.TxID, err)
+			}
+			b.Write(line)
+			b.WriteByte('\n')
+		}
+		key := fmt.Sprintf("%spart-%020d.ndjson", partition, now.UnixNano())
+		if err := kv.Put(key, b.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to write archive object %s: %w", key, err)
+		}
+		for _, record := range records {
+			if err := kv.Put(archiveIndexPrefix+record.TxID, []byte(key)); err != nil {
+				return nil, fmt.Errorf("failed to write archive index: %w", err)
+			}
+		}
+		export.Exported += len(records)
+		export.Objects = append(export.Objects, key)
+	}
+	sort.Strings(export.Objects)
+	if export.Exported > 0 {
+		a.logf(LogInfo, "ExportArchive: archived %d transactions in %d objects\n", export.Exported, len(export.Objects))
+	}
+	return export, nil
+}
+
+// RunArchiver calls ExportArchive every interval until ctx is done, so that confirmed
+// transactions reach the archive without an external scheduler. Failed runs are logged
+// and retried at the next interval.
+//
+// Parameters:
+//   - ctx: Stops the archiver when done.
+//   - kv: The store receiving the archive; see ExportArchive.
+//   - interval: How often to export.
+//
+// Returns:
+//
+//	An error if the interval is not positive, in which case nothing is exported.
+func (a *CEPAccount) RunArchiver(ctx context.Context, kv storage.KV, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("archive interval must be positive, got %s", interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := a.ExportArchive(ctx, kv); err != nil && ctx.Err() == nil {
+				a.logf(LogWarn, "RunArchiver: export failed: %v\n", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// archiveOutcome returns the outcome of txID for its archive record: the cached one if
+// any, the gateway's otherwise, or nil if the gateway no longer finds the transaction.
+func (a *CEPAccount) archiveOutcome(ctx context.Context, txID string) (map[string]interface{}, error) {
+	if outcome, ok := a.cachedOutcome(txID); ok {
+		return outcome, nil
+	}
+	data, err := a.getTransactionByID(ctx, txID, 0, 10)
+	if err != nil {
+		return nil, err
+	}
+	if IsTransactionNotFound(data) {
+		return nil, nil
+	}
+	if result, _ := jsonx.GetFloat(data, "Result"); ResultCode(result) != ResultOK {
+		return nil, fmt.Errorf("outcome lookup returned result %v", data["Result"])
+	}
+	outcome, _ := jsonx.GetMap(data, "Response")
+	return outcome, nil
+}
@@ -0,0 +1,112 @@
+package circular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestExportArchive(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query map[string]string
+		json.NewDecoder(r.Body).Decode(&query)
+		switch {
+		case query["ID"] == "cc" && failing.Load():
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case query["ID"] == "bb":
+			fmt.Fprint(w, `{"Result":118,"Response":"Transaction Not Found"}`)
+		default:
+			fmt.Fprintf(w, `{"Result":200,"Response":{"ID":%q,"BlockID":"b1","Status":"Executed"}}`, query["ID"])
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetBlockchain("0x1234")
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, r := range []*Receipt{
+		{Transaction: Transaction{ID: "aa", Blockchain: "1234", Signature: "3045"}, SubmittedAt: day, Status: ReceiptFinalized, FinalStatus: "Executed"},
+		{Transaction: Transaction{ID: "bb", Blockchain: "1234"}, SubmittedAt: day.Add(24 * time.Hour), Status: ReceiptFinalized, FinalStatus: "Executed"},
+		{Transaction: Transaction{ID: "cc", Blockchain: "1234"}, SubmittedAt: day, Status: ReceiptFinalized, FinalStatus: "Executed"},
+		{Transaction: Transaction{ID: "dd", Blockchain: "1234"}, SubmittedAt: day, Status: ReceiptPending},
+	} {
+		store.SaveReceipt(r)
+	}
+
+	kv := storage.NewMemory()
+	export, err := acc.ExportArchive(t.Context(), kv)
+	if err != nil {
+		t.Fatalf("ExportArchive failed: %v", err)
+	}
+	if export.Exported != 2 || len(export.Objects) != 2 ||
+		!strings.HasPrefix(export.Objects[0], "archive/chain=1234/date=2026-03-01/part-") ||
+		!strings.HasPrefix(export.Objects[1], "archive/chain=1234/date=2026-03-02/part-") {
+		t.Fatalf("Expected 2 transactions in one object per day, got %+v", export)
+	}
+
+	records := readArchive(t, kv, export.Objects[0])
+	if len(records) != 1 || records[0].TxID != "aa" || records[0].Transaction.Signature != "3045" ||
+		records[0].Status != "Executed" || records[0].Outcome["BlockID"] != "b1" {
+		t.Errorf("Expected the signed envelope and outcome of aa, got %+v", records)
+	}
+	if records := readArchive(t, kv, export.Objects[1]); len(records) != 1 || records[0].TxID != "bb" || records[0].Outcome != nil {
+		t.Errorf("Expected bb archived without an outcome, got %+v", records)
+	}
+
+	// The transaction whose lookup failed is archived by the next run, alone.
+	failing.Store(false)
+	export, err = acc.ExportArchive(t.Context(), kv)
+	if err != nil || export.Exported != 1 {
+		t.Fatalf("Expected the next run to archive cc only, got %+v, %v", export, err)
+	}
+	if records := readArchive(t, kv, export.Objects[0]); len(records) != 1 || records[0].TxID != "cc" {
+		t.Errorf("Expected cc in the new object, got %+v", records)
+	}
+	if export, err = acc.ExportArchive(t.Context(), kv); err != nil || export.Exported != 0 {
+		t.Errorf("Expected nothing left to archive, got %+v, %v", export, err)
+	}
+}
+
+func TestExportArchiveRequiresLister(t *testing.T) {
+	acc := NewCEPAccount()
+	if _, err := acc.ExportArchive(t.Context(), storage.NewMemory()); err == nil {
+		t.Error("Expected an error without a receipt store")
+	}
+	if err := acc.RunArchiver(t.Context(), storage.NewMemory(), 0); err == nil {
+		t.Error("Expected an error for a zero interval")
+	}
+}
+
+// readArchive decodes the NDJSON object stored under key.
+func readArchive(t *testing.T, kv storage.KV, key string) []ArchiveRecord {
+	t.Helper()
+	data, err := kv.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", key, err)
+	}
+	var records []ArchiveRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record ArchiveRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid line in %s: %v", key, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
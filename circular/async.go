@@ -0,0 +1,74 @@
+package circular
+
+import "context"
+
+// Handle tracks a certificate submitted with SubmitCertificateAsync while its outcome is
+// awaited in the background. Its methods are safe for concurrent use.
+type Handle struct {
+	txID   string
+	done   chan struct{}
+	cancel context.CancelFunc
+	result *SubmitResult
+	err    error
+}
+
+// SubmitCertificateAsync submits a certificate like SubmitAndWait, but returns as soon
+// as the gateway has accepted the transaction, leaving the wait for its outcome to a
+// goroutine managed by the returned Handle. Submissions are made in call order, so
+// concurrent handles use consecutive nonces. ctx bounds both the submission and the
+// wait; the account's Submit and OutcomeTotal deadlines apply as for SubmitAndWait.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the submission and bounds the wait.
+//   - pdata: The data content of the certificate.
+//   - signer: Signs the transaction; it must hold the account's key.
+//   - opts: Optional settings for the submission, such as WithRecipient or WithTTL.
+//
+// Returns:
+//
+//	A handle on the pending outcome, or nil and an error if the submission fails.
+func (a *CEPAccount) SubmitCertificateAsync(ctx context.Context, pdata string, signer Signer, opts ...SubmitOption) (*Handle, error) {
+	ctx = ensureRequestID(ctx)
+	txID, duplicate, err := a.certify(ctx, pdata, signer, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	h := &Handle{txID: txID, done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer close(h.done)
+		defer cancel()
+		h.result, h.err = a.awaitOutcome(ctx, txID, duplicate)
+	}()
+	return h, nil
+}
+
+// TxID returns the ID of the submitted transaction.
+func (h *Handle) TxID() string {
+	return h.txID
+}
+
+// Done returns a channel closed once the outcome has been obtained or waiting for it
+// has failed, for use in select statements.
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Result waits until Done is closed and returns the outcome, as SubmitAndWait would.
+//
+// Returns:
+//
+//	The submission result and nil once the transaction is finalized. If no outcome was
+//	obtained, the result (with its TxID set) and the error, which wraps
+//	context.Canceled after Cancel.
+func (h *Handle) Result() (*SubmitResult, error) {
+	<-h.done
+	return h.result, h.err
+}
+
+// Cancel stops waiting for the outcome. The transaction itself stays submitted; to give
+// up on it, use AbandonTransaction. Cancel has no effect once Done is closed.
+func (h *Handle) Cancel() {
+	h.cancel()
+}
@@ -0,0 +1,104 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitCertificateAsync(t *testing.T) {
+	var executed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		case executed.Load():
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Pending"}}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastError)
+	}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	first, err := acc.SubmitCertificateAsync(t.Context(), "first", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := acc.SubmitCertificateAsync(t.Context(), "second", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.TxID() == "" || first.TxID() == second.TxID() || acc.Nonce != 7 {
+		t.Errorf("Expected two accepted submissions with consecutive nonces, got %s, %s and nonce %d", first.TxID(), second.TxID(), acc.Nonce)
+	}
+	select {
+	case <-first.Done():
+		t.Fatal("Expected the outcome to be pending")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	executed.Store(true)
+	for _, h := range []*Handle{first, second} {
+		result, err := h.Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.TxID != h.TxID() || result.Outcome["Status"] != "Executed" || !result.Stats.Finalized {
+			t.Errorf("Expected the executed outcome of %s, got %+v", h.TxID(), result)
+		}
+	}
+}
+
+func TestSubmitCertificateAsyncCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Pending"}}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	h, err := acc.SubmitCertificateAsync(t.Context(), "report", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Cancel()
+	result, err := h.Result()
+	if !errors.Is(err, context.Canceled) || result == nil || result.TxID != h.TxID() {
+		t.Errorf("Expected the wait to be cancelled with the TxID kept, got %+v, %v", result, err)
+	}
+	h.Cancel() // A second Cancel is harmless.
+
+	// A failed submission returns no handle.
+	acc.Close()
+	if h, err := acc.SubmitCertificateAsync(t.Context(), "report", signer); err == nil || h != nil {
+		t.Errorf("Expected the submission to fail on a closed account, got %v, %v", h, err)
+	}
+}
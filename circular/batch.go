@@ -0,0 +1,220 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// BatchItem is the successful result for one input of a batch operation.
+type BatchItem[T any] struct {
+	Index int    // The position of the input in the batch.
+	Key   string // The input's identifier: the transaction ID, or the submitted data.
+	Value T      // The result for the input.
+}
+
+// BatchItemError is the failure of one input of a batch operation.
+type BatchItemError struct {
+	Index int    // The position of the input in the batch.
+	Key   string // The input's identifier: the transaction ID, or the submitted data.
+	Err   error  // Why the input failed.
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("item %d (%s): %v", e.Index, e.Key, e.Err)
+}
+
+// Unwrap returns the item's underlying error.
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the code of the item's underlying error; see ErrorCodeOf.
+func (e *BatchItemError) Code() ErrorCode {
+	return ErrorCodeOf(e.Err)
+}
+
+// MultiError collects the failures of a batch operation. errors.Is and errors.As look
+// through every failure, so errors.As(err, &apiErr) finds the first *APIError.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d operations failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the individual failures.
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Code returns the code shared by every failure, or CodeMultiple if they differ.
+func (e *MultiError) Code() ErrorCode {
+	code := CodeUnknown
+	for i, err := range e.Errors {
+		if c := ErrorCodeOf(err); i == 0 {
+			code = c
+		} else if c != code {
+			return CodeMultiple
+		}
+	}
+	return code
+}
+
+// BatchResult reports the outcome of a batch operation input by input, so that callers
+// can retry only the inputs listed in Failed. Both slices are ordered by Index.
+type BatchResult[T any] struct {
+	Succeeded []BatchItem[T]
+	Failed    []*BatchItemError
+}
+
+// Err returns a *MultiError holding every failure, or nil if all inputs succeeded.
+func (r *BatchResult[T]) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Failed))
+	for i, failure := range r.Failed {
+		errs[i] = failure
+	}
+	return &MultiError{Errors: errs}
+}
+
+// FailedKeys returns the keys of the failed inputs, in batch order, ready to be retried.
+func (r *BatchResult[T]) FailedKeys() []string {
+	keys := make([]string, len(r.Failed))
+	for i, failure := range r.Failed {
+		keys[i] = failure.Key
+	}
+	return keys
+}
+
+// add records the result for input index.
+func (r *BatchResult[T]) add(index int, key string, value T, err error) {
+	if err != nil {
+		r.Failed = append(r.Failed, &BatchItemError{Index: index, Key: key, Err: err})
+		return
+	}
+	r.Succeeded = append(r.Succeeded, BatchItem[T]{Index: index, Key: key, Value: value})
+}
+
+// SubmitCertificates submits one certificate for each element of data, in order. A
+// failed submission does not consume a nonce and does not stop the batch. With
+// SetQuotaAware, a batch that would exceed the account's remaining quota fails as a
+// whole before anything is submitted.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the submissions.
+//   - data: The data content of each certificate.
+//   - signer: Signs the transactions; it must hold the account's key.
+//   - opts: Optional settings applied to every submission, such as WithRecipient.
+//
+// Returns:
+//
+//	The transaction ID of each successful submission, keyed by its data, and a
+//	*MultiError if any submission failed.
+func (a *CEPAccount) SubmitCertificates(ctx context.Context, data []string, signer Signer, opts ...SubmitOption) (*BatchResult[string], error) {
+	result := &BatchResult[string]{}
+	ctx = withBatchCalls(ctx)
+	if err := a.checkBatchQuota(ensureRequestID(ctx), data); err != nil {
+		for i, pdata := range data {
+			result.add(i, pdata, "", err)
+		}
+		return result, result.Err()
+	}
+	for i, pdata := range data {
+		txID, err := a.submitCertificate(ensureRequestID(ctx), pdata, signer, opts...)
+		result.add(i, pdata, txID, err)
+	}
+	return result, result.Err()
+}
+
+// GetTransactions looks up each transaction in the most recent blocks, concurrently, or
+// in a single batch request if the account calls a JSON-RPC gateway or node. With
+// SetAdaptiveConcurrency, the concurrent lookups stay within the account's limit.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - txIDs: The transactions to look up.
+//
+// Returns:
+//
+//	The raw gateway response for each transaction that could be fetched, and a
+//	*MultiError if any lookup failed.
+func (a *CEPAccount) GetTransactions(ctx context.Context, txIDs []string) (*BatchResult[map[string]interface{}], error) {
+	ctx = withBatchCalls(ctx)
+	v := a.view()
+	queries := make([]interface{}, len(txIDs))
+	for i, txID := range txIDs {
+		queries[i] = v.transactionQuery(txID, 0, 10)
+	}
+	responses, batched, err := a.postRPCBatch(ctx, transactionByIDEndpoint, queries)
+	if batched {
+		result := &BatchResult[map[string]interface{}]{}
+		for i, txID := range txIDs {
+			switch {
+			case err != nil:
+				result.add(i, txID, nil, err)
+			case responses[i] == nil:
+				result.add(i, txID, nil, fmt.Errorf("no response to the lookup in the JSON-RPC batch"))
+			default:
+				details, decodeErr := a.decodeTransaction(responses[i])
+				result.add(i, txID, details, decodeErr)
+			}
+		}
+		return result, result.Err()
+	}
+	return runBatch(txIDs, func(txID string) (map[string]interface{}, error) {
+		return a.getTransactionByID(ensureRequestID(ctx), txID, 0, 10)
+	})
+}
+
+// WaitForOutcomes waits concurrently for the final outcome of each transaction,
+// polling at the account's IntervalSec (or its adaptive interval). Waiting ends when
+// ctx is done. With SetAdaptiveConcurrency, the polls stay within the account's limit.
+//
+// Parameters:
+//   - ctx: Controls cancellation and bounds the wait.
+//   - txIDs: The transactions to wait for.
+//
+// Returns:
+//
+//	The finalized transaction details of each transaction that finalized in time, and
+//	a *MultiError naming those that did not.
+func (a *CEPAccount) WaitForOutcomes(ctx context.Context, txIDs []string) (*BatchResult[map[string]interface{}], error) {
+	ctx = withBatchCalls(ctx)
+	interval := a.pollInterval(a.view().IntervalSec)
+	return runBatch(txIDs, func(txID string) (map[string]interface{}, error) {
+		return a.waitForOutcome(ctx, txID, interval, &OutcomeStats{})
+	})
+}
+
+// runBatch applies fn to every key concurrently and collects the results in key order.
+func runBatch[T any](keys []string, fn func(key string) (T, error)) (*BatchResult[T], error) {
+	values := make([]T, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = fn(key)
+		}()
+	}
+	wg.Wait()
+
+	result := &BatchResult[T]{}
+	for i, key := range keys {
+		result.add(i, key, values[i], errs[i])
+	}
+	return result, result.Err()
+}
@@ -0,0 +1,82 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestSubmitCertificatesReportsPartialFailure(t *testing.T) {
+	var nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req["Payload"], helpers.StringToHex(helpers.StringToHex("bad"))) {
+			fmt.Fprint(w, `{"Result":108,"Response":"Rejected: Invalid payload"}`)
+			return
+		}
+		nonces = append(nonces, req["Nonce"])
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.Nonce = 1
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	result, err := acc.SubmitCertificates(t.Context(), []string{"one", "bad", "three"}, signer)
+
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 {
+		t.Fatalf("Expected a MultiError with one failure, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ResultCode != 108 {
+		t.Errorf("Expected the MultiError to unwrap to the APIError, got %v", err)
+	}
+	if len(result.Succeeded) != 2 || result.Succeeded[0].Index != 0 || result.Succeeded[1].Index != 2 {
+		t.Errorf("Unexpected successes: %+v", result.Succeeded)
+	}
+	if keys := result.FailedKeys(); !slices.Equal(keys, []string{"bad"}) || result.Failed[0].Index != 1 {
+		t.Errorf("Expected item 1 (bad) to fail, got %v", result.Failed)
+	}
+	if got := strings.Join(nonces, ","); got != "1,2" {
+		t.Errorf("Expected the failed submission not to consume a nonce, got nonces %s", got)
+	}
+}
+
+func TestGetTransactionsCollectsResultsInOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["ID"] == "bb" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"Result":200,"Response":{"ID":%q}}`, req["ID"])
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+
+	result, err := acc.GetTransactions(t.Context(), []string{"aa", "bb", "cc"})
+	if err == nil {
+		t.Fatal("Expected an error for the failed lookup")
+	}
+	if len(result.Succeeded) != 2 || result.Succeeded[0].Key != "aa" || result.Succeeded[1].Key != "cc" {
+		t.Errorf("Unexpected successes: %+v", result.Succeeded)
+	}
+	if !slices.Equal(result.FailedKeys(), []string{"bb"}) {
+		t.Errorf("Expected bb to fail, got %v", result.FailedKeys())
+	}
+}
@@ -0,0 +1,90 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+)
+
+// Headers sending per-call metadata to the NAG; see CallOptions.
+const (
+	TenantIDHeader = "X-Tenant-ID" // The tenant the call is made for.
+	PriorityHeader = "X-Priority"  // The call's priority, "low" or "high"; absent for PriorityNormal.
+)
+
+// Priority orders NAG calls waiting for the same rate limit; see CallOptions.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1 // Background work, such as bulk imports, that yields to other calls.
+	PriorityNormal Priority = 0  // The default.
+	PriorityHigh   Priority = 1  // Interactive work that goes ahead of other calls.
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+}
+
+// CallOptions is request-scoped metadata for the NAG calls made with a context, so that
+// multi-tenant servers can propagate it through the SDK; see WithCallOptions. Every
+// NAG call sends the tenant ID and a non-normal priority in the TenantIDHeader and
+// PriorityHeader headers, and an HTTP client set with SetHTTPOptions or a custom
+// transport can read the options back from the request's context with
+// CallOptionsFromContext.
+type CallOptions struct {
+	// TenantID identifies the tenant the call is made for. It is sent to the gateway and
+	// included in log messages about the call; see CallLogger.
+	TenantID string
+
+	// Authorization, if set, is sent as the Authorization header of the call in place of
+	// the account's own credentials, e.g. "Bearer <token>". It is never logged.
+	Authorization string
+
+	// Priority orders calls waiting for the account's rate limits, its own and the one
+	// set with SetRateLimiter: a call waits while one of higher priority is waiting.
+	Priority Priority
+}
+
+// callOptionsKey is the context key under which the CallOptions are stored.
+type callOptionsKey struct{}
+
+// WithCallOptions returns a copy of ctx carrying opts for every NAG call made with it,
+// replacing any options ctx already carries.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - opts: The metadata of the calls.
+//
+// Returns:
+//
+//	A derived context carrying the options.
+func WithCallOptions(ctx context.Context, opts CallOptions) context.Context {
+	return context.WithValue(ctx, callOptionsKey{}, opts)
+}
+
+// CallOptionsFromContext returns the CallOptions carried by ctx, or the zero value if
+// none are set.
+func CallOptionsFromContext(ctx context.Context) CallOptions {
+	opts, _ := ctx.Value(callOptionsKey{}).(CallOptions)
+	return opts
+}
+
+// CallLogger is a Logger that also receives the CallOptions of the call a message is
+// about, for structured logging of tenant and priority. Messages not about a call, and
+// all messages for loggers that implement only Logger, are passed to Log; the latter are
+// prefixed with the tenant ID when there is one.
+type CallLogger interface {
+	Logger
+
+	// LogCall handles one message about a call made with opts, without a trailing
+	// newline. The options' Authorization is always empty.
+	LogCall(level LogLevel, message string, opts CallOptions)
+}
@@ -0,0 +1,86 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// callRecorder is a CallLogger keeping the options of the calls logged to it.
+type callRecorder struct {
+	recordingLogger
+	calls []CallOptions
+}
+
+func (l *callRecorder) LogCall(level LogLevel, message string, opts CallOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, opts)
+}
+
+func TestCallOptions(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	logger := &callRecorder{}
+	acc.SetLogger(logger)
+
+	ctx := WithCallOptions(t.Context(), CallOptions{TenantID: "acme", Authorization: "Bearer per-call", Priority: PriorityHigh})
+	if _, err := acc.GetBlock(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if headers.Get(TenantIDHeader) != "acme" || headers.Get(PriorityHeader) != "high" || headers.Get("Authorization") != "Bearer per-call" {
+		t.Errorf("Expected the call options in the request headers, got %v", headers)
+	}
+	if len(logger.calls) == 0 || logger.calls[0].TenantID != "acme" || logger.calls[0].Authorization != "" {
+		t.Errorf("Expected the call logged with its tenant and without credentials, got %+v", logger.calls)
+	}
+
+	// Without options, nothing is added; plain loggers get the tenant as a prefix.
+	acc.GetBlock(t.Context(), 1)
+	if headers.Get(TenantIDHeader) != "" || headers.Get(PriorityHeader) != "" || headers.Get("Authorization") != "" {
+		t.Errorf("Expected no call headers, got %v", headers)
+	}
+	plain := &recordingLogger{}
+	acc.SetLogger(plain)
+	acc.GetBlock(WithCallOptions(t.Context(), CallOptions{TenantID: "acme"}), 1)
+	if len(plain.messages) == 0 || !strings.HasPrefix(plain.messages[0], "tenant acme: ") {
+		t.Errorf("Expected messages prefixed with the tenant, got %q", plain.messages)
+	}
+}
+
+func TestRateLimiterPriority(t *testing.T) {
+	var limiter rateLimiter
+	limiter.setRate(20)
+	limiter.wait(t.Context(), PriorityNormal) // Takes the first slot; the next is 50ms away.
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for _, priority := range []Priority{PriorityLow, PriorityHigh} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.wait(t.Context(), priority)
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+	if len(order) != 2 || order[0] != PriorityHigh {
+		t.Errorf("Expected the high priority call to go first, got %v", order)
+	}
+}
@@ -0,0 +1,185 @@
+package circular
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CanonicalJSON encodes v as canonical JSON, following the JSON Canonicalization Scheme
+// (RFC 8785): object keys are sorted by their UTF-16 code units, insignificant whitespace
+// is omitted, strings use minimal escaping (no HTML escaping), and numbers use the shortest
+// round-trip representation in ECMAScript format. Semantically identical values therefore
+// always produce identical bytes, regardless of map iteration order, struct field order
+// or Go version, which makes the output safe to hash and sign.
+//
+// Strings must be valid UTF-8; invalid sequences are rejected rather than silently
+// replaced. Unicode normalization (e.g., NFC) is not applied and remains the caller's
+// responsibility.
+//
+// Parameters:
+//   - v: Any value that encoding/json can marshal.
+//
+// Returns:
+//
+//	The canonical encoding, or an error if v cannot be marshaled, contains invalid
+//	UTF-8, or contains a non-finite number.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return CanonicalizeJSON(raw)
+}
+
+// CanonicalizeJSON re-encodes an existing JSON document in canonical form.
+// See CanonicalJSON for the rules applied.
+//
+// Parameters:
+//   - data: A JSON document.
+//
+// Returns:
+//
+//	The canonical encoding, or an error if data is not valid JSON or contains invalid UTF-8.
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("canonical JSON requires valid UTF-8 input")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCanonical appends the canonical encoding of a decoded JSON value to buf.
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buf, v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %s: %w", v, err)
+		}
+		s, err := formatCanonicalNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported JSON value of type %T", value)
+	}
+	return nil
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what RFC 8785 requires.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatCanonicalNumber formats f the way ECMAScript's Number.prototype.toString does.
+func formatCanonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("non-finite numbers are not allowed in canonical JSON")
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	// Go writes exponents with at least two digits ("1e-07"); ECMAScript does not ("1e-7").
+	mantissa, exponent, _ := strings.Cut(s, "e")
+	sign := exponent[:1]
+	digits := strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + digits, nil
+}
+
+// lessUTF16 orders strings by their UTF-16 code units, as required by RFC 8785.
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
@@ -0,0 +1,59 @@
+package circular
+
+import (
+	"testing"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "sorted keys", input: `{"b":1, "a":{"d":true,"c":null}}`, expected: `{"a":{"c":null,"d":true},"b":1}`},
+		{name: "no html escaping", input: `{"html":"<a&b>"}`, expected: `{"html":"<a&b>"}`},
+		{name: "control characters", input: `"tab\tnul\u0000"`, expected: `"tab\tnul\u0000"`},
+		{name: "unicode kept literal", input: `"été"`, expected: `"été"`},
+		{name: "integers", input: `[1.0, -0, 100, 1e3]`, expected: `[1,0,100,1000]`},
+		{name: "fractions", input: `[0.5, 1.25e-7, 1e21, 123456789012345680000]`, expected: `[0.5,1.25e-7,1e+21,123456789012345680000]`},
+		{name: "utf16 key order", input: `{"😀":1,"￿":2}`, expected: `{"😀":1,"` + "￿" + `":2}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("CanonicalizeJSON failed: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("got %s, want %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSONIsStableAcrossShapes(t *testing.T) {
+	fromMap, err := CanonicalJSON(map[string]interface{}{"Data": "AB", "Action": "CP_CERTIFICATE", "n": 2})
+	if err != nil {
+		t.Fatalf("CanonicalJSON failed: %v", err)
+	}
+	fromStruct, err := CanonicalJSON(struct {
+		N      int    `json:"n"`
+		Data   string `json:"Data"`
+		Action string `json:"Action"`
+	}{2, "AB", "CP_CERTIFICATE"})
+	if err != nil {
+		t.Fatalf("CanonicalJSON failed: %v", err)
+	}
+	if string(fromMap) != string(fromStruct) {
+		t.Errorf("Expected identical encodings, got %s and %s", fromMap, fromStruct)
+	}
+}
+
+func TestCanonicalizeJSONRejectsInvalidInput(t *testing.T) {
+	for _, input := range []string{"{", `{"a":1} {"b":2}`, "\"\xff\""} {
+		if _, err := CanonicalizeJSON([]byte(input)); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package circular
+
+// Capabilities reports which optional subsystems of an account are configured, for
+// health endpoints and for platform teams auditing how services use the SDK.
+type Capabilities struct {
+	Network             bool             // The account has a NAG URL or node client to call.
+	Signer              bool             // The account holds a signer; see NewAccount.
+	Receipts            bool             // Submissions are recorded in a receipt store; see SetReceiptStore.
+	Queue               bool             // Submissions are queued during gateway incidents: a degradation policy is set and there is a receipt store to queue in; see SetDegradationPolicy.
+	Maintenance         bool             // Maintenance windows are set; see SetMaintenanceWindows.
+	Metrics             bool             // Outcomes are sampled for Stats and WriteSLAMetrics; see SetSLATracking.
+	OutcomeCache        bool             // Final outcomes are cached; see SetOutcomeCache.
+	EventJournal        bool             // State changes are journaled; see SetEventJournal.
+	NonceStore          bool             // Nonces are persisted; see SetNonceStore.
+	Dedup               bool             // Identical data is certified once; see SetDedupStore.
+	AccessLog           bool             // Reads of certified data are recorded; see SetAccessLog.
+	RateLimiter         bool             // Gateway calls share a rate limit with other accounts; see SetRateLimiter.
+	AdaptivePolling     bool             // Outcome polling adapts its interval; see SetAdaptivePolling.
+	AdaptiveConcurrency bool             // Batch operations bound their calls in flight; see SetAdaptiveConcurrency.
+	NodeRotation        bool             // The account rotates away from failing gateway nodes; see SetNodeRotation.
+	ConfirmationWatch   bool             // Confirmed transactions are re-checked; see WatchConfirmations.
+	Features            map[Feature]bool // Whether each experimental feature is enabled; see FeatureEnabled.
+}
+
+// Capabilities returns which optional subsystems of the account are configured, with
+// experimental features counted where they enable a subsystem.
+//
+// Returns:
+//
+//	A snapshot of the account's configuration, safe to retain.
+func (a *CEPAccount) Capabilities() Capabilities {
+	v := a.view()
+	caps := Capabilities{
+		Network:         v.NAGURL != "" || v.node != nil,
+		Signer:          v.signer != nil,
+		Receipts:        v.receipts != nil,
+		RateLimiter:     v.shared != nil,
+		AdaptivePolling: v.adaptive != nil,
+		Features:        make(map[Feature]bool, len(knownFeatures)),
+	}
+	for _, feature := range knownFeatures {
+		caps.Features[feature] = a.FeatureEnabled(feature)
+	}
+
+	a.degrade.mu.Lock()
+	caps.Queue = a.degrade.policy != nil && caps.Receipts
+	a.degrade.mu.Unlock()
+	a.maint.mu.Lock()
+	caps.Maintenance = len(a.maint.windows) > 0
+	a.maint.mu.Unlock()
+	a.sla.mu.Lock()
+	caps.Metrics = a.sla.cfg != nil
+	a.sla.mu.Unlock()
+	a.outcomes.mu.Lock()
+	caps.OutcomeCache = a.outcomes.size > 0
+	a.outcomes.mu.Unlock()
+	a.events.mu.Lock()
+	caps.EventJournal = a.events.size >= 0
+	a.events.mu.Unlock()
+	docs, _ := a.nstore.get()
+	caps.NonceStore = docs != nil
+	caps.Dedup = a.dedup.store() != nil
+	a.access.mu.Lock()
+	caps.AccessLog = a.access.docs != nil
+	a.access.mu.Unlock()
+	a.concurrency.mu.Lock()
+	caps.AdaptiveConcurrency = a.concurrency.current(caps.Features[FeatureAdaptiveConcurrency]) != nil
+	a.concurrency.mu.Unlock()
+	a.nodes.mu.Lock()
+	caps.NodeRotation = a.nodes.policy != nil || caps.Features[FeatureNodeRotation]
+	a.nodes.mu.Unlock()
+	a.rechecks.mu.Lock()
+	caps.ConfirmationWatch = a.rechecks.watching
+	a.rechecks.mu.Unlock()
+	return caps
+}
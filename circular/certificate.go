@@ -1,9 +1,9 @@
-package circular_enterprise_apis
+package circular
 
 import (
 	"encoding/json"
 
-	"circular_enterprise_apis/pkg/utils"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
 )
 
 // CCertificate represents a data structure for a Circular Protocol certificate.
@@ -42,7 +42,7 @@ func NewCCertificate() *CCertificate {
 // Parameters:
 //   - data: The string content to be set as the certificate's data.
 func (c *CCertificate) SetData(data string) {
-	c.Data = utils.StringToHex(data)
+	c.Data = helpers.StringToHex(data)
 }
 
 // GetData retrieves the primary data content from the certificate.
@@ -55,7 +55,7 @@ func (c *CCertificate) SetData(data string) {
 //
 //	The original string representation of the certificate's data.
 func (c *CCertificate) GetData() string {
-	return utils.HexToString(c.Data)
+	return helpers.HexToString(c.Data)
 }
 
 // GetJSONCertificate serializes the entire CCertificate object into a JSON string.
@@ -0,0 +1,299 @@
+// Package certtemplate provides reusable certificate templates.
+// A Template declares the fields a certificate of a given shape carries, their types,
+// defaults and which metadata keys are mandatory; Instantiate validates structured input
+// against it and produces a ready-to-submit CCertificate with a deterministic JSON body.
+package certtemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+// FieldType is the JSON type a template field accepts.
+type FieldType string
+
+// Supported field types.
+const (
+	String  FieldType = "string"
+	Number  FieldType = "number"
+	Boolean FieldType = "boolean"
+	Object  FieldType = "object"
+	Array   FieldType = "array"
+)
+
+// Field describes a single field of a certificate template.
+type Field struct {
+	Name     string      `json:"name"`              // The key of the field in the certificate body.
+	Type     FieldType   `json:"type"`              // The JSON type the value must have.
+	Required bool        `json:"required"`          // Whether the value must be supplied (or defaulted).
+	Default  interface{} `json:"default,omitempty"` // The value used when the field is not supplied.
+}
+
+// Template is a reusable definition of a certificate shape.
+type Template struct {
+	Name             string            `json:"name"`             // Unique name of the template, recorded in every certificate.
+	Version          string            `json:"version"`          // Version of the template definition, recorded in every certificate.
+	Fields           []Field           `json:"fields"`           // The fields certificates of this template carry.
+	Metadata         map[string]string `json:"metadata"`         // Fixed metadata merged into every certificate.
+	RequiredMetadata []string          `json:"requiredMetadata"` // Metadata keys the caller must supply on instantiation.
+}
+
+// Document is the JSON body of a certificate produced from a template.
+type Document struct {
+	Template string                 `json:"template"`
+	Version  string                 `json:"version"`
+	Fields   map[string]interface{} `json:"fields"`
+	Metadata map[string]string      `json:"metadata,omitempty"`
+}
+
+// ValidationError lists every problem found while instantiating a template.
+type ValidationError struct {
+	Template string   // The name of the template being instantiated.
+	Problems []string // One entry per invalid, missing or unknown field or metadata key.
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid input for template %s: %s", e.Template, strings.Join(e.Problems, "; "))
+}
+
+// Parse decodes a template definition from JSON and checks that it is well formed.
+//
+// Parameters:
+//   - data: The JSON template definition.
+//
+// Returns:
+//
+//	The parsed Template, or an error if the definition is malformed.
+func Parse(data []byte) (*Template, error) {
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := t.Check(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Check verifies that the template definition itself is consistent: it has a name,
+// field names are unique and non-empty, types are supported and defaults match their types.
+//
+// Returns:
+//
+//	An error describing the first inconsistency, or nil.
+func (t *Template) Check() error {
+	if t.Name == "" {
+		return fmt.Errorf("template name cannot be empty")
+	}
+	seen := make(map[string]bool)
+	for _, f := range t.Fields {
+		if f.Name == "" {
+			return fmt.Errorf("template %s: field name cannot be empty", t.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("template %s: duplicate field %q", t.Name, f.Name)
+		}
+		seen[f.Name] = true
+		switch f.Type {
+		case String, Number, Boolean, Object, Array:
+		default:
+			return fmt.Errorf("template %s: field %q has unsupported type %q", t.Name, f.Name, f.Type)
+		}
+		if f.Default != nil && !matchesType(f.Type, normalize(f.Default)) {
+			return fmt.Errorf("template %s: default for field %q is not of type %s", t.Name, f.Name, f.Type)
+		}
+	}
+	return nil
+}
+
+// Render validates input and metadata against the template and returns the
+// certificate body as JSON. Keys are emitted in sorted order, so identical input
+// always produces identical bytes.
+//
+// Parameters:
+//   - input: Field values keyed by field name.
+//   - metadata: Per-certificate metadata; merged over the template's fixed metadata.
+//
+// Returns:
+//
+//	The JSON document, or a *ValidationError listing every problem with the input.
+func (t *Template) Render(input map[string]interface{}, metadata map[string]string) (string, error) {
+	var problems []string
+	fields := make(map[string]interface{}, len(t.Fields))
+	known := make(map[string]bool, len(t.Fields))
+
+	for _, f := range t.Fields {
+		known[f.Name] = true
+		value, ok := input[f.Name]
+		if !ok || value == nil {
+			if f.Default != nil {
+				fields[f.Name] = f.Default
+			} else if f.Required {
+				problems = append(problems, fmt.Sprintf("missing required field %q", f.Name))
+			}
+			continue
+		}
+		if !matchesType(f.Type, normalize(value)) {
+			problems = append(problems, fmt.Sprintf("field %q must be of type %s", f.Name, f.Type))
+			continue
+		}
+		fields[f.Name] = value
+	}
+
+	unknown := make([]string, 0)
+	for name := range input {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown field %q", name))
+	}
+
+	mergedMetadata := make(map[string]string, len(t.Metadata)+len(metadata))
+	for k, v := range t.Metadata {
+		mergedMetadata[k] = v
+	}
+	for k, v := range metadata {
+		mergedMetadata[k] = v
+	}
+	for _, key := range t.RequiredMetadata {
+		if mergedMetadata[key] == "" {
+			problems = append(problems, fmt.Sprintf("missing required metadata %q", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return "", &ValidationError{Template: t.Name, Problems: problems}
+	}
+
+	doc := Document{Template: t.Name, Version: t.Version, Fields: fields}
+	if len(mergedMetadata) > 0 {
+		doc.Metadata = mergedMetadata
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal certificate document: %w", err)
+	}
+	return string(jsonBytes), nil
+}
+
+// Instantiate renders the template with the given input and wraps the result in a
+// CCertificate, ready to be submitted with CEPAccount.SubmitCertificate(cert.GetData(), key).
+//
+// Parameters:
+//   - input: Field values keyed by field name.
+//   - metadata: Per-certificate metadata; merged over the template's fixed metadata.
+//
+// Returns:
+//
+//	The certificate, or a *ValidationError listing every problem with the input.
+func (t *Template) Instantiate(input map[string]interface{}, metadata map[string]string) (*circular.CCertificate, error) {
+	data, err := t.Render(input, metadata)
+	if err != nil {
+		return nil, err
+	}
+	cert := circular.NewCCertificate()
+	cert.SetData(data)
+	return cert, nil
+}
+
+// Registry is a concurrency-safe collection of templates keyed by name.
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]*Template
+}
+
+// NewRegistry creates an empty template Registry.
+func NewRegistry() *Registry {
+	return &Registry{templates: make(map[string]*Template)}
+}
+
+// Register adds t to the registry, replacing any template with the same name.
+//
+// Returns:
+//
+//	An error if the template definition is inconsistent.
+func (r *Registry) Register(t *Template) error {
+	if err := t.Check(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[t.Name] = t
+	return nil
+}
+
+// Get returns the template registered under name.
+func (r *Registry) Get(name string) (*Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.templates[name]
+	return t, ok
+}
+
+// Instantiate looks up the named template and instantiates it.
+//
+// Returns:
+//
+//	The certificate, or an error if no such template exists or the input is invalid.
+func (r *Registry) Instantiate(name string, input map[string]interface{}, metadata map[string]string) (*circular.CCertificate, error) {
+	t, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown certificate template %q", name)
+	}
+	return t.Instantiate(input, metadata)
+}
+
+// normalize converts Go numeric types to float64 so values supplied programmatically
+// are type-checked the same way as values decoded from JSON.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// matchesType reports whether a normalized value has the given field type.
+func matchesType(t FieldType, value interface{}) bool {
+	switch t {
+	case String:
+		_, ok := value.(string)
+		return ok
+	case Number:
+		_, ok := value.(float64)
+		return ok
+	case Boolean:
+		_, ok := value.(bool)
+		return ok
+	case Object:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case Array:
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return false
+}
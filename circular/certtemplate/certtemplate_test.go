@@ -0,0 +1,78 @@
+package certtemplate
+
+import (
+	"errors"
+	"testing"
+)
+
+const deliveryTemplate = `{
+	"name": "delivery-receipt",
+	"version": "1",
+	"fields": [
+		{"name": "orderId", "type": "string", "required": true},
+		{"name": "quantity", "type": "number", "required": true},
+		{"name": "signedOff", "type": "boolean", "default": false}
+	],
+	"metadata": {"issuer": "ops"},
+	"requiredMetadata": ["site"]
+}`
+
+func TestParse(t *testing.T) {
+	if _, err := Parse([]byte(deliveryTemplate)); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	invalid := []string{
+		`{"fields": []}`,
+		`{"name": "x", "fields": [{"name": "a", "type": "string"}, {"name": "a", "type": "string"}]}`,
+		`{"name": "x", "fields": [{"name": "a", "type": "date"}]}`,
+		`{"name": "x", "fields": [{"name": "a", "type": "number", "default": "one"}]}`,
+	}
+	for _, def := range invalid {
+		if _, err := Parse([]byte(def)); err == nil {
+			t.Errorf("Expected template %s to be rejected", def)
+		}
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	tmpl, _ := Parse([]byte(deliveryTemplate))
+
+	cert, err := tmpl.Instantiate(map[string]interface{}{"orderId": "A-1", "quantity": 3}, map[string]string{"site": "berlin"})
+	if err != nil {
+		t.Fatalf("Instantiate failed: %v", err)
+	}
+
+	expected := `{"template":"delivery-receipt","version":"1","fields":{"orderId":"A-1","quantity":3,"signedOff":false},"metadata":{"issuer":"ops","site":"berlin"}}`
+	if cert.GetData() != expected {
+		t.Errorf("Unexpected certificate data:\n got: %s\nwant: %s", cert.GetData(), expected)
+	}
+}
+
+func TestInstantiateValidation(t *testing.T) {
+	tmpl, _ := Parse([]byte(deliveryTemplate))
+
+	_, err := tmpl.Instantiate(map[string]interface{}{"quantity": "three", "color": "red"}, nil)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got: %v", err)
+	}
+	if len(validationErr.Problems) != 4 {
+		t.Errorf("Expected 4 problems (missing orderId, bad quantity, unknown color, missing site), got: %v", validationErr.Problems)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	tmpl, _ := Parse([]byte(deliveryTemplate))
+	if err := registry.Register(tmpl); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, err := registry.Instantiate("delivery-receipt", map[string]interface{}{"orderId": "A", "quantity": 1}, map[string]string{"site": "x"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := registry.Instantiate("missing", nil, nil); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+}
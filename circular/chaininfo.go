@@ -0,0 +1,134 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ChainInfo describes a blockchain as advertised by the gateway.
+type ChainInfo struct {
+	ID               string                 `json:"ID"`               // The blockchain identifier, without "0x" prefix.
+	Name             string                 `json:"Name"`             // The human-readable chain name.
+	Height           int64                  `json:"Height"`           // The current block height.
+	TransactionTypes []string               `json:"TransactionTypes"` // Transaction types the chain accepts; empty if unrestricted.
+	Parameters       map[string]interface{} `json:"Parameters"`       // Chain-specific parameters, e.g. fees and block time.
+}
+
+// SupportsTransactionType reports whether the chain accepts transactions of type txType.
+func (c *ChainInfo) SupportsTransactionType(txType string) bool {
+	if len(c.TransactionTypes) == 0 {
+		return true
+	}
+	for _, supported := range c.TransactionTypes {
+		if supported == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChainInfo fetches the metadata of chainID from the gateway.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - chainID: The blockchain to describe.
+//
+// Returns:
+//
+//	The chain's metadata, or an error if the request fails or the gateway does not know
+//	the chain.
+func (a *CEPAccount) GetChainInfo(ctx context.Context, chainID string) (*ChainInfo, error) {
+	requestData := map[string]string{
+		"Blockchain": helpers.HexFix(chainID),
+		"Version":    a.view().CodeVersion,
+	}
+
+	resp, err := a.postNAG(ensureRequestID(ctx), "Circular_GetBlockchain_", requestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain info: %w", err)
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+
+	info := &ChainInfo{}
+	if err := json.Unmarshal(resp.Response, info); err != nil {
+		return nil, fmt.Errorf("unexpected chain info format: %w", err)
+	}
+	if info.ID == "" {
+		info.ID = helpers.HexFix(chainID)
+	}
+	return info, nil
+}
+
+// SetStrictChains makes the account check each blockchain against the gateway before
+// first submitting to it: the chain must be known to the gateway and accept the
+// transaction type. The chain's metadata is cached after the first check. Strict mode
+// turns a misconfigured chain ID into an immediate, descriptive error instead of a
+// rejected transaction.
+//
+// Parameters:
+//   - enabled: Whether to validate chains.
+func (a *CEPAccount) SetStrictChains(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.chainCheck = enabled
+}
+
+// ValidateChains checks every configured chain ID against the gateway, for use at
+// startup. It checks the account's Blockchain when no IDs are given.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - chainIDs: The blockchains the application is configured to use.
+//
+// Returns:
+//
+//	An error naming the first chain that is unknown to the gateway or does not accept
+//	certificates.
+func (a *CEPAccount) ValidateChains(ctx context.Context, chainIDs ...string) error {
+	if len(chainIDs) == 0 {
+		chainIDs = []string{a.view().Blockchain}
+	}
+	for _, chainID := range chainIDs {
+		if err := a.validateChain(ctx, chainID, certificateTxType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateChain checks chainID against its cached or freshly fetched metadata.
+func (a *CEPAccount) validateChain(ctx context.Context, chainID string, txType string) error {
+	info := a.chains.info(chainID)
+	if info == nil {
+		fetched, err := a.GetChainInfo(ctx, chainID)
+		if err != nil {
+			return fmt.Errorf("chain %s failed validation: %w", chainID, err)
+		}
+		info = fetched
+		a.chains.setInfo(chainID, info)
+	}
+	if !info.SupportsTransactionType(txType) {
+		return fmt.Errorf("chain %s (%s) does not accept %s transactions", chainID, info.Name, txType)
+	}
+	return nil
+}
+
+func (r *chainRegistry) info(chainID string) *ChainInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.infos[helpers.HexFix(chainID)]
+}
+
+func (r *chainRegistry) setInfo(chainID string, info *ChainInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.infos == nil {
+		r.infos = make(map[string]*ChainInfo)
+	}
+	r.infos[helpers.HexFix(chainID)] = info
+}
@@ -0,0 +1,66 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStrictChainsRejectsUnknownChain(t *testing.T) {
+	var infoCalls, submissions atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_GetBlockchain_") {
+			infoCalls.Add(1)
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["Blockchain"] != "aa" {
+				fmt.Fprint(w, `{"Result":114,"Response":"Invalid Blockchain"}`)
+				return
+			}
+			fmt.Fprint(w, `{"Result":200,"Response":{"Name":"Test Chain","Height":42,"TransactionTypes":["C_TYPE_CERTIFICATE"]}}`)
+			return
+		}
+		submissions.Add(1)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetBlockchain("0xaa")
+	acc.SetStrictChains(true)
+
+	info, err := acc.GetChainInfo(t.Context(), "0xaa")
+	if err != nil || info.Name != "Test Chain" || info.Height != 42 || info.ID != "aa" {
+		t.Fatalf("GetChainInfo() = %+v, %v", info, err)
+	}
+
+	acc.SubmitCertificate("data", testPrivateKey)
+	acc.SubmitCertificate("data", testPrivateKey)
+	if acc.GetLastError() != "" {
+		t.Fatalf("Unexpected error: %s", acc.GetLastError())
+	}
+	if infoCalls.Load() != 2 || submissions.Load() != 2 {
+		t.Errorf("Expected chain info to be fetched once for validation, got %d fetches and %d submissions", infoCalls.Load(), submissions.Load())
+	}
+
+	if err := acc.ValidateChains(t.Context(), "0xaa", "0xbb"); err == nil || !strings.Contains(err.Error(), "0xbb") {
+		t.Errorf("Expected chain 0xbb to fail validation, got %v", err)
+	}
+}
+
+func TestChainInfoSupportsTransactionType(t *testing.T) {
+	info := &ChainInfo{}
+	if !info.SupportsTransactionType(certificateTxType) {
+		t.Error("Expected a chain without restrictions to accept any type")
+	}
+	info.TransactionTypes = []string{"C_TYPE_TOKEN"}
+	if info.SupportsTransactionType(certificateTxType) {
+		t.Error("Expected a restricted chain to reject certificates")
+	}
+}
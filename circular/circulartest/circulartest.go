@@ -0,0 +1,45 @@
+// Package circulartest provides helpers for testing code that uses the circular
+// package, so that tests need not reassign the package's defaults.
+package circulartest
+
+import (
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/internal/defaults"
+)
+
+// OverrideNetworkDiscoveryURL makes circular.NetworkDiscoveryURL, and with it network
+// discovery by GetNAG and SetNetwork, use url until the test and its subtests finish.
+// Tests that override the URL must not run in parallel with each other.
+//
+// Parameters:
+//   - t: The test the override is scoped to.
+//   - url: The discovery endpoint; the network name is appended to it.
+func OverrideNetworkDiscoveryURL(t testing.TB, url string) {
+	t.Helper()
+	t.Cleanup(defaults.SetNetworkURL(url))
+}
+
+// OverrideDefaultNAG makes circular.DefaultNAGURL, and with it the gateway of accounts
+// created by NewCEPAccount and NewAccount, use url until the test and its subtests
+// finish. Tests that override it must not run in parallel with each other.
+//
+// Parameters:
+//   - t: The test the override is scoped to.
+//   - url: The gateway URL, ending in "?cep=" like circular.DefaultNAG.
+func OverrideDefaultNAG(t testing.TB, url string) {
+	t.Helper()
+	t.Cleanup(defaults.SetNAGURL(url))
+}
+
+// OverrideDefaultChain makes circular.DefaultBlockchain, and with it the blockchain of
+// accounts created by NewCEPAccount and NewAccount, return chain until the test and its
+// subtests finish. Tests that override it must not run in parallel with each other.
+//
+// Parameters:
+//   - t: The test the override is scoped to.
+//   - chain: The blockchain ID.
+func OverrideDefaultChain(t testing.TB, chain string) {
+	t.Helper()
+	t.Cleanup(defaults.SetChain(chain))
+}
@@ -0,0 +1,106 @@
+package circulartest
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// FakeClock is a virtual clock for tests of outcome polling and degraded mode, installed
+// with CEPAccount.SetClock. Its time stands still until the test moves it with Advance,
+// which fires the timers that fall due. It is safe for concurrent use.
+//
+// A typical test waits for the code under test to start a timer, then advances past it:
+//
+//	clock := circulartest.NewFakeClock(time.Now())
+//	acc.SetClock(clock)
+//	go acc.WaitForOutcome(ctx, txID)
+//	clock.BlockUntil(1)
+//	clock.Advance(time.Duration(acc.IntervalSec) * time.Second)
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []fakeTimer
+}
+
+// fakeTimer is a channel returned by FakeClock.After, waiting for its time.
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock creates a FakeClock reading start.
+//
+// Parameters:
+//   - start: The clock's initial time.
+//
+// Returns:
+//
+//	The clock.
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the clock's time once Advance has moved it d
+// past the current time. A d of zero or less fires at once.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), c: ch})
+	c.changed.Broadcast()
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the timers that fall due, earliest
+// first.
+//
+// Parameters:
+//   - d: How far to move the clock; it must not be negative.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	var due []fakeTimer
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			due = append(due, timer)
+		}
+	}
+	c.timers = pending
+	slices.SortFunc(due, func(x, y fakeTimer) int { return x.at.Compare(y.at) })
+	for _, timer := range due {
+		timer.c <- c.now
+	}
+	c.changed.Broadcast()
+}
+
+// BlockUntil waits until at least n timers are pending, that is, started with After and
+// not yet fired, so that a following Advance reaches the code waiting on them.
+//
+// Parameters:
+//   - n: The number of pending timers to wait for.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
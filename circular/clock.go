@@ -0,0 +1,44 @@
+package circular
+
+import "time"
+
+// Clock is the source of time for outcome polling, maintenance windows and the
+// degraded-mode queue, so that tests can run them against virtual time rather than
+// sleeping through real polling intervals; see SetClock and circulartest.FakeClock. Context deadlines, including the
+// account's Deadlines, always run on real time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives the current time once d has elapsed, like
+	// time.After. A d of zero or less fires at once.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock of the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SetClock sets the clock the account measures time with when polling for outcomes,
+// timing its polling interval and not-found window, checking its maintenance windows,
+// when queueing transactions and tracking its error rate in degraded mode, and when
+// stamping transactions and key rotations, so that a FakeClock makes envelopes
+// reproducible. Passing nil restores the system clock.
+//
+// Parameters:
+//   - clock: The time source.
+func (a *CEPAccount) SetClock(clock Clock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clock = clock
+}
+
+// timeSource returns the clock set with SetClock, or the system clock.
+func (v accountView) timeSource() Clock {
+	if v.clock != nil {
+		return v.clock
+	}
+	return systemClock{}
+}
@@ -0,0 +1,64 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+func TestSubmitAndWaitOnFakeClock(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		case polls.Add(1) < 3:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Pending"}}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetLogLevel(LogSilent)
+	clock := circulartest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	acc.SetClock(clock)
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	type outcome struct {
+		result *SubmitResult
+		err    error
+	}
+	done := make(chan outcome)
+	go func() {
+		result, err := acc.SubmitAndWait(t.Context(), "data", signer)
+		done <- outcome{result, err}
+	}()
+	// Three polls at the default interval of 2s take 6s of virtual time and none of real.
+	for range 3 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Duration(acc.IntervalSec) * time.Second)
+	}
+	got := <-done
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+	if stats := got.result.Stats; stats.Attempts != 3 || stats.TotalWait != 6*time.Second || !stats.Finalized {
+		t.Errorf("Expected three polls over 6s, got %+v", stats)
+	}
+
+	// Without a clock the account runs on real time again.
+	acc.SetClock(nil)
+	if _, ok := acc.view().timeSource().(systemClock); !ok {
+		t.Error("Expected the system clock to be restored")
+	}
+}
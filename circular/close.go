@@ -0,0 +1,67 @@
+package circular
+
+import (
+	"errors"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ErrAccountClosed is returned by outcome polls stopped because their account was closed.
+var ErrAccountClosed = newError(CodeAccountNotOpen, "account was closed")
+
+// defaultIntervalSec is the outcome polling interval of a new or closed account.
+const defaultIntervalSec = 2
+
+// CloseOption customizes Close.
+type CloseOption func(*closeConfig)
+
+type closeConfig struct {
+	zeroize bool
+}
+
+// WithZeroizeKeys makes Close wipe the key material of the account's signer, if the
+// signer implements Zeroizer, as PrivateKeySigner does. The signer is unusable
+// afterwards, also through any other reference to it, so this suits processes that
+// are shutting down or handing the key back to a keystore.
+func WithZeroizeKeys() CloseOption {
+	return func(c *closeConfig) { c.zeroize = true }
+}
+
+// cleared reports whether the account holds only what Close leaves, so that closing it
+// again has nothing to do; a.mu must be held.
+func (a *CEPAccount) cleared() bool {
+	return a.Address == "" && a.PublicKey == "" && a.signer == nil && a.Info == nil &&
+		a.NAGURL == "" && a.NetworkNode == "" && a.Blockchain == "" && a.LatestTxID == "" &&
+		a.Nonce == 0 && a.IntervalSec == defaultIntervalSec && a.permissions == nil &&
+		a.nagPath == "" && a.nagProto == "" && a.node == nil
+}
+
+// persistOnClose saves the nonces of the account in v, on its blockchain and on every
+// chain used with SubmitCertificateOn, to the nonce store, if one is set, so that the
+// next process can adopt them with RestoreNonce.
+//
+// Returns:
+//
+//	The failures to save, joined, or nil.
+func (a *CEPAccount) persistOnClose(v accountView) error {
+	docs, _ := a.nstore.get()
+	if docs == nil || v.Address == "" {
+		return nil
+	}
+	var errs []error
+	nonces := map[string]int64{}
+	if v.Blockchain != "" && v.Nonce > 0 {
+		nonces[helpers.HexFix(v.Blockchain)] = v.Nonce
+	}
+	for chainID, state := range a.chains.loaded() {
+		if _, ok := nonces[chainID]; !ok && state.Nonce > 0 {
+			nonces[chainID] = state.Nonce
+		}
+	}
+	for chain, nonce := range nonces {
+		if err := saveStoredNonce(docs, v.Address, chain, nonce); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestCloseStopsWatchersAndSavesNonce(t *testing.T) {
+	server := newReceiptTestServer()
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	docs := storage.NewDocumentStore(storage.NewMemory())
+	acc.SetNonceStore(docs, time.Hour)
+	acc.SubmitCertificate("data", testPrivateKey)
+	txID := acc.LatestTxID
+	// A nonce left unsaved, as when persisting it failed, is saved by Close.
+	acc.Nonce = 7
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := acc.WaitForOutcome(context.Background(), txID)
+		done <- err
+	}()
+	waitForWatchers(t, acc, 1)
+
+	if err := acc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrAccountClosed) {
+			t.Errorf("Expected ErrAccountClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the poll to stop when the account was closed")
+	}
+	if stored, err := loadStoredNonce(docs, "0xabcdef", DefaultChain); err != nil || stored == nil || stored.Nonce != 7 {
+		t.Errorf("Expected nonce 7 to be saved, got %+v, %v", stored, err)
+	}
+	events, _ := acc.Events()
+	if err := acc.Close(); err != nil {
+		t.Errorf("Expected closing a closed account to do nothing, got %v", err)
+	}
+	if again, _ := acc.Events(); len(again) != len(events) {
+		t.Errorf("Expected closing a closed account to record no event, got %+v", again[len(events):])
+	}
+
+	// A reopened account starts from the cleared state with working defaults.
+	if !acc.Open("0xabcdef") || acc.Lifecycle() != LifecycleOpen || acc.IntervalSec != defaultIntervalSec {
+		t.Errorf("Expected the account to reopen, got %s with interval %d", acc.Lifecycle(), acc.IntervalSec)
+	}
+}
+
+func TestCloseErrors(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	acc.SetNonceStore(failingDocs{}, time.Hour)
+	acc.Nonce = 3
+	if err := acc.Close(); err == nil {
+		t.Error("Expected the failure to save the nonce to be returned")
+	}
+	if acc.Lifecycle() != LifecycleClosed {
+		t.Errorf("Expected the account to be closed regardless, got %s", acc.Lifecycle())
+	}
+}
+
+func TestCloseZeroizesKeys(t *testing.T) {
+	keep, _ := NewPrivateKeySigner(testPrivateKey)
+	acc, _ := NewAccount(WithSigner(keep))
+	acc.Close()
+	if _, err := keep.Sign("message"); err != nil {
+		t.Errorf("Expected Close without options to leave the signer usable, got %v", err)
+	}
+
+	wiped, _ := NewPrivateKeySigner(testPrivateKey)
+	acc, _ = NewAccount(WithSigner(wiped))
+	acc.Close(WithZeroizeKeys())
+	if _, err := wiped.Sign("message"); !errors.Is(err, ErrKeyZeroized) || wiped.PublicKey() != "" {
+		t.Errorf("Expected the signer to be zeroized, got %v", err)
+	}
+	wiped.Zeroize() // Zeroizing twice is harmless.
+}
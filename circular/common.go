@@ -0,0 +1,176 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lessuselesss/go-enterprise-apis/internal/defaults"
+)
+
+// httpClient is the default HTTP client used for making network requests within the Circular Enterprise APIs.
+// It is configured with standard settings and is utilized by functions that communicate with external services,
+// such as the Network Access Gateway (NAG) for network discovery and transaction submission.
+var httpClient *http.Client = http.DefaultClient
+
+// Constants define fundamental parameters and metadata for the Circular Enterprise APIs.
+const (
+	// LibVersion specifies the current semantic version of the Go client library.
+	// This version is included in various API requests to ensure compatibility
+	// and for tracking purposes on the Circular Protocol network.
+	LibVersion = "1.0.13"
+
+	// DefaultChain represents the blockchain identifier for the default public network.
+	// This hexadecimal string uniquely identifies the primary blockchain that the
+	// Circular Enterprise APIs will interact with by default, unless explicitly overridden.
+	DefaultChain = "0x8a20baa40c45dc5055aeb26197c203e576ef389d9acb171bd62da11dc5ad72b2"
+
+	// DefaultNAG is the base URL for the default public Network Access Gateway (NAG).
+	// The NAG serves as the primary entry point for client applications to interact
+	// with the Circular Protocol network, facilitating operations like transaction
+	// submission and account nonce retrieval.
+	DefaultNAG = "https://nag.circularlabs.io/NAG.php?cep="
+)
+
+// DefaultNetworkURL is the base endpoint used for discovering and resolving the
+// appropriate Network Access Gateway (NAG) for a given network. This URL points to a
+// service that provides the specific NAG endpoint based on the network identifier provided.
+const DefaultNetworkURL = "https://circularlabs.io/network/getNAG?network="
+
+// NetworkDiscoveryURL returns the endpoint GetNAG queries to discover a network's NAG:
+// DefaultNetworkURL, unless a test has overridden it with circulartest.
+//
+// Returns:
+//
+//	The discovery endpoint; the network name is appended to it.
+func NetworkDiscoveryURL() string {
+	if url, ok := defaults.NetworkURL(); ok {
+		return url
+	}
+	return DefaultNetworkURL
+}
+
+// DefaultNAGURL returns the gateway new accounts are created with: DefaultNAG, unless a
+// test has overridden it with circulartest.
+//
+// Returns:
+//
+//	The NAG URL.
+func DefaultNAGURL() string {
+	if url, ok := defaults.NAGURL(); ok {
+		return url
+	}
+	return DefaultNAG
+}
+
+// DefaultBlockchain returns the blockchain new accounts are created with: DefaultChain,
+// unless a test has overridden it with circulartest.
+//
+// Returns:
+//
+//	The blockchain ID.
+func DefaultBlockchain() string {
+	if chain, ok := defaults.Chain(); ok {
+		return chain
+	}
+	return DefaultChain
+}
+
+// GetNAG is a utility function responsible for discovering the Network Access Gateway (NAG) URL
+// for a specified network. It performs an HTTP GET request to the NetworkDiscoveryURL endpoint,
+// appending the `network` identifier as a query parameter. The request is bounded by the
+// Discovery deadline of DefaultDeadlines.
+//
+// Parameters:
+//   - network: A string identifier for the desired network (e.g., "testnet", "mainnet").
+//
+// Returns:
+//   - The resolved NAG URL as a string if the request is successful and a valid URL is returned.
+//   - An error if the network identifier is empty, the HTTP request fails, the network
+//     discovery service returns a non-OK status, or the response cannot be parsed
+//     or indicates an error.
+func GetNAG(network string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDeadlines().Discovery)
+	defer cancel()
+	return getNAG(ctx, network)
+}
+
+// getNAG is GetNAG with its request bounded by ctx.
+func getNAG(ctx context.Context, network string) (string, error) {
+	d, err := discoverNetwork(ctx, network)
+	if err != nil {
+		return "", err
+	}
+	return d.URL, nil
+}
+
+// discovery is the discovery service's answer for a network.
+type discovery struct {
+	URL   string        // The NAG URL to use.
+	Nodes []GatewayNode // Every node serving the network; empty if the service does not list them.
+}
+
+// discoverNetwork queries the discovery service for network, with its request bounded
+// by ctx.
+func discoverNetwork(ctx context.Context, network string) (*discovery, error) {
+	if network == "" {
+		return nil, fmt.Errorf("network identifier cannot be empty")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", NetworkDiscoveryURL()+network, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch NAG URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("network discovery failed with status: %s", resp.Status)
+	}
+
+	// The response is expected to be a JSON object like {"status":"success", "url":"..."},
+	// optionally listing the network's nodes as "nodes":[{"id":"...","url":"..."}].
+	var nagResponse struct {
+		Status  string `json:"status"`
+		URL     string `json:"url"`
+		Message string `json:"message"`
+		Nodes   []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"nodes"`
+	}
+
+	// Discovery runs before there is an account to configure, so the default limit applies.
+	if err := json.NewDecoder(&limitedBody{r: resp.Body, n: DefaultMaxResponseSize}).Decode(&nagResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal NAG response: %w", err)
+	}
+
+	fmt.Printf("NAG Response Status: %s\n", nagResponse.Status)
+	fmt.Printf("NAG Response Message: %s\n", nagResponse.Message)
+
+	if nagResponse.Status == "error" {
+		return nil, fmt.Errorf("failed to get valid NAG URL from response: %s", nagResponse.Message)
+	}
+
+	if nagResponse.Status != "success" || nagResponse.URL == "" {
+		return nil, fmt.Errorf("failed to get valid NAG URL from response: %s", nagResponse.Message)
+	}
+
+	d := &discovery{URL: nagResponse.URL}
+	for _, node := range nagResponse.Nodes {
+		if node.URL == "" {
+			continue
+		}
+		if node.ID == "" {
+			node.ID = node.URL
+		}
+		d.Nodes = append(d.Nodes, GatewayNode{ID: node.ID, URL: node.URL})
+	}
+	return d, nil
+}
@@ -1,18 +1,18 @@
-package circular_enterprise_apis
+package circular
 
 import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
 )
 
 func TestGetNAG(t *testing.T) {
 	// Save original values to restore after tests
-	originalNetworkURL := NetworkURL
 	originalHTTPClient := httpClient
 	defer func() {
-		NetworkURL = originalNetworkURL
 		httpClient = originalHTTPClient
 	}()
 
@@ -86,8 +86,8 @@ func TestGetNAG(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Set the NetworkURL to the mock server's URL
-			NetworkURL = server.URL + "/getNAG?network="
+			// Point network discovery at the mock server
+			circulartest.OverrideNetworkDiscoveryURL(t, server.URL+"/getNAG?network=")
 			httpClient = server.Client() // Use the mock server's client
 
 			url, err := GetNAG(tt.network)
@@ -109,4 +109,36 @@ func TestGetNAG(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestNetworkDiscoveryURLOverride(t *testing.T) {
+	if got := NetworkDiscoveryURL(); got != DefaultNetworkURL {
+		t.Fatalf("NetworkDiscoveryURL() = %q, want %q", got, DefaultNetworkURL)
+	}
+	t.Run("override", func(t *testing.T) {
+		circulartest.OverrideNetworkDiscoveryURL(t, "http://localhost/getNAG?network=")
+		if got := NetworkDiscoveryURL(); got != "http://localhost/getNAG?network=" {
+			t.Errorf("NetworkDiscoveryURL() = %q, want the override", got)
+		}
+	})
+	if got := NetworkDiscoveryURL(); got != DefaultNetworkURL {
+		t.Errorf("Expected the override to be removed after the test, got %q", got)
+	}
+}
+
+func TestDefaultNAGAndChainOverride(t *testing.T) {
+	if DefaultNAGURL() != DefaultNAG || DefaultBlockchain() != DefaultChain {
+		t.Fatalf("Expected the constants without overrides, got %q and %q", DefaultNAGURL(), DefaultBlockchain())
+	}
+	t.Run("override", func(t *testing.T) {
+		circulartest.OverrideDefaultNAG(t, "http://localhost/NAG.php?cep=")
+		circulartest.OverrideDefaultChain(t, "0xb0b0")
+		acc := NewCEPAccount()
+		if acc.NAGURL != "http://localhost/NAG.php?cep=" || acc.Blockchain != "0xb0b0" {
+			t.Errorf("Expected new accounts to use the overrides, got %q and %q", acc.NAGURL, acc.Blockchain)
+		}
+	})
+	if DefaultNAGURL() != DefaultNAG || DefaultBlockchain() != DefaultChain {
+		t.Errorf("Expected the overrides to be removed after the test, got %q and %q", DefaultNAGURL(), DefaultBlockchain())
+	}
+}
@@ -0,0 +1,167 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// versionEndpoint is the NAG operation advertising the client versions a gateway accepts.
+const versionEndpoint = "Circular_GetVersion_"
+
+// VersionCheckMode selects what happens when the gateway does not accept the SDK's version.
+type VersionCheckMode int
+
+const (
+	VersionCheckOff  VersionCheckMode = iota // Do not check; the default.
+	VersionCheckWarn                         // Log a warning and continue.
+	VersionCheckFail                         // Fail every call with an *IncompatibleVersionError.
+)
+
+// GatewayVersion is the version information advertised by a gateway.
+type GatewayVersion struct {
+	Version          string `json:"Version"`          // The gateway's own version.
+	MinClientVersion string `json:"MinClientVersion"` // The oldest client version accepted; empty if unbounded.
+	MaxClientVersion string `json:"MaxClientVersion"` // The newest client version accepted; empty if unbounded.
+}
+
+// Accepts reports whether clientVersion lies within the advertised range.
+func (v *GatewayVersion) Accepts(clientVersion string) bool {
+	if v.MinClientVersion != "" && compareVersions(clientVersion, v.MinClientVersion) < 0 {
+		return false
+	}
+	if v.MaxClientVersion != "" && compareVersions(clientVersion, v.MaxClientVersion) > 0 {
+		return false
+	}
+	return true
+}
+
+// IncompatibleVersionError reports that the gateway does not accept the SDK's version.
+type IncompatibleVersionError struct {
+	ClientVersion string         // The version the account sends, i.e. its CodeVersion.
+	Gateway       GatewayVersion // What the gateway advertised.
+}
+
+func (e *IncompatibleVersionError) Error() string {
+	return fmt.Sprintf("client version %s is not supported by gateway %s (accepts %s to %s)",
+		e.ClientVersion, e.Gateway.Version, versionOrAny(e.Gateway.MinClientVersion), versionOrAny(e.Gateway.MaxClientVersion))
+}
+
+// Code returns CodeIncompatibleVersion.
+func (e *IncompatibleVersionError) Code() ErrorCode {
+	return CodeIncompatibleVersion
+}
+
+func versionOrAny(v string) string {
+	if v == "" {
+		return "any"
+	}
+	return v
+}
+
+// GetGatewayVersion fetches the version information advertised by the gateway.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//
+// Returns:
+//
+//	The gateway's version information, or an error if the request fails.
+func (a *CEPAccount) GetGatewayVersion(ctx context.Context) (*GatewayVersion, error) {
+	resp, err := a.postNAG(ensureRequestID(ctx), versionEndpoint, map[string]string{"Version": a.view().CodeVersion})
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+	version := &GatewayVersion{}
+	if err := json.Unmarshal(resp.Response, version); err != nil {
+		return nil, fmt.Errorf("unexpected gateway version format: %w", err)
+	}
+	return version, nil
+}
+
+// SetVersionCheck enables a compatibility check of the account's CodeVersion against
+// the versions advertised by the gateway, performed once before the first NAG call.
+// Changing the network with SetNetwork or SetNetworkProfile repeats the check. If the
+// gateway cannot report its versions, a warning is logged and calls proceed.
+//
+// Parameters:
+//   - mode: Whether to warn or fail when the versions are incompatible.
+func (a *CEPAccount) SetVersionCheck(mode VersionCheckMode) {
+	a.compat.mu.Lock()
+	defer a.compat.mu.Unlock()
+	a.compat.mode = mode
+	a.compat.checked = false
+	a.compat.err = nil
+}
+
+// versionChecker remembers the outcome of the compatibility check.
+type versionChecker struct {
+	mu      sync.Mutex
+	mode    VersionCheckMode
+	checked bool
+	err     error
+}
+
+// reset schedules the check to run again, e.g. after the gateway changed.
+func (c *versionChecker) reset() {
+	c.mu.Lock()
+	c.checked = false
+	c.err = nil
+	c.mu.Unlock()
+}
+
+// checkCompatibility runs the version check on first use and returns its cached result.
+func (a *CEPAccount) checkCompatibility(ctx context.Context) error {
+	a.compat.mu.Lock()
+	defer a.compat.mu.Unlock()
+	if a.compat.mode == VersionCheckOff || a.compat.checked {
+		return a.compat.err
+	}
+	a.compat.checked = true
+
+	version, err := a.GetGatewayVersion(ctx)
+	if err != nil {
+		a.logf(LogWarn, "checkCompatibility: could not determine gateway version: %v\n", err)
+		return nil
+	}
+	clientVersion := a.view().CodeVersion
+	if version.Accepts(clientVersion) {
+		return nil
+	}
+	incompatible := &IncompatibleVersionError{ClientVersion: clientVersion, Gateway: *version}
+	if a.compat.mode == VersionCheckWarn {
+		a.logf(LogWarn, "checkCompatibility: warning: %v\n", incompatible)
+		return nil
+	}
+	a.compat.err = incompatible
+	return incompatible
+}
+
+// compareVersions compares dotted numeric versions such as "1.0.13", returning -1, 0
+// or 1. Missing components count as zero and non-numeric components compare as zero.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
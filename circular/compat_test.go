@@ -0,0 +1,65 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.13", "1.0.13", 0},
+		{"1.0.9", "1.0.13", -1},
+		{"1.1", "1.0.13", 1},
+		{"v2.0.0", "2", 0},
+	}
+	for _, tc := range testCases {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestVersionCheck(t *testing.T) {
+	var versionCalls, nonceCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), versionEndpoint) {
+			versionCalls.Add(1)
+			fmt.Fprint(w, `{"Result":200,"Response":{"Version":"2.1.0","MinClientVersion":"1.1.0"}}`)
+			return
+		}
+		nonceCalls.Add(1)
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":1}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	acc.SetVersionCheck(VersionCheckWarn)
+	if !acc.UpdateAccount() {
+		t.Fatalf("Expected warn mode to proceed, got %s", acc.GetLastError())
+	}
+
+	acc.SetVersionCheck(VersionCheckFail)
+	for i := 0; i < 2; i++ {
+		if acc.UpdateAccount() {
+			t.Fatal("Expected fail mode to reject calls")
+		}
+		var incompatible *IncompatibleVersionError
+		if !errors.As(acc.LastErr(), &incompatible) || incompatible.Gateway.MinClientVersion != "1.1.0" {
+			t.Errorf("Expected an IncompatibleVersionError, got %v", acc.LastErr())
+		}
+	}
+	if versionCalls.Load() != 2 || nonceCalls.Load() != 1 {
+		t.Errorf("Expected 2 version checks and 1 nonce call, got %d and %d", versionCalls.Load(), nonceCalls.Load())
+	}
+}
@@ -0,0 +1,198 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// AdaptiveConcurrency configures the number of NAG calls the account's batch operations
+// (SubmitCertificates, GetTransactions and WaitForOutcomes) may have in flight at once,
+// adapting it to the gateway in the manner of TCP congestion control: the limit is cut
+// by Backoff when a call is throttled (HTTP 429 or a throttling Result code) or fails
+// with a 5xx status, and grows by one call for each full limit's worth of calls that
+// succeed. Each network has its own limit, since each has its own gateway. Submissions
+// within a batch stay serialized in nonce order, but share the limit with the lookups
+// and outcome polls of the account's other batches.
+type AdaptiveConcurrency struct {
+	Min      int            // The lowest limit; zero means 1.
+	Max      int            // The highest limit; zero means 16.
+	Initial  int            // The limit a network starts at; zero means half its cap.
+	Networks map[string]int // Caps replacing Max for individual networks, keyed by network name such as "mainnet".
+	Backoff  float64        // The factor the limit is multiplied by on congestion, between 0 and 1; zero means 0.5.
+}
+
+// bounds returns the lowest limit, the cap for network and the multiplicative decrease,
+// with the defaults filled in.
+func (c *AdaptiveConcurrency) bounds(network string) (lo, hi int, backoff float64) {
+	lo, hi, backoff = max(c.Min, 1), c.Max, c.Backoff
+	if hi <= 0 {
+		hi = 16
+	}
+	if limit := c.Networks[network]; limit > 0 {
+		hi = limit
+	}
+	if backoff <= 0 || backoff >= 1 {
+		backoff = 0.5
+	}
+	return lo, max(hi, lo), backoff
+}
+
+// SetAdaptiveConcurrency bounds the NAG calls the account's batch operations make at
+// once, adapting the bound to the gateway's responses; see AdaptiveConcurrency. The
+// limits learned so far are discarded. Passing nil removes the bound, so that batch
+// operations make all their calls at once, unless FeatureAdaptiveConcurrency is
+// enabled.
+//
+// Parameters:
+//   - config: The bounds of the limit, or nil.
+func (a *CEPAccount) SetAdaptiveConcurrency(config *AdaptiveConcurrency) {
+	a.concurrency.mu.Lock()
+	defer a.concurrency.mu.Unlock()
+	a.concurrency.config = config
+	a.concurrency.windows = nil
+}
+
+// ConcurrencyLimit returns the number of calls the account's batch operations may
+// currently have in flight on its network.
+//
+// Returns:
+//
+//	The current limit, or zero if the calls are not bounded.
+func (a *CEPAccount) ConcurrencyLimit() int {
+	network := a.view().NetworkNode
+	fallback := a.FeatureEnabled(FeatureAdaptiveConcurrency)
+	c := &a.concurrency
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config := c.current(fallback)
+	if config == nil {
+		return 0
+	}
+	return int(c.window(config, network).limit)
+}
+
+// concurrencyControl holds the adaptive concurrency limits of an account's batch calls.
+type concurrencyControl struct {
+	mu      sync.Mutex
+	config  *AdaptiveConcurrency
+	windows map[string]*concurrencyWindow // By network name.
+}
+
+// concurrencyWindow is the calls in flight on one network and their limit.
+type concurrencyWindow struct {
+	limit    float64
+	inFlight int
+	epoch    uint64        // Advanced on each decrease, so that one burst of congestion decreases the limit once.
+	wake     chan struct{} // Closed, and replaced, when a slot may have freed.
+}
+
+// featureConcurrency is the configuration batch calls are bounded by when
+// FeatureAdaptiveConcurrency is enabled and SetAdaptiveConcurrency has not been called.
+var featureConcurrency = &AdaptiveConcurrency{}
+
+// current returns the configuration in force, falling back to featureConcurrency if
+// fallback is set; c.mu must be held.
+func (c *concurrencyControl) current(fallback bool) *AdaptiveConcurrency {
+	if c.config == nil && fallback {
+		return featureConcurrency
+	}
+	return c.config
+}
+
+// window returns the window of network, creating it at the initial limit of config;
+// c.mu must be held.
+func (c *concurrencyControl) window(config *AdaptiveConcurrency, network string) *concurrencyWindow {
+	w, ok := c.windows[network]
+	if !ok {
+		lo, hi, _ := config.bounds(network)
+		initial := config.Initial
+		if initial <= 0 {
+			initial = hi / 2
+		}
+		w = &concurrencyWindow{limit: float64(min(max(initial, lo), hi)), wake: make(chan struct{})}
+		if c.windows == nil {
+			c.windows = make(map[string]*concurrencyWindow)
+		}
+		c.windows[network] = w
+	}
+	return w
+}
+
+// acquire blocks until a call on network fits within its limit, or ctx is done. If
+// fallback is set, calls are bounded by featureConcurrency while no limit is configured.
+//
+// Returns:
+//
+//	A function to call with whether the call met congestion once it completes, or an
+//	error if ctx is done first. Without a limit the call proceeds at once.
+func (c *concurrencyControl) acquire(ctx context.Context, network string, fallback bool) (func(congested bool), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config := c.current(fallback)
+	if config == nil {
+		return func(bool) {}, nil
+	}
+	w := c.window(config, network)
+	for w.inFlight >= int(w.limit) {
+		wake := w.wake
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			return nil, ctx.Err()
+		case <-wake:
+		}
+		c.mu.Lock()
+		if next := c.current(fallback); next != config || c.windows[network] != w {
+			// SetAdaptiveConcurrency replaced the windows while the call waited.
+			if next == nil {
+				return func(bool) {}, nil
+			}
+			config, w = next, c.window(next, network)
+		}
+	}
+	w.inFlight++
+	epoch := w.epoch
+	return func(congested bool) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		w.inFlight--
+		lo, hi, backoff := config.bounds(network)
+		switch {
+		case !congested:
+			w.limit = min(w.limit+1/w.limit, float64(hi))
+		case epoch == w.epoch:
+			w.limit = max(w.limit*backoff, float64(lo))
+			w.epoch++
+		}
+		close(w.wake)
+		w.wake = make(chan struct{})
+	}, nil
+}
+
+// batchCallKey marks the contexts of calls made by batch operations.
+type batchCallKey struct{}
+
+// withBatchCalls returns a copy of ctx whose NAG calls count against the account's
+// adaptive concurrency limit.
+func withBatchCalls(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchCallKey{}, true)
+}
+
+// batchSlot waits for room within the adaptive concurrency limit if ctx belongs to a
+// batch operation; see acquire.
+func (a *CEPAccount) batchSlot(ctx context.Context, v accountView) (func(congested bool), error) {
+	if batch, _ := ctx.Value(batchCallKey{}).(bool); !batch {
+		return func(bool) {}, nil
+	}
+	return a.concurrency.acquire(ctx, v.NetworkNode, a.FeatureEnabled(FeatureAdaptiveConcurrency))
+}
+
+// isCongestion reports whether a call's result signals an overloaded gateway: it was
+// throttled, or failed with a 5xx status.
+func isCongestion(throttled bool, err error) bool {
+	var apiErr *APIError
+	return throttled || errors.As(err, &apiErr) && apiErr.HTTPStatus >= http.StatusInternalServerError
+}
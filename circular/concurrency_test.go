@@ -0,0 +1,157 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyGateway answers transaction lookups after a delay, recording how many were
+// in flight at once, and throttles them while more than limit are; zero means never.
+type concurrencyGateway struct {
+	limit int
+
+	mu             sync.Mutex
+	inFlight, peak int
+}
+
+func (g *concurrencyGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.inFlight++
+	g.peak = max(g.peak, g.inFlight)
+	throttle := g.limit > 0 && g.inFlight > g.limit
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.inFlight--
+		g.mu.Unlock()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if throttle {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+}
+
+func TestAdaptiveConcurrencyBoundsBatchCalls(t *testing.T) {
+	gateway := &concurrencyGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	if acc.ConcurrencyLimit() != 0 {
+		t.Errorf("Expected no limit by default, got %d", acc.ConcurrencyLimit())
+	}
+	acc.SetAdaptiveConcurrency(&AdaptiveConcurrency{Max: 3, Initial: 3})
+
+	txIDs := make([]string, 12)
+	for i := range txIDs {
+		txIDs[i] = fmt.Sprintf("%04x", i)
+	}
+	if _, err := acc.GetTransactions(t.Context(), txIDs); err != nil {
+		t.Fatal(err)
+	}
+	if gateway.peak > 3 {
+		t.Errorf("Expected at most 3 lookups in flight, got %d", gateway.peak)
+	}
+	if limit := acc.ConcurrencyLimit(); limit != 3 {
+		t.Errorf("Expected the limit to stay at its cap, got %d", limit)
+	}
+
+	// Calls outside batch operations are not counted.
+	gateway.peak = 0
+	var wg sync.WaitGroup
+	for _, txID := range txIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acc.FindTransaction(t.Context(), txID)
+		}()
+	}
+	wg.Wait()
+	if gateway.peak <= 3 {
+		t.Errorf("Expected single lookups to be unbounded, got a peak of %d", gateway.peak)
+	}
+}
+
+func TestAdaptiveConcurrencyBacksOffWhenThrottled(t *testing.T) {
+	gateway := &concurrencyGateway{limit: 2}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.SetLogLevel(LogSilent)
+	acc.SetAdaptiveConcurrency(&AdaptiveConcurrency{Max: 8, Initial: 8})
+
+	txIDs := make([]string, 16)
+	for i := range txIDs {
+		txIDs[i] = fmt.Sprintf("%04x", i)
+	}
+	result, err := acc.GetTransactions(t.Context(), txIDs)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusTooManyRequests {
+		t.Fatalf("Expected the first wave of lookups to be throttled, got %v", err)
+	}
+	if limit := acc.ConcurrencyLimit(); limit >= 8 {
+		t.Errorf("Expected throttling to lower the limit, got %d", limit)
+	}
+
+	// Retrying the failed lookups within the lowered limit succeeds.
+	gateway.peak = 0
+	acc.SetAdaptiveConcurrency(&AdaptiveConcurrency{Max: 2})
+	if _, err := acc.GetTransactions(t.Context(), result.FailedKeys()); err != nil {
+		t.Errorf("Expected the retried lookups to succeed, got %v", err)
+	}
+	if gateway.peak > 2 {
+		t.Errorf("Expected at most 2 lookups in flight, got %d", gateway.peak)
+	}
+}
+
+func TestConcurrencyControlAIMD(t *testing.T) {
+	c := &concurrencyControl{config: &AdaptiveConcurrency{Min: 2, Max: 8, Initial: 4, Networks: map[string]int{"small": 3}}}
+
+	// Simultaneous congestion lowers the limit once.
+	var releases []func(bool)
+	for range 4 {
+		release, err := c.acquire(t.Context(), "mainnet", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.acquire(ctx, "mainnet", false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a fifth call to wait for a slot, got %v", err)
+	}
+	for _, release := range releases {
+		release(true)
+	}
+	if limit := c.windows["mainnet"].limit; limit != 2 {
+		t.Errorf("Expected one halving to 2, got %v", limit)
+	}
+
+	// A full window of successes raises it by one, up to the network's cap.
+	for range 2 {
+		release, _ := c.acquire(t.Context(), "mainnet", false)
+		release(false)
+	}
+	if limit := c.windows["mainnet"].limit; limit < 2.9 || limit > 3 {
+		t.Errorf("Expected the limit to grow to about 3, got %v", limit)
+	}
+	for range 20 {
+		release, _ := c.acquire(t.Context(), "small", false)
+		release(false)
+	}
+	if limit := c.windows["small"].limit; limit != 3 {
+		t.Errorf("Expected the small network to be capped at 3, got %v", limit)
+	}
+}
@@ -0,0 +1,190 @@
+package circular
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config holds the settings of an account that can be changed while it is in use,
+// with Reload or by watching a config file with WatchConfig.
+type Config struct {
+	Network   *NetworkProfile // Gateway override; nil leaves the account's network unchanged.
+	Retry     *RetryPolicy    // Retry behaviour for throttled calls; nil disables retries.
+	RateLimit float64         // Maximum NAG calls per second; zero means unlimited.
+	LogLevel  LogLevel        // The least severe level to log.
+}
+
+// configFile is the JSON layout of a config file. Durations are written as strings
+// such as "500ms" or "30s".
+type configFile struct {
+	Network *NetworkProfile `json:"Network"`
+	Retry   *struct {
+		MaxAttempts int    `json:"MaxAttempts"`
+		BaseDelay   string `json:"BaseDelay"`
+		MaxDelay    string `json:"MaxDelay"`
+	} `json:"Retry"`
+	RateLimit float64  `json:"RateLimit"`
+	LogLevel  LogLevel `json:"LogLevel"`
+}
+
+// LoadConfig reads a JSON config file, for example:
+//
+//	{
+//	  "Network": {"Name": "testnet", "BaseURL": "https://nag.example.com/NAG.php?cep="},
+//	  "Retry": {"MaxAttempts": 4, "BaseDelay": "500ms", "MaxDelay": "30s"},
+//	  "RateLimit": 20,
+//	  "LogLevel": "info"
+//	}
+//
+// Every field is optional.
+//
+// Parameters:
+//   - path: The file to read.
+//
+// Returns:
+//
+//	The configuration, or an error if the file cannot be read or is malformed.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return parseConfig(data)
+}
+
+func parseConfig(data []byte) (*Config, error) {
+	var file configFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	cfg := &Config{Network: file.Network, RateLimit: file.RateLimit, LogLevel: file.LogLevel}
+	if file.Retry != nil {
+		cfg.Retry = &RetryPolicy{MaxAttempts: file.Retry.MaxAttempts}
+		for _, d := range []struct {
+			name  string
+			value string
+			dst   *time.Duration
+		}{
+			{"BaseDelay", file.Retry.BaseDelay, &cfg.Retry.BaseDelay},
+			{"MaxDelay", file.Retry.MaxDelay, &cfg.Retry.MaxDelay},
+		} {
+			if d.value == "" {
+				continue
+			}
+			parsed, err := time.ParseDuration(d.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid config: Retry.%s: %w", d.name, err)
+			}
+			*d.dst = parsed
+		}
+	}
+	return cfg, nil
+}
+
+// Reload applies cfg to the account. Operations already in progress finish under the
+// settings they started with; operations started afterwards use the new ones.
+//
+// Parameters:
+//   - cfg: The settings to apply.
+//
+// Returns:
+//
+//	An error if cfg is invalid, in which case the account is unchanged.
+func (a *CEPAccount) Reload(cfg Config) error {
+	if cfg.Network != nil {
+		if err := cfg.Network.Validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.RateLimit < 0 {
+		return fmt.Errorf("rate limit cannot be negative, got %g", cfg.RateLimit)
+	}
+
+	a.mu.Lock()
+	a.retryPolicy = cfg.Retry
+	a.logLevel = cfg.LogLevel
+	if cfg.Network != nil {
+		a.NAGURL = cfg.Network.BaseURL
+		a.NetworkNode = cfg.Network.Name
+		a.nagPath = cfg.Network.PathTemplate
+		a.nagProto = cfg.Network.Protocol
+		a.node = nil
+	}
+	a.mu.Unlock()
+
+	a.limiter.setRate(cfg.RateLimit)
+	if cfg.Network != nil {
+		a.compat.reset()
+		a.recordEvent(AccountEvent{Type: EventNetwork, NAGURL: cfg.Network.BaseURL, NetworkNode: cfg.Network.Name})
+	}
+	return nil
+}
+
+// WatchConfig loads the config file at path, applies it with Reload, and then checks
+// the file every interval until ctx is done, reloading it whenever it changes. A
+// changed file that fails to load is logged and ignored, keeping the current settings.
+//
+// Parameters:
+//   - ctx: Stops the watch when done.
+//   - path: The config file to watch.
+//   - interval: How often to check the file for changes.
+//
+// Returns:
+//
+//	An error if the file cannot be loaded or applied initially, in which case nothing
+//	is watched.
+func (a *CEPAccount) WatchConfig(ctx context.Context, path string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := a.applyConfig(data); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current, err := os.ReadFile(path)
+				if err != nil {
+					a.logf(LogWarn, "WatchConfig: failed to read %s: %v\n", path, err)
+					continue
+				}
+				if bytes.Equal(current, data) {
+					continue
+				}
+				data = current
+				if err := a.applyConfig(data); err != nil {
+					a.logf(LogWarn, "WatchConfig: keeping current settings: %v\n", err)
+					continue
+				}
+				a.logf(LogInfo, "WatchConfig: reloaded %s\n", path)
+			}
+		}
+	}()
+	return nil
+}
+
+// applyConfig parses data and applies it with Reload.
+func (a *CEPAccount) applyConfig(data []byte) error {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return err
+	}
+	return a.Reload(*cfg)
+}
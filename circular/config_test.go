@@ -0,0 +1,110 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "circular.json")
+	os.WriteFile(path, []byte(`{
+		"Network": {"Name": "testnet", "BaseURL": "https://nag.example.com/?cep="},
+		"Retry": {"MaxAttempts": 3, "BaseDelay": "250ms", "MaxDelay": "5s"},
+		"RateLimit": 10,
+		"LogLevel": "warn"
+	}`), 0o644)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Network == nil || cfg.Network.Name != "testnet" {
+		t.Errorf("Unexpected network: %+v", cfg.Network)
+	}
+	if cfg.Retry == nil || *cfg.Retry != (RetryPolicy{MaxAttempts: 3, BaseDelay: 250 * time.Millisecond, MaxDelay: 5 * time.Second}) {
+		t.Errorf("Unexpected retry policy: %+v", cfg.Retry)
+	}
+	if cfg.RateLimit != 10 || cfg.LogLevel != LogWarn {
+		t.Errorf("Unexpected rate limit %g or log level %s", cfg.RateLimit, cfg.LogLevel)
+	}
+
+	for _, bad := range []string{`{"LogLevel": "loud"}`, `{"Retry": {"BaseDelay": "soon"}}`, `{"Unknown": 1}`} {
+		if _, err := parseConfig([]byte(bad)); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
+
+func TestReloadLetsInFlightCallsFinishOnOldGateway(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	old := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":1}}`)
+	}))
+	defer old.Close()
+	replacement := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":7}}`)
+	}))
+	defer replacement.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = old.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	done := make(chan bool)
+	go func() { done <- acc.UpdateAccount() }()
+	<-started
+
+	if err := acc.Reload(Config{Network: &NetworkProfile{Name: "replacement", BaseURL: replacement.URL + "/?cep="}, LogLevel: LogSilent}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	close(release)
+	if !<-done || acc.State().Nonce != 2 {
+		t.Fatalf("Expected the in-flight call to complete on the old gateway, got nonce %d (%s)", acc.State().Nonce, acc.GetLastError())
+	}
+
+	if !acc.UpdateAccount() || acc.State().Nonce != 8 {
+		t.Errorf("Expected later calls to use the new gateway, got nonce %d", acc.State().Nonce)
+	}
+	if err := acc.Reload(Config{Network: &NetworkProfile{Name: "broken"}}); err == nil {
+		t.Error("Expected Reload to reject an invalid network")
+	}
+	if acc.State().NetworkNode != "replacement" {
+		t.Errorf("Expected a rejected Reload to leave the account unchanged")
+	}
+}
+
+func TestWatchConfigAppliesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "circular.json")
+	os.WriteFile(path, []byte(`{"LogLevel": "info"}`), 0o644)
+
+	acc := NewCEPAccount()
+	if err := acc.WatchConfig(t.Context(), path, 10*time.Millisecond); err != nil {
+		t.Fatalf("WatchConfig() error = %v", err)
+	}
+	if level := acc.view().logLevel; level != LogInfo {
+		t.Fatalf("Expected the initial config to be applied, got log level %s", level)
+	}
+
+	os.WriteFile(path, []byte(`{"LogLevel": "silent", "RateLimit": 50}`), 0o644)
+	deadline := time.Now().Add(2 * time.Second)
+	for acc.view().logLevel != LogSilent {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the changed config to be applied")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	os.WriteFile(path, []byte(`{"LogLevel": `), 0o644)
+	time.Sleep(50 * time.Millisecond)
+	if level := acc.view().logLevel; level != LogSilent {
+		t.Errorf("Expected a malformed config to be ignored, got log level %s", level)
+	}
+}
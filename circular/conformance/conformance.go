@@ -0,0 +1,295 @@
+// Package conformance checks that a Network Access Gateway behaves as the circular
+// package expects, for gateway operators validating a deployment and for users
+// qualifying a gateway before switching to it. Run executes a fixed matrix of
+// scenarios, the same ones the integration and end-to-end tests exercise, against a
+// gateway URL and a funded account, and returns a pass/fail report.
+//
+// The scenarios that certify data submit real transactions and consume the account's
+// nonces; run them against a test network or with an account kept for the purpose.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// DefaultScenarioTimeout bounds each scenario unless Config.Timeout changes it.
+const DefaultScenarioTimeout = 2 * time.Minute
+
+// Outcomes of a scenario; see Result.
+const (
+	StatusPassed  = "passed"  // The gateway behaved as expected.
+	StatusFailed  = "failed"  // The gateway did not behave as expected; Detail says how.
+	StatusSkipped = "skipped" // A scenario this one depends on did not pass.
+)
+
+// Config describes the gateway under test and the account used to exercise it.
+type Config struct {
+	NAGURL     string                     // The gateway URL, in the form of CEPAccount.NAGURL.
+	Address    string                     // The account's address; it must be registered and funded.
+	Signer     circular.Signer            // Holds the account's key.
+	Blockchain string                     // The chain to certify on; circular.DefaultChain if empty.
+	Timeout    time.Duration              // Bounds each scenario; DefaultScenarioTimeout if zero.
+	Scenarios  []string                   // IDs of the scenarios to run, with their prerequisites; all if empty.
+	Setup      func(*circular.CEPAccount) // Called on the account before the run, e.g. to set a retry policy; may be nil.
+}
+
+// Scenario is one check in the conformance matrix.
+type Scenario struct {
+	ID       string   // The scenario's number, such as "2.1"; the first digit groups related scenarios.
+	Name     string   // What the scenario checks.
+	Requires []string // IDs of the scenarios that must pass before this one can run.
+}
+
+// Result is the outcome of one scenario.
+type Result struct {
+	ID       string        `json:"id"`               // The scenario's ID.
+	Name     string        `json:"name"`             // The scenario's name.
+	Status   string        `json:"status"`           // StatusPassed, StatusFailed or StatusSkipped.
+	Detail   string        `json:"detail,omitempty"` // Why the scenario failed or was skipped.
+	Duration time.Duration `json:"duration"`         // How long the scenario ran.
+}
+
+// Report is the result of a conformance run. It encodes to JSON for archiving.
+type Report struct {
+	NAGURL     string    `json:"nagUrl"`     // The gateway tested.
+	Blockchain string    `json:"blockchain"` // The chain certified on.
+	Started    time.Time `json:"started"`    // When the run started.
+	Results    []Result  `json:"results"`    // The scenarios run, in matrix order.
+}
+
+// Passed reports whether every scenario run passed.
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if result.Status != StatusPassed {
+			return false
+		}
+	}
+	return len(r.Results) > 0
+}
+
+// WriteText writes the report as one line per scenario followed by a summary, for
+// terminals and CI logs.
+//
+// Parameters:
+//   - w: The writer to write to.
+//
+// Returns:
+//
+//	An error if writing fails.
+func (r *Report) WriteText(w io.Writer) error {
+	counts := make(map[string]int)
+	for _, result := range r.Results {
+		counts[result.Status]++
+		line := fmt.Sprintf("%-4s %-7s %s (%s)", result.ID, result.Status, result.Name, result.Duration.Round(time.Millisecond))
+		if result.Detail != "" {
+			line += ": " + result.Detail
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s: %d passed, %d failed, %d skipped\n", r.NAGURL, counts[StatusPassed], counts[StatusFailed], counts[StatusSkipped])
+	return err
+}
+
+// Scenarios returns the conformance matrix, in the order Run executes it.
+func Scenarios() []Scenario {
+	scenarios := make([]Scenario, len(matrix))
+	for i, s := range matrix {
+		scenarios[i] = s.Scenario
+	}
+	return scenarios
+}
+
+// Run executes the conformance scenarios against the gateway in cfg. A scenario fails
+// when the gateway answers unexpectedly, and is skipped when a scenario it requires
+// did not pass; neither stops the run.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the run; scenarios not yet run when it is done fail.
+//   - cfg: The gateway and account to test.
+//
+// Returns:
+//
+//	The report, or an error if cfg is incomplete or names an unknown scenario.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.NAGURL == "" || cfg.Address == "" || cfg.Signer == nil {
+		return nil, fmt.Errorf("a NAG URL, an address and a signer are required")
+	}
+	selected, err := selectScenarios(cfg.Scenarios)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Blockchain == "" {
+		cfg.Blockchain = circular.DefaultChain
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultScenarioTimeout
+	}
+
+	acc := circular.NewCEPAccount()
+	if cfg.Setup != nil {
+		cfg.Setup(acc)
+	}
+	acc.NAGURL = cfg.NAGURL
+	acc.SetBlockchain(cfg.Blockchain)
+	if !acc.Open(cfg.Address) {
+		return nil, fmt.Errorf("failed to open account: %s", acc.GetLastError())
+	}
+
+	r := &run{acc: acc, signer: cfg.Signer, outcomes: make(map[string]submission)}
+	report := &Report{NAGURL: cfg.NAGURL, Blockchain: cfg.Blockchain, Started: time.Now()}
+	passed := make(map[string]bool)
+	for _, s := range matrix {
+		if !selected[s.ID] {
+			continue
+		}
+		result := Result{ID: s.ID, Name: s.Name, Status: StatusSkipped}
+		for _, id := range s.Requires {
+			if !passed[id] {
+				result.Detail = fmt.Sprintf("requires %s", id)
+				break
+			}
+		}
+		if result.Detail == "" {
+			start := time.Now()
+			sctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+			err := s.run(r, sctx)
+			cancel()
+			result.Duration = time.Since(start)
+			if err != nil {
+				result.Status, result.Detail = StatusFailed, err.Error()
+			} else {
+				result.Status = StatusPassed
+				passed[s.ID] = true
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// selectScenarios returns the IDs of the scenarios to run for the requested IDs,
+// including their prerequisites.
+func selectScenarios(ids []string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	if len(ids) == 0 {
+		for _, s := range matrix {
+			selected[s.ID] = true
+		}
+		return selected, nil
+	}
+	var add func(id string) error
+	add = func(id string) error {
+		i := slices.IndexFunc(matrix, func(s scenario) bool { return s.ID == id })
+		if i < 0 {
+			return fmt.Errorf("unknown scenario %q", id)
+		}
+		selected[id] = true
+		for _, required := range matrix[i].Requires {
+			if err := add(required); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range ids {
+		if err := add(id); err != nil {
+			return nil, err
+		}
+	}
+	return selected, nil
+}
+
+// run is the state shared by the scenarios of one conformance run.
+type run struct {
+	acc      *circular.CEPAccount
+	signer   circular.Signer
+	outcomes map[string]submission // By the ID of the scenario that submitted.
+}
+
+// submission is a certificate submitted by a scenario and its finalized outcome.
+type submission struct {
+	data    string
+	txID    string
+	outcome map[string]interface{}
+}
+
+// scenario is a Scenario and the check it performs.
+type scenario struct {
+	Scenario
+	run func(r *run, ctx context.Context) error
+}
+
+// matrix is every scenario, in the order they run. Prerequisites come first.
+var matrix = []scenario{
+	{Scenario{ID: "1.1", Name: "fetch the account nonce"}, (*run).fetchNonce},
+	{Scenario{ID: "2.1", Name: "certify text and reach Executed", Requires: []string{"1.1"}}, certify("2.1", "test message")},
+	{Scenario{ID: "2.2", Name: "certify JSON and reach Executed", Requires: []string{"1.1"}}, certify("2.2", `{"test":"data"}`)},
+	{Scenario{ID: "2.3", Name: "certify Hello World and reach Executed", Requires: []string{"1.1"}}, certify("2.3", "Hello World")},
+	{Scenario{ID: "3.1", Name: "outcome records the submitted data", Requires: []string{"2.1"}}, (*run).verifyOutcome},
+	{Scenario{ID: "3.2", Name: "look up the transaction in its block", Requires: []string{"2.1"}}, (*run).lookupByBlock},
+}
+
+// fetchNonce checks that the gateway returns the account's nonce.
+func (r *run) fetchNonce(ctx context.Context) error {
+	if !r.acc.UpdateAccount() {
+		return errors.New(r.acc.GetLastError())
+	}
+	return nil
+}
+
+// certify returns a check submitting data as the scenario with the given ID and
+// waiting for the transaction to be executed.
+func certify(id, data string) func(r *run, ctx context.Context) error {
+	return func(r *run, ctx context.Context) error {
+		result, err := r.acc.SubmitAndWait(ctx, data, r.signer)
+		if err != nil {
+			return err
+		}
+		if status, _ := jsonx.GetString(result.Outcome, "Status"); status != "Executed" {
+			return fmt.Errorf("transaction %s finalized with status %q, expected \"Executed\"", result.TxID, status)
+		}
+		r.outcomes[id] = submission{data: data, txID: result.TxID, outcome: result.Outcome}
+		return nil
+	}
+}
+
+// verifyOutcome checks that the outcome of scenario 2.1 carries the submitted payload.
+func (r *run) verifyOutcome(ctx context.Context) error {
+	sub := r.outcomes["2.1"]
+	report, err := circular.VerifyOutcomeMatchesSubmission(sub.outcome, sub.data)
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return fmt.Errorf("transaction %s does not record the submitted data: %+v", sub.txID, report.Mismatches)
+	}
+	return nil
+}
+
+// lookupByBlock checks that the transaction of scenario 2.1 can be found in the block
+// its outcome names.
+func (r *run) lookupByBlock(ctx context.Context) error {
+	sub := r.outcomes["2.1"]
+	blockID, ok := jsonx.GetString(sub.outcome, "BlockID")
+	if !ok || blockID == "" {
+		return fmt.Errorf("outcome of transaction %s carries no BlockID", sub.txID)
+	}
+	tx := r.acc.GetTransaction(blockID, sub.txID)
+	if tx == nil {
+		return fmt.Errorf("lookup of transaction %s in block %s failed: %s", sub.txID, blockID, r.acc.GetLastError())
+	}
+	if result, _ := tx["Result"].(float64); circular.ResultCode(result) != circular.ResultOK {
+		return fmt.Errorf("lookup of transaction %s in block %s returned Result %v, expected %d", sub.txID, blockID, tx["Result"], circular.ResultOK)
+	}
+	return nil
+}
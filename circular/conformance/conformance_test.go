@@ -0,0 +1,123 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+const testPrivateKey = "0x1111111111111111111111111111111111111111111111111111111111111111"
+
+// fakeGateway answers the NAG calls of a conformance run, recording submitted payloads
+// so that lookups return them. With omitPayload set, lookups leave the payload out.
+type fakeGateway struct {
+	omitPayload bool
+
+	mu       sync.Mutex
+	payloads map[string]string
+}
+
+func (g *fakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	json.NewDecoder(r.Body).Decode(&req)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":7}}`)
+	case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+		if g.payloads == nil {
+			g.payloads = make(map[string]string)
+		}
+		g.payloads[req["ID"]] = req["Payload"]
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	default:
+		response := map[string]interface{}{"Status": "Executed", "BlockID": "42"}
+		if !g.omitPayload {
+			response["Payload"] = g.payloads[req["ID"]]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Result": 200, "Response": response})
+	}
+}
+
+func testConfig(t *testing.T, nagURL string) Config {
+	signer, err := circular.NewPrivateKeySigner(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{
+		NAGURL:  nagURL,
+		Address: "0xabcdef",
+		Signer:  signer,
+		Setup:   func(acc *circular.CEPAccount) { acc.IntervalSec = 1 },
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(&fakeGateway{})
+	defer server.Close()
+
+	report, err := Run(t.Context(), testConfig(t, server.URL+"/?cep="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Passed() || len(report.Results) != len(Scenarios()) {
+		t.Fatalf("Expected every scenario to pass, got %+v", report.Results)
+	}
+	if report.Blockchain != circular.DefaultChain {
+		t.Errorf("Expected the default chain, got %q", report.Blockchain)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "6 passed, 0 failed, 0 skipped") {
+		t.Errorf("Expected a summary line, got %q", buf.String())
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	server := httptest.NewServer(&fakeGateway{omitPayload: true})
+	defer server.Close()
+
+	cfg := testConfig(t, server.URL+"/?cep=")
+	cfg.Scenarios = []string{"3.1"}
+	report, err := Run(t.Context(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, result := range report.Results {
+		got = append(got, result.ID+" "+result.Status)
+	}
+	if strings.Join(got, ", ") != "1.1 passed, 2.1 passed, 3.1 failed" || report.Passed() {
+		t.Errorf("Expected only the verification to fail, got %q", got)
+	}
+
+	// A gateway that cannot return the nonce fails 1.1 and skips what depends on it.
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer down.Close()
+	cfg = testConfig(t, down.URL+"/?cep=")
+	cfg.Scenarios = []string{"2.2"}
+	report, err = Run(t.Context(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 || report.Results[0].Status != StatusFailed || report.Results[1].Status != StatusSkipped || report.Results[1].Detail != "requires 1.1" {
+		t.Errorf("Expected 1.1 to fail and 2.2 to be skipped, got %+v", report.Results)
+	}
+
+	if _, err := Run(t.Context(), Config{NAGURL: down.URL, Address: "0xabcdef", Signer: cfg.Signer, Scenarios: []string{"9.9"}}); err == nil {
+		t.Error("Expected an unknown scenario to be rejected")
+	}
+}
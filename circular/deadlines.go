@@ -0,0 +1,70 @@
+package circular
+
+import (
+	"context"
+	"time"
+)
+
+// Deadlines bounds the account's operations whose context has no deadline, so that a
+// caller who forgets to set one does not wait indefinitely on an unresponsive gateway.
+// A deadline on the operation's context always takes precedence. Zero fields select the
+// defaults of DefaultDeadlines; a negative field leaves the operation unbounded.
+type Deadlines struct {
+	UpdateAccount time.Duration // Fetching the account's nonce, by UpdateAccount, UpdateAccountOn and others.
+	Submit        time.Duration // Building, signing and broadcasting one transaction.
+	OutcomeTotal  time.Duration // Waiting for a transaction's outcome, across all polls.
+	Discovery     time.Duration // Discovering a network's gateway, by SetNetwork and GetNAG.
+}
+
+// DefaultDeadlines returns the deadlines applied unless SetDeadlines overrides them:
+// 5s to fetch a nonce, 15s per submission, DefaultOutcomeTimeout to wait for an
+// outcome and 5s for network discovery.
+func DefaultDeadlines() Deadlines {
+	return Deadlines{
+		UpdateAccount: 5 * time.Second,
+		Submit:        15 * time.Second,
+		OutcomeTotal:  DefaultOutcomeTimeout,
+		Discovery:     5 * time.Second,
+	}
+}
+
+// withDefaults returns d with its zero fields replaced by the defaults.
+func (d Deadlines) withDefaults() Deadlines {
+	defaults := DefaultDeadlines()
+	for _, f := range []struct{ field, fallback *time.Duration }{
+		{&d.UpdateAccount, &defaults.UpdateAccount},
+		{&d.Submit, &defaults.Submit},
+		{&d.OutcomeTotal, &defaults.OutcomeTotal},
+		{&d.Discovery, &defaults.Discovery},
+	} {
+		if *f.field == 0 {
+			*f.field = *f.fallback
+		}
+	}
+	return d
+}
+
+// SetDeadlines overrides the account's default operation deadlines. Individual calls
+// override them in turn by passing a context with a deadline.
+//
+// Parameters:
+//   - deadlines: The deadlines to apply; zero fields keep the defaults.
+func (a *CEPAccount) SetDeadlines(deadlines Deadlines) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.deadlines = deadlines
+}
+
+// Deadlines returns the deadlines the account applies, with defaults filled in.
+func (a *CEPAccount) Deadlines() Deadlines {
+	return a.view().deadlines.withDefaults()
+}
+
+// withDeadline bounds ctx by timeout unless it already has a deadline or timeout is
+// negative. The returned cancel function must be called in either case.
+func withDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout < 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,79 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+func TestDeadlines(t *testing.T) {
+	// The gateway accepts transactions but never answers nonce or discovery requests,
+	// and never finalizes anything.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		case strings.Contains(r.URL.String(), "Circular_GetTransactionbyID_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Pending"}}`)
+		default:
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	circulartest.OverrideNetworkDiscoveryURL(t, server.URL+"/discover?network=")
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	acc.SetDeadlines(Deadlines{UpdateAccount: 20 * time.Millisecond, OutcomeTotal: 50 * time.Millisecond, Discovery: 20 * time.Millisecond})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	if d := acc.Deadlines(); d.UpdateAccount != 20*time.Millisecond || d.Submit != DefaultDeadlines().Submit {
+		t.Errorf("Expected overrides with defaults filled in, got %+v", d)
+	}
+
+	start := time.Now()
+	if acc.UpdateAccount() || !errors.Is(acc.LastErr(), context.DeadlineExceeded) {
+		t.Errorf("Expected UpdateAccount to hit its deadline, got: %v", acc.LastErr())
+	}
+	if acc.SetNetwork("testnet") != "" || !errors.Is(acc.LastErr(), context.DeadlineExceeded) {
+		t.Errorf("Expected discovery to hit its deadline, got: %v", acc.LastErr())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the account's deadlines to apply, took %s", elapsed)
+	}
+
+	// A deadline on the call's context takes precedence over the account's.
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := acc.UpdateAccountOn(ctx, DefaultChain); err == nil {
+		t.Error("Expected UpdateAccountOn to time out")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected the call's deadline to override the account's, took %s", elapsed)
+	}
+
+	start = time.Now()
+	result, err := acc.SubmitAndWait(t.Context(), "report", signer)
+	if !errors.Is(err, context.DeadlineExceeded) || result == nil || result.TxID == "" {
+		t.Errorf("Expected the submission to succeed and waiting to hit its deadline, got %+v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the OutcomeTotal deadline to apply, took %s", elapsed)
+	}
+}
@@ -0,0 +1,94 @@
+package circular
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// dedupCollection is the DocumentStore collection content hashes are kept in.
+const dedupCollection = "dedup"
+
+// dedupRecord maps certified content to the transaction that certified it.
+type dedupRecord struct {
+	Hash        string    // SHA-256 of the certificate data, in hex.
+	Blockchain  string    // The chain the data was certified on.
+	TxID        string    // The transaction that certified it.
+	SubmittedAt time.Time // When it was submitted.
+}
+
+// SetDedupStore makes the account certify identical data only once per blockchain. Before
+// each certificate submission the SHA-256 of its data is looked up in docs; if the data
+// was already certified, no transaction is built and the submission returns the existing
+// transaction ID instead (reported by SubmitResult.Duplicate), without using a nonce.
+// Resubmit always certifies again. Passing nil disables deduplication, which is the
+// default.
+//
+// Parameters:
+//   - docs: The document store to keep content hashes in.
+func (a *CEPAccount) SetDedupStore(docs storage.DocumentStore) {
+	a.dedup.mu.Lock()
+	defer a.dedup.mu.Unlock()
+	a.dedup.docs = docs
+}
+
+// dedupIndex holds the document store content hashes are kept in.
+type dedupIndex struct {
+	mu   sync.Mutex
+	docs storage.DocumentStore
+}
+
+// store returns the index's document store, or nil if deduplication is disabled.
+func (d *dedupIndex) store() storage.DocumentStore {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.docs
+}
+
+// dedupID returns the document ID for pdata certified on chain.
+func dedupID(chain, pdata string) string {
+	sum := sha256.Sum256([]byte(helpers.HexFix(chain) + ":" + pdata))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookupDuplicate returns the ID of the transaction that already certified pdata on
+// chain, or "" if there is none or deduplication is disabled. Lookup failures are logged
+// and treated as no duplicate, so that an unavailable store never blocks submissions.
+func (a *CEPAccount) lookupDuplicate(chain, pdata string) string {
+	docs := a.dedup.store()
+	if docs == nil {
+		return ""
+	}
+	var record dedupRecord
+	if err := docs.Load(dedupCollection, dedupID(chain, pdata), &record); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			a.logf(LogWarn, "sendCertificate: failed to look up duplicate: %v\n", err)
+		}
+		return ""
+	}
+	return record.TxID
+}
+
+// recordCertified remembers that txID certified pdata on chain. Failures are logged but
+// do not fail the submission, which has already happened.
+func (a *CEPAccount) recordCertified(chain, pdata, txID string) {
+	docs := a.dedup.store()
+	if docs == nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(pdata))
+	record := &dedupRecord{
+		Hash:        hex.EncodeToString(sum[:]),
+		Blockchain:  helpers.HexFix(chain),
+		TxID:        txID,
+		SubmittedAt: time.Now(),
+	}
+	if err := docs.Save(dedupCollection, dedupID(chain, pdata), record); err != nil {
+		a.logf(LogWarn, "sendCertificate: failed to record content hash of %s: %v\n", txID, err)
+	}
+}
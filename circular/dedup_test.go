@@ -0,0 +1,82 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestDedupStore(t *testing.T) {
+	var submissions atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			submissions.Add(1)
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	// Poll every millisecond rather than every IntervalSec.
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	acc.SetReceiptStore(NewMemoryReceiptStore())
+	docs := storage.NewDocumentStore(storage.NewMemory())
+	acc.SetDedupStore(docs)
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	first, err := acc.SubmitAndWait(t.Context(), "report", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Duplicate {
+		t.Error("Expected the first submission not to be a duplicate")
+	}
+
+	second, err := acc.SubmitAndWait(t.Context(), "report", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Duplicate || second.TxID != first.TxID {
+		t.Errorf("Expected a duplicate of %s, got %+v", first.TxID, second)
+	}
+	if submissions.Load() != 1 || acc.Nonce != 1 {
+		t.Errorf("Expected one submission using one nonce, got %d submissions and nonce %d", submissions.Load(), acc.Nonce)
+	}
+
+	// Different data, another chain and resubmissions are certified.
+	if _, err := acc.submitCertificate(t.Context(), "other report", signer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acc.SubmitCertificateOn(t.Context(), "0x1234", "report", signer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acc.Resubmit(t.Context(), first.TxID, signer); err != nil {
+		t.Fatal(err)
+	}
+	if submissions.Load() != 4 {
+		t.Errorf("Expected 4 submissions, got %d", submissions.Load())
+	}
+
+	// The index survives the account, like the store backing it.
+	restarted := NewCEPAccount()
+	restarted.NAGURL = acc.NAGURL
+	restarted.Open("0xabcdef")
+	restarted.SetDedupStore(docs)
+	if _, err := restarted.submitCertificate(t.Context(), "other report", signer); err != nil {
+		t.Fatal(err)
+	}
+	if submissions.Load() != 4 {
+		t.Errorf("Expected the restarted account to detect the duplicate, got %d submissions", submissions.Load())
+	}
+}
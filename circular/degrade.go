@@ -0,0 +1,240 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrDegraded is returned by a submission refused because the account is in queue-only
+// mode and has no receipt store to queue it in; see SetDegradationPolicy.
+var ErrDegraded = newError(CodeDegraded, "gateway is degraded")
+
+// DegradationPolicy switches an account to queue-only mode during gateway incidents:
+// once the share of failed NAG calls over a sliding window reaches ErrorRate, certificate
+// submissions are signed and persisted as queued receipts instead of being broadcast,
+// so that nothing is half-submitted to a failing gateway. Failed calls are transport
+// failures and HTTP 5xx responses; rejections and throttling do not count.
+type DegradationPolicy struct {
+	ErrorRate     float64                // Share of failed calls, between 0 and 1, at which the account degrades.
+	Window        time.Duration          // How far back calls are counted.
+	MinCalls      int                    // Calls the window must hold before the account degrades.
+	ProbeInterval time.Duration          // How often a degraded account probes the gateway's health; zero means Window.
+	OnChange      func(DegradationEvent) // Called when the account enters or leaves queue-only mode; may be nil.
+}
+
+// DefaultDegradationPolicy returns a policy that degrades once half of at least 10 calls
+// in the last minute have failed, probing the gateway every 15 seconds while degraded.
+func DefaultDegradationPolicy() *DegradationPolicy {
+	return &DegradationPolicy{ErrorRate: 0.5, Window: time.Minute, MinCalls: 10, ProbeInterval: 15 * time.Second}
+}
+
+// DegradationEvent reports that an account entered or left queue-only mode.
+type DegradationEvent struct {
+	Degraded  bool      // Whether the account is now in queue-only mode.
+	ErrorRate float64   // The share of failed calls in the window when the change happened.
+	Calls     int       // The number of calls in the window.
+	Time      time.Time // When the change happened.
+}
+
+// SetDegradationPolicy enables queue-only mode during gateway incidents. While degraded,
+// certificate submissions are recorded in the receipt store with status ReceiptQueued
+// and return their transaction IDs as usual, or fail with ErrDegraded if no receipt
+// store is set; queries are still sent. The gateway is probed every ProbeInterval, and
+// once the error rate drops below the policy's threshold the queued transactions are
+// broadcast, in submission order, before the next submission. Passing nil disables the
+// policy and leaves queue-only mode.
+//
+// Parameters:
+//   - policy: The thresholds and alert callback to apply.
+func (a *CEPAccount) SetDegradationPolicy(policy *DegradationPolicy) {
+	a.degrade.mu.Lock()
+	defer a.degrade.mu.Unlock()
+	a.degrade.policy = policy
+	a.degrade.samples = nil
+	a.degrade.degraded = false
+}
+
+// Degraded reports whether the account is in queue-only mode.
+func (a *CEPAccount) Degraded() bool {
+	a.degrade.mu.Lock()
+	defer a.degrade.mu.Unlock()
+	return a.degrade.degraded
+}
+
+// FlushQueued broadcasts the transactions queued while the account was degraded, oldest
+// first, and marks their receipts pending. This happens automatically before the next
+// submission once the gateway recovers; FlushQueued is for resuming after a restart and
+// is attempted even while the account is degraded. Broadcasting stops at the first
+// failure, since later transactions depend on the nonces of earlier ones; a queued
+// transaction that is rejected can be skipped with AbandonTransaction.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//
+// Returns:
+//
+//	The number of transactions broadcast, and an error if the receipts cannot be listed
+//	or a broadcast fails.
+func (a *CEPAccount) FlushQueued(ctx context.Context) (int, error) {
+	a.degrade.flushMu.Lock()
+	defer a.degrade.flushMu.Unlock()
+
+	queued, err := a.receiptsWithStatus(ReceiptQueued)
+	if err != nil {
+		return 0, err
+	}
+	store := a.view().receipts
+	for i, receipt := range queued {
+		if err := a.broadcastTransaction(ensureRequestID(ctx), &receipt.Transaction); err != nil {
+			return i, fmt.Errorf("failed to broadcast queued transaction %s: %w", receipt.Transaction.ID, err)
+		}
+		receipt.Status = ReceiptPending
+		receipt.SubmittedAt = time.Now()
+		if err := store.SaveReceipt(receipt); err != nil {
+			a.logf(LogWarn, "FlushQueued: failed to save receipt for %s: %v\n", receipt.Transaction.ID, err)
+		}
+	}
+	a.degrade.mu.Lock()
+	a.degrade.backlog = false
+	a.degrade.mu.Unlock()
+	return len(queued), nil
+}
+
+// queueOnly reports whether submissions must be queued rather than broadcast, probing
+// the gateway first when a degraded account's probe is due. Once the account is healthy,
+// transactions queued by this process are broadcast before it returns.
+func (a *CEPAccount) queueOnly(ctx context.Context) (bool, error) {
+	if a.degrade.probeDue(a.view().timeSource().Now()) {
+		// The probe's outcome is recorded by postNAG like any other call.
+		a.GetGatewayVersion(ctx)
+	}
+	a.degrade.mu.Lock()
+	degraded, backlog := a.degrade.degraded, a.degrade.backlog
+	a.degrade.mu.Unlock()
+	if degraded {
+		return true, nil
+	}
+	if backlog {
+		if _, err := a.FlushQueued(ctx); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// queueTransaction records tx as queued for broadcast once the gateway recovers.
+func (a *CEPAccount) queueTransaction(tx *Transaction, ttl time.Duration, previousTxID string) error {
+	v := a.view()
+	store := v.receipts
+	if store == nil {
+		return fmt.Errorf("%w: no receipt store to queue the submission in", ErrDegraded)
+	}
+	receipt := &Receipt{
+		Transaction:  *tx,
+		SubmittedAt:  time.Now(),
+		Status:       ReceiptQueued,
+		PreviousTxID: previousTxID,
+		Label:        v.label,
+	}
+	if ttl > 0 {
+		receipt.ExpiresAt = receipt.SubmittedAt.Add(ttl)
+	}
+	if err := store.SaveReceipt(receipt); err != nil {
+		return fmt.Errorf("failed to queue transaction %s: %w", tx.ID, err)
+	}
+	a.degrade.mu.Lock()
+	a.degrade.backlog = true
+	a.degrade.mu.Unlock()
+	a.logf(LogWarn, "sendCertificate: gateway unavailable, queued transaction %s\n", tx.ID)
+	return nil
+}
+
+// isGatewayFailure reports whether err, returned by a NAG call made with ctx, indicates
+// an unhealthy gateway rather than a rejected request or a cancelled caller.
+func isGatewayFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus >= 500 && !isThrottled(apiErr.HTTPStatus, apiErr.Result())
+	}
+	return true
+}
+
+// callSample is the outcome of one NAG call.
+type callSample struct {
+	at     time.Time
+	failed bool
+}
+
+// degradation tracks the NAG error rate and the queue-only mode it drives.
+type degradation struct {
+	mu       sync.Mutex
+	policy   *DegradationPolicy
+	samples  []callSample // Calls within the policy's window, oldest first.
+	degraded bool
+	probed   time.Time // When the gateway was last probed, or the account degraded.
+	backlog  bool      // Whether transactions queued by this process may await broadcast.
+	flushMu  sync.Mutex
+}
+
+// record adds the outcome of a NAG call made at now to the window and enters or leaves queue-only
+// mode as the error rate crosses the policy's threshold, alerting the policy's callback.
+func (d *degradation) record(now time.Time, failed bool) {
+	d.mu.Lock()
+	policy := d.policy
+	if policy == nil {
+		d.mu.Unlock()
+		return
+	}
+	d.samples = append(d.samples, callSample{at: now, failed: failed})
+	stale := 0
+	for stale < len(d.samples) && now.Sub(d.samples[stale].at) > policy.Window {
+		stale++
+	}
+	d.samples = append(d.samples[:0], d.samples[stale:]...)
+
+	failures := 0
+	for _, sample := range d.samples {
+		if sample.failed {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(len(d.samples))
+	changed := false
+	switch {
+	case !d.degraded && len(d.samples) >= policy.MinCalls && rate >= policy.ErrorRate:
+		d.degraded, d.probed, changed = true, now, true
+	case d.degraded && rate < policy.ErrorRate:
+		d.degraded, changed = false, true
+	}
+	event := DegradationEvent{Degraded: d.degraded, ErrorRate: rate, Calls: len(d.samples), Time: now}
+	d.mu.Unlock()
+
+	if changed && policy.OnChange != nil {
+		policy.OnChange(event)
+	}
+}
+
+// probeDue reports whether a degraded account should probe the gateway at now, and if
+// so starts the next probe interval.
+func (d *degradation) probeDue(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.degraded {
+		return false
+	}
+	interval := d.policy.ProbeInterval
+	if interval <= 0 {
+		interval = d.policy.Window
+	}
+	if now.Sub(d.probed) < interval {
+		return false
+	}
+	d.probed = now
+	return true
+}
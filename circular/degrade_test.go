@@ -0,0 +1,139 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+func TestDegradationPolicy(t *testing.T) {
+	var healthy atomic.Bool
+	var mu sync.Mutex
+	var broadcast []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			var tx Transaction
+			json.NewDecoder(r.Body).Decode(&tx)
+			mu.Lock()
+			broadcast = append(broadcast, tx.Nonce)
+			mu.Unlock()
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer server.Close()
+
+	var events []DegradationEvent
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	clock := circulartest.NewFakeClock(time.Now())
+	acc.SetClock(clock)
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	acc.SetDegradationPolicy(&DegradationPolicy{
+		ErrorRate:     0.5,
+		Window:        time.Minute,
+		MinCalls:      2,
+		ProbeInterval: 10 * time.Second,
+		OnChange:      func(e DegradationEvent) { events = append(events, e) },
+	})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	// Failing calls degrade the account.
+	acc.GetBlock(t.Context(), 1)
+	acc.GetBlock(t.Context(), 1)
+	if !acc.Degraded() || len(events) != 1 || !events[0].Degraded {
+		t.Fatalf("Expected the account to degrade, got events %+v", events)
+	}
+
+	// Submissions are queued rather than broadcast.
+	result, err := acc.SubmitCertificates(t.Context(), []string{"one", "two"}, signer)
+	if err != nil {
+		t.Fatalf("Expected submissions to be queued, got: %v", err)
+	}
+	for _, item := range result.Succeeded {
+		receipt, err := store.LoadReceipt(item.Value)
+		if err != nil || receipt.Status != ReceiptQueued {
+			t.Fatalf("Expected a queued receipt for %s, got %+v, %v", item.Value, receipt, err)
+		}
+	}
+	if acc.Nonce != 2 || len(broadcast) != 0 {
+		t.Fatalf("Expected nonces to advance without broadcasting, got nonce %d and %v", acc.Nonce, broadcast)
+	}
+
+	// Once the failures leave the window, a probe recovers the account and the queue
+	// is broadcast in order before the next submission.
+	healthy.Store(true)
+	clock.Advance(2 * time.Minute)
+	txID, err := acc.submitCertificate(t.Context(), "three", signer)
+	if err != nil {
+		t.Fatalf("Expected the submission to succeed after recovery, got: %v", err)
+	}
+	if acc.Degraded() || len(events) != 2 || events[1].Degraded {
+		t.Fatalf("Expected the account to recover, got events %+v", events)
+	}
+	if strings.Join(broadcast, ",") != "0,1,2" {
+		t.Errorf("Expected nonces 0, 1 and 2 to be broadcast in order, got %v", broadcast)
+	}
+	pending, _ := acc.PendingReceipts()
+	if len(pending) != 3 || pending[2].Transaction.ID != txID {
+		t.Errorf("Expected 3 pending receipts, the last for %s, got %d", txID, len(pending))
+	}
+}
+
+func TestDegradedWithoutReceiptStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	policy := DefaultDegradationPolicy()
+	policy.MinCalls = 1
+	acc.SetDegradationPolicy(policy)
+	acc.GetBlock(t.Context(), 1)
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	if _, err := acc.submitCertificate(t.Context(), "data", signer); !errors.Is(err, ErrDegraded) {
+		t.Errorf("Expected ErrDegraded, got: %v", err)
+	}
+}
+
+func TestDegradationIgnoresRejections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":108,"Response":"Invalid Signature"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetDegradationPolicy(&DegradationPolicy{ErrorRate: 0.1, Window: time.Minute, MinCalls: 1})
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	for range 3 {
+		if _, err := acc.submitCertificate(t.Context(), "data", signer); err == nil {
+			t.Fatal("Expected the submission to be rejected")
+		}
+	}
+	if acc.Degraded() {
+		t.Error("Expected rejections not to degrade the account")
+	}
+}
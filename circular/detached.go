@@ -0,0 +1,110 @@
+package circular
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// DetachedHashSHA256 is the hash algorithm of detached signatures made by SignDocument.
+const DetachedHashSHA256 = "SHA-256"
+
+// detachedMessagePrefix separates the messages signed for documents from transaction
+// IDs, so that no detached signature is also a valid transaction signature.
+const detachedMessagePrefix = "circular-detached-signature:"
+
+// ErrDetachedSignatureInvalid is returned by VerifyDetachedSignature when the document
+// or the signature does not match.
+var ErrDetachedSignatureInvalid = newError(CodeSignatureInvalid, "detached signature is invalid")
+
+// DetachedSignature is a signature over a document kept apart from it, such as a
+// release artifact or a contract, made with the same keys as transactions but without
+// submitting anything to the chain. It encodes to JSON for storage next to the document.
+type DetachedSignature struct {
+	HashAlgorithm string `json:"hashAlgorithm"` // DetachedHashSHA256.
+	Digest        string `json:"digest"`        // The hex digest of the document.
+	Signature     string `json:"signature"`     // The hex-encoded DER signature over the digest.
+	PublicKey     string `json:"publicKey"`     // The signer's hex-encoded public key.
+}
+
+// detachedMessage returns the message signed for a document with the given digest:
+// the digest prefixed with a fixed label and the hash algorithm.
+func detachedMessage(algorithm, digest string) string {
+	return detachedMessagePrefix + algorithm + ":" + digest
+}
+
+// SignDocument hashes the document read from r with SHA-256 and signs the digest. The
+// signed message is the digest prefixed with a label, so the signature cannot be
+// replayed as the signature of a transaction whose ID happens to equal the digest.
+//
+// Parameters:
+//   - r: The document to sign; it is read to the end.
+//   - signer: Holds the signing key.
+//
+// Returns:
+//
+//	The detached signature, or an error if the document cannot be read or signing fails.
+func SignDocument(r io.Reader, signer Signer) (*DetachedSignature, error) {
+	if signer == nil {
+		return nil, ErrSignerRequired
+	}
+	digest, err := documentDigest(r)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(detachedMessage(DetachedHashSHA256, digest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign document: %w", err)
+	}
+	return &DetachedSignature{
+		HashAlgorithm: DetachedHashSHA256,
+		Digest:        digest,
+		Signature:     helpers.HexFix(signature),
+		PublicKey:     helpers.HexFix(signer.PublicKey()),
+	}, nil
+}
+
+// VerifyDetachedSignature checks that sig was made over the document read from r by the
+// key in sig.PublicKey. Callers must separately check that the key is one they trust,
+// for example with GetRegisteredPublicKey.
+//
+// Parameters:
+//   - r: The document; it is read to the end.
+//   - sig: The detached signature.
+//
+// Returns:
+//
+//	nil if the signature is valid, an error wrapping ErrDetachedSignatureInvalid if the
+//	document's digest or the signature does not match, or another error if the
+//	signature uses an unsupported hash algorithm or the document cannot be read.
+func VerifyDetachedSignature(r io.Reader, sig *DetachedSignature) error {
+	if sig == nil {
+		return fmt.Errorf("a signature is required")
+	}
+	if sig.HashAlgorithm != DetachedHashSHA256 {
+		return fmt.Errorf("unsupported hash algorithm %q", sig.HashAlgorithm)
+	}
+	digest, err := documentDigest(r)
+	if err != nil {
+		return err
+	}
+	if digest != helpers.HexFix(sig.Digest) {
+		return fmt.Errorf("%w: document digest is %s, signature is over %s", ErrDetachedSignatureInvalid, digest, helpers.HexFix(sig.Digest))
+	}
+	if !VerifySignature(sig.PublicKey, detachedMessage(sig.HashAlgorithm, digest), sig.Signature) {
+		return fmt.Errorf("%w: signature does not verify against public key %s", ErrDetachedSignatureInvalid, helpers.HexFix(sig.PublicKey))
+	}
+	return nil
+}
+
+// documentDigest returns the hex SHA-256 digest of everything read from r.
+func documentDigest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to read document: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
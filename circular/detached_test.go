@@ -0,0 +1,76 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetachedSignature(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	document := "release-1.2.3.tar.gz contents"
+
+	sig, err := SignDocument(strings.NewReader(document), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.HashAlgorithm != DetachedHashSHA256 || len(sig.Digest) != 64 || sig.PublicKey != signer.PublicKey() {
+		t.Errorf("Unexpected signature: %+v", sig)
+	}
+	if err := VerifyDetachedSignature(strings.NewReader(document), sig); err != nil {
+		t.Errorf("Expected the signature to verify, got %v", err)
+	}
+
+	// The signature survives a JSON round trip, and prefixed or uppercase hex.
+	data, _ := json.Marshal(sig)
+	var decoded DetachedSignature
+	json.Unmarshal(data, &decoded)
+	decoded.Digest = "0x" + strings.ToUpper(decoded.Digest)
+	decoded.PublicKey = "0x" + decoded.PublicKey
+	if err := VerifyDetachedSignature(strings.NewReader(document), &decoded); err != nil {
+		t.Errorf("Expected the decoded signature to verify, got %v", err)
+	}
+
+	other, _ := NewPrivateKeySigner(strings.Repeat("22", 32))
+	forged := *sig
+	forged.PublicKey = other.PublicKey()
+	testCases := []struct {
+		name     string
+		document string
+		sig      DetachedSignature
+	}{
+		{name: "altered document", document: document + ".", sig: *sig},
+		{name: "other key", document: document, sig: forged},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := VerifyDetachedSignature(strings.NewReader(tc.document), &tc.sig); !errors.Is(err, ErrDetachedSignatureInvalid) {
+				t.Errorf("Expected ErrDetachedSignatureInvalid, got %v", err)
+			}
+		})
+	}
+
+	unsupported := *sig
+	unsupported.HashAlgorithm = "MD5"
+	if err := VerifyDetachedSignature(strings.NewReader(document), &unsupported); err == nil || errors.Is(err, ErrDetachedSignatureInvalid) {
+		t.Errorf("Expected an unsupported algorithm error, got %v", err)
+	}
+}
+
+func TestDetachedSignatureIsNotTransactionSignature(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	// A document crafted to hash to a transaction ID yields no usable transaction signature.
+	preimage := "ab" + "cd" + "cd" + "7B7D" + "1" + "2024:01:02-03:04:05"
+	txID := ComputeTransactionID("ab", "cd", "cd", "7B7D", "1", "2024:01:02-03:04:05")
+	sig, err := SignDocument(strings.NewReader(preimage), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Digest != txID {
+		t.Fatalf("Expected the document digest to equal the transaction ID %s, got %s", txID, sig.Digest)
+	}
+	if VerifySignature(signer.PublicKey(), txID, sig.Signature) {
+		t.Error("Expected a detached signature not to verify as a transaction signature")
+	}
+}
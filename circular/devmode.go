@@ -0,0 +1,78 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+const (
+	// devnetNetwork is the network identifier of the developer network, whose gateway
+	// hands out test funds.
+	devnetNetwork = "devnet"
+
+	// faucetEndpoint is the NAG operation that credits test funds to a devnet wallet.
+	faucetEndpoint = "Circular_RequestTestFunds_"
+)
+
+// IsInsufficientBalance reports whether err is a gateway rejection caused by the
+// account's balance being too low to cover the transaction.
+func IsInsufficientBalance(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Result() == ResultInsufficientBalance
+}
+
+// SetDevMode makes submissions on devnet recover from an insufficient balance: the
+// account requests test funds with RequestTestFunds and retries the rejected
+// transaction once. Dev mode has no effect on other networks.
+//
+// Parameters:
+//   - enabled: Whether to top up and retry automatically.
+func (a *CEPAccount) SetDevMode(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.devMode = enabled
+}
+
+// RequestTestFunds asks the devnet gateway's faucet to credit the account with test
+// funds. It is refused on any other network, where funds have real value.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//
+// Returns:
+//
+//	An error if the account is not open, the account is not on devnet, or the faucet
+//	rejects the request.
+func (a *CEPAccount) RequestTestFunds(ctx context.Context) error {
+	v := a.view()
+	if v.Address == "" {
+		return ErrAccountNotOpen
+	}
+	if v.NetworkNode != devnetNetwork {
+		return fmt.Errorf("test funds are only available on %s, not %q", devnetNetwork, v.NetworkNode)
+	}
+
+	requestData := map[string]string{
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"Address":    helpers.HexFix(v.Address),
+		"Version":    v.CodeVersion,
+	}
+
+	resp, err := a.postNAG(ensureRequestID(ctx), faucetEndpoint, requestData)
+	if err != nil {
+		return fmt.Errorf("failed to request test funds: %w", err)
+	}
+	return resp.resultError("")
+}
+
+// insufficientBalanceMessage describes a balance rejection, pointing developers on
+// devnet at the faucet.
+func (a *CEPAccount) insufficientBalanceMessage() string {
+	if a.view().NetworkNode == devnetNetwork {
+		return "Rejected: Insufficient balance; request devnet funds with RequestTestFunds, or enable SetDevMode(true) to do so automatically"
+	}
+	return "Rejected: Insufficient balance"
+}
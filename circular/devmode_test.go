@@ -0,0 +1,71 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInsufficientBalanceSuggestsFaucetOnDevnet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":115,"Response":"Insufficient Balance"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = devnetNetwork
+	acc.Open("0xabcdef")
+
+	acc.SubmitCertificate("data", testPrivateKey)
+	if !IsInsufficientBalance(acc.LastErr()) {
+		t.Fatalf("Expected an insufficient balance error, got %v", acc.LastErr())
+	}
+	if !strings.Contains(acc.GetLastError(), "RequestTestFunds") {
+		t.Errorf("Expected the error to point at the faucet, got %q", acc.GetLastError())
+	}
+}
+
+func TestDevModeRequestsFundsAndRetries(t *testing.T) {
+	var submissions, faucetCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), faucetEndpoint):
+			faucetCalls++
+			fmt.Fprint(w, `{"Result":200,"Response":"Funds Sent"}`)
+		case submissions == 0:
+			submissions++
+			fmt.Fprint(w, `{"Result":115,"Response":"Insufficient Balance"}`)
+		default:
+			submissions++
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = devnetNetwork
+	acc.Open("0xabcdef")
+	acc.SetDevMode(true)
+
+	acc.SubmitCertificate("data", testPrivateKey)
+	if acc.GetLastError() != "" {
+		t.Fatalf("Unexpected error: %s", acc.GetLastError())
+	}
+	if faucetCalls != 1 || submissions != 2 {
+		t.Errorf("Expected 1 faucet call and 2 submissions, got %d and %d", faucetCalls, submissions)
+	}
+}
+
+func TestRequestTestFundsRefusedOutsideDevnet(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.NetworkNode = "mainnet"
+	acc.Open("0xabcdef")
+
+	if err := acc.RequestTestFunds(t.Context()); err == nil {
+		t.Error("Expected RequestTestFunds to be refused on mainnet")
+	}
+}
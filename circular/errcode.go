@@ -0,0 +1,126 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// ErrorCode identifies a class of SDK failure independently of the wording of its
+// message, so that support procedures and alerting rules can refer to it. Codes are
+// stable: a code keeps its meaning across releases, and new conditions get new codes.
+type ErrorCode string
+
+// Error codes. The leading digit groups them: 0 general, 1 invalid input or
+// configuration, 2 connectivity, 3 transactions, 4 governance, 5 integrity.
+const (
+	CodeUnknown  ErrorCode = "CIRC-0000" // An error without a more specific code.
+	CodeCanceled ErrorCode = "CIRC-0001" // The caller cancelled the operation.
+	CodeMultiple ErrorCode = "CIRC-0002" // A batch failed in several ways; see MultiError.
+
+	CodeInvalidAddress ErrorCode = "CIRC-1001" // The account address is missing or malformed.
+	CodeAccountNotOpen ErrorCode = "CIRC-1002" // The operation needs an account opened with Open.
+	CodeNetworkNotSet  ErrorCode = "CIRC-1003" // No gateway is configured; see SetNetwork.
+	CodeSignerRequired ErrorCode = "CIRC-1004" // The operation needs a Signer.
+	CodeInvalidPayload ErrorCode = "CIRC-1005" // The payload is malformed, e.g. hex-encoded twice.
+	CodeSchemaInvalid  ErrorCode = "CIRC-1006" // The data does not match its schema; see SchemaValidationError.
+
+	CodeNetwork             ErrorCode = "CIRC-2001" // The gateway could not be reached.
+	CodeGatewayError        ErrorCode = "CIRC-2002" // The gateway failed with a server error.
+	CodeTimeout             ErrorCode = "CIRC-2003" // A deadline passed before the operation completed.
+	CodeRateLimited         ErrorCode = "CIRC-2004" // The gateway throttled the request.
+	CodeDegraded            ErrorCode = "CIRC-2005" // The gateway is degraded; see SetDegradationPolicy.
+	CodeIncompatibleVersion ErrorCode = "CIRC-2006" // The gateway's version is not supported; see IncompatibleVersionError.
+	CodeMaintenance         ErrorCode = "CIRC-2007" // The gateway is in a maintenance window; see DeferredError.
+	CodeResponseTooLarge    ErrorCode = "CIRC-2008" // The gateway's response exceeded the size limit; see SetMaxResponseSize.
+
+	CodeRejected             ErrorCode = "CIRC-3001" // The gateway refused the request or transaction.
+	CodeTransactionNotFound  ErrorCode = "CIRC-3002" // The gateway does not know the transaction.
+	CodeTransactionExpired   ErrorCode = "CIRC-3003" // The transaction's TTL elapsed; see WithTTL.
+	CodeTransactionAbandoned ErrorCode = "CIRC-3004" // The transaction was abandoned; see AbandonTransaction.
+	CodeReceiptNotFound      ErrorCode = "CIRC-3005" // No receipt is stored for the transaction.
+
+	CodeGuard            ErrorCode = "CIRC-4001" // A guard refused the write; see GuardError.
+	CodePermissionDenied ErrorCode = "CIRC-4002" // The account lacks a permission; see PermissionError.
+	CodePolicyViolation  ErrorCode = "CIRC-4003" // The submission violates a policy; see PolicyViolationError.
+	CodeQuotaExceeded    ErrorCode = "CIRC-4004" // The account's quota is exhausted; see QuotaError.
+	CodePIIDetected      ErrorCode = "CIRC-4005" // The data contains personal data; see PIIError and SetScanner.
+
+	CodeSignatureInvalid ErrorCode = "CIRC-5001" // A detached signature does not verify.
+	CodeReadTokenExpired ErrorCode = "CIRC-5002" // A delegated read token has expired.
+)
+
+var (
+	// ErrInvalidAddress is returned when an account address is missing or malformed.
+	ErrInvalidAddress = newError(CodeInvalidAddress, "invalid address format")
+
+	// ErrAccountNotOpen is returned by operations that need an account opened with Open.
+	ErrAccountNotOpen = newError(CodeAccountNotOpen, "account is not open")
+
+	// ErrNetworkNotSet is returned by gateway calls made before a network is configured.
+	ErrNetworkNotSet = newError(CodeNetworkNotSet, "network is not set")
+
+	// ErrSignerRequired is returned by operations given a nil Signer.
+	ErrSignerRequired = newError(CodeSignerRequired, "a signer is required")
+)
+
+// ErrorCodeOf returns the code of err: that of the first error in its chain with a
+// Code method, such as *APIError or the package's sentinel errors, otherwise the code
+// implied by a context or network error in the chain, or CodeUnknown.
+//
+// Parameters:
+//   - err: The error to classify.
+//
+// Returns:
+//
+//	The error's code, or "" if err is nil.
+func ErrorCodeOf(err error) ErrorCode {
+	var coded interface{ Code() ErrorCode }
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.Code()
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return CodeTimeout
+		}
+		return CodeNetwork
+	default:
+		return CodeUnknown
+	}
+}
+
+// codedError is an error with a fixed code, used for the package's sentinel errors.
+type codedError struct {
+	code ErrorCode
+	msg  string
+}
+
+// newError returns a sentinel error with the given code and message.
+func newError(code ErrorCode, msg string) error {
+	return &codedError{code: code, msg: msg}
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+// Code returns the error's code.
+func (e *codedError) Code() ErrorCode {
+	return e.code
+}
+
+// loggedError formats an error in log messages with its code appended.
+type loggedError struct {
+	err error
+}
+
+func (e loggedError) Error() string {
+	return e.err.Error() + " [" + string(ErrorCodeOf(e.err)) + "]"
+}
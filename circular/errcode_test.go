@@ -0,0 +1,69 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"plain", errors.New("boom"), CodeUnknown},
+		{"sentinel", ErrAccountNotOpen, CodeAccountNotOpen},
+		{"wrapped sentinel", fmt.Errorf("polling stopped: %w", ErrTransactionExpired), CodeTransactionExpired},
+		{"deadline", fmt.Errorf("waiting: %w", context.DeadlineExceeded), CodeTimeout},
+		{"canceled", context.Canceled, CodeCanceled},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("refused")}, CodeNetwork},
+		{"rejected", &APIError{HTTPStatus: 200, ResultCode: 108}, CodeRejected},
+		{"not found", &APIError{HTTPStatus: 200, ResultCode: 118}, CodeTransactionNotFound},
+		{"server error", &APIError{HTTPStatus: 502}, CodeGatewayError},
+		{"throttled", &APIError{HTTPStatus: 429}, CodeRateLimited},
+		{"policy", &PolicyViolationError{Rule: "allowed-hours"}, CodePolicyViolation},
+		{"quota", &QuotaError{}, CodeQuotaExceeded},
+		{"pii", &PIIError{}, CodePIIDetected},
+		{"batch item", &BatchItemError{Err: ErrDegraded}, CodeDegraded},
+		{"same failures", &MultiError{Errors: []error{ErrDegraded, &BatchItemError{Err: ErrDegraded}}}, CodeDegraded},
+		{"mixed failures", &MultiError{Errors: []error{ErrDegraded, &GuardError{}}}, CodeMultiple},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCodeOf(tt.err); got != tt.want {
+				t.Errorf("ErrorCodeOf(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingLogger keeps the messages logged to it.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Log(level LogLevel, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, message)
+}
+
+func TestLogIncludesErrorCode(t *testing.T) {
+	acc := NewCEPAccount()
+	logger := &recordingLogger{}
+	acc.SetLogger(logger)
+	acc.logf(LogWarn, "lookup failed: %v\n", fmt.Errorf("wrapped: %w", ErrNetworkNotSet))
+	if len(logger.messages) != 1 || logger.messages[0] != "lookup failed: wrapped: network is not set [CIRC-1003]" {
+		t.Errorf("Expected the error's code in the log, got %q", logger.messages)
+	}
+	if !strings.Contains(ErrInvalidAddress.Error(), "invalid address") {
+		t.Errorf("Expected sentinel messages to be unchanged, got %q", ErrInvalidAddress)
+	}
+}
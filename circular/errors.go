@@ -0,0 +1,83 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyLen bounds how much of a raw response body is retained in an APIError.
+const maxErrorBodyLen = 512
+
+// APIError describes a failed call to a Network Access Gateway (NAG) endpoint.
+// It is returned (and recorded by the account, see CEPAccount.LastErr) whenever the
+// gateway answers with a non-200 HTTP status or a non-200 `Result` code, so callers
+// can branch on the precise failure with errors.As instead of parsing messages.
+type APIError struct {
+	Endpoint      string // The NAG operation that was called, e.g. "Circular_AddTransaction_".
+	HTTPStatus    int    // The HTTP status code of the response.
+	ResultCode    int    // The NAG `Result` code, or 0 if the body could not be decoded; see Result.
+	Message       string // The NAG `Response` message, when the gateway returned one as a string.
+	Body          string // The raw response body, truncated to a bounded length.
+	RequestID     string // The request ID reported by the gateway in the X-Request-ID header, if present.
+	CorrelationID string // The client-side request ID sent with the call; see WithRequestID.
+}
+
+// Error formats the failure consistently as
+// "<endpoint>: <message> (HTTP <status>, result <code>[ <name>][, request <id>][, correlation <id>])",
+// where name is that of a known ResultCode.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Endpoint)
+	b.WriteString(": ")
+	if e.Message != "" {
+		b.WriteString(e.Message)
+	} else {
+		b.WriteString("request failed")
+	}
+	fmt.Fprintf(&b, " (HTTP %d, result %d", e.HTTPStatus, e.ResultCode)
+	if e.Result().Known() {
+		fmt.Fprintf(&b, " %s", e.Result())
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request %s", e.RequestID)
+	}
+	if e.CorrelationID != "" && e.CorrelationID != e.RequestID {
+		fmt.Fprintf(&b, ", correlation %s", e.CorrelationID)
+	}
+	b.WriteString(")")
+	if e.Message == "" && e.Body != "" {
+		fmt.Fprintf(&b, ", body: %s", e.Body)
+	}
+	return b.String()
+}
+
+// Code classifies the failure: CodeGatewayError for a server error, CodeRateLimited
+// for throttling, CodeTransactionNotFound for an unknown transaction, and CodeRejected
+// when the gateway refused the request.
+func (e *APIError) Code() ErrorCode {
+	switch {
+	case e.HTTPStatus >= http.StatusInternalServerError:
+		return CodeGatewayError
+	case e.HTTPStatus == http.StatusTooManyRequests:
+		return CodeRateLimited
+	case e.Result() == ResultTransactionNotFound:
+		return CodeTransactionNotFound
+	default:
+		return CodeRejected
+	}
+}
+
+// Result returns the NAG `Result` code of the failure as a ResultCode, for comparing
+// against the package's constants and for its String and Description.
+func (e *APIError) Result() ResultCode {
+	return ResultCode(e.ResultCode)
+}
+
+// truncateBody shortens body to at most maxErrorBodyLen bytes for inclusion in errors.
+func truncateBody(body []byte) string {
+	if len(body) <= maxErrorBodyLen {
+		return string(body)
+	}
+	return string(body[:maxErrorBodyLen]) + "...(truncated)"
+}
@@ -0,0 +1,99 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrorSurfacing(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockStatusCode int
+		mockBody       string
+		expectedStatus int
+		expectedResult int
+		expectedMsg    string
+	}{
+		{
+			name:           "result code",
+			mockStatusCode: http.StatusOK,
+			mockBody:       `{"Result":115,"Response":"Insufficient balance"}`,
+			expectedStatus: http.StatusOK,
+			expectedResult: 115,
+			expectedMsg:    "Rejected: Insufficient balance",
+		},
+		{
+			name:           "http status with envelope",
+			mockStatusCode: http.StatusServiceUnavailable,
+			mockBody:       `{"Result":503,"Response":"Maintenance"}`,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedResult: 503,
+			expectedMsg:    "Maintenance",
+		},
+		{
+			name:           "http status with raw body",
+			mockStatusCode: http.StatusBadGateway,
+			mockBody:       `bad gateway`,
+			expectedStatus: http.StatusBadGateway,
+			expectedResult: 0,
+			expectedMsg:    "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-ID", "req-123")
+				w.WriteHeader(tc.mockStatusCode)
+				fmt.Fprint(w, tc.mockBody)
+			}))
+			defer server.Close()
+
+			acc := NewCEPAccount()
+			acc.NAGURL = server.URL + "/?cep="
+			acc.Open("0xabcdef")
+
+			if acc.UpdateAccount() {
+				t.Fatal("Expected UpdateAccount to fail")
+			}
+
+			var apiErr *APIError
+			if !errors.As(acc.LastErr(), &apiErr) {
+				t.Fatalf("Expected an *APIError, got %T: %v", acc.LastErr(), acc.LastErr())
+			}
+			if apiErr.Endpoint != "Circular_GetWalletNonce_" {
+				t.Errorf("Endpoint = %q, want Circular_GetWalletNonce_", apiErr.Endpoint)
+			}
+			if apiErr.HTTPStatus != tc.expectedStatus {
+				t.Errorf("HTTPStatus = %d, want %d", apiErr.HTTPStatus, tc.expectedStatus)
+			}
+			if apiErr.ResultCode != tc.expectedResult {
+				t.Errorf("ResultCode = %d, want %d", apiErr.ResultCode, tc.expectedResult)
+			}
+			if apiErr.Message != tc.expectedMsg {
+				t.Errorf("Message = %q, want %q", apiErr.Message, tc.expectedMsg)
+			}
+			if apiErr.RequestID != "req-123" {
+				t.Errorf("RequestID = %q, want req-123", apiErr.RequestID)
+			}
+			if acc.GetLastError() != apiErr.Error() {
+				t.Errorf("Expected LastError to match the typed error, got %q", acc.GetLastError())
+			}
+		})
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	long := strings.Repeat("a", maxErrorBodyLen+10)
+	got := truncateBody([]byte(long))
+	if !strings.HasSuffix(got, "...(truncated)") || len(got) != maxErrorBodyLen+len("...(truncated)") {
+		t.Errorf("Unexpected truncation result of length %d", len(got))
+	}
+	if truncateBody([]byte("short")) != "short" {
+		t.Error("Expected short bodies to be kept intact")
+	}
+}
@@ -0,0 +1,230 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// eventsCollection is the DocumentStore collection account events are journaled in.
+const eventsCollection = "events"
+
+// DefaultEventJournalSize is the number of events an account keeps in memory unless
+// SetEventJournal changes it.
+const DefaultEventJournalSize = 1000
+
+// AccountEventType identifies the kind of state change an AccountEvent records.
+type AccountEventType string
+
+// Kinds of account events.
+const (
+	EventOpen       AccountEventType = "open"       // Open set the Address.
+	EventClose      AccountEventType = "close"      // Close cleared the account.
+	EventNetwork    AccountEventType = "network"    // The NAGURL and NetworkNode changed.
+	EventBlockchain AccountEventType = "blockchain" // SetBlockchain changed the Blockchain.
+	EventNonce      AccountEventType = "nonce"      // The Nonce changed.
+	EventSubmission AccountEventType = "submission" // A transaction was accepted and became the LatestTxID.
+	EventOutcome    AccountEventType = "outcome"    // A transaction reached its final outcome.
+	EventReorg      AccountEventType = "reorg"      // A re-check found a confirmed transaction changed or gone; see WatchConfirmations.
+)
+
+// AccountEvent is one state change of an account, as recorded in its event journal.
+// Only the fields relevant to its Type are set.
+type AccountEvent struct {
+	Seq         uint64           `json:"seq"`                   // Position in the journal, starting at 1.
+	Time        time.Time        `json:"time"`                  // When the change was made.
+	Type        AccountEventType `json:"type"`                  // The kind of change.
+	Address     string           `json:"address,omitempty"`     // EventOpen: the address opened.
+	NAGURL      string           `json:"nagUrl,omitempty"`      // EventNetwork: the new gateway URL.
+	NetworkNode string           `json:"networkNode,omitempty"` // EventNetwork: the new network name.
+	Blockchain  string           `json:"blockchain,omitempty"`  // EventBlockchain: the new chain; EventSubmission: the chain submitted to.
+	Nonce       int64            `json:"nonce,omitempty"`       // EventNonce: the new nonce; EventSubmission: the nonce used.
+	Reason      string           `json:"reason,omitempty"`      // EventNonce: "update", "submission", "reservation", "resync" or "blockchain".
+	TxID        string           `json:"txId,omitempty"`        // EventSubmission, EventOutcome and EventReorg: the transaction.
+	Status      string           `json:"status,omitempty"`      // EventOutcome: the final status; EventReorg: the status now recorded, empty if the transaction disappeared.
+}
+
+// SetEventJournal configures the journal of state changes the account records, for
+// answering questions such as how its nonce reached its current value. Every change
+// made through the account's methods is recorded: Open, Close, network and blockchain
+// changes, nonce updates, accepted submissions and final outcomes. Assignments to the
+// exported fields are not. The most recent events are kept in memory; with docs, every
+// event is also persisted there, and the journal continues where a previous one using
+// the same store left off.
+//
+// Parameters:
+//   - size: The number of events to keep in memory; zero means
+//     DefaultEventJournalSize, and a negative size turns the journal off.
+//   - docs: The document store to persist events in; may be nil.
+//
+// Returns:
+//
+//	An error if the events already in docs cannot be read, in which case the journal
+//	is unchanged.
+func (a *CEPAccount) SetEventJournal(size int, docs storage.DocumentStore) error {
+	var seq uint64
+	if docs != nil {
+		ids, err := docs.IDs(eventsCollection)
+		if err != nil {
+			return fmt.Errorf("failed to read event journal: %w", err)
+		}
+		if len(ids) > 0 {
+			seq, _ = strconv.ParseUint(ids[len(ids)-1], 10, 64)
+		}
+	}
+	if size == 0 {
+		size = DefaultEventJournalSize
+	}
+	a.events.mu.Lock()
+	defer a.events.mu.Unlock()
+	a.events.size = size
+	a.events.docs = docs
+	a.events.recent = nil
+	if docs != nil {
+		a.events.seq = seq
+	}
+	return nil
+}
+
+// Events returns the account's journaled events, oldest first: every persisted event if
+// the journal has a store, and otherwise those still held in memory.
+//
+// Returns:
+//
+//	The events, or an error if the store cannot be read.
+func (a *CEPAccount) Events() ([]AccountEvent, error) {
+	a.events.mu.Lock()
+	docs := a.events.docs
+	recent := append([]AccountEvent(nil), a.events.recent...)
+	a.events.mu.Unlock()
+	if docs == nil {
+		return recent, nil
+	}
+
+	ids, err := docs.IDs(eventsCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event journal: %w", err)
+	}
+	events := make([]AccountEvent, 0, len(ids))
+	for _, id := range ids {
+		var event AccountEvent
+		if err := docs.Load(eventsCollection, id, &event); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read event %s: %w", id, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Replay reconstructs the account's state from its journaled events; see ReplayEvents.
+//
+// Returns:
+//
+//	The reconstructed state, or an error if the journal cannot be read.
+func (a *CEPAccount) Replay() (AccountState, error) {
+	events, err := a.Events()
+	if err != nil {
+		return AccountState{}, err
+	}
+	return ReplayEvents(events), nil
+}
+
+// ReplayEvents applies events in order to an empty account state. Replaying a prefix of
+// a journal shows the state after each step. Only the fields the journal tracks are
+// set: Address, NAGURL, NetworkNode, Blockchain, Nonce and LatestTxID. When older
+// events were dropped from memory, the fields they set stay empty until a retained
+// event sets them again.
+//
+// Parameters:
+//   - events: The events to apply, oldest first.
+//
+// Returns:
+//
+//	The state after the last event.
+func ReplayEvents(events []AccountEvent) AccountState {
+	var state AccountState
+	for _, event := range events {
+		switch event.Type {
+		case EventOpen:
+			if state.Address != "" && !sameHex(state.Address, event.Address) {
+				state.Nonce, state.LatestTxID = 0, ""
+			}
+			state.Address = event.Address
+		case EventClose:
+			state = AccountState{}
+		case EventNetwork:
+			state.NAGURL = event.NAGURL
+			state.NetworkNode = event.NetworkNode
+		case EventBlockchain:
+			if !sameHex(state.Blockchain, event.Blockchain) {
+				state.Nonce = 0
+			}
+			state.Blockchain = event.Blockchain
+		case EventNonce:
+			state.Nonce = event.Nonce
+		case EventSubmission:
+			state.LatestTxID = event.TxID
+		}
+	}
+	return state
+}
+
+// eventJournal holds an account's recent events and the store they are persisted in.
+type eventJournal struct {
+	mu     sync.Mutex
+	size   int // Zero until SetEventJournal, meaning DefaultEventJournalSize; negative when off.
+	docs   storage.DocumentStore
+	seq    uint64
+	recent []AccountEvent
+}
+
+// recordEvent appends event to the account's journal. It must not be called with a.mu
+// held, since persistence failures are logged.
+func (a *CEPAccount) recordEvent(event AccountEvent) {
+	j := &a.events
+	j.mu.Lock()
+	size := j.size
+	if size == 0 {
+		size = DefaultEventJournalSize
+	}
+	if size < 0 {
+		j.mu.Unlock()
+		return
+	}
+	j.seq++
+	event.Seq = j.seq
+	event.Time = time.Now()
+	j.recent = append(j.recent, event)
+	if len(j.recent) > size {
+		j.recent = append(j.recent[:0], j.recent[len(j.recent)-size:]...)
+	}
+	var err error
+	if j.docs != nil {
+		// Zero-padded, so that IDs sort in journal order.
+		err = j.docs.Save(eventsCollection, fmt.Sprintf("%020d", event.Seq), event)
+	}
+	j.mu.Unlock()
+	if err != nil {
+		a.logf(LogWarn, "recordEvent: failed to persist %s event %d: %v\n", event.Type, event.Seq, err)
+	}
+}
+
+// recordNonce journals a change of the account's nonce to nonce, and persists it in the
+// nonce store, if any.
+func (a *CEPAccount) recordNonce(chain string, nonce int64, reason string) {
+	a.recordEvent(AccountEvent{Type: EventNonce, Nonce: nonce, Reason: reason})
+	a.persistNonce(chain, nonce)
+}
+
+// recordSubmission journals the acceptance of txID, sent on chain with nonce, as the
+// account's latest transaction.
+func (a *CEPAccount) recordSubmission(txID, chain string, nonce int64) {
+	a.recordEvent(AccountEvent{Type: EventSubmission, TxID: txID, Blockchain: chain, Nonce: nonce})
+}
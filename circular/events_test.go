@@ -0,0 +1,123 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestEventJournal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		}
+	}))
+	defer server.Close()
+
+	docs := storage.NewDocumentStore(storage.NewMemory())
+	acc := NewCEPAccount()
+	if err := acc.SetEventJournal(0, docs); err != nil {
+		t.Fatal(err)
+	}
+	// Poll every millisecond rather than every IntervalSec.
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.Open("0xabcdef")
+	if err := acc.SetNetworkProfile(NetworkProfile{Name: "local", BaseURL: server.URL + "/?cep="}); err != nil {
+		t.Fatal(err)
+	}
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	acc.SetBlockchain("0x1234")
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastError)
+	}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	result, err := acc.SubmitAndWait(t.Context(), "report", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acc.ReserveNonces(3); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := acc.Events()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for i, event := range events {
+		types = append(types, string(event.Type))
+		if event.Seq != uint64(i+1) || event.Time.IsZero() {
+			t.Errorf("Expected event %d to be numbered and timed, got %+v", i, event)
+		}
+	}
+	expected := "open network blockchain nonce submission nonce outcome nonce"
+	if strings.Join(types, " ") != expected {
+		t.Errorf("Expected events %q, got %q", expected, strings.Join(types, " "))
+	}
+	if events[3].Reason != "update" || events[5].Reason != "submission" || events[7].Reason != "reservation" {
+		t.Errorf("Expected each nonce change to carry its reason, got %+v", events)
+	}
+	if events[4].TxID != result.TxID || events[6].TxID != result.TxID || events[6].Status != "Executed" {
+		t.Errorf("Expected the submission and outcome of %s, got %+v and %+v", result.TxID, events[4], events[6])
+	}
+
+	replayed, err := acc.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := acc.State()
+	if replayed.Address != state.Address || replayed.NAGURL != state.NAGURL || replayed.NetworkNode != state.NetworkNode ||
+		replayed.Blockchain != state.Blockchain || replayed.Nonce != state.Nonce || replayed.LatestTxID != state.LatestTxID {
+		t.Errorf("Expected replay to reconstruct %+v, got %+v", state, replayed)
+	}
+	// Replaying a prefix shows how the nonce got there.
+	if before := ReplayEvents(events[:5]); before.Nonce != events[3].Nonce || before.LatestTxID != result.TxID {
+		t.Errorf("Expected the state after the submission, got %+v", before)
+	}
+
+	// A journal sharing the store continues it, and Close resets the replayed state.
+	restarted := NewCEPAccount()
+	if err := restarted.SetEventJournal(0, docs); err != nil {
+		t.Fatal(err)
+	}
+	restarted.Open("0x99")
+	restarted.Close()
+	all, _ := restarted.Events()
+	if len(all) != len(events)+2 || all[len(all)-1].Seq != uint64(len(events)+2) {
+		t.Errorf("Expected the journal to continue at %d, got %+v", len(events)+1, all[len(events):])
+	}
+	if replayed, _ := restarted.Replay(); replayed != (AccountState{}) {
+		t.Errorf("Expected an empty state after Close, got %+v", replayed)
+	}
+}
+
+func TestEventJournalSize(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.SetEventJournal(2, nil)
+	acc.Open("0xaa")
+	acc.SetBlockchain("0x01")
+	acc.Open("0xbb")
+	events, _ := acc.Events()
+	if len(events) != 2 || events[0].Seq != 2 || events[1].Address != "0xbb" {
+		t.Errorf("Expected the 2 most recent events, got %+v", events)
+	}
+	if state := ReplayEvents(events); state.Address != "0xbb" || state.Blockchain != "0x01" {
+		t.Errorf("Expected the retained events to be replayed, got %+v", state)
+	}
+
+	acc.SetEventJournal(-1, nil)
+	acc.Open("0xcc")
+	if events, _ := acc.Events(); len(events) != 0 {
+		t.Errorf("Expected a disabled journal to record nothing, got %+v", events)
+	}
+}
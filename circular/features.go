@@ -0,0 +1,109 @@
+package circular
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FeaturesEnv is the environment variable that enables experimental features in every
+// account of a process, as a comma-separated list of feature names, such as
+// "node-rotation,adaptive-concurrency". A name prefixed with "-" disables the feature.
+// It is read on every check, so changes made with os.Setenv take effect at once.
+const FeaturesEnv = "CIRCULAR_FEATURES"
+
+// Feature names an experimental SDK behavior that is off unless enabled, so that
+// platform teams can roll it out gradually across services; see SetFeature and
+// FeaturesEnv.
+type Feature string
+
+// Experimental features.
+const (
+	// FeatureNodeRotation rotates accounts without a NodeRotation away from failing
+	// gateway nodes by DefaultNodeRotation.
+	FeatureNodeRotation Feature = "node-rotation"
+	// FeatureAdaptiveConcurrency bounds the batch operations of accounts without an
+	// AdaptiveConcurrency by its defaults.
+	FeatureAdaptiveConcurrency Feature = "adaptive-concurrency"
+)
+
+// knownFeatures lists every experimental feature, in the order KnownFeatures reports them.
+var knownFeatures = []Feature{FeatureNodeRotation, FeatureAdaptiveConcurrency}
+
+// KnownFeatures returns the experimental features this version of the SDK has.
+func KnownFeatures() []Feature {
+	return slices.Clone(knownFeatures)
+}
+
+// SetFeature enables or disables an experimental feature for the account, overriding
+// FeaturesEnv. The change applies to the account's next operations.
+//
+// Parameters:
+//   - feature: One of the features returned by KnownFeatures.
+//   - enabled: Whether the account uses the feature.
+//
+// Returns:
+//
+//	An error if the SDK has no such feature, in which case nothing changes.
+func (a *CEPAccount) SetFeature(feature Feature, enabled bool) error {
+	if !slices.Contains(knownFeatures, feature) {
+		return fmt.Errorf("unknown feature %q", feature)
+	}
+	a.features.mu.Lock()
+	defer a.features.mu.Unlock()
+	if a.features.set == nil {
+		a.features.set = make(map[Feature]bool)
+	}
+	a.features.set[feature] = enabled
+	return nil
+}
+
+// ClearFeature removes the account's own setting of feature, so that FeaturesEnv
+// decides again whether it is enabled.
+//
+// Parameters:
+//   - feature: The feature to clear.
+func (a *CEPAccount) ClearFeature(feature Feature) {
+	a.features.mu.Lock()
+	defer a.features.mu.Unlock()
+	delete(a.features.set, feature)
+}
+
+// FeatureEnabled reports whether the account uses an experimental feature: as set with
+// SetFeature, or else as listed in FeaturesEnv. Features are off by default.
+//
+// Parameters:
+//   - feature: The feature to check.
+func (a *CEPAccount) FeatureEnabled(feature Feature) bool {
+	a.features.mu.Lock()
+	enabled, ok := a.features.set[feature]
+	a.features.mu.Unlock()
+	if ok {
+		return enabled
+	}
+	return envFeature(feature)
+}
+
+// envFeature reports whether FeaturesEnv enables feature. When a feature is listed more
+// than once, the last mention wins.
+func envFeature(feature Feature) bool {
+	enabled := false
+	for _, name := range strings.Split(os.Getenv(FeaturesEnv), ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.EqualFold(name, string(feature)):
+			enabled = true
+		case strings.EqualFold(name, "-"+string(feature)):
+			enabled = false
+		}
+	}
+	return enabled
+}
+
+// featureFlags holds an account's own settings of experimental features.
+type featureFlags struct {
+	mu  sync.Mutex
+	set map[Feature]bool
+}
@@ -0,0 +1,100 @@
+package circular
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	t.Setenv(FeaturesEnv, "")
+	acc := NewCEPAccount()
+	for _, feature := range KnownFeatures() {
+		if acc.FeatureEnabled(feature) {
+			t.Errorf("Expected %s to be off by default", feature)
+		}
+	}
+
+	t.Setenv(FeaturesEnv, " node-rotation, adaptive-concurrency,-adaptive-concurrency")
+	if !acc.FeatureEnabled(FeatureNodeRotation) || acc.FeatureEnabled(FeatureAdaptiveConcurrency) {
+		t.Errorf("Expected the environment to enable only node rotation")
+	}
+	if err := acc.SetFeature(FeatureNodeRotation, false); err != nil {
+		t.Fatal(err)
+	}
+	if acc.FeatureEnabled(FeatureNodeRotation) {
+		t.Error("Expected the account's setting to override the environment")
+	}
+	acc.ClearFeature(FeatureNodeRotation)
+	if !acc.FeatureEnabled(FeatureNodeRotation) {
+		t.Error("Expected the environment to apply again once the setting is cleared")
+	}
+	if err := acc.SetFeature("warp-drive", true); err == nil {
+		t.Error("Expected an unknown feature to be refused")
+	}
+}
+
+func TestFeaturesEnableSubsystems(t *testing.T) {
+	t.Setenv(FeaturesEnv, "")
+	acc := NewCEPAccount()
+	if acc.ConcurrencyLimit() != 0 {
+		t.Fatalf("Expected no limit by default, got %d", acc.ConcurrencyLimit())
+	}
+	acc.SetFeature(FeatureAdaptiveConcurrency, true)
+	if limit := acc.ConcurrencyLimit(); limit != 8 {
+		t.Errorf("Expected the feature to apply the default limits, got %d", limit)
+	}
+
+	// Without a policy, nodes are only rotated with the feature on.
+	nodes := []GatewayNode{{ID: "a", URL: "https://a.example.com/?cep="}, {ID: "b", URL: "https://b.example.com/?cep="}}
+	r := &nodeRegistry{}
+	r.discovered("testnet", nodes)
+	now := time.Now()
+	for range 20 {
+		if _, _, ok := r.record("testnet", nodes[0].URL, now, true, false); ok {
+			t.Fatal("Expected no rotation without a policy")
+		}
+	}
+	rotated := false
+	for range 10 {
+		if event, _, ok := r.record("testnet", nodes[0].URL, now, true, true); ok {
+			rotated = event.To.ID == "b"
+		}
+	}
+	if !rotated {
+		t.Error("Expected the feature to rotate by the default policy")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Setenv(FeaturesEnv, "")
+	acc := NewCEPAccount()
+	caps := acc.Capabilities()
+	if !caps.Network || caps.Receipts || caps.Queue || caps.Metrics || caps.NodeRotation || !caps.EventJournal {
+		t.Errorf("Unexpected default capabilities %+v", caps)
+	}
+	if len(caps.Features) != len(KnownFeatures()) || caps.Features[FeatureNodeRotation] {
+		t.Errorf("Expected every feature to be reported off, got %v", caps.Features)
+	}
+
+	acc.SetDegradationPolicy(&DegradationPolicy{})
+	if acc.Capabilities().Queue {
+		t.Error("Expected no queue without a receipt store to queue in")
+	}
+	acc.SetReceiptStore(NewMemoryReceiptStore())
+	acc.SetSLATracking(&SLAConfig{})
+	acc.SetOutcomeCache(0, nil)
+	acc.SetEventJournal(-1, nil)
+	acc.SetFeature(FeatureNodeRotation, true)
+	caps = acc.Capabilities()
+	if !caps.Receipts || !caps.Queue || !caps.Metrics || !caps.OutcomeCache || caps.EventJournal {
+		t.Errorf("Expected the configured subsystems to be reported, got %+v", caps)
+	}
+	if !caps.NodeRotation || !caps.Features[FeatureNodeRotation] || caps.AdaptiveConcurrency {
+		t.Errorf("Expected node rotation to be enabled by its feature alone, got %+v", caps)
+	}
+
+	acc.Close()
+	if acc.Capabilities().Network {
+		t.Error("Expected a closed account to have no network")
+	}
+}
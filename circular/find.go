@@ -0,0 +1,61 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// findDepths are the numbers of most recent blocks FindTransaction searches, in turn.
+var findDepths = []int64{10, 100, 1000, 10000, 100000}
+
+// FindTransaction looks up a transaction by its ID alone, without the number of the
+// block that contains it. The most recent 10 blocks are searched first, then ten
+// times as many at each step up to the last 100000, so recent transactions are found
+// with one cheap request. Finalized outcomes in the outcome cache are returned without
+// contacting the gateway.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - txID: The transaction, with or without "0x" prefix.
+//
+// Returns:
+//
+//	The transaction details, including its BlockID, or an error wrapping
+//	ErrTransactionNotFound if no searched block contains it, or another error if a
+//	request fails or the gateway rejects it.
+func (a *CEPAccount) FindTransaction(ctx context.Context, txID string) (map[string]interface{}, error) {
+	if outcome, ok := a.cachedOutcome(txID); ok {
+		return outcome, nil
+	}
+	return a.findTransaction(ctx, txID)
+}
+
+// findTransaction searches for txID as FindTransaction does, always asking the gateway.
+func (a *CEPAccount) findTransaction(ctx context.Context, txID string) (map[string]interface{}, error) {
+	v := a.view()
+	if v.NAGURL == "" {
+		return nil, ErrNetworkNotSet
+	}
+	ctx = ensureRequestID(ctx)
+	for _, depth := range findDepths {
+		resp, err := a.postNAG(ctx, transactionByIDEndpoint, v.transactionQuery(txID, 0, depth))
+		if err != nil {
+			return nil, err
+		}
+		if resp.Result == ResultTransactionNotFound || strings.EqualFold(resp.message(), "Transaction Not Found") {
+			a.logf(LogDebug, "FindTransaction: %s not in the last %d blocks\n", txID, depth)
+			continue
+		}
+		if err := resp.resultError(""); err != nil {
+			return nil, err
+		}
+		var transaction map[string]interface{}
+		if err := json.Unmarshal(resp.Response, &transaction); err != nil {
+			return nil, fmt.Errorf("unexpected transaction format: %w", err)
+		}
+		return transaction, nil
+	}
+	return nil, fmt.Errorf("%w: %s is not in the last %d blocks", ErrTransactionNotFound, txID, findDepths[len(findDepths)-1])
+}
@@ -0,0 +1,49 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindTransaction(t *testing.T) {
+	var depths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query map[string]string
+		json.NewDecoder(r.Body).Decode(&query)
+		depths = append(depths, query["End"])
+		switch {
+		case query["ID"] == "dead":
+			fmt.Fprint(w, `{"Result":114,"Response":"Rejected: Invalid Blockchain"}`)
+		case query["ID"] == "abc123" && query["End"] == "1000":
+			fmt.Fprint(w, `{"Result":200,"Response":{"ID":"abc123","BlockID":"4711","Status":"Executed"}}`)
+		default:
+			fmt.Fprint(w, `{"Result":118,"Response":"Transaction Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	tx, err := acc.FindTransaction(t.Context(), "0xabc123")
+	if err != nil || tx["BlockID"] != "4711" {
+		t.Fatalf("Expected the transaction's details, got %v, %v", tx, err)
+	}
+	if fmt.Sprint(depths) != "[10 100 1000]" {
+		t.Errorf("Expected the search to widen until found, got depths %v", depths)
+	}
+
+	depths = nil
+	if _, err := acc.FindTransaction(t.Context(), "def456"); !errors.Is(err, ErrTransactionNotFound) || len(depths) != len(findDepths) {
+		t.Errorf("Expected ErrTransactionNotFound after %d searches, got %v after %d", len(findDepths), err, len(depths))
+	}
+	var apiErr *APIError
+	if _, err := acc.FindTransaction(t.Context(), "dead"); !errors.As(err, &apiErr) || apiErr.ResultCode != 114 {
+		t.Errorf("Expected the gateway's rejection, got %v", err)
+	}
+}
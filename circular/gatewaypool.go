@@ -0,0 +1,185 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// gatewayProbeTimeout bounds each gateway probe.
+const gatewayProbeTimeout = 5 * time.Second
+
+// GatewayPool lists interchangeable gateways of one network, such as regional NAGs, for
+// SelectGateway to choose between.
+type GatewayPool struct {
+	Name         string   // The network identifier, e.g. "mainnet".
+	BaseURLs     []string // The base URL of each gateway.
+	PathTemplate string   // The gateways' path layout; empty means LegacyPathTemplate.
+}
+
+// Validate checks that the pool has at least one gateway and that each is a valid
+// NetworkProfile.
+func (p *GatewayPool) Validate() error {
+	if len(p.BaseURLs) == 0 {
+		return fmt.Errorf("gateway pool %q has no gateways", p.Name)
+	}
+	for _, profile := range p.profiles() {
+		if err := profile.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profiles returns the NetworkProfile of each gateway in the pool.
+func (p *GatewayPool) profiles() []NetworkProfile {
+	profiles := make([]NetworkProfile, len(p.BaseURLs))
+	for i, baseURL := range p.BaseURLs {
+		profiles[i] = NetworkProfile{Name: p.Name, BaseURL: baseURL, PathTemplate: p.PathTemplate}
+	}
+	return profiles
+}
+
+// GatewayProbe is the outcome of probing one gateway of a pool.
+type GatewayProbe struct {
+	BaseURL string        // The gateway probed.
+	Latency time.Duration // How long the gateway took to answer; zero if it failed.
+	Err     error         // Why the gateway is considered unhealthy; nil if it is healthy.
+}
+
+// SelectGateway probes every gateway of pool concurrently and routes the account to the
+// healthy one with the lowest latency. Selection is sticky: if the account already uses
+// a gateway of the pool that is still healthy, it is kept even when another answers
+// faster, so that latency jitter does not move a session between gateways.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the probes.
+//   - pool: The gateways to choose between.
+//
+// Returns:
+//
+//	The probe of each gateway, in the pool's order, and an error if the pool is invalid
+//	or no gateway is healthy, in which case the account is unchanged.
+func (a *CEPAccount) SelectGateway(ctx context.Context, pool GatewayPool) ([]GatewayProbe, error) {
+	if err := pool.Validate(); err != nil {
+		return nil, err
+	}
+	profiles := pool.profiles()
+	probes := make([]GatewayProbe, len(profiles))
+	var wg sync.WaitGroup
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probes[i] = a.probeGateway(ctx, profile)
+		}()
+	}
+	wg.Wait()
+
+	current := a.view().NAGURL
+	best := -1
+	for i, probe := range probes {
+		if probe.Err != nil {
+			continue
+		}
+		if probe.BaseURL == current {
+			return probes, nil
+		}
+		if best < 0 || probe.Latency < probes[best].Latency {
+			best = i
+		}
+	}
+	if best < 0 {
+		return probes, fmt.Errorf("no healthy gateway in pool %q: %w", pool.Name, probes[0].Err)
+	}
+	if err := a.SetNetworkProfile(profiles[best]); err != nil {
+		return probes, err
+	}
+	a.logf(LogInfo, "SelectGateway: routing %s through %s (%s)\n", pool.Name, probes[best].BaseURL, probes[best].Latency)
+	return probes, nil
+}
+
+// WatchGateways selects a gateway of pool with SelectGateway, and then probes the pool
+// again every interval until ctx is done, moving the account to the fastest healthy
+// gateway whenever the one in use becomes unhealthy. Failed re-probes are logged and
+// keep the current gateway.
+//
+// Parameters:
+//   - ctx: Stops the watch when done.
+//   - pool: The gateways to choose between.
+//   - interval: How often to probe the gateways.
+//
+// Returns:
+//
+//	An error if the interval is not positive or no gateway can be selected initially,
+//	in which case nothing is watched.
+func (a *CEPAccount) WatchGateways(ctx context.Context, pool GatewayPool, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+	if _, err := a.SelectGateway(ctx, pool); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := a.SelectGateway(ctx, pool); err != nil {
+					a.logf(LogWarn, "WatchGateways: keeping current gateway: %v\n", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// probeGateway times a version request to the gateway described by profile. Any answer
+// in the NAG envelope counts as healthy, since gateways that do not advertise versions
+// still reply with a result code.
+func (a *CEPAccount) probeGateway(ctx context.Context, profile NetworkProfile) GatewayProbe {
+	probe := GatewayProbe{BaseURL: profile.BaseURL}
+	ctx, cancel := context.WithTimeout(ensureRequestID(ctx), gatewayProbeTimeout)
+	defer cancel()
+
+	jsonData, err := json.Marshal(map[string]string{"Version": a.view().CodeVersion})
+	if err != nil {
+		probe.Err = fmt.Errorf("failed to marshal request data: %w", err)
+		return probe
+	}
+	req, err := a.newNAGRequest(ctx, profile.URL(versionEndpoint), jsonData, RequestIDFromContext(ctx))
+	if err != nil {
+		probe.Err = err
+		return probe
+	}
+
+	start := time.Now()
+	resp, err := a.view().do(req)
+	if err != nil {
+		probe.Err = fmt.Errorf("gateway %s is unreachable: %w", profile.BaseURL, err)
+		return probe
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		probe.Err = fmt.Errorf("gateway %s returned HTTP %d", profile.BaseURL, resp.StatusCode)
+		return probe
+	}
+	body, _, err := decodeBody(resp.Body, a.view().responseLimit())
+	latency := time.Since(start)
+	if err == nil {
+		err = json.Unmarshal(body, &nagResponse{})
+	}
+	if err != nil {
+		probe.Err = fmt.Errorf("gateway %s returned an undecodable response: %w", profile.BaseURL, err)
+		return probe
+	}
+	probe.Latency = latency
+	return probe
+}
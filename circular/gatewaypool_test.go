@@ -0,0 +1,81 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newProbedGateway starts a mock gateway answering version probes after delay.
+func newProbedGateway(t *testing.T, delay time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		fmt.Fprint(w, `{"Result":200,"Response":{"Version":"1.0.13"}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSelectGateway(t *testing.T) {
+	slow := newProbedGateway(t, 50*time.Millisecond)
+	fast := newProbedGateway(t, 0)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	pool := GatewayPool{Name: "testnet", BaseURLs: []string{slow.URL + "/?cep=", broken.URL + "/?cep=", fast.URL + "/?cep="}}
+	acc := NewCEPAccount()
+	probes, err := acc.SelectGateway(t.Context(), pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 3 || probes[0].Err != nil || probes[1].Err == nil || probes[2].Err != nil {
+		t.Fatalf("Unexpected probes: %+v", probes)
+	}
+	if acc.NAGURL != fast.URL+"/?cep=" || acc.NetworkNode != "testnet" {
+		t.Errorf("Expected the fastest gateway to be selected, got %s", acc.NAGURL)
+	}
+
+	// A healthy gateway in use is kept even though another is faster.
+	acc.SetNetworkProfile(NetworkProfile{Name: "testnet", BaseURL: slow.URL + "/?cep="})
+	if _, err := acc.SelectGateway(t.Context(), pool); err != nil {
+		t.Fatal(err)
+	}
+	if acc.NAGURL != slow.URL+"/?cep=" {
+		t.Errorf("Expected the selection to be sticky, got %s", acc.NAGURL)
+	}
+
+	broken.Close()
+	if _, err := acc.SelectGateway(t.Context(), GatewayPool{Name: "testnet", BaseURLs: []string{broken.URL + "/?cep="}}); err == nil {
+		t.Error("Expected an error when no gateway is healthy")
+	}
+	if acc.NAGURL != slow.URL+"/?cep=" {
+		t.Errorf("Expected the account to be unchanged, got %s", acc.NAGURL)
+	}
+}
+
+func TestWatchGatewaysFailsOver(t *testing.T) {
+	primary := newProbedGateway(t, 0)
+	secondary := newProbedGateway(t, 30*time.Millisecond)
+	pool := GatewayPool{Name: "testnet", BaseURLs: []string{primary.URL + "/?cep=", secondary.URL + "/?cep="}}
+
+	acc := NewCEPAccount()
+	if err := acc.WatchGateways(t.Context(), pool, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if got := acc.view().NAGURL; got != primary.URL+"/?cep=" {
+		t.Fatalf("Expected the primary gateway to be selected, got %s", got)
+	}
+
+	primary.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for acc.view().NAGURL != secondary.URL+"/?cep=" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the account to fail over to the secondary gateway")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,94 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	testCases := []struct {
+		name    string
+		network string
+		guard   Guard
+		env     map[string]string
+		allowed bool
+	}{
+		{name: "testnet by default", network: "testnet", allowed: true},
+		{name: "mainnet by default", network: "mainnet", allowed: false},
+		{name: "mainnet allowed", network: "mainnet", guard: Guard{AllowMainnet: true}, allowed: true},
+		{name: "mainnet allowed by env", network: "MainNet", env: map[string]string{AllowMainnetEnv: "true"}, allowed: true},
+		{name: "read-only network", network: "testnet", guard: Guard{ReadOnly: []string{"testnet"}}, allowed: false},
+		{name: "read-only overrides allow", network: "mainnet", guard: Guard{AllowMainnet: true, ReadOnly: []string{"mainnet"}}, allowed: false},
+		{name: "other network read-only", network: "devnet", guard: Guard{ReadOnly: []string{"testnet"}}, allowed: true},
+		{name: "read-only env list", network: "testnet", env: map[string]string{ReadOnlyEnv: "devnet, testnet"}, allowed: false},
+		{name: "read-only env everywhere", network: "devnet", env: map[string]string{ReadOnlyEnv: "1"}, allowed: false},
+		{name: "read-only env disabled", network: "devnet", env: map[string]string{ReadOnlyEnv: "false"}, allowed: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+			var submissions atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+					submissions.Add(1)
+					fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+					return
+				}
+				fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":1}}`)
+			}))
+			defer server.Close()
+
+			acc := NewCEPAccount()
+			acc.NAGURL = server.URL + "/?cep="
+			acc.NetworkNode = tc.network
+			acc.Open("0xabcdef")
+			acc.SetGuard(tc.guard)
+
+			signer, _ := NewPrivateKeySigner(testPrivateKey)
+			_, err := acc.SubmitCertificates(t.Context(), []string{"data"}, signer)
+			var guardErr *GuardError
+			if tc.allowed {
+				if err != nil {
+					t.Fatalf("Expected the write to be permitted, got: %v", err)
+				}
+				if submissions.Load() != 1 {
+					t.Errorf("Expected 1 submission, got %d", submissions.Load())
+				}
+				return
+			}
+			if !errors.As(err, &guardErr) {
+				t.Fatalf("Expected a GuardError, got: %v", err)
+			}
+			if guardErr.Network != tc.network || guardErr.Operation != "Circular_AddTransaction_" {
+				t.Errorf("Unexpected GuardError: %+v", guardErr)
+			}
+			if submissions.Load() != 0 {
+				t.Errorf("Expected nothing to be submitted, got %d submissions", submissions.Load())
+			}
+		})
+	}
+}
+
+func TestGuardPermitsReads(t *testing.T) {
+	t.Setenv(ReadOnlyEnv, "true")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = "mainnet"
+	acc.Open("0xabcdef")
+
+	if _, err := acc.GetBlock(t.Context(), 1); err != nil {
+		t.Fatalf("Expected reads to be permitted on a read-only mainnet, got: %v", err)
+	}
+}
@@ -1,4 +1,18 @@
-package utils
+// Package helpers provides the hex and timestamp encodings used throughout the Circular
+// Enterprise APIs. The functions are total: they never panic and never return an error,
+// and their behaviour on unusual input (empty strings, NUL bytes, invalid hex) is part of
+// their documented contract, so that external tools hashing or signing transactions
+// produce byte-identical results to the SDK.
+//
+// Hex prefixes: every function of the SDK that takes hex accepts it with or without a
+// "0x" or "0X" prefix and in either case. Hex the SDK emits (transaction IDs, addresses
+// and blockchain IDs on the wire, keys, signatures and digests) is in the canonical form
+// returned by HexFix: lowercase, unprefixed and of even length. Use Normalize0x where a
+// prefixed form is expected, such as for display. The one exception is a transaction's
+// payload, which is hashed into its ID verbatim: it is uppercase as produced by
+// StringToHex, and only its prefix is removed, with Strip0x, because changing its case
+// would change the ID.
+package helpers
 
 import (
 	"encoding/hex"
@@ -25,6 +39,9 @@ func PadNumber(num int) string {
 	return strconv.Itoa(num)
 }
 
+// TimestampLayout is the time.Format layout of timestamps produced by GetFormattedTimestamp.
+const TimestampLayout = "2006:01:02-15:04:05"
+
 // GetFormattedTimestamp generates a UTC timestamp string in the format "YYYY:MM:DD-HH:MM:SS".
 // This format is specifically designed for internal use within the Circular Enterprise APIs
 // to ensure consistent time representation across various operations, such as transaction
@@ -34,7 +51,7 @@ func PadNumber(num int) string {
 //
 //	A string representing the current UTC timestamp in "YYYY:MM:DD-HH:MM:SS" format.
 func GetFormattedTimestamp() string {
-	return time.Now().UTC().Format("2006:01:02-15:04:05")
+	return time.Now().UTC().Format(TimestampLayout)
 }
 
 // HexFix normalizes and sanitizes a given hexadecimal string to a consistent format.
@@ -46,6 +63,11 @@ func GetFormattedTimestamp() string {
 //     by prepending a "0" if its length is odd. This is crucial for correct
 //     byte-level decoding.
 //
+// HexFix does not validate its input: non-hex characters are lowercased and passed
+// through unchanged, so callers that require valid hex must check the result (e.g.,
+// with hex.DecodeString). HexFix is idempotent except for inputs that, after one pass,
+// again begin with "0x" (e.g., "0x0x12").
+//
 // Parameters:
 //   - hexStr: The input string to be normalized, which may or may not be a valid hexadecimal string.
 //
@@ -57,13 +79,8 @@ func HexFix(hexStr string) string {
 		return ""
 	}
 
-	// Remove "0x" or "0X" prefix
-	if strings.HasPrefix(hexStr, "0x") || strings.HasPrefix(hexStr, "0X") {
-		hexStr = hexStr[2:]
-	}
-
-	// Convert to lower
-	hexStr = strings.ToLower(hexStr)
+	// Remove "0x" or "0X" prefix and convert to lower
+	hexStr = strings.ToLower(Strip0x(hexStr))
 
 	// Pad with '0' if length is odd
 	if len(hexStr)%2 != 0 {
@@ -73,10 +90,55 @@ func HexFix(hexStr string) string {
 	return hexStr
 }
 
+// Strip0x removes a single leading "0x" or "0X" prefix from s and leaves it otherwise
+// unchanged: unlike HexFix, it neither changes case nor pads. It is meant for values
+// hashed or compared verbatim, such as transaction payloads.
+//
+// Parameters:
+//   - s: The string to strip, with or without prefix.
+//
+// Returns:
+//
+//	s without its prefix.
+//
+// Example:
+//
+//	"0xABC" -> "ABC"
+func Strip0x(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s[2:]
+	}
+	return s
+}
+
+// Normalize0x returns the canonical prefixed form of a hexadecimal string: "0x" followed
+// by HexFix of it. Like HexFix it does not validate its input, and it returns an empty
+// string for empty input, or a bare prefix, rather than "0x".
+//
+// Parameters:
+//   - hexStr: The hexadecimal string, with or without prefix and in either case.
+//
+// Returns:
+//
+//	The lowercase, even-length hexadecimal string prefixed with "0x".
+//
+// Example:
+//
+//	"ABC" -> "0x0abc"
+func Normalize0x(hexStr string) string {
+	fixed := HexFix(hexStr)
+	if fixed == "" {
+		return ""
+	}
+	return "0x" + fixed
+}
+
 // StringToHex converts a standard UTF-8 string into its hexadecimal representation.
 // Each character in the input string is first converted to its UTF-8 byte sequence,
 // and then each byte is encoded as two hexadecimal characters (0-F).
-// The resulting hexadecimal string is always in uppercase.
+// The resulting hexadecimal string is always in uppercase and exactly twice the byte
+// length of s. Bytes are encoded verbatim: NUL bytes become "00" and invalid UTF-8
+// sequences are encoded as-is rather than replaced.
 // This function is essential for preparing string data for cryptographic operations
 // or for storage in systems that require hexadecimal encoding.
 //
@@ -105,6 +167,8 @@ func StringToHex(s string) string {
 //   - It gracefully handles optional "0x" or "0X" prefixes, removing them before decoding.
 //   - If the input hexadecimal string contains invalid characters or has an odd length
 //     (which would result in an incomplete byte), the function will return an empty string.
+//   - Decoded bytes are returned verbatim: NUL bytes are preserved and no UTF-8
+//     validation is performed, so HexToString(StringToHex(s)) == s for every s.
 //
 // This behavior aligns with the error handling in the corresponding Java implementation,
 // ensuring consistency across different API versions.
@@ -128,9 +192,7 @@ func HexToString(hexStr string) string {
 	}
 
 	// Remove "0x" or "0X" prefix if present
-	if strings.HasPrefix(hexStr, "0x") || strings.HasPrefix(hexStr, "0X") {
-		hexStr = hexStr[2:]
-	}
+	hexStr = Strip0x(hexStr)
 
 	// Convert to lowercase to ensure consistent input for hex.DecodeString
 	// (though hex.DecodeString is case-insensitive, this can prevent subtle issues)
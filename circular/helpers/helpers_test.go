@@ -1,7 +1,10 @@
-package utils
+package helpers
 
 import (
+	"encoding/hex"
+	"strings"
 	"testing"
+	"testing/quick"
 )
 
 func TestPadNumber(t *testing.T) {
@@ -136,3 +139,135 @@ func TestHexToString(t *testing.T) {
 		}
 	}
 }
+
+func TestHexRoundTripProperty(t *testing.T) {
+	roundTrip := func(s string) bool {
+		return HexToString(StringToHex(s)) == s
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+
+	// Arbitrary bytes, including NUL and invalid UTF-8, survive the round trip.
+	bytesRoundTrip := func(b []byte) bool {
+		return HexToString(StringToHex(string(b))) == string(b)
+	}
+	if err := quick.Check(bytesRoundTrip, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStringToHexProperty(t *testing.T) {
+	shape := func(s string) bool {
+		h := StringToHex(s)
+		return len(h) == 2*len(s) && h == strings.ToUpper(h)
+	}
+	if err := quick.Check(shape, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHexFixProperty(t *testing.T) {
+	// For every valid hex input, with or without prefix and in any case, HexFix yields
+	// the canonical lowercase encoding, which is itself a fixed point of HexFix.
+	normalized := func(b []byte, prefixed bool, upper bool) bool {
+		want := hex.EncodeToString(b)
+		in := want
+		if upper {
+			in = strings.ToUpper(in)
+		}
+		if prefixed {
+			in = "0x" + in
+		}
+		out := HexFix(in)
+		return out == want && HexFix(out) == out
+	}
+	if err := quick.Check(normalized, nil); err != nil {
+		t.Error(err)
+	}
+
+	// Odd-length input is left-padded to a whole number of bytes.
+	padded := func(b []byte) bool {
+		want := hex.EncodeToString(append([]byte{0x0f}, b...))
+		return HexFix(want[1:]) == want
+	}
+	if err := quick.Check(padded, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrip0x(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"0x", ""},
+		{"0X", ""},
+		{"0xABC", "ABC"},
+		{"0Xabc", "abc"},
+		{"ABC", "ABC"},
+		{"abc", "abc"},
+		{"0", "0"},
+		{"x12", "x12"},
+		{"00x12", "00x12"},
+		{"0x0x12", "0x12"}, // Only one prefix is removed.
+		{" 0x12", " 0x12"},
+	}
+
+	for _, test := range tests {
+		actual := Strip0x(test.input)
+		if actual != test.expected {
+			t.Errorf("Strip0x(%q): Expected %q, Got %q", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestNormalize0x(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"0x", ""},
+		{"0X", ""},
+		{"abc", "0x0abc"},
+		{"ABC", "0x0abc"},
+		{"0xABC", "0x0abc"},
+		{"0XaBcD", "0xabcd"},
+		{"0", "0x00"},
+		{"ff", "0xff"},
+		{"0x0x12", "0x0x12"}, // Not validated, like HexFix.
+	}
+
+	for _, test := range tests {
+		actual := Normalize0x(test.input)
+		if actual != test.expected {
+			t.Errorf("Normalize0x(%q): Expected %q, Got %q", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestHexPrefixProperty(t *testing.T) {
+	// Every spelling of the same bytes has the same canonical forms, and the canonical
+	// forms convert into each other.
+	forms := func(b []byte) bool {
+		if len(b) == 0 {
+			return true
+		}
+		lower := hex.EncodeToString(b)
+		upper := strings.ToUpper(lower)
+		for _, in := range []string{lower, upper, "0x" + lower, "0X" + upper, "0x" + upper} {
+			if HexFix(in) != lower || Normalize0x(in) != "0x"+lower || HexToString(in) != string(b) {
+				return false
+			}
+			if stripped := Strip0x(in); stripped != lower && stripped != upper {
+				return false
+			}
+		}
+		return HexFix(Normalize0x(lower)) == lower && Normalize0x(Normalize0x(lower)) == "0x"+lower
+	}
+	if err := quick.Check(forms, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,195 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPOptions hardens the account's connections to its gateway against IPv6 and DNS
+// failures; see SetHTTPOptions. Zero values select the defaults noted on each field.
+type HTTPOptions struct {
+	// DialTimeout limits how long establishing a connection may take; zero means 30s.
+	DialTimeout time.Duration
+
+	// FallbackDelay configures Happy Eyeballs (RFC 6555) dialing of hosts with both IPv6
+	// and IPv4 addresses: how long an attempt over the preferred family runs before one
+	// over the other family is started in parallel. Zero means 300ms; a negative delay
+	// disables the fallback, so each address is tried in turn.
+	FallbackDelay time.Duration
+
+	// ResolveAfterFailures is the number of consecutive requests to a host that may fail
+	// in transport before its pooled connections are dropped, so that the next request
+	// resolves the host again and dials a fresh address instead of retrying a dead one.
+	// Zero means 3; a negative value disables the reset.
+	ResolveAfterFailures int
+
+	// PinnedAddrs maps host names to the addresses to dial instead of resolving them,
+	// each an IP address optionally followed by a port. They are tried in order, starting
+	// over from the next address after each reset.
+	PinnedAddrs map[string][]string
+}
+
+// SetHTTPOptions gives the account its own HTTP client configured by opts, used for all
+// of its gateway and node calls. Network discovery with SetNetwork keeps using the
+// package's default client.
+//
+// Parameters:
+//   - opts: The dialing and DNS settings to apply.
+//
+// Returns:
+//
+//	An error if a pinned address is not an IP address, in which case the account is
+//	unchanged.
+func (a *CEPAccount) SetHTTPOptions(opts HTTPOptions) error {
+	pinned := make(map[string][]string, len(opts.PinnedAddrs))
+	for host, addrs := range opts.PinnedAddrs {
+		for _, addr := range addrs {
+			ip := addr
+			if h, _, err := net.SplitHostPort(addr); err == nil {
+				ip = h
+			}
+			if net.ParseIP(ip) == nil {
+				return fmt.Errorf("pinned address %q for %s is not an IP address", addr, host)
+			}
+		}
+		pinned[host] = append([]string(nil), addrs...)
+	}
+	if opts.DialTimeout == 0 {
+		opts.DialTimeout = 30 * time.Second
+	}
+	if opts.ResolveAfterFailures == 0 {
+		opts.ResolveAfterFailures = 3
+	}
+
+	dialer := &pinningDialer{
+		dialer: net.Dialer{Timeout: opts.DialTimeout, FallbackDelay: opts.FallbackDelay, KeepAlive: 30 * time.Second},
+		pinned: pinned,
+		start:  make(map[string]int),
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.DialContext = dialer.DialContext
+	transport := &failoverTransport{
+		base:      base,
+		dialer:    dialer,
+		threshold: opts.ResolveAfterFailures,
+		failures:  make(map[string]int),
+		logf:      a.logf,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.client = &http.Client{Transport: transport}
+	return nil
+}
+
+// do sends req with the account's HTTP client, or the package's default client if
+// SetHTTPOptions has not been called.
+func (v accountView) do(req *http.Request) (*http.Response, error) {
+	if v.client != nil {
+		return v.client.Do(req)
+	}
+	return httpClient.Do(req)
+}
+
+// pinningDialer dials pinned addresses in place of resolving their host names, and
+// other hosts normally.
+type pinningDialer struct {
+	dialer net.Dialer
+	pinned map[string][]string
+
+	mu    sync.Mutex
+	start map[string]int // The pinned address each host's dials start from.
+}
+
+// DialContext connects to addr, trying the host's pinned addresses in order if it has any.
+func (d *pinningDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	addrs := d.pinned[host]
+	if err != nil || len(addrs) == 0 {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+	d.mu.Lock()
+	start := d.start[host]
+	d.mu.Unlock()
+
+	var firstErr error
+	for i := range addrs {
+		target := addrs[(start+i)%len(addrs)]
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			target = net.JoinHostPort(target, port)
+		}
+		conn, err := d.dialer.DialContext(ctx, network, target)
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// rotate makes the host's dials start from its next pinned address.
+func (d *pinningDialer) rotate(host string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n := len(d.pinned[host]); n > 0 {
+		d.start[host] = (d.start[host] + 1) % n
+	}
+}
+
+// failoverTransport counts consecutive transport failures per host and, once a host
+// reaches the threshold, drops the pooled connections and rotates its pinned addresses
+// so that the next request dials afresh.
+type failoverTransport struct {
+	base      *http.Transport
+	dialer    *pinningDialer
+	threshold int
+	logf      func(LogLevel, string, ...interface{})
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	host := req.URL.Hostname()
+	if err != nil {
+		if req.Context().Err() == nil {
+			t.fail(host)
+		}
+		return nil, err
+	}
+	t.mu.Lock()
+	delete(t.failures, host)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+// fail records a transport failure for host and resets its connections at the threshold.
+func (t *failoverTransport) fail(host string) {
+	if t.threshold < 0 {
+		return
+	}
+	t.mu.Lock()
+	t.failures[host]++
+	reset := t.failures[host] >= t.threshold
+	if reset {
+		delete(t.failures, host)
+	}
+	t.mu.Unlock()
+	if !reset {
+		return
+	}
+	t.dialer.rotate(host)
+	t.base.CloseIdleConnections()
+	t.logf(LogWarn, "HTTP: %d consecutive failures reaching %s, re-resolving and reconnecting\n", t.threshold, host)
+}
@@ -0,0 +1,69 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPOptionsPinnedAddrs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer server.Close()
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+
+	acc := NewCEPAccount()
+	// The .invalid domain never resolves, so the request succeeds only if it is pinned.
+	acc.NAGURL = "http://gateway.invalid:" + port + "/?cep="
+	acc.Open("0xabcdef")
+	if err := acc.SetHTTPOptions(HTTPOptions{PinnedAddrs: map[string][]string{"gateway.invalid": {"127.0.0.1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acc.GetBlock(t.Context(), 1); err != nil {
+		t.Fatalf("Expected the pinned address to be dialed, got: %v", err)
+	}
+
+	if err := acc.SetHTTPOptions(HTTPOptions{PinnedAddrs: map[string][]string{"gateway.invalid": {"gateway.example.com"}}}); err == nil {
+		t.Error("Expected a pinned host name to be rejected")
+	}
+}
+
+func TestHTTPOptionsResetAfterFailures(t *testing.T) {
+	// The first pinned address accepts connections and drops them without answering,
+	// like a gateway IP that has gone bad behind a load balancer.
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer dead.Close()
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer live.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = "http://gateway.invalid/?cep="
+	acc.Open("0xabcdef")
+	err := acc.SetHTTPOptions(HTTPOptions{
+		ResolveAfterFailures: 2,
+		PinnedAddrs: map[string][]string{"gateway.invalid": {
+			strings.TrimPrefix(dead.URL, "http://"),
+			strings.TrimPrefix(live.URL, "http://"),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 2 {
+		if _, err := acc.GetBlock(t.Context(), 1); err == nil {
+			t.Fatalf("Expected request %d to reach the dead address and fail", i+1)
+		}
+	}
+	if _, err := acc.GetBlock(t.Context(), 1); err != nil {
+		t.Errorf("Expected the next request to dial the other address, got: %v", err)
+	}
+}
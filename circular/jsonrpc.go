@@ -0,0 +1,223 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error codes reserved by the JSON-RPC 2.0 specification. A JSON-RPC error is reported
+// like a failed NAG call, with its code as the APIError's ResultCode.
+const (
+	JSONRPCParseError     = -32700 // The endpoint could not parse the request.
+	JSONRPCInvalidRequest = -32600 // The request is not a valid JSON-RPC request.
+	JSONRPCMethodNotFound = -32601 // The endpoint does not support the operation.
+	JSONRPCInvalidParams  = -32602 // The operation's parameters were rejected.
+	JSONRPCInternalError  = -32603 // The endpoint failed internally.
+)
+
+// jsonRPCMessages describes the reserved error codes, for errors that carry no message.
+var jsonRPCMessages = map[int]string{
+	JSONRPCParseError:     "parse error",
+	JSONRPCInvalidRequest: "invalid request",
+	JSONRPCMethodNotFound: "operation not supported",
+	JSONRPCInvalidParams:  "invalid parameters",
+	JSONRPCInternalError:  "internal error",
+}
+
+// rpcRequest is a JSON-RPC 2.0 request.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      string          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"error"`
+}
+
+// jsonRPC reports whether the viewed gateway or node is called over JSON-RPC.
+func (v accountView) jsonRPC() bool {
+	return v.node != nil || v.nagProto == ProtocolJSONRPC
+}
+
+// requestTarget returns the URL and body of a call to endpoint with the JSON request
+// jsonData: the gateway's URL for the operation, or for JSON-RPC gateways and nodes
+// their single endpoint with the request wrapped in a JSON-RPC envelope whose ID is
+// requestID.
+func (v accountView) requestTarget(endpoint string, requestID string, jsonData []byte) (string, []byte, error) {
+	if !v.jsonRPC() {
+		return v.endpointURL(endpoint), jsonData, nil
+	}
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: requestID, Method: endpoint, Params: jsonData})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+	return v.NAGURL, body, nil
+}
+
+// rpcEnvelope rewrites a JSON-RPC response body as the equivalent NAG envelope: a result
+// becomes a successful `Response`, and an error's code and message the `Result` code and
+// message of a failed one. Bodies that are not JSON-RPC responses, such as errors from a
+// proxy, are returned unchanged. A response to a request other than id is an error.
+func rpcEnvelope(body []byte, id string) ([]byte, error) {
+	var resp rpcResponse
+	if json.Unmarshal(body, &resp) != nil || resp.JSONRPC == "" {
+		return body, nil
+	}
+	return resp.envelope(id)
+}
+
+// envelope returns the NAG envelope equivalent to the response to request id.
+func (r *rpcResponse) envelope(id string) ([]byte, error) {
+	var got string
+	// Endpoints answer requests they could not parse with a null ID.
+	if json.Unmarshal(r.ID, &got) == nil && got != id {
+		return nil, fmt.Errorf("JSON-RPC response is for request %q, not %q", got, id)
+	}
+	envelope := map[string]interface{}{"Result": ResultOK, "Response": r.Result}
+	if r.Error != nil {
+		envelope = map[string]interface{}{"Result": r.Error.Code, "Response": r.errorMessage()}
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON-RPC response: %w", err)
+	}
+	return data, nil
+}
+
+// errorMessage returns the error's message, falling back to the meaning of a reserved
+// code, followed by its data if there is any.
+func (r *rpcResponse) errorMessage() string {
+	msg := r.Error.Message
+	if msg == "" {
+		msg = jsonRPCMessages[r.Error.Code]
+	}
+	if len(r.Error.Data) > 0 && string(r.Error.Data) != "null" {
+		var data string
+		if json.Unmarshal(r.Error.Data, &data) != nil {
+			data = string(r.Error.Data)
+		}
+		msg += ": " + data
+	}
+	return msg
+}
+
+// postRPCBatch sends one JSON request per element of requestData to endpoint as a
+// single JSON-RPC batch, and returns the responses in request order, correlated by ID;
+// calls the batch response does not answer have a nil response. It reports batched as
+// false if the account does not use JSON-RPC, without sending anything, or if the
+// endpoint does not support batches; the calls must then be made one by one.
+func (a *CEPAccount) postRPCBatch(ctx context.Context, endpoint string, requestData []interface{}) (responses []*nagResponse, batched bool, err error) {
+	v := a.view()
+	if !v.jsonRPC() {
+		return nil, false, nil
+	}
+	if err := v.checkGuard(endpoint); err != nil {
+		return nil, true, err
+	}
+	ctx = ensureRequestID(ctx)
+	requestID := RequestIDFromContext(ctx)
+	requests := make([]rpcRequest, len(requestData))
+	for i, data := range requestData {
+		params, err := json.Marshal(data)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		requests[i] = rpcRequest{JSONRPC: "2.0", ID: fmt.Sprintf("%s-%d", requestID, i), Method: endpoint, Params: params}
+	}
+	jsonData, err := json.Marshal(requests)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	if err := a.checkCompatibility(ctx); err != nil {
+		return nil, true, err
+	}
+	a.pressure.enter()
+	defer a.pressure.leave()
+	if err := a.pressure.wait(ctx); err != nil {
+		return nil, true, fmt.Errorf("gave up waiting for gateway backpressure (request %s): %w", requestID, err)
+	}
+	if err := a.waitRateLimits(ctx, v); err != nil {
+		return nil, true, fmt.Errorf("gave up waiting for the rate limit (request %s): %w", requestID, err)
+	}
+	req, err := a.newNAGRequest(ctx, v.NAGURL, jsonData, requestID)
+	if err != nil {
+		return nil, true, err
+	}
+	a.logCall(ctx, LogDebug, "%s [%s]: Request URL: %s (batch of %d)\n", endpoint, requestID, v.NAGURL, len(requests))
+
+	resp, err := v.do(req)
+	a.degrade.record(v.timeSource().Now(), isGatewayFailure(ctx, err))
+	if err != nil {
+		return nil, true, fmt.Errorf("http request failed (request %s): %w", requestID, err)
+	}
+	defer resp.Body.Close()
+	limit := v.responseLimit()
+	if resp.StatusCode != http.StatusOK {
+		body, err := readBody(resp.Body, limit)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to read response body (request %s): %w", requestID, err)
+		}
+		a.logCall(ctx, LogDebug, "%s [%s]: Response Body: %s\n", endpoint, requestID, string(body))
+		return nil, true, &APIError{
+			Endpoint:      endpoint,
+			HTTPStatus:    resp.StatusCode,
+			Body:          truncateBody(body),
+			RequestID:     resp.Header.Get(RequestIDHeader),
+			CorrelationID: requestID,
+		}
+	}
+
+	body, _, err := decodeBody(resp.Body, limit)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil, true, fmt.Errorf("failed to read response body (request %s): %w", requestID, err)
+	}
+	a.logCall(ctx, LogDebug, "%s [%s]: Response Body: %s\n", endpoint, requestID, string(body))
+	var batch []rpcResponse
+	if err := json.Unmarshal(body, &batch); err != nil {
+		// Endpoints without batch support reject the array with a single error.
+		a.logCall(ctx, LogInfo, "%s [%s]: endpoint does not support JSON-RPC batches, sending calls individually\n", endpoint, requestID)
+		return nil, false, nil
+	}
+	byID := make(map[string]*rpcResponse, len(batch))
+	for i := range batch {
+		var id string
+		if json.Unmarshal(batch[i].ID, &id) == nil {
+			byID[id] = &batch[i]
+		}
+	}
+	responses = make([]*nagResponse, len(requests))
+	for i, request := range requests {
+		rpc, ok := byID[request.ID]
+		if !ok {
+			continue
+		}
+		result := &nagResponse{
+			endpoint:      endpoint,
+			httpStatus:    resp.StatusCode,
+			requestID:     resp.Header.Get(RequestIDHeader),
+			correlationID: request.ID,
+		}
+		if result.body, err = rpc.envelope(request.ID); err != nil {
+			return nil, true, err
+		}
+		if err := json.Unmarshal(result.body, result); err != nil {
+			return nil, true, fmt.Errorf("failed to decode response body (request %s): %w", request.ID, err)
+		}
+		responses[i] = result
+	}
+	return responses, true, nil
+}
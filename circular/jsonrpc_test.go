@@ -0,0 +1,114 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestJSONRPCProfile(t *testing.T) {
+	var requests atomic.Int32
+	var batches atomic.Bool // Whether the gateway accepts batch requests.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/rpc" {
+			t.Errorf("Expected every call at the RPC endpoint, got %s", r.URL)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(string(body), "[") {
+			if !batches.Load() {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600}}`)
+				return
+			}
+			var batch []rpcRequest
+			json.Unmarshal(body, &batch)
+			// Answer out of order, and leave the last call unanswered.
+			var answers []string
+			for i := len(batch) - 2; i >= 0; i-- {
+				var query map[string]string
+				json.Unmarshal(batch[i].Params, &query)
+				answers = append(answers, fmt.Sprintf(`{"jsonrpc":"2.0","id":%q,"result":{"ID":%q,"Status":"Executed"}}`, batch[i].ID, query["ID"]))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(answers, ","))
+			return
+		}
+
+		var req rpcRequest
+		json.Unmarshal(body, &req)
+		switch req.Method {
+		case "Circular_GetBlock_":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"result":{"BlockID":"1"}}`, req.ID)
+		case "Circular_GetTransactionbyID_":
+			var query map[string]string
+			json.Unmarshal(req.Params, &query)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"result":{"ID":%q,"Status":"Executed"}}`, req.ID, query["ID"])
+		case "Circular_GetWalletNonce_":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":"someone-else","result":{"Nonce":1}}`)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"error":{"code":-32601,"data":%q}}`, req.ID, req.Method)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	if err := acc.SetNetworkProfile(NetworkProfile{Name: "rpcnet", BaseURL: server.URL + "/rpc", Protocol: ProtocolJSONRPC}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := acc.GetBlock(t.Context(), 1); err != nil {
+		t.Fatalf("Expected the block over JSON-RPC, got: %v", err)
+	}
+	_, err := acc.GetUsage(t.Context())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ResultCode != JSONRPCMethodNotFound || apiErr.Message != "operation not supported: Circular_GetAccountUsage_" {
+		t.Errorf("Expected the error object as an APIError, got: %v", err)
+	}
+	if _, err := acc.fetchNonce(t.Context(), DefaultChain); err == nil || !strings.Contains(err.Error(), "someone-else") {
+		t.Errorf("Expected a response to another request to be rejected, got: %v", err)
+	}
+
+	// Without batch support, lookups fall back to one call each.
+	txIDs := []string{"aa", "bb", "cc"}
+	requests.Store(0)
+	result, err := acc.GetTransactions(t.Context(), txIDs)
+	if err != nil || len(result.Succeeded) != 3 || requests.Load() != 4 {
+		t.Fatalf("Expected a rejected batch and 3 single calls, got %d requests: %v", requests.Load(), err)
+	}
+
+	batches.Store(true)
+	requests.Store(0)
+	result, _ = acc.GetTransactions(t.Context(), txIDs)
+	if requests.Load() != 1 {
+		t.Errorf("Expected one batch request, got %d", requests.Load())
+	}
+	if len(result.Succeeded) != 2 || len(result.Failed) != 1 || result.Failed[0].Key != "cc" {
+		t.Fatalf("Expected the unanswered call to fail, got %+v", result)
+	}
+	for _, item := range result.Succeeded {
+		if id, _ := item.Value["Response"].(map[string]interface{})["ID"].(string); id != item.Key {
+			t.Errorf("Expected responses correlated by ID, got %s for %s", id, item.Key)
+		}
+	}
+}
+
+func TestJSONRPCProfileValidate(t *testing.T) {
+	for _, profile := range []NetworkProfile{
+		{Name: "rpc", BaseURL: "https://rpc.example.com", Protocol: ProtocolJSONRPC, PathTemplate: "/API/{operation}"},
+		{Name: "grpc", BaseURL: "https://rpc.example.com", Protocol: "grpc"},
+	} {
+		if err := profile.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", profile)
+		}
+	}
+	profile := NetworkProfile{Name: "rpc", BaseURL: "https://rpc.example.com/v1", Protocol: ProtocolJSONRPC}
+	if got := profile.URL("Circular_GetBlock_"); got != profile.BaseURL {
+		t.Errorf("Expected every operation at the base URL, got %s", got)
+	}
+}
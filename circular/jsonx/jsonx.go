@@ -0,0 +1,112 @@
+// Package jsonx navigates decoded JSON values, such as the raw maps returned by
+// CEPAccount.GetTransaction and GetTransactionOutcome, without type assertions that
+// panic when a response changes shape. Every accessor takes a dot-separated path
+// (e.g. "Response.Status" or "Response.Transactions.0.ID", where numeric segments index
+// arrays) and reports with its ok result whether a value of the expected type was found.
+package jsonx
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Get returns the value at path within v. An empty path returns v itself.
+//
+// Parameters:
+//   - v: A value decoded by encoding/json into interface{} (maps, slices and scalars).
+//   - path: The dot-separated path to the value.
+//
+// Returns:
+//
+//	The value and true, or nil and false if any segment of the path does not exist.
+func Get(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// GetString returns the string at path within v.
+func GetString(v interface{}, path string) (string, bool) {
+	value, ok := Get(v, path)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+// GetFloat returns the number at path within v. Numbers decoded as json.Number are
+// converted as well.
+func GetFloat(v interface{}, path string) (float64, bool) {
+	value, ok := Get(v, path)
+	if !ok {
+		return 0, false
+	}
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// GetInt returns the number at path within v if it is a whole number.
+func GetInt(v interface{}, path string) (int64, bool) {
+	f, ok := GetFloat(v, path)
+	if !ok || f != float64(int64(f)) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// GetBool returns the boolean at path within v.
+func GetBool(v interface{}, path string) (bool, bool) {
+	value, ok := Get(v, path)
+	if !ok {
+		return false, false
+	}
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// GetMap returns the object at path within v.
+func GetMap(v interface{}, path string) (map[string]interface{}, bool) {
+	value, ok := Get(v, path)
+	if !ok {
+		return nil, false
+	}
+	m, ok := value.(map[string]interface{})
+	return m, ok
+}
+
+// GetSlice returns the array at path within v.
+func GetSlice(v interface{}, path string) ([]interface{}, bool) {
+	value, ok := Get(v, path)
+	if !ok {
+		return nil, false
+	}
+	s, ok := value.([]interface{})
+	return s, ok
+}
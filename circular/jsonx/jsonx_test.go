@@ -0,0 +1,57 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return v
+}
+
+func TestAccessors(t *testing.T) {
+	v := decode(t, `{"Result":200,"Response":{"Status":"Executed","Final":true,"Transactions":[{"ID":"a"},{"ID":"b"}]}}`)
+
+	if s, ok := GetString(v, "Response.Status"); !ok || s != "Executed" {
+		t.Errorf("GetString() = %q, %v", s, ok)
+	}
+	if f, ok := GetFloat(v, "Result"); !ok || f != 200 {
+		t.Errorf("GetFloat() = %v, %v", f, ok)
+	}
+	if n, ok := GetInt(v, "Result"); !ok || n != 200 {
+		t.Errorf("GetInt() = %v, %v", n, ok)
+	}
+	if b, ok := GetBool(v, "Response.Final"); !ok || !b {
+		t.Errorf("GetBool() = %v, %v", b, ok)
+	}
+	if s, ok := GetString(v, "Response.Transactions.1.ID"); !ok || s != "b" {
+		t.Errorf("GetString() with an index = %q, %v", s, ok)
+	}
+	if m, ok := GetMap(v, "Response"); !ok || len(m) != 3 {
+		t.Errorf("GetMap() = %v, %v", m, ok)
+	}
+	if s, ok := GetSlice(v, "Response.Transactions"); !ok || len(s) != 2 {
+		t.Errorf("GetSlice() = %v, %v", s, ok)
+	}
+}
+
+func TestAccessorsNeverPanic(t *testing.T) {
+	v := decode(t, `{"Result":118,"Response":"Transaction Not Found"}`)
+
+	for _, path := range []string{"Response.Status", "Response.0", "Missing", "Result.Value", "Response.Transactions.5.ID"} {
+		if _, ok := GetString(v, path); ok {
+			t.Errorf("Expected GetString(%q) to report a missing value", path)
+		}
+	}
+	if _, ok := GetMap(nil, "Response"); ok {
+		t.Error("Expected GetMap(nil) to report a missing value")
+	}
+	if _, ok := GetFloat(v, "Response"); ok {
+		t.Error("Expected GetFloat() on a string to fail")
+	}
+}
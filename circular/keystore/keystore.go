@@ -0,0 +1,231 @@
+// Package keystore keeps private keys encrypted at rest under a password. A Keystore is
+// a JSON document holding the key encrypted with AES-256-GCM under a key derived from
+// the password with PBKDF2-SHA256, next to the address and public key in the clear, so
+// that the account can be identified without the password.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+const (
+	// Version is the keystore format written by Encrypt.
+	Version = 1
+
+	// DefaultIterations is the PBKDF2 iteration count used when none is given.
+	DefaultIterations = 600000
+
+	kdfName    = "pbkdf2-sha256"
+	cipherName = "aes-256-gcm"
+	saltLen    = 16
+	keyLen     = 32
+)
+
+// ErrWrongPassword is returned by Decrypt when the password does not open the keystore.
+var ErrWrongPassword = errors.New("keystore: wrong password")
+
+// KDF records how the encryption key is derived from the password.
+type KDF struct {
+	Name       string `json:"name"`       // Always "pbkdf2-sha256".
+	Iterations int    `json:"iterations"` // The PBKDF2 iteration count.
+	Salt       string `json:"salt"`       // The hex-encoded random salt.
+}
+
+// Keystore is an encrypted private key.
+type Keystore struct {
+	Version    int    `json:"version"`    // The format version; see Version.
+	Address    string `json:"address"`    // The address owned by the key; see circular.AddressFromPublicKey.
+	PublicKey  string `json:"publicKey"`  // The hex-encoded, uncompressed public key.
+	KDF        KDF    `json:"kdf"`        // How the encryption key is derived.
+	Cipher     string `json:"cipher"`     // Always "aes-256-gcm".
+	Nonce      string `json:"nonce"`      // The hex-encoded GCM nonce.
+	Ciphertext string `json:"ciphertext"` // The hex-encoded encrypted private key.
+}
+
+// Encrypt encrypts a private key under password. The address and public key are
+// authenticated along with the key, so Decrypt detects if they are altered.
+//
+// Parameters:
+//   - privateKeyHex: The private key in hexadecimal format, with or without "0x" prefix.
+//   - password: The password protecting the key; it must not be empty.
+//   - iterations: The PBKDF2 iteration count, or 0 for DefaultIterations.
+//
+// Returns:
+//
+//	The keystore, or an error if the key or password is invalid.
+func Encrypt(privateKeyHex, password string, iterations int) (*Keystore, error) {
+	if password == "" {
+		return nil, fmt.Errorf("keystore: password must not be empty")
+	}
+	if iterations == 0 {
+		iterations = DefaultIterations
+	}
+	if iterations < 0 {
+		return nil, fmt.Errorf("keystore: iterations must be positive, got %d", iterations)
+	}
+	signer, err := circular.NewPrivateKeySigner(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	key, _ := hex.DecodeString(helpers.HexFix(privateKeyHex))
+
+	ks := &Keystore{
+		Version:   Version,
+		Address:   circular.AddressFromPublicKey(signer.PublicKey()),
+		PublicKey: signer.PublicKey(),
+		KDF:       KDF{Name: kdfName, Iterations: iterations},
+		Cipher:    cipherName,
+	}
+	salt := make([]byte, saltLen)
+	rand.Read(salt)
+	ks.KDF.Salt = hex.EncodeToString(salt)
+
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	ks.Nonce = hex.EncodeToString(nonce)
+	ks.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, key, ks.additionalData()))
+	return ks, nil
+}
+
+// Decrypt opens the keystore with password.
+//
+// Parameters:
+//   - password: The password the keystore was encrypted with.
+//
+// Returns:
+//
+//	A signer holding the key, ErrWrongPassword if the password is wrong or the keystore
+//	was altered, or another error if the keystore is malformed.
+func (ks *Keystore) Decrypt(password string) (*circular.PrivateKeySigner, error) {
+	if ks.Version != Version || ks.KDF.Name != kdfName || ks.Cipher != cipherName {
+		return nil, fmt.Errorf("keystore: unsupported format (version %d, %s, %s)", ks.Version, ks.KDF.Name, ks.Cipher)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid ciphertext: %w", err)
+	}
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("keystore: invalid nonce length %d", len(nonce))
+	}
+	key, err := aead.Open(nil, nonce, ciphertext, ks.additionalData())
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	return circular.NewPrivateKeySigner(hex.EncodeToString(key))
+}
+
+// aead returns the cipher keyed by password and the keystore's KDF parameters.
+func (ks *Keystore) aead(password string) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(ks.KDF.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("keystore: invalid salt")
+	}
+	if ks.KDF.Iterations <= 0 {
+		return nil, fmt.Errorf("keystore: invalid iteration count %d", ks.KDF.Iterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, ks.KDF.Iterations, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the cleartext identity of the key to the ciphertext.
+func (ks *Keystore) additionalData() []byte {
+	return []byte(ks.Address + ":" + ks.PublicKey)
+}
+
+// Save writes ks to path, readable only by its owner. It never overwrites an existing
+// file, so a key cannot be lost by mistake.
+//
+// Parameters:
+//   - path: The file to create.
+//   - ks: The keystore to write.
+//
+// Returns:
+//
+//	An error if the file exists or cannot be written.
+func Save(path string, ks *Keystore) error {
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("keystore: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("keystore: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("keystore: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads a keystore written by Save.
+//
+// Parameters:
+//   - path: The keystore file.
+//
+// Returns:
+//
+//	The keystore, or an error if the file cannot be read or is not a keystore.
+func Load(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	ks, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+	return ks, nil
+}
+
+// Parse decodes a keystore from its JSON form, for keystores kept somewhere other than
+// a file, such as a storage.KV.
+//
+// Parameters:
+//   - data: The keystore's JSON encoding.
+//
+// Returns:
+//
+//	The keystore, or an error if data is not a keystore.
+func Parse(data []byte) (*Keystore, error) {
+	var ks Keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("keystore: invalid keystore: %w", err)
+	}
+	if ks.Version == 0 || ks.Ciphertext == "" {
+		return nil, fmt.Errorf("keystore: not a keystore")
+	}
+	return &ks, nil
+}
@@ -0,0 +1,60 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+const testPrivateKey = "1c7a1a6f9a1b0b4b2b8d25d22c1f1e53f0ad1ad7f3b0a5fd44b99a0b33e97a01"
+
+func TestEncryptDecrypt(t *testing.T) {
+	ks, err := Encrypt("0x"+testPrivateKey, "correct horse", 1000)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	want, _ := circular.NewPrivateKeySigner(testPrivateKey)
+	if ks.PublicKey != want.PublicKey() || ks.Address != circular.AddressFromPublicKey(want.PublicKey()) {
+		t.Errorf("Unexpected identity: %s, %s", ks.Address, ks.PublicKey)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := Save(path, ks); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the keystore to be private, got mode %v", info.Mode())
+	}
+	if err := Save(path, ks); err == nil {
+		t.Error("Expected Save to refuse to overwrite a keystore")
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	signer, err := loaded.Decrypt("correct horse")
+	if err != nil || signer.PublicKey() != want.PublicKey() {
+		t.Fatalf("Expected the original key back, got %v", err)
+	}
+
+	if _, err := loaded.Decrypt("wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Expected ErrWrongPassword, got %v", err)
+	}
+	loaded.Address = "0xattacker"
+	if _, err := loaded.Decrypt("correct horse"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Expected an altered address to be detected, got %v", err)
+	}
+}
+
+func TestEncryptRejectsInvalidInput(t *testing.T) {
+	if _, err := Encrypt(testPrivateKey, "", 1000); err == nil {
+		t.Error("Expected an error for an empty password")
+	}
+	if _, err := Encrypt("zz", "password", 1000); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+}
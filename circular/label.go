@@ -0,0 +1,20 @@
+package circular
+
+// SetLabel attaches a user-defined label, such as "invoices-prod", to the account, so
+// that deployments running many accounts can attribute traffic and failures to the
+// business workload behind each. The label prefixes the account's log messages in
+// brackets, is reported in Stats and as the account label of WriteSLAMetrics, and is
+// recorded in receipts, access records and the Submission that policies evaluate.
+//
+// Parameters:
+//   - label: The label; empty removes it.
+func (a *CEPAccount) SetLabel(label string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.label = label
+}
+
+// Label returns the label set with SetLabel, or an empty string.
+func (a *CEPAccount) Label() string {
+	return a.view().label
+}
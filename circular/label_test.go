@@ -0,0 +1,70 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccountLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = "testnet"
+	acc.Open("0xabcdef")
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	acc.SetLabel("invoices-prod")
+	logger := &recordingLogger{}
+	acc.SetLogger(logger)
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	acc.SetSLATracking(&SLAConfig{})
+	var evaluated *Submission
+	acc.SetPolicy(PolicyFunc(func(s *Submission) error {
+		evaluated = s
+		return nil
+	}))
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	result, err := acc.SubmitAndWait(t.Context(), "data", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.Label() != "invoices-prod" || acc.Stats().Label != "invoices-prod" {
+		t.Errorf("Expected the label to be reported, got %q", acc.Stats().Label)
+	}
+	if len(logger.messages) == 0 || !strings.HasPrefix(logger.messages[0], "[invoices-prod] ") {
+		t.Errorf("Expected log messages prefixed with the label, got %q", logger.messages)
+	}
+	if receipt, _ := store.LoadReceipt(result.TxID); receipt == nil || receipt.Label != "invoices-prod" {
+		t.Errorf("Expected the receipt to carry the label, got %+v", receipt)
+	}
+	if evaluated == nil || evaluated.Label != "invoices-prod" {
+		t.Errorf("Expected policies to see the label, got %+v", evaluated)
+	}
+	var metrics strings.Builder
+	acc.WriteSLAMetrics(&metrics)
+	if !strings.Contains(metrics.String(), `circular_outcomes{network="testnet",account="invoices-prod",result="executed"} 1`) {
+		t.Errorf("Expected metrics labelled with the account, got:\n%s", metrics.String())
+	}
+
+	acc.SetLabel("")
+	metrics.Reset()
+	acc.WriteSLAMetrics(&metrics)
+	if strings.Contains(metrics.String(), "account=") {
+		t.Errorf("Expected no account label once removed, got:\n%s", metrics.String())
+	}
+}
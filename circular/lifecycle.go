@@ -0,0 +1,121 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// errAccountChanged is returned by operations whose result belongs to an address and
+// chain the account no longer has.
+var errAccountChanged = errors.New("account was closed, reopened or switched chain during the operation")
+
+// Lifecycle is the stage of an account's life, derived from its state. An account is
+// created Closed; Open makes it Open, and setting a gateway, with SetNetwork,
+// SetNetworkProfile or SetNodeClient, makes it Configured. It is Submitting while a
+// transaction is being broadcast, and Close returns it to Closed from any stage. A
+// Closed account can be opened again.
+//
+// The account maintains these invariants across the stages:
+//   - A Closed account submits nothing, and its nonce and latest transaction are empty.
+//   - The nonce belongs to the account's address and chain: Open with another address
+//     and SetBlockchain with another chain reset it to zero until UpdateAccount,
+//     RestoreNonce or ResyncNonce fetches it again. Open with another address also
+//     forgets the chain state of SubmitCertificateOn and the cached permissions.
+//   - Each submission on a chain advances the nonce past the one it used, so the nonces
+//     of successive submissions strictly increase; only UpdateAccount and ResyncNonce
+//     set an earlier one.
+//   - The result of an operation that ran while the account was closed, reopened or
+//     switched to another chain is not applied to it.
+type Lifecycle int
+
+const (
+	LifecycleClosed     Lifecycle = iota // No address; see Open.
+	LifecycleOpen                        // An address but no gateway.
+	LifecycleConfigured                  // An address and a gateway; ready to submit.
+	LifecycleSubmitting                  // Configured, with a transaction being broadcast.
+)
+
+var lifecycleNames = []string{"closed", "open", "configured", "submitting"}
+
+func (l Lifecycle) String() string {
+	if l < 0 || int(l) >= len(lifecycleNames) {
+		return fmt.Sprintf("Lifecycle(%d)", int(l))
+	}
+	return lifecycleNames[l]
+}
+
+// Lifecycle returns the account's current stage.
+func (a *CEPAccount) Lifecycle() Lifecycle {
+	v := a.view()
+	switch {
+	case v.Address == "":
+		return LifecycleClosed
+	case v.NAGURL == "" && v.node == nil:
+		return LifecycleOpen
+	case a.inflight.Load() > 0:
+		return LifecycleSubmitting
+	default:
+		return LifecycleConfigured
+	}
+}
+
+// sameSubject reports whether the account is still in the session v was taken in, with
+// v's address and chain; a.mu must be held. Opening another address, switching chain
+// and closing each start a new session, so that an operation that began before Close
+// and Open of the same address, or before a switch to another chain and back, does not
+// count as current.
+func (a *CEPAccount) sameSubject(v accountView) bool {
+	return a.Address != "" && a.session == v.session && sameHex(a.Address, v.Address) && sameHex(a.Blockchain, v.Blockchain)
+}
+
+// setNonce makes nonce the account's next nonce as the result of an operation that
+// started from v, and journals it with reason.
+//
+// Returns:
+//
+//	errAccountChanged, changing nothing, if the account no longer has v's address and
+//	chain.
+func (a *CEPAccount) setNonce(v accountView, nonce int64, reason string) error {
+	a.mu.Lock()
+	current := a.sameSubject(v)
+	if current {
+		a.Nonce = nonce
+	}
+	a.mu.Unlock()
+	if !current {
+		a.logf(LogWarn, "%s: nonce %d for %s not applied: %v\n", reason, nonce, v.Address, errAccountChanged)
+		return errAccountChanged
+	}
+	a.recordNonce(v.Blockchain, nonce, reason)
+	return nil
+}
+
+// applySubmission records txID, sent with nonce by an operation that started from v, as
+// the account's latest transaction and, if advance is set, moves the nonce past nonce.
+// Nothing is recorded if the account no longer has v's address and chain.
+func (a *CEPAccount) applySubmission(v accountView, txID string, nonce int64, advance bool) {
+	a.mu.Lock()
+	current := a.sameSubject(v)
+	if current {
+		a.LatestTxID = txID
+		if advance {
+			a.Nonce = nonce + 1
+		}
+	}
+	a.mu.Unlock()
+	if !current {
+		a.logf(LogWarn, "submission of %s not applied to the account: %v\n", txID, errAccountChanged)
+		return
+	}
+	a.recordSubmission(txID, v.Blockchain, nonce)
+	if advance {
+		a.recordNonce(v.Blockchain, nonce+1, "submission")
+	}
+}
+
+// sameHex reports whether two addresses or chain IDs are equal, ignoring prefix and case.
+func sameHex(a, b string) bool {
+	return helpers.HexFix(a) == helpers.HexFix(b)
+}
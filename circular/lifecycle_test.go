@@ -0,0 +1,379 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/quick"
+)
+
+// nonceGateway is a gateway that accepts every transaction and answers nonce queries
+// with the last nonce it accepted from the address on the chain.
+type nonceGateway struct {
+	mu       sync.Mutex
+	accepted map[string]int64   // The last nonce accepted, by address and chain.
+	sent     map[string][]int64 // The nonces broadcast, by address and chain, in order.
+	hold     chan struct{}      // If set, the next transaction is answered once it is closed.
+	held     chan struct{}      // Closed when the held transaction has been received.
+}
+
+// holdNext makes the gateway receive the next transaction but delay its answer until
+// the returned function is called. The channel is closed once the transaction arrives.
+func (g *nonceGateway) holdNext() (<-chan struct{}, func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	hold, held := make(chan struct{}), make(chan struct{})
+	g.hold, g.held = hold, held
+	return held, sync.OnceFunc(func() { close(hold) })
+}
+
+// increasing reports the first address and chain on which the nonces broadcast do not
+// strictly increase, if any.
+func (g *nonceGateway) increasing() (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, sent := range g.sent {
+		for i := 1; i < len(sent); i++ {
+			if sent[i] <= sent[i-1] {
+				return key, false
+			}
+		}
+	}
+	return "", true
+}
+
+func (g *nonceGateway) reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.accepted = make(map[string]int64)
+	g.sent = make(map[string][]int64)
+}
+
+func (g *nonceGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var hold chan struct{}
+	defer func() {
+		if hold != nil {
+			<-hold
+		}
+	}()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+		var query map[string]string
+		json.NewDecoder(r.Body).Decode(&query)
+		fmt.Fprintf(w, `{"Result":200,"Response":{"Nonce":%d}}`, g.accepted[query["Address"]+"/"+query["Blockchain"]])
+	case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+		var tx Transaction
+		json.NewDecoder(r.Body).Decode(&tx)
+		nonce, _ := strconv.ParseInt(tx.Nonce, 10, 64)
+		key := tx.From + "/" + tx.Blockchain
+		g.sent[key] = append(g.sent[key], nonce)
+		g.accepted[key] = max(g.accepted[key], nonce)
+		if g.hold != nil {
+			hold = g.hold
+			close(g.held)
+			g.hold, g.held = nil, nil
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	default:
+		fmt.Fprint(w, `{"Result":200,"Response":{}}`)
+	}
+}
+
+// lifecycleOp is one step of a random sequence of account operations.
+type lifecycleOp struct {
+	Kind uint8 // Selects the operation.
+	Arg  uint8 // Selects its argument, such as the address to open.
+}
+
+// lifecycleModel is the account state the invariants documented on Lifecycle predict.
+type lifecycleModel struct {
+	address, chain, latest string
+	nonce                  int64
+	configured             bool
+	chainNonces            map[string]int64 // SubmitCertificateOn's nonces on other chains, once loaded.
+}
+
+func (m *lifecycleModel) lifecycle() Lifecycle {
+	switch {
+	case m.address == "":
+		return LifecycleClosed
+	case !m.configured:
+		return LifecycleOpen
+	default:
+		return LifecycleConfigured
+	}
+}
+
+// TestLifecycleInvariants applies random operation sequences to an account and checks
+// after each step that its state, lifecycle stage and event journal agree with the
+// model, that closed or unconfigured accounts broadcast nothing, that submissions,
+// including those of SubmitCertificateOn, use the nonce the model predicts, and that the
+// nonces the gateway receives on each chain strictly increase, also when the account
+// switches chains or is reopened while a submission is in flight.
+func TestLifecycleInvariants(t *testing.T) {
+	gateway := &nonceGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+	addresses := []string{"aa01", "bb02"}
+	chains := []string{"c001", "c002"}
+	local := NetworkProfile{Name: "local", BaseURL: server.URL + "/?cep="}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	property := func(ops []lifecycleOp) bool {
+		gateway.reset()
+		acc := NewCEPAccount()
+		acc.SetLogLevel(LogSilent)
+		acc.SetEventJournal(4*len(ops)+4, nil)
+		acc.SetBlockchain(chains[0])
+		acc.SetNetworkProfile(local)
+		model := &lifecycleModel{chain: chains[0], configured: true}
+
+		for step, op := range ops {
+			fail := func(format string, args ...interface{}) bool {
+				t.Logf("step %d (%+v) of %+v: %s", step, op, ops, fmt.Sprintf(format, args...))
+				return false
+			}
+			switch op.Kind % 8 {
+			case 0:
+				address := addresses[op.Arg%2]
+				acc.Open(address)
+				if model.address != "" && model.address != address {
+					model.nonce, model.latest, model.chainNonces = 0, "", nil
+				}
+				model.address = address
+			case 1:
+				acc.Close()
+				*model = lifecycleModel{}
+			case 2:
+				acc.SetNetworkProfile(local)
+				model.configured = true
+			case 3:
+				chain := chains[op.Arg%2]
+				acc.SetBlockchain(chain)
+				if model.chain != chain && model.address != "" {
+					// The nonces of the two chains trade places.
+					adopted := model.chainNonces[chain]
+					if model.chainNonces == nil {
+						model.chainNonces = make(map[string]int64)
+					}
+					delete(model.chainNonces, chain)
+					if model.nonce > 0 {
+						model.chainNonces[model.chain] = model.nonce
+					}
+					model.nonce = adopted
+				}
+				model.chain = chain
+			case 4, 6, 7:
+				// Kind 6 submits with SubmitCertificateOn, to the account's chain or another.
+				// Kind 7 submits either way and, while the gateway holds the transaction,
+				// reopens the account with the same address. If Arg is odd, it closes the
+				// account first and restores its gateway and chain, so that the stale
+				// submission finds the account as it left it but in a new session.
+				kind, arg := op.Kind%8, op.Arg
+				if kind == 7 {
+					arg >>= 1
+				}
+				on := kind == 6 || kind == 7 && arg&2 != 0
+				chain, own := model.chain, true
+				if on {
+					chain = chains[arg%2]
+					own = chain == model.chain
+				}
+				if own && model.nonce == 0 && model.lifecycle() == LifecycleConfigured {
+					// As an application would, fetch the nonce rather than submit from zero.
+					if !acc.UpdateAccount() {
+						return fail("UpdateAccount failed: %v", acc.LastErr())
+					}
+					model.nonce = gateway.accepted[model.address+"/"+chain] + 1
+				}
+				expected := model.nonce
+				if !own {
+					var loaded bool
+					if expected, loaded = model.chainNonces[chain]; !loaded {
+						expected = gateway.accepted[model.address+"/"+chain] + 1
+					}
+				}
+				key := model.address + "/" + chain
+				sent := len(gateway.sent[key])
+
+				held, release := gateway.holdNext()
+				type result struct {
+					txID string
+					err  error
+				}
+				done := make(chan result, 1)
+				go func() {
+					var r result
+					if on {
+						r.txID, r.err = acc.SubmitCertificateOn(t.Context(), chain, fmt.Sprintf("step %d", step), signer)
+					} else {
+						r.txID, r.err = acc.submitCertificate(t.Context(), fmt.Sprintf("step %d", step), signer)
+					}
+					done <- r
+				}()
+				var r result
+				reopened := false
+				select {
+				case <-held:
+					switched := make(chan struct{})
+					if reopened = kind == 7 && op.Arg%2 == 1; reopened {
+						acc.Close()
+						acc.Open(model.address)
+						acc.SetNetworkProfile(local)
+						// SetBlockchain waits for a submission in flight on the chain.
+						go func() {
+							acc.SetBlockchain(model.chain)
+							close(switched)
+						}()
+					} else {
+						if kind == 7 {
+							acc.Open(model.address)
+						}
+						close(switched)
+					}
+					release()
+					r = <-done
+					<-switched
+				case r = <-done:
+					release()
+				}
+				txID, err := r.txID, r.err
+
+				switch {
+				case model.address == "":
+					if !errors.Is(err, ErrAccountNotOpen) {
+						return fail("expected ErrAccountNotOpen, got %v", err)
+					}
+				case !model.configured:
+					if !errors.Is(err, ErrNetworkNotSet) {
+						return fail("expected ErrNetworkNotSet, got %v", err)
+					}
+				case err != nil:
+					return fail("submission failed: %v", err)
+				default:
+					if len(gateway.sent[key]) != sent+1 || gateway.sent[key][sent] != expected {
+						return fail("expected nonce %d to be broadcast on %s, got %v", expected, chain, gateway.sent[key])
+					}
+					switch {
+					case reopened:
+						// The submission belongs to the session Close ended.
+						*model = lifecycleModel{address: model.address, chain: model.chain, configured: true}
+					case own:
+						model.nonce++
+						model.latest = txID
+					default:
+						if model.chainNonces == nil {
+							model.chainNonces = make(map[string]int64)
+						}
+						model.chainNonces[chain] = expected + 1
+					}
+				}
+				if err != nil && len(gateway.sent[key]) != sent {
+					return fail("expected a failed submission to broadcast nothing")
+				}
+			case 5:
+				ok := acc.UpdateAccount()
+				if ok != (model.lifecycle() == LifecycleConfigured) {
+					return fail("UpdateAccount returned %v in stage %s", ok, model.lifecycle())
+				}
+				if ok {
+					model.nonce = gateway.accepted[model.address+"/"+model.chain] + 1
+				}
+			}
+
+			if key, ok := gateway.increasing(); !ok {
+				return fail("nonces broadcast to %s do not increase: %v", key, gateway.sent[key])
+			}
+			state := acc.State()
+			if state.Address != model.address || state.Blockchain != model.chain || state.Nonce != model.nonce || state.LatestTxID != model.latest {
+				return fail("state %+v does not match model %+v", state, *model)
+			}
+			if stage := acc.Lifecycle(); stage != model.lifecycle() {
+				return fail("stage %s, model %s", stage, model.lifecycle())
+			}
+			events, _ := acc.Events()
+			replayed := ReplayEvents(events)
+			if replayed.Address != state.Address || replayed.Blockchain != state.Blockchain || replayed.Nonce != state.Nonce || replayed.LatestTxID != state.LatestTxID {
+				return fail("replayed state %+v does not match %+v", replayed, state)
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloseDuringSubmission(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			close(received)
+			<-release
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetLogLevel(LogSilent)
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	done := make(chan error)
+	go func() {
+		_, err := acc.submitCertificate(t.Context(), "report", signer)
+		done <- err
+	}()
+	<-received
+	if stage := acc.Lifecycle(); stage != LifecycleSubmitting {
+		t.Errorf("Expected the account to be submitting, got %s", stage)
+	}
+	acc.Close()
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected the submission to succeed, got: %v", err)
+	}
+	if state := acc.State(); state.Nonce != 0 || state.LatestTxID != "" || acc.Lifecycle() != LifecycleClosed {
+		t.Errorf("Expected the closed account to stay empty, got %+v", state)
+	}
+}
+
+func TestSessionChangesDiscardStaleResults(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.SetLogLevel(LogSilent)
+	acc.Open("0xabcdef")
+	chain := acc.Blockchain
+
+	v := acc.view()
+	acc.Open("0xABCDEF") // The same address: the session goes on.
+	if err := acc.setNonce(v, 3, "update"); err != nil {
+		t.Errorf("Expected the nonce to apply within the session, got %v", err)
+	}
+
+	v = acc.view()
+	acc.Close()
+	acc.Open("0xabcdef")
+	acc.SetBlockchain(chain)
+	if err := acc.setNonce(v, 5, "update"); !errors.Is(err, errAccountChanged) {
+		t.Errorf("Expected a result from before Close and Open to be discarded, got %v", err)
+	}
+
+	v = acc.view()
+	acc.SetBlockchain("0xb0b0")
+	acc.SetBlockchain(chain)
+	acc.applySubmission(v, "0x01", 7, true)
+	if state := acc.State(); state.Nonce == 8 || state.LatestTxID != "" {
+		t.Errorf("Expected a submission from before switching chain and back to be discarded, got %+v", state)
+	}
+}
@@ -0,0 +1,146 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// listPageSize is the number of transactions requested per page by Transactions.
+const listPageSize = 50
+
+// ListTransactions returns one page of the transactions sent or received by address,
+// most recent first, as numbered by the gateway from start up to (not including) end.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - address: The account whose transactions to list.
+//   - start: The index of the first transaction to return.
+//   - end: The index after the last transaction to return.
+//
+// Returns:
+//
+//	The transactions in the page, which is shorter than requested once the history is
+//	exhausted, or an error if the request fails or the response cannot be decoded.
+func (a *CEPAccount) ListTransactions(ctx context.Context, address string, start, end int) ([]map[string]interface{}, error) {
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid transaction range [%d, %d)", start, end)
+	}
+	v := a.view()
+	requestData := map[string]string{
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"Address":    helpers.HexFix(address),
+		"Start":      fmt.Sprintf("%d", start),
+		"End":        fmt.Sprintf("%d", end),
+		"Version":    v.CodeVersion,
+	}
+
+	resp, err := a.postNAG(ctx, "Circular_GetTransactionbyAddress_", requestData)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+
+	var transactions []map[string]interface{}
+	if err := json.Unmarshal(resp.Response, &transactions); err != nil {
+		return nil, fmt.Errorf("unexpected transaction list format: %w", err)
+	}
+	return transactions, nil
+}
+
+// Transactions iterates over every transaction sent or received by address, most
+// recent first. Pages are fetched from the gateway only as the loop consumes them, so
+// breaking out of the loop early stops further requests. If a request fails, the error
+// is yielded with a nil transaction and iteration ends.
+//
+//	for tx, err := range account.Transactions(ctx, address) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - address: The account whose transactions to list.
+func (a *CEPAccount) Transactions(ctx context.Context, address string) iter.Seq2[map[string]interface{}, error] {
+	return func(yield func(map[string]interface{}, error) bool) {
+		ctx := ensureRequestID(ctx)
+		for start := 0; ; start += listPageSize {
+			page, err := a.ListTransactions(ctx, address, start, start+listPageSize)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, tx := range page {
+				if !yield(tx, nil) {
+					return
+				}
+			}
+			if len(page) < listPageSize {
+				return
+			}
+		}
+	}
+}
+
+// GetBlock retrieves a single block, including its transactions.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - blockNumber: The height of the block.
+//
+// Returns:
+//
+//	The block, or an error if the request fails or the gateway rejects it.
+func (a *CEPAccount) GetBlock(ctx context.Context, blockNumber int64) (map[string]interface{}, error) {
+	v := a.view()
+	requestData := map[string]string{
+		"Blockchain":  helpers.HexFix(v.Blockchain),
+		"BlockNumber": fmt.Sprintf("%d", blockNumber),
+		"Version":     v.CodeVersion,
+	}
+
+	resp, err := a.postNAG(ctx, "Circular_GetBlock_", requestData)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+
+	var block map[string]interface{}
+	if err := json.Unmarshal(resp.Response, &block); err != nil {
+		return nil, fmt.Errorf("unexpected block format: %w", err)
+	}
+	return block, nil
+}
+
+// Blocks iterates over the blocks from start to end inclusive, fetching each one only
+// when the loop reaches it, so breaking out of the loop early stops further requests.
+// If a request fails, the error is yielded with a nil block and iteration ends.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - start: The height of the first block.
+//   - end: The height of the last block.
+func (a *CEPAccount) Blocks(ctx context.Context, start, end int64) iter.Seq2[map[string]interface{}, error] {
+	return func(yield func(map[string]interface{}, error) bool) {
+		ctx := ensureRequestID(ctx)
+		for height := start; height <= end; height++ {
+			block, err := a.GetBlock(ctx, height)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(block, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTransactionsPagesLazily(t *testing.T) {
+	const total = listPageSize + 10
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		start, _ := strconv.Atoi(req["Start"])
+		end, _ := strconv.Atoi(req["End"])
+		pages++
+		var txs []string
+		for i := start; i < end && i < total; i++ {
+			txs = append(txs, fmt.Sprintf(`{"ID":"%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"Result":200,"Response":[%s]}`, strings.Join(txs, ","))
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+
+	var count int
+	for tx, err := range acc.Transactions(t.Context(), "0xabcdef") {
+		if err != nil {
+			t.Fatalf("Transactions() error = %v", err)
+		}
+		if tx["ID"] != strconv.Itoa(count) {
+			t.Fatalf("Expected transaction %d, got %v", count, tx["ID"])
+		}
+		count++
+	}
+	if count != total || pages != 2 {
+		t.Errorf("Expected %d transactions over 2 pages, got %d over %d", total, count, pages)
+	}
+
+	pages = 0
+	for range acc.Transactions(t.Context(), "0xabcdef") {
+		break
+	}
+	if pages != 1 {
+		t.Errorf("Expected breaking early to stop after 1 page, got %d", pages)
+	}
+}
+
+func TestBlocksStopsOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["BlockNumber"] == "3" {
+			fmt.Fprint(w, `{"Result":108,"Response":"Block Not Found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"Result":200,"Response":{"BlockID":"%s"}}`, req["BlockNumber"])
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+
+	var heights []string
+	var lastErr error
+	for block, err := range acc.Blocks(t.Context(), 1, 5) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		heights = append(heights, block["BlockID"].(string))
+	}
+	if strings.Join(heights, ",") != "1,2" || lastErr == nil {
+		t.Errorf("Expected blocks 1 and 2 followed by an error, got %v and %v", heights, lastErr)
+	}
+}
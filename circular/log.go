@@ -0,0 +1,111 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LogLevel selects how much an account logs to standard output.
+type LogLevel int
+
+const (
+	LogDebug  LogLevel = iota // Every request and response; the default.
+	LogInfo                   // Retries, top-ups and other notable events.
+	LogWarn                   // Only problems the account recovered from.
+	LogSilent                 // Nothing.
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "silent"}
+
+func (l LogLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the level names
+// "debug", "info", "warn" and "silent" in any case.
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	for i, n := range logLevelNames {
+		if n == name {
+			*l = LogLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q", text)
+}
+
+// Logger receives the messages an account logs in place of standard output; see
+// SetLogger. Implementations must be safe for concurrent use.
+type Logger interface {
+	// Log handles one message, without a trailing newline.
+	Log(level LogLevel, message string)
+}
+
+// SetLogger directs the account's log messages at or above its log level to logger.
+//
+// Parameters:
+//   - logger: Receives the messages; nil restores logging to standard output.
+func (a *CEPAccount) SetLogger(logger Logger) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.logger = logger
+}
+
+// SetLogLevel sets the minimum level of the messages the account logs.
+//
+// Parameters:
+//   - level: The least severe level to log.
+func (a *CEPAccount) SetLogLevel(level LogLevel) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.logLevel = level
+}
+
+// logf prints a message at level, or passes it to the account's Logger, unless the
+// account's log level is above it. Error arguments are formatted with their ErrorCode.
+func (a *CEPAccount) logf(level LogLevel, format string, args ...interface{}) {
+	a.logCall(context.Background(), level, format, args...)
+}
+
+// logCall is logf for a message about the NAG call made with ctx, passing the call's
+// CallOptions to a CallLogger or prefixing the message with their tenant ID. Messages
+// of labelled accounts are prefixed with the label in brackets.
+func (a *CEPAccount) logCall(ctx context.Context, level LogLevel, format string, args ...interface{}) {
+	v := a.view()
+	if level < v.logLevel {
+		return
+	}
+	// Errors are logged with their code, for alerting rules that match on it.
+	for i, arg := range args {
+		if err, ok := arg.(error); ok && err != nil {
+			args[i] = loggedError{err}
+		}
+	}
+	message := fmt.Sprintf(format, args...)
+	if v.label != "" {
+		message = "[" + v.label + "] " + message
+	}
+	call := CallOptionsFromContext(ctx)
+	call.Authorization = ""
+	if callLogger, ok := v.logger.(CallLogger); ok && call != (CallOptions{}) {
+		callLogger.LogCall(level, strings.TrimSuffix(message, "\n"), call)
+		return
+	}
+	if call.TenantID != "" {
+		message = "tenant " + call.TenantID + ": " + message
+	}
+	if v.logger != nil {
+		v.logger.Log(level, strings.TrimSuffix(message, "\n"))
+		return
+	}
+	fmt.Print(message)
+}
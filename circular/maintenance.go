@@ -0,0 +1,258 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMaintenanceDuration bounds the length of a maintenance window.
+const maxMaintenanceDuration = 7 * 24 * time.Hour
+
+// DeferredError is returned by a submission made during a gateway maintenance window;
+// see SetMaintenanceWindows. If the account has a receipt store, the transaction was
+// signed and queued for broadcast once the window ends, and the account's nonce and
+// latest transaction ID were updated as for a broadcast transaction.
+type DeferredError struct {
+	TxID     string    // The queued transaction; empty if it was not queued.
+	ResumeAt time.Time // When the maintenance window is expected to end.
+	Queued   bool      // Whether the transaction awaits broadcast in the receipt store.
+}
+
+func (e *DeferredError) Error() string {
+	if e.Queued {
+		return fmt.Sprintf("gateway is in maintenance until %s: transaction %s queued", e.ResumeAt.Format(time.RFC3339), e.TxID)
+	}
+	return fmt.Sprintf("gateway is in maintenance until %s: no receipt store to queue the submission in", e.ResumeAt.Format(time.RFC3339))
+}
+
+// Code returns CodeMaintenance.
+func (e *DeferredError) Code() ErrorCode {
+	return CodeMaintenance
+}
+
+// MaintenanceWindow is a recurring period during which the gateway is known to be
+// down, starting at the times matched by a cron schedule.
+type MaintenanceWindow struct {
+	spec     string
+	duration time.Duration
+	loc      *time.Location
+	fields   [5]uint64 // Minute, hour, day of month, month and day of week, as bit sets.
+	anyDay   [2]bool   // Whether the day of month and day of week fields are "*".
+}
+
+// ParseMaintenanceWindow parses a maintenance window from a standard five-field cron
+// schedule of its start times: minute, hour, day of month, month and day of week, each
+// "*", a value, a range "a-b" or a comma-separated list of them, optionally with a step
+// "/n". Days of the week run from 0 (Sunday) to 6, with 7 also meaning Sunday. As in
+// cron, when both day fields are restricted a day matching either one matches.
+//
+// Parameters:
+//   - spec: The schedule, e.g. "0 2 * * 0" for 02:00 every Sunday.
+//   - duration: How long each window lasts, at most a week.
+//   - loc: The time zone of the schedule; nil means UTC.
+//
+// Returns:
+//
+//	The window, or an error if spec is malformed or duration is out of range.
+func ParseMaintenanceWindow(spec string, duration time.Duration, loc *time.Location) (*MaintenanceWindow, error) {
+	if duration <= 0 || duration > maxMaintenanceDuration {
+		return nil, fmt.Errorf("maintenance window duration %s is not between 0 and %s", duration, maxMaintenanceDuration)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("maintenance schedule %q must have 5 fields", spec)
+	}
+	w := &MaintenanceWindow{spec: spec, duration: duration, loc: loc}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	for i, part := range parts {
+		set, err := parseCronField(part, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("maintenance schedule %q: %w", spec, err)
+		}
+		w.fields[i] = set
+	}
+	if w.fields[4]&(1<<7) != 0 {
+		w.fields[4] |= 1
+	}
+	w.anyDay = [2]bool{parts[2] == "*", parts[4] == "*"}
+	return w, nil
+}
+
+// String returns the window's schedule and duration.
+func (w *MaintenanceWindow) String() string {
+	return fmt.Sprintf("%q for %s (%s)", w.spec, w.duration, w.loc)
+}
+
+// Active reports whether t falls within the window, and if so when that window ends.
+//
+// Parameters:
+//   - t: The time to check.
+//
+// Returns:
+//
+//	The end of the window containing t, and whether there is one.
+func (w *MaintenanceWindow) Active(t time.Time) (time.Time, bool) {
+	if start, ok := w.latestStart(t); ok {
+		return start.Add(w.duration), true
+	}
+	return time.Time{}, false
+}
+
+// latestStart returns the latest minute at or before t in which a window starts, looking
+// back less than the window's duration. Rather than testing every minute, it skips
+// whole months, days and hours that do not match and jumps to the previous matching
+// minute, so it takes a few hundred steps at most.
+func (w *MaintenanceWindow) latestStart(t time.Time) (time.Time, bool) {
+	has := func(field, value int) bool { return w.fields[field]&(1<<value) != 0 }
+	start := t.In(w.loc).Truncate(time.Minute)
+	for t.Sub(start) < w.duration {
+		year, month, day := start.Date()
+		minute := start.Minute()
+		switch {
+		case !has(3, int(month)):
+			start = time.Date(year, month, 1, 0, 0, 0, 0, w.loc).Add(-time.Minute)
+		case !w.matchesDay(start):
+			start = time.Date(year, month, day, 0, 0, 0, 0, w.loc).Add(-time.Minute)
+		case !has(1, start.Hour()):
+			// Stepped back from the minute rather than rebuilt from the wall clock, which
+			// names the first of the two hours repeated when clocks go back.
+			start = start.Add(-time.Duration(minute+1) * time.Minute)
+		case !has(0, minute):
+			// The highest matching minute below this one in the same hour, if any.
+			back := minute + 1
+			if earlier := w.fields[0] & (1<<minute - 1); earlier != 0 {
+				back = minute - (bits.Len64(earlier) - 1)
+			}
+			start = start.Add(-time.Duration(back) * time.Minute)
+		default:
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// matchesDay reports whether the day of t, in the window's time zone, matches the day of
+// month and day of week fields.
+func (w *MaintenanceWindow) matchesDay(t time.Time) bool {
+	t = t.In(w.loc)
+	dom, dow := w.fields[2]&(1<<t.Day()) != 0, w.fields[4]&(1<<int(t.Weekday())) != 0
+	switch {
+	case w.anyDay[0] && w.anyDay[1]:
+		return true
+	case w.anyDay[0]:
+		return dow
+	case w.anyDay[1]:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseCronField parses one cron field whose values range from lo to hi into a bit set.
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+			step = n
+		}
+		first, last := lo, hi
+		if rangePart != "*" {
+			a, b, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if first, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", item)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", item)
+				}
+			} else if hasStep {
+				last = hi
+			}
+		}
+		if first < lo || last > hi || first > last {
+			return 0, fmt.Errorf("%q is outside %d-%d", item, lo, hi)
+		}
+		for v := first; v <= last; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// SetMaintenanceWindows declares when the gateway is known to be down. During a window,
+// certificate submissions are not sent: they are signed and recorded in the receipt
+// store with status ReceiptQueued, and fail with a *DeferredError giving the expected
+// end of the window, so that callers can retry or wait instead of hammering the gateway.
+// Queued transactions are broadcast before the first submission after the window, or
+// with FlushQueued. Without a receipt store nothing is queued and the nonce is
+// unchanged. Queries are still sent. Calling it with no windows removes them.
+//
+// Parameters:
+//   - windows: The maintenance windows; overlapping windows extend each other.
+func (a *CEPAccount) SetMaintenanceWindows(windows ...*MaintenanceWindow) {
+	a.maint.mu.Lock()
+	defer a.maint.mu.Unlock()
+	a.maint.windows = append([]*MaintenanceWindow(nil), windows...)
+}
+
+// MaintenanceUntil reports whether the gateway is in a maintenance window now, by the
+// account's Clock, and if so when it is expected to end.
+//
+// Returns:
+//
+//	The end of the current window, and whether one is active.
+func (a *CEPAccount) MaintenanceUntil() (time.Time, bool) {
+	return a.maint.until(a.view().timeSource().Now())
+}
+
+// maintenance holds the account's maintenance windows.
+type maintenance struct {
+	mu      sync.Mutex
+	windows []*MaintenanceWindow
+}
+
+// until returns the end of the maintenance covering t, following windows that overlap
+// or adjoin each other, and whether t is in a window at all.
+func (m *maintenance) until(t time.Time) (time.Time, bool) {
+	m.mu.Lock()
+	windows := m.windows
+	m.mu.Unlock()
+
+	end := t
+	// Bounded, since schedules such as "* * * * *" cover all time.
+	for range 100 {
+		extended := false
+		for _, w := range windows {
+			if e, ok := w.Active(end); ok && e.After(end) {
+				end, extended = e, true
+			}
+		}
+		if !extended {
+			break
+		}
+	}
+	return end, end.After(t)
+}
+
+// queuedForLater reports whether err is a DeferredError for a queued transaction, which
+// consumed its nonce like a broadcast one.
+func queuedForLater(err error) bool {
+	var deferred *DeferredError
+	return errors.As(err, &deferred) && deferred.Queued
+}
@@ -0,0 +1,224 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	// 02:00 on Sundays, and on the first of the month, for three hours.
+	w, err := ParseMaintenanceWindow("0 2 1 * 0", 3*time.Hour, nil)
+	if err != nil {
+		t.Fatalf("ParseMaintenanceWindow failed: %v", err)
+	}
+	tests := []struct {
+		at   string
+		want string // The end of the window, or "" if at is outside one.
+	}{
+		{"2026-10-18T01:59:00Z", ""},                     // Sunday, before the window.
+		{"2026-10-18T02:00:00Z", "2026-10-18T05:00:00Z"}, // Sunday.
+		{"2026-10-18T04:59:59Z", "2026-10-18T05:00:00Z"},
+		{"2026-10-18T05:00:00Z", ""},
+		{"2026-10-01T03:30:00Z", "2026-10-01T05:00:00Z"}, // Thursday the 1st.
+		{"2026-10-15T03:30:00Z", ""},                     // Thursday the 15th.
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		got := ""
+		if end, ok := w.Active(at); ok {
+			got = end.UTC().Format(time.RFC3339)
+		}
+		if got != tt.want {
+			t.Errorf("Active(%s) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"0 2 * *", "60 * * * *", "*/0 * * * *", "0 5-2 * * *", "x * * * *"} {
+		if _, err := ParseMaintenanceWindow(spec, time.Hour, nil); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+	if _, err := ParseMaintenanceWindow("0 2 * * 7", 8*24*time.Hour, nil); err == nil {
+		t.Error("Expected a window longer than a week to be rejected")
+	}
+}
+
+func TestMaintenanceDefersSubmissions(t *testing.T) {
+	var broadcast []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			var tx Transaction
+			json.NewDecoder(r.Body).Decode(&tx)
+			broadcast = append(broadcast, tx.Nonce)
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	// Windows are checked against the account's clock: 02:30, in a window from 02:00
+	// to 03:00 that is overlapped by a second one until 04:00.
+	clock := circulartest.NewFakeClock(time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC))
+	acc.SetClock(clock)
+	first, _ := ParseMaintenanceWindow("0 2 * * *", time.Hour, nil)
+	second, _ := ParseMaintenanceWindow("0 3 * * *", time.Hour, nil)
+	acc.SetMaintenanceWindows(first, second)
+	resumeAt, ok := acc.MaintenanceUntil()
+	if want := time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC); !ok || !resumeAt.Equal(want) {
+		t.Fatalf("Expected maintenance until %s, got %v until %s", want, ok, resumeAt)
+	}
+
+	// Without a receipt store, nothing is queued.
+	var deferred *DeferredError
+	_, err := acc.submitCertificate(t.Context(), "one", signer)
+	if !errors.As(err, &deferred) || deferred.Queued || !deferred.ResumeAt.Equal(resumeAt) || acc.Nonce != 0 {
+		t.Fatalf("Expected a DeferredError without queueing, got %v (nonce %d)", err, acc.Nonce)
+	}
+	if ErrorCodeOf(err) != CodeMaintenance {
+		t.Errorf("Expected code %s, got %s", CodeMaintenance, ErrorCodeOf(err))
+	}
+
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	txID, err := acc.submitCertificate(t.Context(), "one", signer)
+	if !errors.As(err, &deferred) || !deferred.Queued || deferred.TxID != txID || acc.Nonce != 1 {
+		t.Fatalf("Expected a queued DeferredError, got %v (nonce %d)", err, acc.Nonce)
+	}
+	if receipt, err := store.LoadReceipt(txID); err != nil || receipt.Status != ReceiptQueued {
+		t.Fatalf("Expected a queued receipt, got %+v, %v", receipt, err)
+	}
+	if len(broadcast) != 0 {
+		t.Fatalf("Expected nothing to be broadcast during maintenance, got %v", broadcast)
+	}
+
+	// After the window, the queue is broadcast before the next submission.
+	clock.Advance(90 * time.Minute)
+	if _, ok := acc.MaintenanceUntil(); ok {
+		t.Fatal("Expected the window to be over")
+	}
+	if _, err := acc.submitCertificate(t.Context(), "two", signer); err != nil {
+		t.Fatalf("Expected the submission to succeed, got: %v", err)
+	}
+	if len(broadcast) != 2 || broadcast[0] != "0" || broadcast[1] != "1" {
+		t.Errorf("Expected the queued transaction to be broadcast first, got %v", broadcast)
+	}
+}
+
+func TestMaintenanceDefersPrecomputedAndRegistrations(t *testing.T) {
+	var broadcast []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			var tx Transaction
+			json.NewDecoder(r.Body).Decode(&tx)
+			broadcast = append(broadcast, tx.Type)
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.PublicKey = signer.PublicKey()
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	clock := circulartest.NewFakeClock(time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC))
+	acc.SetClock(clock)
+	window, _ := ParseMaintenanceWindow("0 2 * * *", time.Hour, nil)
+	acc.SetMaintenanceWindows(window)
+
+	payload := helpers.StringToHex(`{"Action":"CP_CERTIFICATE","Data":"6869"}`)
+	timestamp := "2024:01:02-03:04:05"
+	id := ComputeTransactionID(acc.Blockchain, acc.Address, acc.Address, payload, "7", timestamp)
+	signature, _ := signer.Sign(id)
+	var deferred *DeferredError
+	txID, err := acc.SubmitWithPrecomputedID(t.Context(), PrecomputedTransaction{ID: id, Payload: payload, Signature: signature, Timestamp: timestamp, Nonce: 7})
+	if !errors.As(err, &deferred) || !deferred.Queued || txID != id || acc.Nonce != 8 {
+		t.Fatalf("Expected the precomputed transaction to be queued, got %v (nonce %d)", err, acc.Nonce)
+	}
+
+	wallet, _ := NewPrivateKeySigner(testPrivateKey)
+	address, err := acc.CreateAccount(t.Context(), wallet)
+	if !errors.As(err, &deferred) || !deferred.Queued || address != AddressFromPublicKey(wallet.PublicKey()) {
+		t.Fatalf("Expected the registration to be queued, got %q, %v", address, err)
+	}
+	if receipt, err := store.LoadReceipt(deferred.TxID); err != nil || receipt.Status != ReceiptQueued {
+		t.Fatalf("Expected a queued receipt for the registration, got %+v, %v", receipt, err)
+	}
+	if len(broadcast) != 0 {
+		t.Fatalf("Expected nothing to be broadcast during maintenance, got %v", broadcast)
+	}
+
+	clock.Advance(time.Hour)
+	if n, err := acc.FlushQueued(t.Context()); err != nil || n != 2 {
+		t.Fatalf("FlushQueued() = %d, %v", n, err)
+	}
+	slices.Sort(broadcast)
+	if len(broadcast) != 2 || broadcast[0] != certificateTxType || broadcast[1] != registerWalletTxType {
+		t.Errorf("Expected both transactions to be broadcast after the window, got %v", broadcast)
+	}
+}
+
+func TestMaintenanceWindowActiveMatchesMinuteScan(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// scan finds the window containing at by testing every minute back from it.
+	scan := func(w *MaintenanceWindow, at time.Time) (time.Time, bool) {
+		has := func(field, value int) bool { return w.fields[field]&(1<<value) != 0 }
+		for start := at.Truncate(time.Minute); at.Sub(start) < w.duration; start = start.Add(-time.Minute) {
+			local := start.In(w.loc)
+			if has(0, local.Minute()) && has(1, local.Hour()) && has(3, int(local.Month())) && w.matchesDay(local) {
+				return start.Add(w.duration), true
+			}
+		}
+		return time.Time{}, false
+	}
+	specs := []string{"0 2 * * 0", "30 1 * * *", "*/15 9-17 * * 1-5", "0 0 1 * *", "45 23 31 12 *", "0 3 13 * 5", "* * * * *"}
+	durations := []time.Duration{time.Minute, 90 * time.Minute, 26 * time.Hour, maxMaintenanceDuration}
+	rng := rand.New(rand.NewPCG(1, 2))
+	// From before the 2026 autumn clock change in New York to past the spring one.
+	from := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	span := int64(160 * 24 * time.Hour)
+	for _, spec := range specs {
+		for _, duration := range durations {
+			for _, loc := range []*time.Location{nil, newYork} {
+				w, err := ParseMaintenanceWindow(spec, duration, loc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for range 20 {
+					at := from.Add(time.Duration(rng.Int64N(span)))
+					gotEnd, gotOK := w.Active(at)
+					wantEnd, wantOK := scan(w, at)
+					if gotOK != wantOK || !gotEnd.Equal(wantEnd) {
+						t.Errorf("%s: Active(%s) = %s, %v; want %s, %v", w, at, gotEnd, gotOK, wantEnd, wantOK)
+					}
+				}
+			}
+		}
+	}
+	// The hour repeated when clocks go back holds a start in each of its occurrences.
+	w, _ := ParseMaintenanceWindow("30 1 * * *", time.Hour, newYork)
+	second := time.Date(2026, 11, 1, 6, 10, 0, 0, time.UTC) // 01:10 EST, after 01:30 EDT.
+	if end, ok := w.Active(second); !ok || !end.Equal(time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected the window from 01:30 EDT to be active, got %s, %v", end, ok)
+	}
+}
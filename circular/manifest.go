@@ -0,0 +1,234 @@
+package circular
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestType is the value of the `type` field of a certified manifest.
+const ManifestType = "CP_MANIFEST"
+
+// ManifestEntry records the digest of a single file in a manifest.
+type ManifestEntry struct {
+	Name   string `json:"name"`   // Slash-separated path of the file, relative to the manifest root.
+	Size   int64  `json:"size"`   // Size of the file in bytes.
+	SHA256 string `json:"sha256"` // Lowercase hex SHA-256 digest of the file contents.
+}
+
+// Manifest lists a set of files by name, size and SHA-256 digest so that all of them
+// can be certified in a single transaction. Entries are kept sorted by name, making the
+// serialized manifest deterministic for a given set of files.
+type Manifest struct {
+	Type    string          `json:"type"`    // Always ManifestType.
+	Version string          `json:"version"` // The library version that built the manifest.
+	Entries []ManifestEntry `json:"entries"` // One entry per file, sorted by name.
+}
+
+// ManifestFile names a stream of file contents to be included in a manifest.
+type ManifestFile struct {
+	Name   string    // The name recorded in the manifest entry.
+	Reader io.Reader // The file contents.
+}
+
+// ManifestMismatch describes a file whose local contents differ from its manifest entry.
+type ManifestMismatch struct {
+	Name     string        // The file name.
+	Expected ManifestEntry // The entry recorded in the manifest.
+	Actual   ManifestEntry // The entry computed from the local file.
+}
+
+// ManifestReport is the result of verifying a manifest against a local directory.
+type ManifestReport struct {
+	Matched    []string           // Files whose size and digest match the manifest.
+	Mismatched []ManifestMismatch // Files present locally whose contents differ.
+	Missing    []string           // Files listed in the manifest but absent locally.
+}
+
+// OK reports whether every file in the manifest was found with matching contents.
+func (r *ManifestReport) OK() bool {
+	return len(r.Mismatched) == 0 && len(r.Missing) == 0
+}
+
+// BuildManifest hashes each file and assembles a manifest of their names, sizes and digests.
+//
+// Parameters:
+//   - files: The files to include; names must be unique.
+//
+// Returns:
+//
+//	The manifest, or an error if a name is empty or duplicated or a reader fails.
+func BuildManifest(files []ManifestFile) (*Manifest, error) {
+	m := &Manifest{Type: ManifestType, Version: LibVersion, Entries: make([]ManifestEntry, 0, len(files))}
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		if f.Name == "" {
+			return nil, fmt.Errorf("manifest file name cannot be empty")
+		}
+		if seen[f.Name] {
+			return nil, fmt.Errorf("duplicate manifest file name %q", f.Name)
+		}
+		seen[f.Name] = true
+
+		entry, err := digestEntry(f.Name, f.Reader)
+		if err != nil {
+			return nil, err
+		}
+		m.Entries = append(m.Entries, entry)
+	}
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Name < m.Entries[j].Name })
+	return m, nil
+}
+
+// BuildManifestFromPaths builds a manifest of the files at paths, recording each name
+// relative to baseDir so the manifest can later be verified with VerifyManifest.
+//
+// Parameters:
+//   - baseDir: The directory the recorded names are relative to.
+//   - paths: Paths of the files to include, either absolute or relative to baseDir.
+//
+// Returns:
+//
+//	The manifest, or an error if a file cannot be read or lies outside baseDir.
+func BuildManifestFromPaths(baseDir string, paths ...string) (*Manifest, error) {
+	files := make([]ManifestFile, 0, len(paths))
+	for _, p := range paths {
+		full := p
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(baseDir, p)
+		}
+		rel, err := filepath.Rel(baseDir, full)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("file %q is outside of %q", p, baseDir)
+		}
+		f, err := os.Open(full)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %q: %w", p, err)
+		}
+		defer f.Close()
+		files = append(files, ManifestFile{Name: filepath.ToSlash(rel), Reader: f})
+	}
+	return BuildManifest(files)
+}
+
+// BuildManifestCertificate builds a manifest of the given files and wraps it in a
+// certificate, so a whole set of artifacts can be certified in one transaction with
+// CEPAccount.SubmitCertificate(cert.GetData(), key).
+//
+// Parameters:
+//   - baseDir: The directory the recorded names are relative to.
+//   - paths: Paths of the files to include.
+//
+// Returns:
+//
+//	The certificate and the manifest it contains, or an error if the manifest cannot be built.
+func BuildManifestCertificate(baseDir string, paths ...string) (*CCertificate, *Manifest, error) {
+	m, err := BuildManifestFromPaths(baseDir, paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := m.Certificate()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, m, nil
+}
+
+// JSON returns the serialized manifest.
+func (m *Manifest) JSON() (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return string(data), nil
+}
+
+// Certificate wraps the serialized manifest in a CCertificate.
+func (m *Manifest) Certificate() (*CCertificate, error) {
+	data, err := m.JSON()
+	if err != nil {
+		return nil, err
+	}
+	cert := NewCCertificate()
+	cert.SetData(data)
+	return cert, nil
+}
+
+// ParseManifest decodes a manifest from certificate data.
+//
+// Parameters:
+//   - data: The JSON manifest, as returned by CCertificate.GetData.
+//
+// Returns:
+//
+//	The manifest, or an error if data is not a manifest.
+func ParseManifest(data string) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if m.Type != ManifestType {
+		return nil, fmt.Errorf("unexpected manifest type: %q", m.Type)
+	}
+	return &m, nil
+}
+
+// VerifyManifest re-hashes the files listed in m from dir and reports which match,
+// which differ and which are missing. Files in dir that are not listed are ignored.
+// Manifests come from the chain, so only files inside dir are read: a name that is
+// absolute or climbs out of dir, directly or through a symbolic link, is an error.
+//
+// Parameters:
+//   - m: The manifest to verify.
+//   - dir: The local directory containing the files.
+//
+// Returns:
+//
+//	A report of the verification, or an error if a name lies outside dir or a file
+//	exists but cannot be read.
+func VerifyManifest(m *Manifest, dir string) (*ManifestReport, error) {
+	report := &ManifestReport{}
+	for _, expected := range m.Entries {
+		name := filepath.FromSlash(expected.Name)
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("manifest entry %q is not a path inside the directory", expected.Name)
+		}
+		f, err := os.OpenInRoot(dir, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			report.Missing = append(report.Missing, expected.Name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %q: %w", expected.Name, err)
+		}
+		actual, err := digestEntry(expected.Name, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if actual.Size != expected.Size || actual.SHA256 != expected.SHA256 {
+			report.Mismatched = append(report.Mismatched, ManifestMismatch{Name: expected.Name, Expected: expected, Actual: actual})
+			continue
+		}
+		report.Matched = append(report.Matched, expected.Name)
+	}
+	return report, nil
+}
+
+// digestEntry streams r through SHA-256 and returns its manifest entry.
+func digestEntry(name string, r io.Reader) (ManifestEntry, error) {
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return ManifestEntry{}, fmt.Errorf("failed to hash %q: %w", name, err)
+	}
+	return ManifestEntry{Name: name, Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
@@ -0,0 +1,87 @@
+package circular
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildManifest(t *testing.T) {
+	m, err := BuildManifest([]ManifestFile{
+		{Name: "b.txt", Reader: strings.NewReader("bravo")},
+		{Name: "a.txt", Reader: strings.NewReader("alpha")},
+	})
+	if err != nil {
+		t.Fatalf("BuildManifest failed: %v", err)
+	}
+	if len(m.Entries) != 2 || m.Entries[0].Name != "a.txt" {
+		t.Fatalf("Expected entries sorted by name, got %+v", m.Entries)
+	}
+	if m.Entries[0].Size != 5 || m.Entries[0].SHA256 != "8ed3f6ad685b959ead7022518e1af76cd816f8e8ec7ccdda1ed4018e8f2223f8" {
+		t.Errorf("Unexpected entry: %+v", m.Entries[0])
+	}
+
+	if _, err := BuildManifest([]ManifestFile{{Name: "a", Reader: strings.NewReader("")}, {Name: "a", Reader: strings.NewReader("")}}); err == nil {
+		t.Error("Expected duplicate names to be rejected")
+	}
+}
+
+func TestBuildManifestCertificateAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "bin"), 0o755)
+	os.WriteFile(filepath.Join(dir, "bin", "app"), []byte("binary"), 0o644)
+	os.WriteFile(filepath.Join(dir, "README"), []byte("docs"), 0o644)
+	os.WriteFile(filepath.Join(dir, "CHANGELOG"), []byte("changes"), 0o644)
+
+	cert, _, err := BuildManifestCertificate(dir, "bin/app", "README", "CHANGELOG")
+	if err != nil {
+		t.Fatalf("BuildManifestCertificate failed: %v", err)
+	}
+
+	m, err := ParseManifest(cert.GetData())
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if m.Entries[0].Name != "CHANGELOG" || m.Entries[2].Name != "bin/app" {
+		t.Errorf("Unexpected entry names: %+v", m.Entries)
+	}
+
+	report, err := VerifyManifest(m, dir)
+	if err != nil || !report.OK() || len(report.Matched) != 3 {
+		t.Fatalf("Expected all files to match, got %+v (err %v)", report, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "README"), []byte("tampered"), 0o644)
+	os.Remove(filepath.Join(dir, "CHANGELOG"))
+
+	report, err = VerifyManifest(m, dir)
+	if err != nil {
+		t.Fatalf("VerifyManifest failed: %v", err)
+	}
+	if report.OK() || len(report.Mismatched) != 1 || report.Mismatched[0].Name != "README" || len(report.Missing) != 1 || report.Missing[0] != "CHANGELOG" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestBuildManifestFromPathsOutsideBase(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := BuildManifestFromPaths(filepath.Join(dir, "sub"), filepath.Join(dir, "file")); err == nil {
+		t.Error("Expected files outside the base directory to be rejected")
+	}
+}
+
+func TestVerifyManifestStaysInsideDir(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "release")
+	os.Mkdir(dir, 0o755)
+	os.WriteFile(filepath.Join(parent, "secret"), []byte("outside"), 0o644)
+	os.Symlink(filepath.Join(parent, "secret"), filepath.Join(dir, "link"))
+
+	for _, name := range []string{"../secret", filepath.ToSlash(filepath.Join(parent, "secret")), "a/../../secret", "", "link"} {
+		m := &Manifest{Type: ManifestType, Entries: []ManifestEntry{{Name: name, Size: 7}}}
+		if report, err := VerifyManifest(m, dir); err == nil {
+			t.Errorf("Expected %q to be rejected, got %+v", name, report)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ChainState is the state an account tracks for one blockchain used with
+// SubmitCertificateOn.
+type ChainState struct {
+	Nonce      int64  // The nonce the next transaction on the chain will use.
+	LatestTxID string // The ID of the most recent transaction submitted on the chain.
+}
+
+// chainRegistry holds per-blockchain state keyed by normalized chain ID. Each chain
+// has its own lock, so submissions on different chains proceed concurrently while
+// submissions on the same chain are serialized in nonce order.
+type chainRegistry struct {
+	mu     sync.Mutex
+	chains map[string]*chainEntry
+	infos  map[string]*ChainInfo // Metadata cached by strict chain validation.
+}
+
+type chainEntry struct {
+	mu     sync.Mutex
+	loaded bool
+	state  ChainState
+}
+
+func (r *chainRegistry) entry(chainID string) *chainEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.chains == nil {
+		r.chains = make(map[string]*chainEntry)
+	}
+	key := helpers.HexFix(chainID)
+	e, ok := r.chains[key]
+	if !ok {
+		e = &chainEntry{}
+		r.chains[key] = e
+	}
+	return e
+}
+
+// loaded returns the state of every chain whose state has been loaded, by chain ID.
+func (r *chainRegistry) loaded() map[string]ChainState {
+	r.mu.Lock()
+	entries := make(map[string]*chainEntry, len(r.chains))
+	for chainID, e := range r.chains {
+		entries[chainID] = e
+	}
+	r.mu.Unlock()
+	states := make(map[string]ChainState)
+	for chainID, e := range entries {
+		e.mu.Lock()
+		if e.loaded {
+			states[chainID] = e.state
+		}
+		e.mu.Unlock()
+	}
+	return states
+}
+
+func (r *chainRegistry) reset() {
+	r.mu.Lock()
+	r.chains = nil
+	r.infos = nil
+	r.mu.Unlock()
+}
+
+// handOverNonce moves the account's Nonce into the state of chain old and the state of
+// chain next, if loaded, into the Nonce, for SetBlockchain; a.submitMu must be held.
+// While a chain is the account's Blockchain, its state stays unloaded, so that the
+// Nonce is its only nonce.
+//
+// Returns:
+//
+//	The nonce adopted from the state of next, or zero if it had none.
+func (a *CEPAccount) handOverNonce(old, next string) int64 {
+	if old != "" {
+		e := a.chains.entry(old)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		nonce := a.view().Nonce
+		e.state.Nonce, e.loaded = nonce, nonce > 0
+	}
+	e := a.chains.entry(next)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	a.mu.Lock()
+	a.Nonce = 0
+	if e.loaded {
+		a.Nonce = e.state.Nonce
+	}
+	nonce := a.Nonce
+	a.mu.Unlock()
+	e.state.Nonce, e.loaded = 0, false
+	return nonce
+}
+
+// ChainState returns the state tracked for chainID, and false if the account has not
+// yet used that chain with UpdateAccountOn or SubmitCertificateOn, or if the chain is
+// the account's Blockchain, whose nonce is the account's Nonce.
+func (a *CEPAccount) ChainState(chainID string) (ChainState, bool) {
+	e := a.chains.entry(chainID)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.state, e.loaded
+}
+
+// UpdateAccountOn fetches the account's nonce on chainID, like UpdateAccount does for
+// the account's Blockchain, and stores it in the chain's state. Concurrent calls for the
+// same chain share one request; a caller whose ctx is done stops waiting for it without
+// cancelling it for the others.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the wait.
+//   - chainID: The blockchain to refresh.
+//
+// Returns:
+//
+//	An error if the account is not open or the nonce cannot be fetched.
+func (a *CEPAccount) UpdateAccountOn(ctx context.Context, chainID string) error {
+	if a.view().Address == "" {
+		return ErrAccountNotOpen
+	}
+	_, err := a.flights.do(ctx, "chain\x00"+a.view().Address+"\x00"+chainID, func(ctx context.Context) error {
+		e := a.chains.entry(chainID)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return a.loadChain(ctx, chainID, e)
+	})
+	return err
+}
+
+// loadChain refreshes the nonce of e, whose lock must be held.
+func (a *CEPAccount) loadChain(ctx context.Context, chainID string, e *chainEntry) error {
+	nonce, err := a.fetchNonce(ensureRequestID(ctx), chainID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch nonce on chain %s: %w", chainID, err)
+	}
+	e.state.Nonce = nonce
+	e.loaded = true
+	a.persistNonce(chainID, nonce)
+	return nil
+}
+
+// SubmitCertificateOn submits a certificate to chainID using the state tracked for that
+// chain, leaving the account's Blockchain, Nonce and LatestTxID untouched. The chain's
+// nonce is fetched on first use, unless a fresh one is persisted (see SetNonceStore).
+// It is safe to submit to different chains from different goroutines; submissions to
+// the same chain are serialized. A submission to the account's own Blockchain is made
+// as SubmitCertificate makes it, with the account's Nonce, so that the two never send
+// the same nonce.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - chainID: The blockchain to submit to.
+//   - pdata: The data content of the certificate.
+//   - signer: Signs the transaction; it must hold the account's key.
+//   - opts: Optional settings for the submission, such as WithRecipient.
+//
+// Returns:
+//
+//	The transaction ID, or an error if the nonce cannot be fetched or the submission fails.
+func (a *CEPAccount) SubmitCertificateOn(ctx context.Context, chainID string, pdata string, signer Signer, opts ...SubmitOption) (string, error) {
+	if a.view().Address == "" {
+		return "", ErrAccountNotOpen
+	}
+	ctx = ensureRequestID(ctx)
+	a.submitMu.Lock()
+	if v := a.view(); sameHex(chainID, v.Blockchain) {
+		defer a.submitMu.Unlock()
+		id, _, err := a.certifyLocked(ctx, v, pdata, signer, opts)
+		return id, err
+	}
+	a.submitMu.Unlock()
+
+	e := a.chains.entry(chainID)
+	e.mu.Lock()
+	if sameHex(chainID, a.view().Blockchain) {
+		// SetBlockchain made the chain the account's own meanwhile, handing its nonce over.
+		e.mu.Unlock()
+		return a.SubmitCertificateOn(ctx, chainID, pdata, signer, opts...)
+	}
+	defer e.mu.Unlock()
+
+	if !e.loaded {
+		if nonce, ok := a.freshNonce(chainID); ok {
+			e.state.Nonce, e.loaded = nonce, true
+		} else if err := a.loadChain(ctx, chainID, e); err != nil {
+			return "", err
+		}
+	}
+	id, nonce, duplicate, err := a.sendCertificate(ctx, chainID, e.state.Nonce, pdata, signer, opts)
+	if err != nil && !queuedForLater(err) {
+		return "", err
+	}
+	e.state.LatestTxID = id
+	if !duplicate {
+		e.state.Nonce = nonce + 1
+		a.persistNonce(chainID, e.state.Nonce)
+	}
+	return id, err
+}
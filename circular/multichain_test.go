@@ -0,0 +1,146 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSubmitCertificateOnTracksChainsIndependently(t *testing.T) {
+	var mu sync.Mutex
+	nonces := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(r.URL.String(), "Circular_GetWalletNonce_") {
+			start := map[string]int{"aa": 9, "bb": 99}[req["Blockchain"]]
+			fmt.Fprintf(w, `{"Result":200,"Response":{"Nonce":%d}}`, start)
+			return
+		}
+		mu.Lock()
+		nonces[req["Blockchain"]] = append(nonces[req["Blockchain"]], req["Nonce"])
+		mu.Unlock()
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	var wg sync.WaitGroup
+	for _, chain := range []string{"0xaa", "0xbb"} {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := acc.SubmitCertificateOn(t.Context(), chain, "data", signer); err != nil {
+					t.Errorf("SubmitCertificateOn(%s) error = %v", chain, err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if got := strings.Join(nonces["aa"], ","); got != "10,11,12" {
+		t.Errorf("Expected nonces 10,11,12 on chain aa, got %s", got)
+	}
+	if got := strings.Join(nonces["bb"], ","); got != "100,101,102" {
+		t.Errorf("Expected nonces 100,101,102 on chain bb, got %s", got)
+	}
+	state, ok := acc.ChainState("aa")
+	if !ok || state.Nonce != 13 || state.LatestTxID == "" {
+		t.Errorf("Unexpected chain state: %+v, %v", state, ok)
+	}
+	if acc.Nonce != 0 || acc.LatestTxID != "" {
+		t.Errorf("Expected the account's own chain state to be untouched, got nonce %d and tx %q", acc.Nonce, acc.LatestTxID)
+	}
+}
+
+func TestSubmitCertificateOnOwnChainSharesNonce(t *testing.T) {
+	var mu sync.Mutex
+	var nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(r.URL.String(), "Circular_GetWalletNonce_") {
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+			return
+		}
+		mu.Lock()
+		nonces = append(nonces, req["Nonce"])
+		mu.Unlock()
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetLogLevel(LogSilent)
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastErr())
+	}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	acc.SubmitCertificate("first", testPrivateKey)
+	id, err := acc.SubmitCertificateOn(t.Context(), strings.ToUpper(acc.Blockchain), "second", signer)
+	if err != nil {
+		t.Fatalf("SubmitCertificateOn() error = %v", err)
+	}
+	acc.SubmitCertificate("third", testPrivateKey)
+	if acc.LastErr() != nil {
+		t.Fatal(acc.LastErr())
+	}
+
+	first := acc.Nonce - 3
+	if want := fmt.Sprintf("%d,%d,%d", first, first+1, first+2); strings.Join(nonces, ",") != want {
+		t.Errorf("Expected nonces %s, got %v", want, nonces)
+	}
+	if _, tracked := acc.ChainState(acc.Blockchain); tracked {
+		t.Error("Expected the account's own chain not to get separate chain state")
+	}
+	if id == "" || id == acc.LatestTxID {
+		t.Errorf("Unexpected transaction ID %q", id)
+	}
+}
+
+func TestSetBlockchainHandsOverNonce(t *testing.T) {
+	gateway := &nonceGateway{}
+	gateway.reset()
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetLogLevel(LogSilent)
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	chainA, chainB := acc.Blockchain, "0xb0b0"
+
+	if _, err := acc.SubmitCertificateOn(t.Context(), chainB, "first", signer); err != nil {
+		t.Fatal(err)
+	}
+	acc.SetBlockchain(chainB)
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastErr())
+	}
+	acc.SubmitCertificate("second", testPrivateKey)
+	acc.SetBlockchain(chainA)
+	if _, err := acc.SubmitCertificateOn(t.Context(), chainB, "third", signer); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent := gateway.sent["abcdef/b0b0"]; !slices.Equal(sent, []int64{1, 2, 3}) {
+		t.Errorf("Expected nonces [1 2 3] on chain B, got %v", sent)
+	}
+	if state, _ := acc.ChainState(chainB); state.Nonce != 4 {
+		t.Errorf("Expected chain B to continue from nonce 4, got %d", state.Nonce)
+	}
+}
@@ -0,0 +1,236 @@
+package circular
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// nagResponse is the standard envelope returned by every NAG endpoint.
+type nagResponse struct {
+	Result   ResultCode      `json:"Result"`
+	Response json.RawMessage `json:"Response"`
+
+	endpoint      string
+	httpStatus    int
+	requestID     string
+	correlationID string
+	body          []byte
+}
+
+// message returns the `Response` field when the gateway sent it as a plain string,
+// which is how NAG endpoints report errors.
+func (r *nagResponse) message() string {
+	var msg string
+	if err := json.Unmarshal(r.Response, &msg); err != nil {
+		return ""
+	}
+	return msg
+}
+
+// resultError returns an APIError describing a non-200 `Result` code, or nil if the
+// call succeeded. An explicit message overrides the one reported by the gateway.
+func (r *nagResponse) resultError(message string) error {
+	if r.Result == ResultOK {
+		return nil
+	}
+	if message == "" {
+		message = r.message()
+	}
+	return &APIError{
+		Endpoint:      r.endpoint,
+		HTTPStatus:    r.httpStatus,
+		ResultCode:    int(r.Result),
+		Message:       message,
+		Body:          truncateBody(r.body),
+		RequestID:     r.requestID,
+		CorrelationID: r.correlationID,
+	}
+}
+
+// endpointURL builds the full URL for a NAG operation on the viewed network.
+func (v accountView) endpointURL(endpoint string) string {
+	profile := v.networkProfile()
+	return profile.URL(endpoint)
+}
+
+// postNAG sends requestData as JSON to the given NAG endpoint and decodes the standard
+// response envelope. Transport failures and undecodable bodies are returned as plain
+// errors; non-200 HTTP statuses are returned as *APIError. A non-200 `Result` code is
+// not treated as an error here; callers inspect it with resultError. Throttled responses
+// pause further calls on the account and are retried according to its RetryPolicy.
+func (a *CEPAccount) postNAG(ctx context.Context, endpoint string, requestData interface{}) (*nagResponse, error) {
+	v := a.view()
+	if v.NAGURL == "" {
+		return nil, ErrNetworkNotSet
+	}
+	if err := v.checkGuard(endpoint); err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(requestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	ctx = ensureRequestID(ctx)
+	requestID := RequestIDFromContext(ctx)
+	url, jsonData, err := v.requestTarget(endpoint, requestID, jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint != versionEndpoint {
+		if err := a.checkCompatibility(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	policy := v.retryPolicy
+	attempts := 1
+	if policy != nil && policy.MaxAttempts > 1 {
+		attempts = policy.MaxAttempts
+	}
+
+	a.pressure.enter()
+	defer a.pressure.leave()
+
+	for attempt := 1; ; attempt++ {
+		if err := a.pressure.wait(ctx); err != nil {
+			return nil, fmt.Errorf("gave up waiting for gateway backpressure (request %s): %w", requestID, err)
+		}
+		if err := a.waitRateLimits(ctx, v); err != nil {
+			return nil, fmt.Errorf("gave up waiting for the rate limit (request %s): %w", requestID, err)
+		}
+
+		release, err := a.batchSlot(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("gave up waiting for the concurrency limit (request %s): %w", requestID, err)
+		}
+		result, throttled, retryAfter, err := a.postOnce(ctx, endpoint, url, jsonData, requestID, v.jsonRPC())
+		release(isCongestion(throttled, err))
+		a.degrade.record(v.timeSource().Now(), isGatewayFailure(ctx, err))
+		a.recordNodeCall(v, isGatewayFailure(ctx, err))
+		if !throttled {
+			a.pressure.recover()
+			return result, err
+		}
+
+		if attempt >= attempts {
+			a.pressure.throttle(retryAfter)
+			return result, err
+		}
+		delay := policy.delay(attempt, retryAfter)
+		a.pressure.throttle(delay)
+		a.logCall(ctx, LogInfo, "%s [%s]: Throttled by gateway, retrying in %s (attempt %d of %d)\n", endpoint, requestID, delay, attempt+1, attempts)
+	}
+}
+
+// postOnce performs a single POST to the NAG, or of a JSON-RPC request if rpc is set.
+// Besides the decoded response or error, it reports whether the gateway signalled
+// backpressure and any Retry-After it requested.
+func (a *CEPAccount) postOnce(ctx context.Context, endpoint string, url string, jsonData []byte, requestID string, rpc bool) (*nagResponse, bool, time.Duration, error) {
+	req, err := a.newNAGRequest(ctx, url, jsonData, requestID)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	a.logCall(ctx, LogDebug, "%s [%s]: Request URL: %s\n", endpoint, requestID, url)
+	a.logCall(ctx, LogDebug, "%s [%s]: Request Body: %s\n", endpoint, requestID, string(jsonData))
+
+	resp, err := a.view().do(req)
+	if err != nil {
+		return nil, false, 0, fmt.Errorf("http request failed (request %s): %w", requestID, err)
+	}
+	defer resp.Body.Close()
+
+	a.logCall(ctx, LogDebug, "%s [%s]: Response Status: %s\n", endpoint, requestID, resp.Status)
+	a.logCall(ctx, LogDebug, "%s [%s]: Response Headers: %v\n", endpoint, requestID, resp.Header)
+
+	// Successful responses are decoded as they arrive; error bodies may not be JSON at
+	// all, so they are read whole. Either way the account's size limit applies.
+	limit := a.view().responseLimit()
+	var body []byte
+	if resp.StatusCode == http.StatusOK {
+		var prefix []byte
+		if body, prefix, err = decodeBody(resp.Body, limit); err != nil {
+			return nil, false, 0, fmt.Errorf("failed to decode response body (request %s): %w, body: %s", requestID, err, truncateBody(prefix))
+		}
+	} else if body, err = readBody(resp.Body, limit); err != nil {
+		return nil, false, 0, fmt.Errorf("failed to read response body (request %s): %w", requestID, err)
+	}
+	a.logCall(ctx, LogDebug, "%s [%s]: Response Body: %s\n", endpoint, requestID, string(body))
+	if rpc {
+		if body, err = rpcEnvelope(body, requestID); err != nil {
+			return nil, false, 0, fmt.Errorf("invalid JSON-RPC response (request %s): %w", requestID, err)
+		}
+	}
+
+	result := &nagResponse{
+		endpoint:      endpoint,
+		httpStatus:    resp.StatusCode,
+		requestID:     resp.Header.Get(RequestIDHeader),
+		correlationID: requestID,
+		body:          body,
+	}
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			Endpoint:      endpoint,
+			HTTPStatus:    resp.StatusCode,
+			Body:          truncateBody(body),
+			RequestID:     result.requestID,
+			CorrelationID: requestID,
+		}
+		// Error bodies are frequently still NAG envelopes; surface their details when they are.
+		if json.Unmarshal(body, result) == nil {
+			a.results.record(result.Result)
+			apiErr.ResultCode = int(result.Result)
+			apiErr.Message = result.message()
+		}
+		return nil, isThrottled(resp.StatusCode, result.Result), retryAfter, apiErr
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, false, 0, fmt.Errorf("failed to decode response body (request %s): %w, body: %s", requestID, err, truncateBody(body))
+	}
+
+	a.results.record(result.Result)
+	return result, isThrottled(resp.StatusCode, result.Result), retryAfter, nil
+}
+
+// newNAGRequest builds a POST of jsonData to url carrying the account's identification
+// and correlation headers, and the CallOptions of ctx.
+func (a *CEPAccount) newNAGRequest(ctx context.Context, url string, jsonData []byte, requestID string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RequestIDHeader, requestID)
+	v := a.view()
+	req.Header.Set("User-Agent", v.userAgentHeader())
+	if v.appName != "" {
+		req.Header.Set(ClientNameHeader, v.appName)
+	}
+	call := CallOptionsFromContext(ctx)
+	if call.TenantID != "" {
+		req.Header.Set(TenantIDHeader, call.TenantID)
+	}
+	if call.Priority != PriorityNormal {
+		req.Header.Set(PriorityHeader, call.Priority.String())
+	}
+	switch {
+	case call.Authorization != "":
+		req.Header.Set("Authorization", call.Authorization)
+	case v.readToken != "":
+		req.Header.Set("Authorization", "Bearer "+v.readToken)
+	case v.node != nil && v.node.Token != "":
+		req.Header.Set("Authorization", "Bearer "+v.node.Token)
+	}
+	return req, nil
+}
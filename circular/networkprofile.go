@@ -0,0 +1,117 @@
+package circular
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Placeholders substituted in a NetworkProfile's PathTemplate.
+const (
+	OperationPlaceholder = "{operation}" // The NAG operation, e.g. "Circular_AddTransaction_".
+	NetworkPlaceholder   = "{network}"   // The profile's network name; empty if none.
+)
+
+// LegacyPathTemplate reproduces the addressing used by the PHP gateways, where the
+// operation and network name are appended to a base URL ending in "?cep=".
+const LegacyPathTemplate = OperationPlaceholder + NetworkPlaceholder
+
+// Protocols a gateway can speak; see NetworkProfile.Protocol.
+const (
+	ProtocolNAG     = "nag"     // The NAG's own envelope, one URL per operation.
+	ProtocolJSONRPC = "jsonrpc" // JSON-RPC 2.0 at a single URL.
+)
+
+// NetworkProfile describes how to reach a Network Access Gateway. Gateways that route
+// operations REST-style (e.g. "https://gateway.example.com" with "/API/{operation}")
+// are described by a PathTemplate; the PHP gateways use LegacyPathTemplate. Gateways
+// exposing a JSON-RPC 2.0 interface set Protocol to ProtocolJSONRPC, and receive every
+// operation at BaseURL as a request whose method is the operation name.
+type NetworkProfile struct {
+	Name         string // The network identifier, e.g. "testnet"; substituted for {network}.
+	BaseURL      string // The gateway URL the expanded template is appended to.
+	PathTemplate string // The path for each operation; empty means LegacyPathTemplate.
+	Protocol     string // The gateway's protocol; empty means ProtocolNAG.
+}
+
+// Validate checks that the profile has a base URL and that its template names the operation.
+func (p *NetworkProfile) Validate() error {
+	if p.BaseURL == "" {
+		return fmt.Errorf("network profile %q has no base URL", p.Name)
+	}
+	if _, err := url.Parse(p.BaseURL); err != nil {
+		return fmt.Errorf("network profile %q has an invalid base URL: %w", p.Name, err)
+	}
+	switch p.Protocol {
+	case "", ProtocolNAG:
+		if p.PathTemplate != "" && !strings.Contains(p.PathTemplate, OperationPlaceholder) {
+			return fmt.Errorf("network profile %q path template %q does not contain %s", p.Name, p.PathTemplate, OperationPlaceholder)
+		}
+	case ProtocolJSONRPC:
+		if p.PathTemplate != "" {
+			return fmt.Errorf("network profile %q cannot use a path template with %s", p.Name, ProtocolJSONRPC)
+		}
+	default:
+		return fmt.Errorf("network profile %q has unknown protocol %q", p.Name, p.Protocol)
+	}
+	return nil
+}
+
+// URL returns the full URL of operation on the gateway described by the profile; for
+// JSON-RPC gateways, the BaseURL every operation is sent to.
+//
+// Parameters:
+//   - operation: The NAG operation, e.g. "Circular_GetWalletNonce_".
+func (p *NetworkProfile) URL(operation string) string {
+	if p.Protocol == ProtocolJSONRPC {
+		return p.BaseURL
+	}
+	template := p.PathTemplate
+	if template == "" {
+		template = LegacyPathTemplate
+	}
+	path := strings.NewReplacer(
+		OperationPlaceholder, url.PathEscape(operation),
+		NetworkPlaceholder, url.PathEscape(p.Name),
+	).Replace(template)
+	if template == LegacyPathTemplate {
+		return p.BaseURL + path
+	}
+	return strings.TrimRight(p.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// SetNetworkProfile points the account at the gateway described by profile, replacing
+// any network configured with SetNetwork.
+//
+// Parameters:
+//   - profile: The gateway to use.
+//
+// Returns:
+//
+//	An error if the profile is invalid, in which case the account is unchanged.
+func (a *CEPAccount) SetNetworkProfile(profile NetworkProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.NAGURL = profile.BaseURL
+	a.NetworkNode = profile.Name
+	a.nagPath = profile.PathTemplate
+	a.nagProto = profile.Protocol
+	a.node = nil
+	a.mu.Unlock()
+	a.compat.reset()
+	a.nodes.unpin()
+	a.recordEvent(AccountEvent{Type: EventNetwork, NAGURL: profile.BaseURL, NetworkNode: profile.Name})
+	return nil
+}
+
+// NetworkProfile returns the profile describing the account's current gateway.
+func (a *CEPAccount) NetworkProfile() NetworkProfile {
+	return a.view().networkProfile()
+}
+
+// networkProfile returns the profile describing the viewed gateway.
+func (v accountView) networkProfile() NetworkProfile {
+	return NetworkProfile{Name: v.NetworkNode, BaseURL: v.NAGURL, PathTemplate: v.nagPath, Protocol: v.nagProto}
+}
@@ -0,0 +1,64 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNetworkProfileURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		profile  NetworkProfile
+		expected string
+	}{
+		{
+			name:     "legacy",
+			profile:  NetworkProfile{Name: "testnet", BaseURL: "https://nag.example.com/NAG.php?cep="},
+			expected: "https://nag.example.com/NAG.php?cep=Circular_GetWalletNonce_testnet",
+		},
+		{
+			name:     "rest",
+			profile:  NetworkProfile{Name: "testnet", BaseURL: "https://gw.example.com/", PathTemplate: "/API/{network}/{operation}"},
+			expected: "https://gw.example.com/API/testnet/Circular_GetWalletNonce_",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.profile.URL("Circular_GetWalletNonce_"); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetNetworkProfileRoutesRequests(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	if err := acc.SetNetworkProfile(NetworkProfile{BaseURL: server.URL, PathTemplate: "/API/{operation}"}); err != nil {
+		t.Fatalf("SetNetworkProfile() error = %v", err)
+	}
+
+	if !acc.UpdateAccount() {
+		t.Fatalf("UpdateAccount() failed: %s", acc.GetLastError())
+	}
+	if path != "/API/Circular_GetWalletNonce_" {
+		t.Errorf("Expected a REST-style path, got %s", path)
+	}
+}
+
+func TestNetworkProfileValidate(t *testing.T) {
+	profile := NetworkProfile{BaseURL: "https://gw.example.com", PathTemplate: "/API/nonce"}
+	if err := profile.Validate(); err == nil {
+		t.Error("Expected a template without {operation} to be rejected")
+	}
+}
@@ -0,0 +1,174 @@
+package circular
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// Option configures an account created with NewAccount.
+type Option func(*accountConfig)
+
+// accountConfig collects the options of NewAccount.
+type accountConfig struct {
+	network    string          // Network to discover; see WithNetwork.
+	profile    *NetworkProfile // Gateway to use; see WithNetworkProfile.
+	blockchain string          // See WithBlockchain.
+	address    string          // See WithAddress.
+	signer     Signer          // See WithSigner.
+	client     *http.Client    // See WithHTTPClient.
+	logger     Logger          // See WithLogger.
+}
+
+// WithNetwork configures the account for a network discovered by name, as SetNetwork
+// does.
+//
+// Parameters:
+//   - network: The network, e.g. "testnet" or "mainnet".
+func WithNetwork(network string) Option {
+	return func(c *accountConfig) {
+		c.network = network
+	}
+}
+
+// WithNetworkProfile points the account at the gateway described by profile, as
+// SetNetworkProfile does, for gateways that are not discovered by name.
+//
+// Parameters:
+//   - profile: The gateway to use.
+func WithNetworkProfile(profile NetworkProfile) Option {
+	return func(c *accountConfig) {
+		c.profile = &profile
+	}
+}
+
+// WithBlockchain sets the blockchain the account submits to, in place of DefaultBlockchain().
+//
+// Parameters:
+//   - chain: The blockchain ID in hexadecimal, with or without "0x" prefix.
+func WithBlockchain(chain string) Option {
+	return func(c *accountConfig) {
+		c.blockchain = chain
+	}
+}
+
+// WithAddress opens the account for address, as Open does. It is not needed with
+// WithSigner, which implies the address.
+//
+// Parameters:
+//   - address: The blockchain address of the account.
+func WithAddress(address string) Option {
+	return func(c *accountConfig) {
+		c.address = address
+	}
+}
+
+// WithSigner opens the account for the address owned by signer's key and sets the
+// account's PublicKey. The signer is returned by the account's Signer method, for
+// passing to SubmitAndWait and the other methods that sign.
+//
+// Parameters:
+//   - signer: Holds the account's key.
+func WithSigner(signer Signer) Option {
+	return func(c *accountConfig) {
+		c.signer = signer
+	}
+}
+
+// WithHTTPClient makes the account call its gateway with client, for instance one with
+// a custom transport, instead of the SDK's shared client.
+//
+// Parameters:
+//   - client: The HTTP client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *accountConfig) {
+		c.client = client
+	}
+}
+
+// WithLogger directs the account's log messages to logger, as SetLogger does.
+//
+// Parameters:
+//   - logger: Receives the messages.
+func WithLogger(logger Logger) Option {
+	return func(c *accountConfig) {
+		c.logger = logger
+	}
+}
+
+// NewAccount creates an account configured by opts, validating them together so that
+// the account is either ready to use or not created at all. Options may be given in
+// any order; NewAccount applies them in an order that satisfies their dependencies,
+// setting the blockchain before the address is opened. Without options the account is
+// the same as one from NewCEPAccount.
+//
+// Parameters:
+//   - opts: The account's settings, such as WithNetwork and WithSigner.
+//
+// Returns:
+//
+//	The account, or nil and an error if an option is invalid, the options conflict, or
+//	network discovery fails.
+func NewAccount(opts ...Option) (*CEPAccount, error) {
+	cfg := &accountConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.network != "" && cfg.profile != nil {
+		return nil, errors.New("WithNetwork and WithNetworkProfile cannot be combined")
+	}
+	address := cfg.address
+	if cfg.signer != nil {
+		owned := AddressFromPublicKey(cfg.signer.PublicKey())
+		if address != "" && !sameHex(address, owned) {
+			return nil, fmt.Errorf("%w: %s is not the address of the signer's key, %s", ErrInvalidAddress, address, owned)
+		}
+		address = owned
+	}
+	if address != "" {
+		if err := validateAddress(address); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+		}
+	}
+	if cfg.blockchain != "" {
+		if _, err := hex.DecodeString(helpers.HexFix(cfg.blockchain)); err != nil || helpers.HexFix(cfg.blockchain) == "" {
+			return nil, fmt.Errorf("invalid blockchain %q: expected a hexadecimal ID", cfg.blockchain)
+		}
+	}
+
+	a := NewCEPAccount()
+	a.client = cfg.client
+	a.logger = cfg.logger
+	if cfg.blockchain != "" {
+		a.SetBlockchain(cfg.blockchain)
+	}
+	switch {
+	case cfg.profile != nil:
+		if err := a.SetNetworkProfile(*cfg.profile); err != nil {
+			return nil, err
+		}
+	case cfg.network != "":
+		if _, err := a.setNetwork(cfg.network); err != nil {
+			return nil, err
+		}
+	}
+	if address != "" {
+		a.Open(address)
+	}
+	if cfg.signer != nil {
+		a.mu.Lock()
+		a.PublicKey = helpers.HexFix(cfg.signer.PublicKey())
+		a.signer = cfg.signer
+		a.mu.Unlock()
+	}
+	return a, nil
+}
+
+// Signer returns the signer given to NewAccount with WithSigner, or nil. Close forgets
+// it along with the address.
+func (a *CEPAccount) Signer() Signer {
+	return a.view().signer
+}
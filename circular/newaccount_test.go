@@ -0,0 +1,96 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+// countingTransport counts the requests it passes on.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewAccount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.String(), "network=nowhere") {
+			fmt.Fprint(w, `{"status":"error","message":"Network not found"}`)
+			return
+		}
+		if strings.Contains(r.URL.String(), "network=") {
+			fmt.Fprintf(w, `{"status":"success","url":"http://%s/?cep="}`, r.Host)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+	}))
+	defer server.Close()
+	circulartest.OverrideNetworkDiscoveryURL(t, server.URL+"/discover?network=")
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	transport := &countingTransport{}
+	logger := &recordingLogger{}
+	// Options apply in a working order whatever order they are given in.
+	acc, err := NewAccount(
+		WithSigner(signer),
+		WithLogger(logger),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithBlockchain("0xc0ffee"),
+		WithNetwork("testnet"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := acc.State()
+	if state.Address != AddressFromPublicKey(signer.PublicKey()) || state.Blockchain != "0xc0ffee" || state.NetworkNode != "testnet" || state.NAGURL != server.URL+"/?cep=" {
+		t.Errorf("Expected a configured account, got %+v", state)
+	}
+	if acc.Signer() != signer || acc.Lifecycle() != LifecycleConfigured {
+		t.Errorf("Expected an account ready to submit with its signer, got stage %s", acc.Lifecycle())
+	}
+	if !acc.UpdateAccount() || acc.Nonce != 5 {
+		t.Fatalf("Expected the nonce to be fetched, got %d: %s", acc.Nonce, acc.LastError)
+	}
+	if transport.requests.Load() != 1 || len(logger.messages) == 0 {
+		t.Errorf("Expected the HTTP client and logger to be used, got %d requests and %d messages", transport.requests.Load(), len(logger.messages))
+	}
+	acc.Close()
+	if acc.Signer() != nil {
+		t.Error("Expected Close to forget the signer")
+	}
+
+	if acc, err := NewAccount(); err != nil || acc.State() != NewCEPAccount().State() {
+		t.Errorf("Expected the defaults of NewCEPAccount without options, got %+v, %v", acc, err)
+	}
+
+	testCases := []struct {
+		name string
+		opts []Option
+		want error
+	}{
+		{name: "conflicting networks", opts: []Option{WithNetwork("testnet"), WithNetworkProfile(NetworkProfile{Name: "local", BaseURL: server.URL})}},
+		{name: "foreign address", opts: []Option{WithSigner(signer), WithAddress("0xabcdef")}, want: ErrInvalidAddress},
+		{name: "invalid address", opts: []Option{WithAddress("not hex")}, want: ErrInvalidAddress},
+		{name: "invalid blockchain", opts: []Option{WithBlockchain("chain")}},
+		{name: "invalid profile", opts: []Option{WithNetworkProfile(NetworkProfile{Name: "local"})}},
+		{name: "discovery", opts: []Option{WithNetwork("nowhere")}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			acc, err := NewAccount(tc.opts...)
+			if acc != nil || err == nil || (tc.want != nil && !errors.Is(err, tc.want)) {
+				t.Errorf("Expected no account and an error, got %v, %v", acc, err)
+			}
+		})
+	}
+}
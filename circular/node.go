@@ -0,0 +1,61 @@
+package circular
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// NodeClient describes the JSON-RPC endpoint of a Circular node, which an account can
+// call directly instead of a Network Access Gateway; see SetNodeClient.
+//
+// Every NAG operation is sent to URL as a JSON-RPC 2.0 request whose method is the
+// operation name (e.g. "Circular_AddTransaction_") and whose params are the request the
+// gateway would receive. A result is treated as a successful `Response`, and an error's
+// code and message as the `Result` code and message of a failed one, so the account's
+// API behaves exactly as it does against a gateway.
+type NodeClient struct {
+	Name  string // The network the node belongs to, e.g. "mainnet"; used like a gateway's network name.
+	URL   string // The node's RPC endpoint, e.g. "http://10.0.0.5:9633/rpc".
+	Token string // A bearer token the node requires, if any.
+}
+
+// Validate checks that the node has a usable RPC URL.
+func (n *NodeClient) Validate() error {
+	if n.URL == "" {
+		return fmt.Errorf("node %q has no RPC URL", n.Name)
+	}
+	u, err := url.Parse(n.URL)
+	if err != nil {
+		return fmt.Errorf("node %q has an invalid RPC URL: %w", n.Name, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("node %q RPC URL must be http or https, got %q", n.Name, n.URL)
+	}
+	return nil
+}
+
+// SetNodeClient points the account at a node's RPC endpoint, bypassing the public NAG,
+// for deployments that run their own nodes. It replaces any network configured with
+// SetNetwork or SetNetworkProfile, and either of those switches back to a gateway.
+//
+// Parameters:
+//   - node: The node to call.
+//
+// Returns:
+//
+//	An error if the node is invalid, in which case the account is unchanged.
+func (a *CEPAccount) SetNodeClient(node NodeClient) error {
+	if err := node.Validate(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.NAGURL = node.URL
+	a.NetworkNode = node.Name
+	a.nagPath = ""
+	a.nagProto = ""
+	a.node = &node
+	a.mu.Unlock()
+	a.compat.reset()
+	a.recordEvent(AccountEvent{Type: EventNetwork, NAGURL: node.URL, NetworkNode: node.Name})
+	return nil
+}
@@ -0,0 +1,88 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newNodeServer starts a mock node answering JSON-RPC requests with respond, which
+// returns the JSON of either a result or an error member.
+func newNodeServer(t *testing.T, respond func(req rpcRequest) string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.JSONRPC != "2.0" || req.ID == "" {
+			t.Errorf("Expected a JSON-RPC 2.0 request, got %+v (%v)", req, err)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,%s}`, req.ID, respond(req))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNodeClient(t *testing.T) {
+	var methods []string
+	server := newNodeServer(t, func(req rpcRequest) string {
+		methods = append(methods, req.Method)
+		switch req.Method {
+		case "Circular_GetWalletNonce_":
+			return `"result":{"Nonce":6}`
+		case "Circular_AddTransaction_":
+			var tx Transaction
+			json.Unmarshal(req.Params, &tx)
+			if tx.Nonce != "7" {
+				return `"error":{"code":108,"message":"Invalid Nonce"}`
+			}
+			return `"result":"Transaction Added"`
+		}
+		return `"error":{"code":-32601,"message":"Method not found"}`
+	})
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	if err := acc.SetNodeClient(NodeClient{Name: "onprem", URL: server.URL + "/rpc", Token: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if !acc.UpdateAccount() || acc.Nonce != 7 {
+		t.Fatalf("Expected the nonce to be fetched from the node, got %d: %s", acc.Nonce, acc.GetLastError())
+	}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	if _, err := acc.submitCertificate(t.Context(), "data", signer); err != nil {
+		t.Fatalf("Expected the node to accept the submission, got: %v", err)
+	}
+
+	// Node errors surface like gateway result codes.
+	_, err := acc.submitCertificate(t.Context(), "data", signer)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ResultCode != 108 || apiErr.Message == "" {
+		t.Errorf("Expected an APIError with result code 108, got: %v", err)
+	}
+	if len(methods) != 3 || methods[0] != "Circular_GetWalletNonce_" {
+		t.Errorf("Unexpected node methods: %v", methods)
+	}
+
+	if got := acc.NetworkProfile(); got.Name != "onprem" || got.BaseURL != server.URL+"/rpc" {
+		t.Errorf("Unexpected network profile in node mode: %+v", got)
+	}
+	if err := acc.SetNetworkProfile(NetworkProfile{Name: "testnet", BaseURL: "https://nag.example.com/?cep="}); err != nil {
+		t.Fatal(err)
+	}
+	if acc.view().node != nil {
+		t.Error("Expected SetNetworkProfile to leave node mode")
+	}
+}
+
+func TestNodeClientValidate(t *testing.T) {
+	for _, node := range []NodeClient{{}, {URL: "ftp://node"}, {URL: "://bad"}} {
+		if err := NewCEPAccount().SetNodeClient(node); err == nil {
+			t.Errorf("Expected %+v to be rejected", node)
+		}
+	}
+}
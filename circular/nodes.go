@@ -0,0 +1,349 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// GatewayNode is one of the nodes serving a network's NAG, as listed by the discovery
+// service.
+type GatewayNode struct {
+	ID  string // Identifies the node, e.g. "eu-west-1"; its URL if the discovery service gives no ID.
+	URL string // The node's NAG URL in the legacy layout, e.g. "https://eu1.example.com/NAG.php?cep=".
+}
+
+// NodeRotation moves an account away from the node it uses when the share of failed
+// NAG calls through the node over a sliding window reaches ErrorRate, to the next node
+// of the network that has not itself been left within Cooldown. Failed calls are
+// transport failures and HTTP 5xx responses; rejections and throttling do not count.
+type NodeRotation struct {
+	ErrorRate float64                 // Share of failed calls, between 0 and 1, at which the account leaves a node.
+	Window    time.Duration           // How far back calls are counted.
+	MinCalls  int                     // Calls the window must hold before the account leaves a node.
+	Cooldown  time.Duration           // How long a node that was left is avoided; zero means Window.
+	OnRotate  func(NodeRotationEvent) // Called when the account moves to another node; may be nil.
+}
+
+// DefaultNodeRotation returns a policy that leaves a node once half of at least 10 calls
+// in the last minute have failed, and avoids it for five minutes.
+func DefaultNodeRotation() *NodeRotation {
+	return &NodeRotation{ErrorRate: 0.5, Window: time.Minute, MinCalls: 10, Cooldown: 5 * time.Minute}
+}
+
+// NodeRotationEvent reports that an account moved away from a failing node.
+type NodeRotationEvent struct {
+	From      GatewayNode // The node left.
+	To        GatewayNode // The node now in use.
+	ErrorRate float64     // The share of failed calls through From in the window.
+	Calls     int         // The number of calls through From in the window.
+	Time      time.Time   // When the account moved.
+}
+
+// NodeStats totals the NAG calls an account made through one node.
+type NodeStats struct {
+	Node     GatewayNode // The node.
+	Calls    int64       // The calls made through the node.
+	Failures int64       // The calls that failed; see NodeRotation.
+	Active   bool        // Whether the account currently uses the node.
+	Pinned   bool        // Whether the account is pinned to the node; see PinNode.
+}
+
+// ListNetworkNodes asks the discovery service for the nodes serving the account's
+// network. Discovery services that do not list nodes return only the NAG URL to use,
+// which is then reported as the network's only node, identified by its URL. The nodes
+// are remembered for PinNode, CurrentNode and rotation; SetNetwork remembers the nodes
+// it discovers as well.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request; the account's Discovery deadline
+//     applies if it has none.
+//
+// Returns:
+//
+//	The network's nodes, or an error if the account has no network name or the
+//	discovery request fails.
+func (a *CEPAccount) ListNetworkNodes(ctx context.Context) ([]GatewayNode, error) {
+	v := a.view()
+	if v.NetworkNode == "" {
+		return nil, fmt.Errorf("%w: the account has no network name to discover nodes for", ErrNetworkNotSet)
+	}
+	ctx, cancel := withDeadline(ctx, v.deadlines.withDefaults().Discovery)
+	defer cancel()
+	found, err := discoverNetwork(ctx, v.NetworkNode)
+	if err != nil {
+		return nil, fmt.Errorf("node discovery failed: %w", err)
+	}
+	nodes := found.Nodes
+	if len(nodes) == 0 {
+		nodes = []GatewayNode{{ID: found.URL, URL: found.URL}}
+	}
+	a.nodes.discovered(v.NetworkNode, nodes)
+	return slices.Clone(nodes), nil
+}
+
+// PinNode routes the account's NAG calls through node, keeping its network name, and
+// keeps them there: NodeRotation does not move a pinned account. A later SetNetwork,
+// SetNetworkProfile or UnpinNode releases the pin.
+//
+// Parameters:
+//   - node: The node to use, typically one returned by ListNetworkNodes.
+//
+// Returns:
+//
+//	An error if the node has no valid URL, in which case the account is unchanged.
+func (a *CEPAccount) PinNode(node GatewayNode) error {
+	if node.URL == "" {
+		return fmt.Errorf("node %q has no URL", node.ID)
+	}
+	if _, err := url.Parse(node.URL); err != nil {
+		return fmt.Errorf("node %q has an invalid URL: %w", node.ID, err)
+	}
+	if node.ID == "" {
+		node.ID = node.URL
+	}
+	v := a.view()
+	a.nodes.pin(v.NetworkNode, node)
+	a.routeToNode(node, "")
+	a.logf(LogInfo, "PinNode: routing %s through node %s\n", v.NetworkNode, node.ID)
+	return nil
+}
+
+// UnpinNode releases the pin set by PinNode. The account keeps using the node until
+// NodeRotation moves it away.
+func (a *CEPAccount) UnpinNode() {
+	a.nodes.unpin()
+}
+
+// CurrentNode returns the node the account's NAG calls go through.
+//
+// Returns:
+//
+//	The node, and false if the account's NAG URL is not one of its network's known
+//	nodes, e.g. before ListNetworkNodes or after SetNetworkProfile.
+func (a *CEPAccount) CurrentNode() (GatewayNode, bool) {
+	v := a.view()
+	return a.nodes.lookup(v.NetworkNode, v.NAGURL)
+}
+
+// SetNodeRotation enables automatic rotation away from failing nodes among the nodes
+// of the account's network known from SetNetwork or ListNetworkNodes; see NodeRotation.
+// Passing nil disables it, unless FeatureNodeRotation is enabled, in which case
+// DefaultNodeRotation applies.
+//
+// Parameters:
+//   - policy: The thresholds and callback to apply.
+func (a *CEPAccount) SetNodeRotation(policy *NodeRotation) {
+	a.nodes.mu.Lock()
+	defer a.nodes.mu.Unlock()
+	a.nodes.policy = policy
+	a.nodes.samples = nil
+	a.nodes.sampled = ""
+}
+
+// NodeStats returns the call totals of each known node of the account's network, in
+// the order the discovery service listed them.
+func (a *CEPAccount) NodeStats() []NodeStats {
+	v := a.view()
+	r := &a.nodes
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.network != v.NetworkNode {
+		return nil
+	}
+	stats := make([]NodeStats, len(r.known))
+	for i, node := range r.known {
+		counts := r.counts[node.URL]
+		stats[i] = NodeStats{Node: node, Active: node.URL == v.NAGURL, Pinned: node.URL == r.pinned}
+		if counts != nil {
+			stats[i].Calls, stats[i].Failures = counts.calls, counts.failures
+		}
+	}
+	return stats
+}
+
+// routeToNode points the account's NAG calls at node, which uses the legacy layout.
+// If from is set, the account is only moved if it still calls the NAG at from.
+//
+// Returns:
+//
+//	Whether the account was moved.
+func (a *CEPAccount) routeToNode(node GatewayNode, from string) bool {
+	a.mu.Lock()
+	if from != "" && a.NAGURL != from {
+		a.mu.Unlock()
+		return false
+	}
+	a.NAGURL = node.URL
+	a.nagPath = ""
+	a.nagProto = ""
+	a.node = nil
+	network := a.NetworkNode
+	a.mu.Unlock()
+	a.compat.reset()
+	a.recordEvent(AccountEvent{Type: EventNetwork, NAGURL: node.URL, NetworkNode: network})
+	return true
+}
+
+// recordNodeCall counts a NAG call made through the node of v and, when the node's
+// error rate calls for it, moves the account to the next node.
+func (a *CEPAccount) recordNodeCall(v accountView, failed bool) {
+	fallback := a.FeatureEnabled(FeatureNodeRotation)
+	event, policy, ok := a.nodes.record(v.NetworkNode, v.NAGURL, v.timeSource().Now(), failed, fallback)
+	if !ok || !a.routeToNode(event.To, event.From.URL) {
+		return
+	}
+	a.logf(LogWarn, "node %s failed %.0f%% of %d calls, rotating to %s\n", event.From.ID, event.ErrorRate*100, event.Calls, event.To.ID)
+	if policy.OnRotate != nil {
+		policy.OnRotate(event)
+	}
+}
+
+// nodeCounts are the call totals of one node.
+type nodeCounts struct {
+	calls, failures int64
+}
+
+// nodeRegistry holds the known nodes of the account's network, their call totals, and
+// the state of node rotation.
+type nodeRegistry struct {
+	mu      sync.Mutex
+	network string                 // The network the nodes serve.
+	known   []GatewayNode          // In the order the discovery service listed them.
+	pinned  string                 // The URL of the node the account is pinned to, if any.
+	policy  *NodeRotation          // Nil when rotation is off.
+	sampled string                 // The URL of the node the samples were taken through.
+	samples []callSample           // Calls through the sampled node within the policy's window, oldest first.
+	left    map[string]time.Time   // When the account rotated away from each node, by URL.
+	counts  map[string]*nodeCounts // By URL.
+}
+
+// discovered replaces the known nodes with those listed for network.
+func (r *nodeRegistry) discovered(network string, nodes []GatewayNode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.network != network {
+		r.network, r.left, r.counts = network, nil, nil
+	}
+	r.known = slices.Clone(nodes)
+	r.pinned = ""
+}
+
+// pin makes node, of network, the pinned node, adding it to the known nodes if needed.
+func (r *nodeRegistry) pin(network string, node GatewayNode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.network != network {
+		r.network, r.known, r.left, r.counts = network, nil, nil, nil
+	}
+	if !slices.ContainsFunc(r.known, func(known GatewayNode) bool { return known.URL == node.URL }) {
+		r.known = append(r.known, node)
+	}
+	r.pinned = node.URL
+}
+
+// unpin releases any pin.
+func (r *nodeRegistry) unpin() {
+	r.mu.Lock()
+	r.pinned = ""
+	r.mu.Unlock()
+}
+
+// lookup returns the known node of network at nagURL.
+func (r *nodeRegistry) lookup(network, nagURL string) (GatewayNode, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.network != network {
+		return GatewayNode{}, false
+	}
+	i := slices.IndexFunc(r.known, func(node GatewayNode) bool { return node.URL == nagURL })
+	if i < 0 {
+		return GatewayNode{}, false
+	}
+	return r.known[i], true
+}
+
+// featureRotation is the policy nodes are rotated by when FeatureNodeRotation is enabled
+// and SetNodeRotation has not been called.
+var featureRotation = DefaultNodeRotation()
+
+// record counts a call made at now through the node of network at nagURL, if it is a
+// known node, and decides whether to rotate away from it. If fallback is set, nodes are
+// rotated by featureRotation while no policy is set.
+//
+// Returns:
+//
+//	The rotation to make and the policy calling for it, and false if the account stays
+//	on its node.
+func (r *nodeRegistry) record(network, nagURL string, now time.Time, failed, fallback bool) (NodeRotationEvent, *NodeRotation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.network != network {
+		return NodeRotationEvent{}, nil, false
+	}
+	from := slices.IndexFunc(r.known, func(node GatewayNode) bool { return node.URL == nagURL })
+	if from < 0 {
+		return NodeRotationEvent{}, nil, false
+	}
+	if r.counts == nil {
+		r.counts = make(map[string]*nodeCounts)
+	}
+	counts := r.counts[nagURL]
+	if counts == nil {
+		counts = &nodeCounts{}
+		r.counts[nagURL] = counts
+	}
+	counts.calls++
+	if failed {
+		counts.failures++
+	}
+
+	policy := r.policy
+	if policy == nil && fallback {
+		policy = featureRotation
+	}
+	if policy == nil || r.pinned == nagURL {
+		return NodeRotationEvent{}, nil, false
+	}
+	if r.sampled != nagURL {
+		r.sampled, r.samples = nagURL, nil
+	}
+	r.samples = append(r.samples, callSample{at: now, failed: failed})
+	stale := 0
+	for stale < len(r.samples) && now.Sub(r.samples[stale].at) > policy.Window {
+		stale++
+	}
+	r.samples = append(r.samples[:0], r.samples[stale:]...)
+	failures := 0
+	for _, sample := range r.samples {
+		if sample.failed {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(len(r.samples))
+	if len(r.samples) < policy.MinCalls || rate < policy.ErrorRate {
+		return NodeRotationEvent{}, nil, false
+	}
+
+	cooldown := policy.Cooldown
+	if cooldown <= 0 {
+		cooldown = policy.Window
+	}
+	for step := 1; step < len(r.known); step++ {
+		to := r.known[(from+step)%len(r.known)]
+		if left, ok := r.left[to.URL]; ok && now.Sub(left) < cooldown {
+			continue
+		}
+		if r.left == nil {
+			r.left = make(map[string]time.Time)
+		}
+		r.left[nagURL] = now
+		event := NodeRotationEvent{From: r.known[from], To: to, ErrorRate: rate, Calls: len(r.samples), Time: now}
+		r.sampled, r.samples = "", nil
+		return event, policy, true
+	}
+	return NodeRotationEvent{}, nil, false
+}
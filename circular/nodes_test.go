@@ -0,0 +1,105 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+func TestNodeRotation(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.String(), "network=testnet"):
+			fmt.Fprintf(w, `{"status":"success","url":"%[1]s/a?cep=","nodes":[{"id":"a","url":"%[1]s/a?cep="},{"id":"b","url":"%[1]s/b?cep="}]}`, server.URL)
+		case strings.HasSuffix(r.URL.String(), "network=solo"):
+			fmt.Fprintf(w, `{"status":"success","url":"%s/b?cep="}`, server.URL)
+		case r.URL.Path == "/a":
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		}
+	}))
+	defer server.Close()
+	circulartest.OverrideNetworkDiscoveryURL(t, server.URL+"/discover?network=")
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	acc.SetLogLevel(LogSilent)
+	acc.SetReceiptStore(NewMemoryReceiptStore())
+	if acc.SetNetwork("testnet") == "" {
+		t.Fatal(acc.LastError)
+	}
+	nodes, err := acc.ListNetworkNodes(t.Context())
+	if err != nil || len(nodes) != 2 || nodes[1] != (GatewayNode{ID: "b", URL: server.URL + "/b?cep="}) {
+		t.Fatalf("Expected the two listed nodes, got %+v (%v)", nodes, err)
+	}
+	if node, ok := acc.CurrentNode(); !ok || node.ID != "a" {
+		t.Errorf("Expected the discovered node a to be current, got %+v", node)
+	}
+
+	var rotations []NodeRotationEvent
+	acc.SetNodeRotation(&NodeRotation{ErrorRate: 0.5, Window: time.Minute, MinCalls: 3, OnRotate: func(e NodeRotationEvent) { rotations = append(rotations, e) }})
+	for range 3 {
+		if acc.UpdateAccount() {
+			t.Fatal("Expected node a to fail")
+		}
+	}
+	if node, _ := acc.CurrentNode(); node.ID != "b" || len(rotations) != 1 || rotations[0].From.ID != "a" || rotations[0].Calls != 3 {
+		t.Fatalf("Expected a rotation from a to b, got node %+v and %+v", node, rotations)
+	}
+	if acc.NetworkNode != "testnet" || !acc.UpdateAccount() {
+		t.Errorf("Expected node b of testnet to serve the account: %s", acc.LastError)
+	}
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	txID, err := acc.submitCertificate(t.Context(), "report", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt := acc.loadReceipt(txID); receipt == nil || receipt.Node != "b" {
+		t.Errorf("Expected the receipt to name node b, got %+v", receipt)
+	}
+	stats := acc.Stats().Nodes
+	if len(stats) != 2 || stats[0].Calls != 3 || stats[0].Failures != 3 || stats[0].Active || !stats[1].Active || stats[1].Calls == 0 {
+		t.Errorf("Unexpected node stats %+v", stats)
+	}
+	var metrics strings.Builder
+	acc.WriteSLAMetrics(&metrics)
+	for _, want := range []string{`circular_node_calls{network="testnet",node="a",result="failed"} 3`, `circular_node_active{network="testnet",node="b"} 1`} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("Expected %s in the metrics, got:\n%s", want, metrics.String())
+		}
+	}
+
+	// A pinned account stays on its node.
+	if err := acc.PinNode(nodes[0]); err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		acc.UpdateAccount()
+	}
+	if node, _ := acc.CurrentNode(); node.ID != "a" || len(rotations) != 1 || !acc.NodeStats()[0].Pinned {
+		t.Errorf("Expected the account to stay pinned to a, got %+v", node)
+	}
+	acc.UnpinNode()
+	for range 3 {
+		acc.UpdateAccount()
+	}
+	if node, _ := acc.CurrentNode(); node.ID != "b" {
+		t.Errorf("Expected the unpinned account to rotate back to b, got %+v", node)
+	}
+
+	// Discovery services that do not list nodes report the gateway as the only node.
+	acc.SetNetwork("solo")
+	if nodes, err := acc.ListNetworkNodes(t.Context()); err != nil || len(nodes) != 1 || nodes[0].ID != server.URL+"/b?cep=" {
+		t.Errorf("Expected the gateway as the only node, got %+v (%v)", nodes, err)
+	}
+}
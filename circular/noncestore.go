@@ -0,0 +1,164 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// noncesCollection is the DocumentStore collection holding persisted nonces.
+const noncesCollection = "nonces"
+
+// RestoredNonce reports how RestoreNonce chose the account's nonce.
+type RestoredNonce struct {
+	Nonce   int64     // The nonce adopted.
+	Stored  int64     // The persisted nonce; zero if none was stored.
+	SavedAt time.Time // When Stored was persisted.
+	Network int64     // The gateway's nonce; zero unless it was fetched.
+}
+
+// storedNonce is the document persisting the next nonce of an address on a chain.
+type storedNonce struct {
+	Address    string    `json:"address"`
+	Blockchain string    `json:"blockchain"`
+	Nonce      int64     `json:"nonce"`
+	SavedAt    time.Time `json:"savedAt"`
+}
+
+// SetNonceStore persists the account's next nonce in docs, per address and chain,
+// whenever it changes, so that a later process can adopt it with RestoreNonce instead
+// of fetching it. Nonces of chains used with SubmitCertificateOn are persisted too, and
+// adopted on first use when fresh. docs may be shared by every account of a process.
+//
+// Parameters:
+//   - docs: The document store holding the nonces; nil stops persisting them.
+//   - maxAge: How long a persisted nonce is trusted without asking the gateway; zero
+//     means it is always reconciled with the gateway's.
+func (a *CEPAccount) SetNonceStore(docs storage.DocumentStore, maxAge time.Duration) {
+	a.nstore.mu.Lock()
+	defer a.nstore.mu.Unlock()
+	a.nstore.docs = docs
+	a.nstore.maxAge = maxAge
+}
+
+// RestoreNonce sets the account's nonce on its Blockchain from the nonce store, for
+// short-lived processes such as CLI invocations and cron jobs. A nonce persisted within
+// the store's maximum age is adopted without contacting the gateway. Otherwise the
+// gateway's nonce is fetched and reconciled with the persisted one by taking the
+// larger: a larger gateway nonce means the key was used elsewhere, and a larger
+// persisted one covers transactions the gateway has not yet counted. A persisted nonce
+// left ahead by transactions that never reached the chain is corrected by ResyncNonce.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the nonce fetch.
+//
+// Returns:
+//
+//	How the nonce was chosen, or an error if the account is not open, no nonce store
+//	is set, or the store or the gateway fails, in which case the nonce is unchanged.
+func (a *CEPAccount) RestoreNonce(ctx context.Context) (*RestoredNonce, error) {
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+
+	v := a.view()
+	if v.Address == "" {
+		return nil, ErrAccountNotOpen
+	}
+	docs, maxAge := a.nstore.get()
+	if docs == nil {
+		return nil, fmt.Errorf("restoring the nonce requires a nonce store")
+	}
+	stored, err := loadStoredNonce(docs, v.Address, v.Blockchain)
+	if err != nil {
+		return nil, err
+	}
+	restored := &RestoredNonce{}
+	if stored != nil {
+		restored.Stored, restored.SavedAt = stored.Nonce, stored.SavedAt
+	}
+	if stored != nil && time.Since(stored.SavedAt) < maxAge {
+		restored.Nonce = stored.Nonce
+	} else {
+		if restored.Network, err = a.fetchNonce(ensureRequestID(ctx), v.Blockchain); err != nil {
+			return nil, fmt.Errorf("failed to fetch nonce: %w", err)
+		}
+		restored.Nonce = max(restored.Network, restored.Stored)
+	}
+
+	if err := a.setNonce(v, restored.Nonce, "restore"); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// nonceStore holds the document store nonces are persisted in.
+type nonceStore struct {
+	mu     sync.Mutex
+	docs   storage.DocumentStore
+	maxAge time.Duration
+}
+
+// get returns the store's documents and maximum age.
+func (s *nonceStore) get() (storage.DocumentStore, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.docs, s.maxAge
+}
+
+// persistNonce saves nonce as the account's next nonce on chain, if a nonce store is
+// set. Failures are logged: the nonce in memory remains authoritative.
+func (a *CEPAccount) persistNonce(chain string, nonce int64) {
+	docs, _ := a.nstore.get()
+	address := a.view().Address
+	if docs == nil || address == "" {
+		return
+	}
+	if err := saveStoredNonce(docs, address, chain, nonce); err != nil {
+		a.logf(LogWarn, "persistNonce: %v\n", err)
+	}
+}
+
+// saveStoredNonce saves nonce as the next nonce of address on chain in docs.
+func saveStoredNonce(docs storage.DocumentStore, address, chain string, nonce int64) error {
+	doc := storedNonce{Address: helpers.HexFix(address), Blockchain: helpers.HexFix(chain), Nonce: nonce, SavedAt: time.Now()}
+	if err := docs.Save(noncesCollection, doc.Address+"-"+doc.Blockchain, doc); err != nil {
+		return fmt.Errorf("failed to persist nonce %d on %s: %w", nonce, doc.Blockchain, err)
+	}
+	return nil
+}
+
+// freshNonce returns the account's nonce on chain persisted within the store's maximum
+// age, if any.
+func (a *CEPAccount) freshNonce(chain string) (int64, bool) {
+	docs, maxAge := a.nstore.get()
+	if docs == nil {
+		return 0, false
+	}
+	stored, err := loadStoredNonce(docs, a.view().Address, chain)
+	if err != nil {
+		a.logf(LogWarn, "freshNonce: %v\n", err)
+		return 0, false
+	}
+	if stored == nil || time.Since(stored.SavedAt) >= maxAge {
+		return 0, false
+	}
+	return stored.Nonce, true
+}
+
+// loadStoredNonce returns the nonce persisted for address on chain, or nil if none is.
+func loadStoredNonce(docs storage.DocumentStore, address, chain string) (*storedNonce, error) {
+	var stored storedNonce
+	err := docs.Load(noncesCollection, helpers.HexFix(address)+"-"+helpers.HexFix(chain), &stored)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read persisted nonce: %w", err)
+	}
+	return &stored, nil
+}
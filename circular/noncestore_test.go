@@ -0,0 +1,84 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestNoncePersistence(t *testing.T) {
+	var network, fetches atomic.Int64
+	network.Store(5)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fetches.Add(1)
+			fmt.Fprintf(w, `{"Result":200,"Response":{"Nonce":%d}}`, network.Load())
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		}
+	}))
+	defer server.Close()
+	docs := storage.NewDocumentStore(storage.NewMemory())
+	newAccount := func(maxAge time.Duration) *CEPAccount {
+		acc := NewCEPAccount()
+		acc.NAGURL = server.URL + "/?cep="
+		acc.Open("0xabcdef")
+		acc.SetNonceStore(docs, maxAge)
+		return acc
+	}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	first := newAccount(time.Hour)
+	if !first.UpdateAccount() {
+		t.Fatalf("UpdateAccount failed: %v", first.LastErr())
+	}
+	if _, err := first.SubmitCertificates(t.Context(), []string{"a"}, signer); err != nil {
+		t.Fatalf("Submission failed: %v", err)
+	}
+
+	// A later process adopts the persisted nonce without asking the gateway.
+	fetches.Store(0)
+	restored, err := newAccount(time.Hour).RestoreNonce(t.Context())
+	if err != nil || restored.Nonce != 7 || restored.Stored != 7 || restored.Network != 0 || fetches.Load() != 0 {
+		t.Errorf("Expected nonce 7 from the store alone, got %+v, %v after %d fetches", restored, err, fetches.Load())
+	}
+
+	// Without trusting the store, the gateway's nonce is fetched; the larger one wins.
+	restored, err = newAccount(0).RestoreNonce(t.Context())
+	if err != nil || restored.Nonce != 7 || restored.Network != 6 || fetches.Load() != 1 {
+		t.Errorf("Expected the persisted nonce 7 over the gateway's 6, got %+v, %v", restored, err)
+	}
+	network.Store(20)
+	third := newAccount(0)
+	if restored, err = third.RestoreNonce(t.Context()); err != nil || restored.Nonce != 21 || third.State().Nonce != 21 {
+		t.Errorf("Expected the gateway's nonce 21 after outside use of the key, got %+v, %v", restored, err)
+	}
+
+	// Chains used with SubmitCertificateOn persist their nonces too.
+	fetches.Store(0)
+	if _, err := third.SubmitCertificateOn(t.Context(), "0x1234", "b", signer); err != nil || fetches.Load() != 1 {
+		t.Fatalf("Expected one nonce fetch for a new chain, got %d, %v", fetches.Load(), err)
+	}
+	other := newAccount(time.Hour)
+	if _, err := other.SubmitCertificateOn(t.Context(), "0x1234", "c", signer); err != nil || fetches.Load() != 1 {
+		t.Errorf("Expected the chain's persisted nonce to be used, got %d fetches, %v", fetches.Load(), err)
+	}
+	if state, _ := other.ChainState("0x1234"); state.Nonce != 23 {
+		t.Errorf("Expected chain nonce 23, got %d", state.Nonce)
+	}
+}
+
+func TestRestoreNonceRequiresStore(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	if _, err := acc.RestoreNonce(t.Context()); err == nil {
+		t.Error("Expected an error without a nonce store")
+	}
+}
@@ -0,0 +1,160 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// NonceAlert reports that the account's nonce on the gateway advanced past every nonce
+// this process used or reserved, which indicates that the account's key is signing
+// transactions elsewhere.
+type NonceAlert struct {
+	Blockchain string    // The chain the nonce advanced on.
+	Expected   int64     // The next nonce according to this process's own transactions.
+	Observed   int64     // The next nonce according to the gateway.
+	Time       time.Time // When the advance was detected.
+}
+
+// Unexplained returns the number of nonces used without a local submission.
+func (n NonceAlert) Unexplained() int64 {
+	return n.Observed - n.Expected
+}
+
+// NonceWatchStats summarizes the checks of a nonce watch; see WatchNonce.
+type NonceWatchStats struct {
+	Checks      int64     // Nonce checks completed.
+	Failures    int64     // Nonce checks that could not fetch the nonce.
+	Alerts      int64     // Checks that raised a NonceAlert.
+	Unexplained int64     // Nonces used without a local submission, in total.
+	LastCheck   time.Time // When the last check completed; zero before the first.
+}
+
+// WatchNonce monitors the account's nonce on its Blockchain for use of the account's key
+// outside this process. The nonce is fetched every interval until ctx is done and
+// compared with the nonces this account has broadcast or set aside with ReserveNonces;
+// whenever it advances past them, onAlert is called and the advance is counted in
+// NonceWatchStats. Each advance is reported once. Nonces adopted from the gateway by
+// UpdateAccount or ResyncNonce do not count as local, so resyncing does not hide an
+// advance. Failed checks are logged and retried at the next interval.
+//
+// Parameters:
+//   - ctx: Stops the watch when done.
+//   - interval: How often to fetch the nonce.
+//   - onAlert: Called from the watch's goroutine for each unexplained advance; may be
+//     nil to rely on NonceWatchStats alone.
+//
+// Returns:
+//
+//	An error if the account is not open, the interval is not positive, or the initial
+//	nonce cannot be fetched, in which case nothing is watched.
+func (a *CEPAccount) WatchNonce(ctx context.Context, interval time.Duration, onAlert func(NonceAlert)) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+	v := a.view()
+	if v.Address == "" {
+		return ErrAccountNotOpen
+	}
+	chain := helpers.HexFix(v.Blockchain)
+	// Nonces used before the watch started are the baseline, not an intrusion.
+	observed, err := a.fetchNonce(ensureRequestID(ctx), chain)
+	if err != nil {
+		return fmt.Errorf("failed to fetch initial nonce: %w", err)
+	}
+	expected := max(observed, v.Nonce)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// Read the local nonces before fetching, so that a submission racing the
+			// fetch can only make the check more lenient.
+			expected = max(expected, a.nwatch.next(chain))
+			observed, err := a.fetchNonce(ensureRequestID(ctx), chain)
+			if err != nil {
+				if ctx.Err() == nil {
+					a.nwatch.check(0, true)
+					a.logf(LogWarn, "WatchNonce: failed to fetch nonce: %v\n", err)
+				}
+				continue
+			}
+			if observed <= expected {
+				a.nwatch.check(0, false)
+				continue
+			}
+			alert := NonceAlert{Blockchain: chain, Expected: expected, Observed: observed, Time: time.Now()}
+			expected = observed
+			a.nwatch.check(alert.Unexplained(), false)
+			a.logf(LogWarn, "WatchNonce: nonce on %s advanced to %d without a local submission (expected %d)\n", chain, observed, alert.Expected)
+			if onAlert != nil {
+				onAlert(alert)
+			}
+		}
+	}()
+	return nil
+}
+
+// NonceWatchStats returns the totals of the account's nonce watches; see WatchNonce.
+func (a *CEPAccount) NonceWatchStats() NonceWatchStats {
+	a.nwatch.mu.Lock()
+	defer a.nwatch.mu.Unlock()
+	return a.nwatch.stats
+}
+
+// nonceWatch tracks the nonces used locally on each chain, and the watch statistics.
+type nonceWatch struct {
+	mu    sync.Mutex
+	used  map[string]int64 // The nonce after the highest one used locally, by chain.
+	stats NonceWatchStats
+}
+
+// use records that nonces before next were used locally on chain.
+func (w *nonceWatch) use(chain string, next int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	chain = helpers.HexFix(chain)
+	if w.used == nil {
+		w.used = make(map[string]int64)
+	}
+	w.used[chain] = max(w.used[chain], next)
+}
+
+// next returns the nonce after the highest one used locally on chain.
+func (w *nonceWatch) next(chain string) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.used[chain]
+}
+
+// check counts a check that either failed or found the given number of unexplained nonces.
+func (w *nonceWatch) check(unexplained int64, failed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stats.LastCheck = time.Now()
+	if failed {
+		w.stats.Failures++
+		return
+	}
+	w.stats.Checks++
+	if unexplained > 0 {
+		w.stats.Alerts++
+		w.stats.Unexplained += unexplained
+	}
+}
+
+// useTxNonce records the nonce of a transaction about to be broadcast.
+func (w *nonceWatch) useTxNonce(tx *Transaction) {
+	if nonce, err := strconv.ParseInt(tx.Nonce, 10, 64); err == nil {
+		w.use(tx.Blockchain, nonce+1)
+	}
+}
@@ -0,0 +1,75 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchNonce(t *testing.T) {
+	var chainNonce atomic.Int64 // The last nonce the gateway has seen.
+	chainNonce.Store(4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_GetWalletNonce_") {
+			fmt.Fprintf(w, `{"Result":200,"Response":{"Nonce":%d}}`, chainNonce.Load())
+			return
+		}
+		chainNonce.Add(1)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.UpdateAccount()
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	alerts := make(chan NonceAlert, 1)
+	if err := acc.WatchNonce(t.Context(), time.Millisecond, func(alert NonceAlert) { alerts <- alert }); err != nil {
+		t.Fatal(err)
+	}
+	waitChecks := func(n int64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for start := acc.NonceWatchStats().Checks; acc.NonceWatchStats().Checks < start+n; {
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for nonce checks")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Local submissions and reservations are expected.
+	if _, err := acc.submitCertificate(t.Context(), "report", signer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acc.ReserveNonces(2); err != nil {
+		t.Fatal(err)
+	}
+	chainNonce.Add(2)
+	waitChecks(3)
+	if stats := acc.NonceWatchStats(); stats.Alerts != 0 {
+		t.Fatalf("Expected no alerts for local use, got %+v", stats)
+	}
+
+	// Another signer uses three nonces, and the application resyncs before the next check.
+	chainNonce.Add(3)
+	acc.UpdateAccount()
+	select {
+	case alert := <-alerts:
+		if alert.Expected != 8 || alert.Observed != 11 || alert.Unexplained() != 3 {
+			t.Errorf("Expected an alert for nonces 8 to 10, got %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an alert for the unexplained advance")
+	}
+	waitChecks(3)
+	if stats := acc.NonceWatchStats(); stats.Alerts != 1 || stats.Unexplained != 3 {
+		t.Errorf("Expected the advance to be reported once, got %+v", stats)
+	}
+}
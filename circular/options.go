@@ -0,0 +1,83 @@
+package circular
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// SubmitOption customizes a single certificate submission.
+// Options are applied in order, so later options override earlier ones.
+type SubmitOption func(*submitConfig)
+
+// submitConfig collects the per-submission settings derived from SubmitOptions.
+type submitConfig struct {
+	to         string        // Recipient address; defaults to the submitting account.
+	skipSchema bool          // Skip schema validation for SDK-generated payloads.
+	ttl        time.Duration // Lifetime of the submission's receipt; see WithTTL.
+	previousTx string        // Transaction this submission supersedes; see Resubmit.
+	hashData   bool          // Embed the data's SHA-256 in the envelope; see WithContentHash.
+}
+
+// WithRecipient addresses the certificate to a different account instead of the
+// submitting account itself. The address may be supplied with or without a "0x" prefix.
+//
+// Parameters:
+//   - address: The blockchain address of the recipient.
+func WithRecipient(address string) SubmitOption {
+	return func(c *submitConfig) {
+		c.to = address
+	}
+}
+
+// WithContentHash embeds the hex SHA-256 digest of the certificate data in the payload
+// envelope, as a SHA256 field next to Data. Verifiers can then check the data's
+// integrity, and tools holding only the digest can match the certificate, without
+// re-deriving how the data is encoded; see VerifyOutcomeDigest and Payload.ContentHash.
+func WithContentHash() SubmitOption {
+	return func(c *submitConfig) {
+		c.hashData = true
+	}
+}
+
+// withoutSchemaValidation bypasses the account's schema registry, for payloads such as
+// key rotation attestations whose shape is defined by the SDK rather than the caller.
+func withoutSchemaValidation() SubmitOption {
+	return func(c *submitConfig) {
+		c.skipSchema = true
+	}
+}
+
+// withPreviousTxID links the certificate to the transaction it supersedes by adding a
+// PreviousTxID field to the payload envelope.
+func withPreviousTxID(txID string) SubmitOption {
+	return func(c *submitConfig) {
+		c.previousTx = txID
+	}
+}
+
+// newSubmitConfig applies opts on top of the defaults for an account at address.
+func newSubmitConfig(address string, opts []SubmitOption) (*submitConfig, error) {
+	cfg := &submitConfig{to: address}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := validateAddress(cfg.to); err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+	return cfg, nil
+}
+
+// validateAddress checks that address is a non-empty hexadecimal blockchain address.
+func validateAddress(address string) error {
+	fixed := helpers.HexFix(address)
+	if fixed == "" {
+		return fmt.Errorf("address cannot be empty")
+	}
+	if _, err := hex.DecodeString(fixed); err != nil {
+		return fmt.Errorf("address %q is not valid hex", address)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package circular
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+func TestSubmitCertificateWithRecipient(t *testing.T) {
+	var submitted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &submitted)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		opts       []SubmitOption
+		expectedTo string
+	}{
+		{name: "default recipient", opts: nil, expectedTo: "abcdef"},
+		{name: "explicit recipient", opts: []SubmitOption{WithRecipient("0x0123456789")}, expectedTo: "0123456789"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			acc := NewCEPAccount()
+			acc.NAGURL = server.URL + "/?cep="
+			acc.Open("0xABCDEF")
+
+			acc.SubmitCertificate("data", testPrivateKey, tc.opts...)
+			if acc.GetLastError() != "" {
+				t.Fatalf("Unexpected error: %s", acc.GetLastError())
+			}
+			if submitted["To"] != tc.expectedTo {
+				t.Errorf("To = %q, want %q", submitted["To"], tc.expectedTo)
+			}
+
+			hashInput := submitted["Blockchain"] + submitted["From"] + submitted["To"] + submitted["Payload"] + submitted["Nonce"] + submitted["Timestamp"]
+			hash := sha256.Sum256([]byte(hashInput))
+			if hex.EncodeToString(hash[:]) != submitted["ID"] {
+				t.Error("Expected the transaction ID to be derived from the recipient address")
+			}
+		})
+	}
+}
+
+func TestSubmitCertificateInvalidRecipient(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+
+	acc.SubmitCertificate("data", testPrivateKey, WithRecipient("not-an-address"))
+	if !strings.Contains(acc.GetLastError(), "invalid recipient") {
+		t.Errorf("Expected an invalid recipient error, got: %q", acc.GetLastError())
+	}
+}
+
+func TestFakeClockMakesSubmissionsDeterministic(t *testing.T) {
+	var submitted []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tx map[string]string
+		json.NewDecoder(r.Body).Decode(&tx)
+		submitted = append(submitted, tx)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		acc := NewCEPAccount()
+		acc.NAGURL = server.URL + "/?cep="
+		acc.Open("0xabcdef")
+		acc.SetClock(circulartest.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+		acc.Nonce = 7
+		acc.SubmitCertificate("golden", testPrivateKey)
+		if acc.GetLastError() != "" {
+			t.Fatalf("Unexpected error: %s", acc.GetLastError())
+		}
+		if acc.Nonce != 8 {
+			t.Errorf("Expected the nonce to continue from 8, got %d", acc.Nonce)
+		}
+	}
+
+	first, second := submitted[0], submitted[1]
+	if first["Timestamp"] != "2024:01:02-03:04:05" || first["Nonce"] != "7" {
+		t.Errorf("Expected the fixed timestamp and nonce, got %s and %s", first["Timestamp"], first["Nonce"])
+	}
+	for _, field := range []string{"ID", "Payload", "Signature"} {
+		if first[field] != second[field] {
+			t.Errorf("Expected identical %s across runs, got %s and %s", field, first[field], second[field])
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// OutcomeStats describes the polling performed to obtain a single transaction outcome.
+type OutcomeStats struct {
+	Attempts         int             // Number of queries sent to the NAG.
+	TotalWait        time.Duration   // Time from the start of polling until it finished.
+	AttemptLatencies []time.Duration // Round-trip time of each query, in order.
+	Finalized        bool            // Whether a final (non-pending) outcome was obtained.
+}
+
+// PollingStats aggregates OutcomeStats over every outcome polled on one network.
+type PollingStats struct {
+	Outcomes  int           // Polls that obtained a final outcome.
+	Failures  int           // Polls that timed out or otherwise gave up.
+	Attempts  int           // Total queries sent across all polls.
+	TotalWait time.Duration // Sum of the time spent by polls that obtained a final outcome.
+	MaxWait   time.Duration // Longest time any poll took to obtain a final outcome.
+}
+
+// MeanWait returns the average time taken to obtain a final outcome, or zero if none was obtained.
+func (s PollingStats) MeanWait() time.Duration {
+	if s.Outcomes == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Outcomes)
+}
+
+// recentConfirmations bounds how many confirmation latencies are kept per network
+// for adaptive interval selection.
+const recentConfirmations = 32
+
+// AdaptivePolling configures outcome polling to derive its interval from the median
+// (p50) of recently observed confirmation latencies on the current network, instead
+// of the account's static IntervalSec. Until a confirmation has been observed the
+// static interval is used. The derived interval is clamped to [MinInterval, MaxInterval];
+// a zero bound is not enforced.
+type AdaptivePolling struct {
+	MinInterval time.Duration // Lower bound for the derived interval.
+	MaxInterval time.Duration // Upper bound for the derived interval.
+}
+
+// interval clamps latency to the configured bounds.
+func (p *AdaptivePolling) interval(latency time.Duration) time.Duration {
+	if p.MinInterval > 0 && latency < p.MinInterval {
+		latency = p.MinInterval
+	}
+	if p.MaxInterval > 0 && latency > p.MaxInterval {
+		latency = p.MaxInterval
+	}
+	return latency
+}
+
+// DefaultNotFoundWindow is how long outcome polling treats "Transaction Not Found" as a
+// transaction still propagating to the gateway before giving up on it.
+const DefaultNotFoundWindow = 30 * time.Second
+
+// ErrTransactionNotFound is returned by outcome polling when the gateway still does not
+// know the transaction once the not-found window has passed; see SetNotFoundWindow.
+var ErrTransactionNotFound = newError(CodeTransactionNotFound, "transaction not found")
+
+// IsTransactionNotFound reports whether a transaction query response, as returned by
+// GetTransaction, says that the gateway does not know the transaction. Gateways signal
+// this either with ResultTransactionNotFound or with the message "Transaction Not Found".
+func IsTransactionNotFound(response map[string]interface{}) bool {
+	if result, _ := jsonx.GetFloat(response, "Result"); ResultCode(result) == ResultTransactionNotFound {
+		return true
+	}
+	message, _ := jsonx.GetString(response, "Response")
+	return strings.EqualFold(message, "Transaction Not Found")
+}
+
+// SetNotFoundWindow sets how long outcome polling keeps polling a transaction the
+// gateway reports as not found, measured from the start of polling. A freshly submitted
+// transaction may take a moment to reach the gateway being queried, so "Transaction Not
+// Found" is retried within the window and terminal after it, failing with
+// ErrTransactionNotFound. The default is DefaultNotFoundWindow.
+//
+// Parameters:
+//   - window: The retry window; zero restores the default, and a negative window keeps
+//     polling until the poll's timeout.
+func (a *CEPAccount) SetNotFoundWindow(window time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if window == 0 {
+		window = DefaultNotFoundWindow
+	}
+	a.notFound = window
+}
+
+// SetAdaptivePolling enables adaptive outcome polling intervals. Passing nil restores
+// the static IntervalSec behaviour.
+//
+// Parameters:
+//   - policy: The bounds for the derived interval.
+func (a *CEPAccount) SetAdaptivePolling(policy *AdaptivePolling) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.adaptive = policy
+}
+
+// ConfirmationLatency returns the median time recent transactions took to finalize on
+// the account's current network, as observed by outcome polling, along with the number
+// of confirmations it is based on. It returns zero and 0 until a confirmation has been
+// observed.
+func (a *CEPAccount) ConfirmationLatency() (time.Duration, int) {
+	return a.polling.median(a.networkLabel())
+}
+
+// pollInterval returns the interval to poll outcomes at: the adaptive interval for the
+// current network when enabled and observations exist, otherwise intervalSec.
+func (a *CEPAccount) pollInterval(intervalSec int) time.Duration {
+	if adaptive := a.view().adaptive; adaptive != nil {
+		if p50, n := a.ConfirmationLatency(); n > 0 {
+			return adaptive.interval(p50)
+		}
+	}
+	return time.Duration(intervalSec) * time.Second
+}
+
+// pollingRecorder accumulates PollingStats and recent confirmation latencies per
+// network. It is safe for concurrent use.
+type pollingRecorder struct {
+	mu            sync.Mutex
+	stats         map[string]PollingStats
+	confirmations map[string][]time.Duration
+}
+
+func (r *pollingRecorder) record(network string, s *OutcomeStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats == nil {
+		r.stats = make(map[string]PollingStats)
+	}
+	agg := r.stats[network]
+	agg.Attempts += s.Attempts
+	if s.Finalized {
+		agg.Outcomes++
+		agg.TotalWait += s.TotalWait
+		if s.TotalWait > agg.MaxWait {
+			agg.MaxWait = s.TotalWait
+		}
+		if r.confirmations == nil {
+			r.confirmations = make(map[string][]time.Duration)
+		}
+		recent := append(r.confirmations[network], s.TotalWait)
+		if len(recent) > recentConfirmations {
+			recent = recent[len(recent)-recentConfirmations:]
+		}
+		r.confirmations[network] = recent
+	} else {
+		agg.Failures++
+	}
+	r.stats[network] = agg
+}
+
+// median returns the median recent confirmation latency on network and the number of
+// samples it was computed from.
+func (r *pollingRecorder) median(network string) (time.Duration, int) {
+	r.mu.Lock()
+	samples := append([]time.Duration(nil), r.confirmations[network]...)
+	r.mu.Unlock()
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	mid := len(samples) / 2
+	if len(samples)%2 == 0 {
+		return (samples[mid-1] + samples[mid]) / 2, len(samples)
+	}
+	return samples[mid], len(samples)
+}
+
+func (r *pollingRecorder) snapshot() map[string]PollingStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]PollingStats, len(r.stats))
+	for k, v := range r.stats {
+		out[k] = v
+	}
+	return out
+}
+
+// PollingStats returns aggregate polling statistics for every network the account has
+// polled outcomes on, keyed by NetworkNode (or by NAG URL when no network node is set).
+// The result is a copy and safe to retain.
+func (a *CEPAccount) PollingStats() map[string]PollingStats {
+	return a.polling.snapshot()
+}
+
+// GetTransactionOutcomeWithStats behaves like GetTransactionOutcome and additionally
+// reports how the outcome was obtained: the number of queries sent, the total time
+// waited and the latency of each query. The statistics are also folded into the
+// account's aggregate PollingStats.
+//
+// Parameters:
+//   - txID: The unique identifier of the transaction to monitor.
+//   - timeoutSec: The maximum time (in seconds) to wait for the transaction to finalize.
+//   - intervalSec: The delay (in seconds) between consecutive polling attempts. Ignored
+//     once adaptive polling has observations to work from; see SetAdaptivePolling.
+//
+// Returns:
+//
+//	The finalized transaction details (or `nil` on failure, with the error stored in
+//	`a.LastError`) and the polling statistics, which are returned in either case.
+func (a *CEPAccount) GetTransactionOutcomeWithStats(txID string, timeoutSec int, intervalSec int) (map[string]interface{}, *OutcomeStats) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
+	defer cancel()
+
+	stats := &OutcomeStats{}
+	outcome, err := a.waitForOutcome(ctx, txID, a.pollInterval(intervalSec), stats)
+	if err != nil {
+		a.setError(err)
+		return nil, stats
+	}
+	return outcome, stats
+}
+
+// WaitForOutcome waits for the final outcome of a transaction, polling at the account's
+// IntervalSec (or its adaptive interval), until it finalizes or ctx is done.
+//
+// Parameters:
+//   - ctx: Controls cancellation and bounds the wait.
+//   - txID: The transaction to wait for.
+//
+// Returns:
+//
+//	The finalized transaction details, or an error if no outcome was obtained.
+func (a *CEPAccount) WaitForOutcome(ctx context.Context, txID string) (map[string]interface{}, error) {
+	return a.waitForOutcome(ctx, txID, a.pollInterval(a.view().IntervalSec), &OutcomeStats{})
+}
+
+// waitForOutcome polls for the outcome of txID until it finalizes or ctx is done,
+// keeping the transaction's receipt, if any, in step with the result. Outcomes in the
+// account's outcome cache are returned without polling.
+func (a *CEPAccount) waitForOutcome(ctx context.Context, txID string, interval time.Duration, stats *OutcomeStats) (map[string]interface{}, error) {
+	v := a.view()
+	if v.NAGURL == "" {
+		return nil, ErrNetworkNotSet
+	}
+	ctx, cancel := withDeadline(ctx, v.deadlines.withDefaults().OutcomeTotal)
+	defer cancel()
+
+	if receipt := a.loadReceipt(txID); receipt != nil && receipt.Status == ReceiptAbandoned {
+		return nil, ErrTransactionAbandoned
+	}
+
+	// All polls belong to one operation and share a single correlation ID.
+	ctx, stopWatching := a.watchOutcome(ensureRequestID(ctx), txID)
+	defer stopWatching()
+
+	outcome, cached := a.cachedOutcome(txID)
+	if cached {
+		if stats != nil {
+			stats.Finalized = true
+		}
+	} else {
+		var err error
+		if outcome, err = a.pollOutcome(ctx, txID, interval, stats); err != nil {
+			a.sampleOutcome("", stats.TotalWait, err)
+			if errors.Is(err, ErrTransactionExpired) {
+				a.updateReceipt(txID, func(r *Receipt) { r.Status = ReceiptExpired })
+			}
+			return nil, err
+		}
+		a.cacheOutcome(txID, outcome)
+		a.rechecks.add(txID, outcome)
+	}
+	status, _ := jsonx.GetString(outcome, "Status")
+	if !cached {
+		a.sampleOutcome(status, stats.TotalWait, nil)
+	}
+	a.updateReceipt(txID, func(r *Receipt) {
+		r.Status = ReceiptFinalized
+		r.FinalStatus = status
+	})
+	a.recordEvent(AccountEvent{Type: EventOutcome, TxID: helpers.HexFix(txID), Status: status})
+	return outcome, nil
+}
+
+// pollOutcome queries the NAG every interval of the account's clock until the
+// transaction leaves the "Pending" state or ctx is done, recording each attempt in stats.
+func (a *CEPAccount) pollOutcome(ctx context.Context, txID string, interval time.Duration, stats *OutcomeStats) (map[string]interface{}, error) {
+	clock := a.view().timeSource()
+	start := clock.Now()
+	defer func() {
+		stats.TotalWait = clock.Now().Sub(start)
+		a.polling.record(a.networkLabel(), stats)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if cause := context.Cause(ctx); errors.Is(cause, ErrTransactionAbandoned) || errors.Is(cause, ErrTransactionExpired) || errors.Is(cause, ErrWatcherCanceled) || errors.Is(cause, ErrAccountClosed) {
+				return nil, cause
+			}
+			return nil, fmt.Errorf("timeout exceeded while waiting for transaction outcome: %w", ctx.Err())
+		case <-clock.After(interval):
+			if ctx.Err() != nil {
+				continue // Report the cancellation rather than poll once more.
+			}
+			attemptStart := clock.Now()
+			data, err := a.getTransactionByID(ctx, txID, 0, 10) // Search recent blocks
+			stats.Attempts++
+			stats.AttemptLatencies = append(stats.AttemptLatencies, clock.Now().Sub(attemptStart))
+			if err != nil {
+				// Log non-critical errors and continue polling
+				a.logf(LogInfo, "pollOutcome: attempt %d for %s failed: %v\n", stats.Attempts, txID, err)
+				continue
+			}
+			if IsTransactionNotFound(data) {
+				if window := a.notFoundWindow(); window >= 0 && clock.Now().Sub(start) >= window {
+					return nil, fmt.Errorf("%w: %s is still unknown to the gateway after %s", ErrTransactionNotFound, txID, window)
+				}
+				continue
+			}
+
+			if result, _ := jsonx.GetFloat(data, "Result"); ResultCode(result) == ResultOK {
+				if status, ok := jsonx.GetString(data, "Response.Status"); ok && status != "Pending" {
+					response, _ := jsonx.GetMap(data, "Response")
+					stats.Finalized = true
+					return response, nil // Transaction finalized
+				}
+			}
+		}
+	}
+}
+
+// notFoundWindow returns the window set with SetNotFoundWindow, or the default.
+func (a *CEPAccount) notFoundWindow() time.Duration {
+	if window := a.view().notFound; window != 0 {
+		return window
+	}
+	return DefaultNotFoundWindow
+}
+
+// networkLabel identifies the account's current network in statistics.
+func (a *CEPAccount) networkLabel() string {
+	v := a.view()
+	if v.NetworkNode != "" {
+		return v.NetworkNode
+	}
+	return v.NAGURL
+}
@@ -0,0 +1,236 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+)
+
+func TestPollOutcomeStats(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Pending"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = "testnet"
+
+	stats := &OutcomeStats{}
+	outcome, err := acc.pollOutcome(context.Background(), "0xabc", time.Millisecond, stats)
+	if err != nil {
+		t.Fatalf("pollOutcome() error = %v", err)
+	}
+	if outcome["Status"] != "Executed" {
+		t.Errorf("Expected Executed outcome, got %v", outcome)
+	}
+	if !stats.Finalized || stats.Attempts != 3 || len(stats.AttemptLatencies) != 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.TotalWait <= 0 {
+		t.Errorf("Expected a positive total wait, got %s", stats.TotalWait)
+	}
+
+	// A poll that never finalizes is counted as a failure.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls.Store(-100)
+	if _, err := acc.pollOutcome(ctx, "0xdef", time.Millisecond, &OutcomeStats{}); err == nil {
+		t.Fatal("Expected a timeout error")
+	}
+
+	agg := acc.PollingStats()["testnet"]
+	if agg.Outcomes != 1 || agg.Failures != 1 {
+		t.Errorf("Expected 1 outcome and 1 failure, got %+v", agg)
+	}
+	if agg.Attempts < 4 {
+		t.Errorf("Expected at least 4 attempts in total, got %d", agg.Attempts)
+	}
+	if agg.MeanWait() != stats.TotalWait || agg.MaxWait != stats.TotalWait {
+		t.Errorf("Expected mean and max wait %s, got %s and %s", stats.TotalWait, agg.MeanWait(), agg.MaxWait)
+	}
+}
+
+func TestPollOutcomeTransactionNotFound(t *testing.T) {
+	var calls atomic.Int32
+	var foundAfter atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := calls.Add(1); foundAfter.Load() == 0 || n < foundAfter.Load() {
+			fmt.Fprint(w, `{"Result":118,"Response":"Transaction Not Found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.SetNotFoundWindow(time.Hour)
+
+	// Within the window, not found means not yet propagated: polling continues.
+	foundAfter.Store(3)
+	outcome, err := acc.pollOutcome(t.Context(), "0xabc", time.Millisecond, &OutcomeStats{})
+	if err != nil || outcome["Status"] != "Executed" {
+		t.Fatalf("Expected polling to continue through not-found responses, got %v, %v", outcome, err)
+	}
+
+	// After the window, not found is terminal.
+	foundAfter.Store(0)
+	acc.SetNotFoundWindow(30 * time.Second)
+	clock := circulartest.NewFakeClock(time.Now())
+	acc.SetClock(clock)
+	stats := &OutcomeStats{}
+	done := make(chan error)
+	go func() {
+		_, err := acc.pollOutcome(t.Context(), "0xabc", 2*time.Second, stats)
+		done <- err
+	}()
+	for range 15 {
+		clock.BlockUntil(1)
+		clock.Advance(2 * time.Second)
+	}
+	if err := <-done; !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("Expected ErrTransactionNotFound, got %v", err)
+	}
+	if stats.Attempts != 15 || stats.TotalWait != 30*time.Second {
+		t.Errorf("Expected polling to continue for the window, gave up after %d attempts and %s", stats.Attempts, stats.TotalWait)
+	}
+	acc.SetClock(nil)
+
+	// A negative window leaves it to the poll's timeout.
+	acc.SetNotFoundWindow(-1)
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := acc.pollOutcome(ctx, "0xabc", time.Millisecond, &OutcomeStats{}); err == nil || errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
+
+func TestIsTransactionNotFound(t *testing.T) {
+	testCases := []struct {
+		response map[string]interface{}
+		want     bool
+	}{
+		{map[string]interface{}{"Result": 118.0, "Response": "Transaction Not Found"}, true},
+		{map[string]interface{}{"Result": 200.0, "Response": "Transaction Not Found"}, true},
+		{map[string]interface{}{"Result": 200.0, "Response": map[string]interface{}{"Status": "Pending"}}, false},
+		{map[string]interface{}{"Result": 108.0, "Response": "Wallet Not Found"}, false},
+	}
+	for _, tc := range testCases {
+		if got := IsTransactionNotFound(tc.response); got != tc.want {
+			t.Errorf("IsTransactionNotFound(%v) = %v, want %v", tc.response, got, tc.want)
+		}
+	}
+}
+
+func TestPollingStatsMeanWait(t *testing.T) {
+	if (PollingStats{}).MeanWait() != 0 {
+		t.Error("Expected zero mean wait with no outcomes")
+	}
+	s := PollingStats{Outcomes: 2, TotalWait: 3 * time.Second}
+	if s.MeanWait() != 1500*time.Millisecond {
+		t.Errorf("MeanWait() = %s, want 1.5s", s.MeanWait())
+	}
+}
+
+func TestAdaptivePollInterval(t *testing.T) {
+	acc := NewCEPAccount()
+	acc.NetworkNode = "testnet"
+
+	if got := acc.pollInterval(2); got != 2*time.Second {
+		t.Errorf("Expected static interval without adaptive polling, got %s", got)
+	}
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: 500 * time.Millisecond, MaxInterval: 5 * time.Second})
+	if got := acc.pollInterval(2); got != 2*time.Second {
+		t.Errorf("Expected static interval before any confirmation, got %s", got)
+	}
+
+	for _, wait := range []time.Duration{time.Second, 3 * time.Second, 4 * time.Second} {
+		acc.polling.record("testnet", &OutcomeStats{Finalized: true, TotalWait: wait})
+	}
+	if p50, n := acc.ConfirmationLatency(); p50 != 3*time.Second || n != 3 {
+		t.Errorf("ConfirmationLatency() = %s, %d; want 3s, 3", p50, n)
+	}
+	if got := acc.pollInterval(2); got != 3*time.Second {
+		t.Errorf("Expected p50 interval of 3s, got %s", got)
+	}
+
+	testCases := []struct {
+		name    string
+		latency time.Duration
+		want    time.Duration
+	}{
+		{name: "below minimum", latency: 100 * time.Millisecond, want: 500 * time.Millisecond},
+		{name: "above maximum", latency: time.Minute, want: 5 * time.Second},
+		{name: "within bounds", latency: 2 * time.Second, want: 2 * time.Second},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := acc.adaptive.interval(tc.latency); got != tc.want {
+				t.Errorf("interval(%s) = %s, want %s", tc.latency, got, tc.want)
+			}
+		})
+	}
+
+	// Observations are kept per network.
+	acc.NetworkNode = "mainnet"
+	if got := acc.pollInterval(2); got != 2*time.Second {
+		t.Errorf("Expected static interval on a network without observations, got %s", got)
+	}
+}
+
+func TestSubmitAndWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	// Poll every millisecond rather than every IntervalSec.
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	result, err := acc.SubmitAndWait(context.Background(), "data", signer)
+	if err != nil {
+		t.Fatalf("SubmitAndWait() error = %v", err)
+	}
+	if result.TxID != acc.LatestTxID || result.Outcome["Status"] != "Executed" || !result.Stats.Finalized {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestWaitForOutcome(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed","BlockID":"b7"}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	outcome, err := Account(acc).WaitForOutcome(t.Context(), "0xabc")
+	if err != nil || outcome["BlockID"] != "b7" {
+		t.Errorf("Expected the finalized outcome, got %v, %v", outcome, err)
+	}
+}
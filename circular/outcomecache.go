@@ -0,0 +1,146 @@
+package circular
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// outcomesCollection is the DocumentStore collection final outcomes are kept in.
+const outcomesCollection = "outcomes"
+
+// DefaultOutcomeCacheSize is the number of outcomes SetOutcomeCache keeps in memory
+// when no size is given.
+const DefaultOutcomeCacheSize = 1024
+
+// SetOutcomeCache makes the account remember the outcome of every transaction that
+// reaches a final state, which does not change afterwards short of a reorganization
+// (see WatchConfirmations), so that waiting for the same transaction again (with
+// GetTransactionOutcome, SubmitAndWait or WaitForOutcomes) returns at once without
+// querying the gateway. The most recently used outcomes are kept in memory; with docs,
+// every outcome is also persisted there, surviving eviction and restarts. The cache is
+// disabled by default.
+//
+// Parameters:
+//   - size: The number of outcomes to keep in memory; zero means
+//     DefaultOutcomeCacheSize, and a negative size disables the cache.
+//   - docs: The document store to persist outcomes in; may be nil.
+func (a *CEPAccount) SetOutcomeCache(size int, docs storage.DocumentStore) {
+	a.outcomes.mu.Lock()
+	defer a.outcomes.mu.Unlock()
+	if size == 0 {
+		size = DefaultOutcomeCacheSize
+	}
+	a.outcomes.size = size
+	a.outcomes.docs = docs
+	a.outcomes.order = list.New()
+	a.outcomes.items = make(map[string]*list.Element)
+}
+
+// outcomeCache is a least recently used cache of final outcomes, backed by an optional
+// document store. Outcomes are kept encoded, so that callers never share a map.
+type outcomeCache struct {
+	mu    sync.Mutex
+	size  int // Zero or negative while the cache is disabled.
+	docs  storage.DocumentStore
+	order *list.List // Most recently used first; elements hold *cachedOutcome.
+	items map[string]*list.Element
+}
+
+// cachedOutcome is a final outcome held in memory.
+type cachedOutcome struct {
+	txID    string
+	outcome json.RawMessage
+}
+
+// cachedOutcome returns the final outcome of txID if it is cached.
+func (a *CEPAccount) cachedOutcome(txID string) (map[string]interface{}, bool) {
+	c := &a.outcomes
+	key := helpers.HexFix(txID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return nil, false
+	}
+	var outcome map[string]interface{}
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		if json.Unmarshal(elem.Value.(*cachedOutcome).outcome, &outcome) == nil {
+			return outcome, true
+		}
+	}
+	if c.docs == nil {
+		return nil, false
+	}
+	if err := c.docs.Load(outcomesCollection, key, &outcome); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			a.logf(LogWarn, "waitForOutcome: failed to load cached outcome of %s: %v\n", txID, err)
+		}
+		return nil, false
+	}
+	if data, err := json.Marshal(outcome); err == nil {
+		c.add(key, data)
+	}
+	return outcome, true
+}
+
+// cacheOutcome remembers the final outcome of txID.
+func (a *CEPAccount) cacheOutcome(txID string, outcome map[string]interface{}) {
+	c := &a.outcomes
+	key := helpers.HexFix(txID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	data, err := json.Marshal(outcome)
+	if err != nil {
+		return
+	}
+	c.add(key, data)
+	if c.docs != nil {
+		if err := c.docs.Save(outcomesCollection, key, json.RawMessage(data)); err != nil {
+			a.logf(LogWarn, "waitForOutcome: failed to persist outcome of %s: %v\n", txID, err)
+		}
+	}
+}
+
+// forgetOutcome removes the outcome of txID from the cache, and from its store.
+func (a *CEPAccount) forgetOutcome(txID string) {
+	c := &a.outcomes
+	key := helpers.HexFix(txID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+	if c.docs != nil {
+		if err := c.docs.Delete(outcomesCollection, key); err != nil {
+			a.logf(LogWarn, "forgetOutcome: failed to remove outcome of %s: %v\n", txID, err)
+		}
+	}
+}
+
+// add puts an encoded outcome at the front of the cache, evicting the least recently
+// used one if the cache is full. c.mu must be held.
+func (c *outcomeCache) add(key string, data json.RawMessage) {
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cachedOutcome).outcome = data
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cachedOutcome{txID: key, outcome: data})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedOutcome).txID)
+	}
+}
@@ -0,0 +1,116 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestOutcomeCache(t *testing.T) {
+	var mu sync.Mutex
+	queries := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		queries[req["ID"]]++
+		mu.Unlock()
+		status := "Executed"
+		if req["ID"] == "cc" {
+			status = "Pending"
+		}
+		fmt.Fprintf(w, `{"Result":200,"Response":{"ID":%q,"Status":%q}}`, req["ID"], status)
+	}))
+	defer server.Close()
+	count := func(txID string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return queries[txID]
+	}
+
+	newAccount := func() *CEPAccount {
+		acc := NewCEPAccount()
+		acc.NAGURL = server.URL + "/?cep="
+		acc.Open("0xabcdef")
+		// Poll every millisecond rather than every IntervalSec.
+		acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+		acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+		return acc
+	}
+	acc := newAccount()
+	docs := storage.NewDocumentStore(storage.NewMemory())
+	acc.SetOutcomeCache(1, docs)
+
+	first := acc.GetTransactionOutcome("0xaa", 5, 1)
+	if first == nil {
+		t.Fatal(acc.LastError)
+	}
+	outcome, stats := acc.GetTransactionOutcomeWithStats("aa", 5, 1)
+	if count("aa") != 1 {
+		t.Errorf("Expected the final outcome to be queried once, got %d queries", count("aa"))
+	}
+	if fmt.Sprint(outcome) != fmt.Sprint(first) || !stats.Finalized || stats.Attempts != 0 {
+		t.Errorf("Expected the cached outcome %v without queries, got %v and %+v", first, outcome, stats)
+	}
+	// Callers never share the cached outcome.
+	outcome["Status"] = "Tampered"
+	if again := acc.GetTransactionOutcome("aa", 5, 1); again["Status"] == "Tampered" {
+		t.Error("Expected changes to a returned outcome not to affect the cache")
+	}
+
+	// Outcomes still pending are not cached.
+	for range 2 {
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		before := count("cc")
+		if _, err := acc.waitForOutcome(ctx, "cc", time.Millisecond, &OutcomeStats{}); err == nil {
+			t.Error("Expected waiting for a pending transaction to time out")
+		}
+		cancel()
+		if count("cc") == before {
+			t.Error("Expected a pending transaction to be queried")
+		}
+	}
+
+	// "bb" evicts "aa" from memory, which is then loaded from the store.
+	acc.GetTransactionOutcome("bb", 5, 1)
+	acc.GetTransactionOutcome("aa", 5, 1)
+	if count("aa") != 1 || count("bb") != 1 {
+		t.Errorf("Expected evicted outcomes to be loaded from the store, got %v", queries)
+	}
+
+	// The store outlives the account.
+	restarted := newAccount()
+	restarted.SetOutcomeCache(0, docs)
+	if restarted.GetTransactionOutcome("bb", 5, 1) == nil || count("bb") != 1 {
+		t.Errorf("Expected the restarted account to use the stored outcome, got %d queries", count("bb"))
+	}
+
+	// Without a store, evicted outcomes are queried again.
+	memory := newAccount()
+	memory.SetOutcomeCache(1, nil)
+	memory.GetTransactionOutcome("aa", 5, 1)
+	memory.GetTransactionOutcome("bb", 5, 1)
+	memory.GetTransactionOutcome("aa", 5, 1)
+	if count("aa") != 3 {
+		t.Errorf("Expected the evicted outcome to be queried again, got %d queries", count("aa"))
+	}
+
+	// The cache is disabled by default and by a negative size.
+	for _, uncached := range []*CEPAccount{newAccount(), memory} {
+		if uncached == memory {
+			memory.SetOutcomeCache(-1, docs)
+		}
+		before := count("bb")
+		uncached.GetTransactionOutcome("bb", 5, 1)
+		if count("bb") != before+1 {
+			t.Errorf("Expected an uncached account to query the gateway")
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package circular
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ErrPayloadDoubleEncoded is returned when a payload envelope is hex-encoded twice,
+// which the gateway would record as opaque data instead of an Action.
+var ErrPayloadDoubleEncoded = newError(CodeInvalidPayload, "payload is hex-encoded twice")
+
+// Payload is the value of a transaction's Payload field: a JSON envelope such as
+// {"Action":"CP_CERTIFICATE","Data":"<hex>"} hex-encoded exactly once, in uppercase as
+// produced by helpers.StringToHex. The envelope's Data field is hex-encoded in turn, so
+// certificate data is encoded twice in total, but the envelope itself only once. A
+// Payload is obtained from EncodePayload or ParsePayload, which enforce this; it is
+// hashed into the transaction ID verbatim, so its case must not be changed.
+type Payload string
+
+// EncodePayload hex-encodes a JSON envelope as a Payload. Encode map envelopes with
+// CanonicalJSON first, since the payload is hashed into the transaction ID.
+//
+// Parameters:
+//   - envelope: The JSON object to encode, not yet hex-encoded.
+//
+// Returns:
+//
+//	The payload, or ErrPayloadDoubleEncoded if envelope is already a hex-encoded
+//	payload, or an error if it is not a JSON object.
+func EncodePayload(envelope []byte) (Payload, error) {
+	if !isJSONObject(envelope) {
+		if isPayload(string(envelope)) {
+			return "", ErrPayloadDoubleEncoded
+		}
+		return "", fmt.Errorf("payload envelope is not a JSON object")
+	}
+	return Payload(helpers.StringToHex(string(envelope))), nil
+}
+
+// ParsePayload checks a hex-encoded payload as found in a transaction, with or without
+// "0x" prefix.
+//
+// Parameters:
+//   - hexStr: The hex-encoded payload.
+//
+// Returns:
+//
+//	The payload, with its prefix removed but its case unchanged, or
+//	ErrPayloadDoubleEncoded if it decodes to another hex-encoded payload, or an error if
+//	it is not valid hex or does not decode to a JSON object.
+func ParsePayload(hexStr string) (Payload, error) {
+	payload := Payload(helpers.Strip0x(hexStr))
+	envelope, err := payload.Envelope()
+	if err != nil {
+		return "", err
+	}
+	if !isJSONObject(envelope) {
+		if isPayload(string(envelope)) {
+			return "", ErrPayloadDoubleEncoded
+		}
+		return "", fmt.Errorf("payload is not a valid envelope")
+	}
+	return payload, nil
+}
+
+// Envelope decodes the payload's hex into its JSON envelope.
+func (p Payload) Envelope() ([]byte, error) {
+	envelope, err := hex.DecodeString(helpers.Strip0x(string(p)))
+	if err != nil {
+		return nil, fmt.Errorf("payload is not valid hex: %w", err)
+	}
+	return envelope, nil
+}
+
+// payloadEnvelope holds the envelope fields the SDK interprets.
+type payloadEnvelope struct {
+	Action string `json:"Action"`
+	Data   string `json:"Data"`
+	SHA256 string `json:"SHA256"` // See WithContentHash.
+}
+
+// fields decodes the payload's envelope.
+func (p Payload) fields() (*payloadEnvelope, error) {
+	envelope, err := p.Envelope()
+	if err != nil {
+		return nil, err
+	}
+	var payloadObject payloadEnvelope
+	if err := json.Unmarshal(envelope, &payloadObject); err != nil {
+		return nil, fmt.Errorf("payload is not a valid envelope: %w", err)
+	}
+	return &payloadObject, nil
+}
+
+// Decode returns the envelope's Action and the decoded contents of its Data field.
+//
+// Returns:
+//
+//	The Action and data, or an error if the payload or its Data field cannot be decoded.
+func (p Payload) Decode() (action string, data string, err error) {
+	payloadObject, err := p.fields()
+	if err != nil {
+		return "", "", err
+	}
+	decoded, err := hex.DecodeString(helpers.HexFix(payloadObject.Data))
+	if err != nil {
+		return "", "", fmt.Errorf("payload data is not valid hex: %w", err)
+	}
+	return payloadObject.Action, string(decoded), nil
+}
+
+// ContentHash returns the hex SHA-256 digest of the data that the envelope carries
+// when it was submitted with WithContentHash.
+//
+// Returns:
+//
+//	The digest in canonical lowercase form, or an empty string if the envelope carries
+//	none, or an error if the payload cannot be decoded.
+func (p Payload) ContentHash() (string, error) {
+	payloadObject, err := p.fields()
+	if err != nil {
+		return "", err
+	}
+	return helpers.HexFix(payloadObject.SHA256), nil
+}
+
+// isPayload reports whether s is a hex-encoded JSON object, with or without prefix.
+func isPayload(s string) bool {
+	envelope, err := Payload(s).Envelope()
+	return err == nil && isJSONObject(envelope)
+}
+
+// isJSONObject reports whether data is a single JSON object.
+func isJSONObject(data []byte) bool {
+	var object map[string]json.RawMessage
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) && json.Unmarshal(data, &object) == nil
+}
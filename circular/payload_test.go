@@ -0,0 +1,107 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// gatewayPayload is the payload field of a CP_CERTIFICATE transaction certifying "hi",
+// as the gateway records it.
+const gatewayPayload = "7B22416374696F6E223A2243505F4345525449464943415445222C2244617461223A2236383639227D"
+
+func TestEncodePayload(t *testing.T) {
+	envelope, _ := CanonicalJSON(map[string]string{"Action": certificateAction, "Data": helpers.StringToHex("hi")})
+	payload, err := EncodePayload(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload != gatewayPayload {
+		t.Errorf("Expected the gateway's format %s, got %s", gatewayPayload, payload)
+	}
+	action, data, err := payload.Decode()
+	if err != nil || action != certificateAction || data != "hi" {
+		t.Errorf("Expected the payload to decode to %s and \"hi\", got %q, %q, %v", certificateAction, action, data, err)
+	}
+
+	if _, err := EncodePayload([]byte(payload)); !errors.Is(err, ErrPayloadDoubleEncoded) {
+		t.Errorf("Expected encoding a payload again to fail with ErrPayloadDoubleEncoded, got %v", err)
+	}
+	if _, err := EncodePayload([]byte(`"hi"`)); err == nil || errors.Is(err, ErrPayloadDoubleEncoded) {
+		t.Errorf("Expected a non-object envelope to be rejected, got %v", err)
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	doubled := helpers.StringToHex(gatewayPayload)
+	testCases := []struct {
+		name        string
+		input       string
+		expected    Payload
+		expectedErr error
+		errContains string
+	}{
+		{name: "gateway format", input: gatewayPayload, expected: gatewayPayload},
+		{name: "prefixed", input: "0x" + gatewayPayload, expected: gatewayPayload},
+		{name: "lowercase keeps its case", input: strings.ToLower(gatewayPayload), expected: Payload(strings.ToLower(gatewayPayload))},
+		{name: "double encoded", input: doubled, expectedErr: ErrPayloadDoubleEncoded},
+		{name: "double encoded and prefixed", input: "0X" + doubled, expectedErr: ErrPayloadDoubleEncoded},
+		{name: "not hex", input: "zz", errContains: "not valid hex"},
+		{name: "not an envelope", input: helpers.StringToHex("hi"), errContains: "not a valid envelope"},
+		{name: "empty", input: "", errContains: "not a valid envelope"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := ParsePayload(tc.input)
+			switch {
+			case tc.expectedErr != nil:
+				if !errors.Is(err, tc.expectedErr) {
+					t.Errorf("Expected %v, got %v", tc.expectedErr, err)
+				}
+			case tc.errContains != "":
+				if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tc.errContains, err)
+				}
+			case err != nil || payload != tc.expected:
+				t.Errorf("Expected %s, got %s, %v", tc.expected, payload, err)
+			}
+		})
+	}
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	var sent Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	// Data that looks hex-encoded, or like an envelope or payload, is still encoded once.
+	for _, data := range []string{"", "hi", "6869", "0x6869", `{"Action":"CP_CERTIFICATE"}`, gatewayPayload, "\x00ü"} {
+		if _, err := acc.submitCertificate(t.Context(), data, signer); err != nil {
+			t.Fatal(err)
+		}
+		payload, err := ParsePayload(sent.Payload)
+		if err != nil {
+			t.Fatalf("Expected the submitted payload for %q to parse, got %v", data, err)
+		}
+		if action, decoded, err := payload.Decode(); err != nil || action != certificateAction || decoded != data {
+			t.Errorf("Expected %q to round-trip, got %q, %q, %v", data, action, decoded, err)
+		}
+		if string(payload) != strings.ToUpper(string(payload)) {
+			t.Errorf("Expected an uppercase payload, got %s", payload)
+		}
+	}
+}
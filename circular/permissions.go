@@ -0,0 +1,148 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+const (
+	// certificateTxType is the transaction type used for data certificates.
+	certificateTxType = "C_TYPE_CERTIFICATE"
+
+	// certificateAction is the payload Action used for data certificates.
+	certificateAction = "CP_CERTIFICATE"
+)
+
+// AccountPermissions describes what an account is allowed to do on the network.
+// An empty list means the gateway did not restrict that dimension.
+type AccountPermissions struct {
+	Blockchains      []string `json:"Blockchains"`      // Blockchain identifiers the account may submit to.
+	TransactionTypes []string `json:"TransactionTypes"` // Transaction types (e.g., "C_TYPE_CERTIFICATE") the account may submit.
+}
+
+// AllowsBlockchain reports whether the account may submit transactions to chain.
+func (p *AccountPermissions) AllowsBlockchain(chain string) bool {
+	if len(p.Blockchains) == 0 {
+		return true
+	}
+	for _, allowed := range p.Blockchains {
+		if helpers.HexFix(allowed) == helpers.HexFix(chain) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsTransactionType reports whether the account may submit transactions of type txType.
+func (p *AccountPermissions) AllowsTransactionType(txType string) bool {
+	if len(p.TransactionTypes) == 0 {
+		return true
+	}
+	for _, allowed := range p.TransactionTypes {
+		if allowed == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionError is returned when a submission is rejected client-side because the
+// account's permissions, as reported by the gateway, do not allow it.
+type PermissionError struct {
+	Address         string // The account attempting the operation.
+	Blockchain      string // The blockchain targeted by the operation.
+	TransactionType string // The transaction type of the operation.
+	Reason          string // Which permission was missing.
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied for account %s: %s (blockchain %s, type %s)", e.Address, e.Reason, e.Blockchain, e.TransactionType)
+}
+
+// Code returns CodePermissionDenied.
+func (e *PermissionError) Code() ErrorCode {
+	return CodePermissionDenied
+}
+
+// GetPermissions fetches the account's wallet record from the NAG and extracts the
+// permissions it advertises. The result is cached on the account and used to reject
+// disallowed submissions before they reach the gateway. If the wallet record carries
+// no permissions, the returned value is unrestricted.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//
+// Returns:
+//
+//	The account's permissions, or an error if the account is not open, the network is
+//	not set, or the wallet record cannot be retrieved.
+func (a *CEPAccount) GetPermissions(ctx context.Context) (*AccountPermissions, error) {
+	address := a.view().Address
+	if address == "" {
+		return nil, ErrAccountNotOpen
+	}
+
+	wallet, err := a.getWallet(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	permissions := &AccountPermissions{}
+	if raw, ok := wallet["Permissions"]; ok && raw != nil {
+		rawBytes, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal permissions: %w", err)
+		}
+		if err := json.Unmarshal(rawBytes, permissions); err != nil {
+			return nil, fmt.Errorf("failed to decode permissions: %w", err)
+		}
+	}
+
+	a.mu.Lock()
+	a.permissions = permissions
+	a.mu.Unlock()
+	return permissions, nil
+}
+
+// checkPermissions validates a submission against the cached permissions, if any have
+// been fetched with GetPermissions.
+func (a *CEPAccount) checkPermissions(chain string, txType string) error {
+	v := a.view()
+	if v.permissions == nil {
+		return nil
+	}
+	if !v.permissions.AllowsBlockchain(chain) {
+		return &PermissionError{Address: v.Address, Blockchain: chain, TransactionType: txType, Reason: "blockchain not permitted"}
+	}
+	if !v.permissions.AllowsTransactionType(txType) {
+		return &PermissionError{Address: v.Address, Blockchain: chain, TransactionType: txType, Reason: "transaction type not permitted"}
+	}
+	return nil
+}
+
+// getWallet retrieves the wallet record for address on the account's blockchain.
+func (a *CEPAccount) getWallet(ctx context.Context, address string) (map[string]interface{}, error) {
+	v := a.view()
+	requestData := map[string]string{
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"Address":    helpers.HexFix(address),
+		"Version":    v.CodeVersion,
+	}
+
+	resp, err := a.postNAG(ctx, "Circular_GetWallet_", requestData)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+
+	var wallet map[string]interface{}
+	if err := json.Unmarshal(resp.Response, &wallet); err != nil {
+		return nil, fmt.Errorf("unexpected wallet response format: %w", err)
+	}
+	return wallet, nil
+}
@@ -0,0 +1,93 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetPermissions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mockResponse  string
+		expectedError bool
+		allowedChain  bool
+		allowedType   bool
+	}{
+		{
+			name:         "unrestricted",
+			mockResponse: `{"Result":200,"Response":{"Address":"abcdef","Nonce":3}}`,
+			allowedChain: true,
+			allowedType:  true,
+		},
+		{
+			name:         "chain not permitted",
+			mockResponse: `{"Result":200,"Response":{"Permissions":{"Blockchains":["0x1234"]}}}`,
+			allowedChain: false,
+			allowedType:  true,
+		},
+		{
+			name:         "type not permitted",
+			mockResponse: `{"Result":200,"Response":{"Permissions":{"TransactionTypes":["C_TYPE_TOKEN"]}}}`,
+			allowedChain: true,
+			allowedType:  false,
+		},
+		{
+			name:          "wallet not found",
+			mockResponse:  `{"Result":108,"Response":"Wallet Not Found"}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("cep") != "Circular_GetWallet_" {
+					t.Errorf("Unexpected endpoint: %s", r.URL.RawQuery)
+				}
+				fmt.Fprint(w, tc.mockResponse)
+			}))
+			defer server.Close()
+
+			acc := NewCEPAccount()
+			acc.NAGURL = server.URL + "/?cep="
+			acc.Open("0xabcdef")
+
+			permissions, err := acc.GetPermissions(context.Background())
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := permissions.AllowsBlockchain(acc.Blockchain); got != tc.allowedChain {
+				t.Errorf("AllowsBlockchain() = %v, want %v", got, tc.allowedChain)
+			}
+			if got := permissions.AllowsTransactionType(certificateTxType); got != tc.allowedType {
+				t.Errorf("AllowsTransactionType() = %v, want %v", got, tc.allowedType)
+			}
+
+			err = acc.checkPermissions(acc.Blockchain, certificateTxType)
+			var permErr *PermissionError
+			if tc.allowedChain && tc.allowedType {
+				if err != nil {
+					t.Errorf("Expected submission to be permitted, got: %v", err)
+				}
+			} else if !errors.As(err, &permErr) {
+				t.Errorf("Expected a PermissionError, got: %v", err)
+			}
+
+			// The cached permissions are those of the address they were fetched for.
+			acc.Open("0x123456")
+			if err := acc.checkPermissions(acc.Blockchain, certificateTxType); err != nil {
+				t.Errorf("Expected another address not to inherit the permissions, got: %v", err)
+			}
+		})
+	}
+}
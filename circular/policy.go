@@ -0,0 +1,304 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// Submission describes a certificate about to be submitted, for evaluation by a Policy.
+type Submission struct {
+	Network    string                 // The account's NetworkNode; empty if not set.
+	Blockchain string                 // The chain submitted to, without "0x" prefix.
+	From       string                 // The submitting address, without "0x" prefix.
+	To         string                 // The recipient address, without "0x" prefix.
+	Data       string                 // The data being certified.
+	Metadata   map[string]interface{} // The "Metadata" (or "metadata") object of Data when Data is a JSON document carrying one, as built by the CLI's manifests and by certtemplate; nil otherwise.
+	Time       time.Time              // When the submission is evaluated.
+	Label      string                 // The submitting account's label, if any; see SetLabel.
+}
+
+// newSubmission describes the certification of data from one address to another on chain.
+func newSubmission(network, chain, from, to, data string) *Submission {
+	s := &Submission{
+		Network:    network,
+		Blockchain: helpers.HexFix(chain),
+		From:       helpers.HexFix(from),
+		To:         helpers.HexFix(to),
+		Data:       data,
+		Time:       time.Now(),
+	}
+	var doc map[string]json.RawMessage
+	if json.Unmarshal([]byte(data), &doc) == nil {
+		for _, key := range []string{"Metadata", "metadata"} {
+			if raw, ok := doc[key]; ok && json.Unmarshal(raw, &s.Metadata) == nil && s.Metadata != nil {
+				break
+			}
+		}
+	}
+	return s
+}
+
+// Policy is a governance rule evaluated before each certificate submission; see
+// SetPolicy.
+type Policy interface {
+	// Check returns a *PolicyViolationError if s must not be submitted, and nil otherwise.
+	Check(s *Submission) error
+}
+
+// SubmissionObserver is implemented by policies that keep state about past
+// submissions, such as MaxDailyVolume. Submitted is called once the gateway has
+// accepted a submission that passed Check.
+type SubmissionObserver interface {
+	Submitted(s *Submission)
+}
+
+// PolicyFunc adapts a function to the Policy interface.
+type PolicyFunc func(s *Submission) error
+
+// Check implements Policy.
+func (f PolicyFunc) Check(s *Submission) error {
+	return f(s)
+}
+
+// PolicyViolationError is returned when a submission is refused by the account's policy.
+type PolicyViolationError struct {
+	Rule   string // The rule that failed, e.g. "max-payload-size".
+	Reason string // Why the submission breaks the rule.
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("submission violates policy %s: %s", e.Rule, e.Reason)
+}
+
+// Code returns CodePolicyViolation.
+func (e *PolicyViolationError) Code() ErrorCode {
+	return CodePolicyViolation
+}
+
+// SetPolicy installs the policy every certificate submission must satisfy, including
+// those of SubmitWithPrecomputedID, before anything is signed or sent. Combine rules
+// with AllPolicies. A refused submission fails with the policy's *PolicyViolationError
+// and leaves the nonce unchanged.
+//
+// Parameters:
+//   - policy: The policy to enforce; nil removes it.
+func (a *CEPAccount) SetPolicy(policy Policy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.policy = policy
+}
+
+// AllPolicies returns a policy satisfied when every one of policies is, checked in
+// order; the first violation is returned. It forwards Submitted to those that observe
+// submissions.
+//
+// Parameters:
+//   - policies: The policies to combine.
+//
+// Returns:
+//
+//	The combined policy.
+func AllPolicies(policies ...Policy) Policy {
+	return allPolicies(append([]Policy(nil), policies...))
+}
+
+type allPolicies []Policy
+
+func (p allPolicies) Check(s *Submission) error {
+	for _, policy := range p {
+		if err := policy.Check(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p allPolicies) Submitted(s *Submission) {
+	for _, policy := range p {
+		if observer, ok := policy.(SubmissionObserver); ok {
+			observer.Submitted(s)
+		}
+	}
+}
+
+// MaxPayloadSize refuses data longer than maxBytes, under the rule "max-payload-size".
+//
+// Parameters:
+//   - maxBytes: The largest data size allowed, in bytes, before hex encoding.
+//
+// Returns:
+//
+//	The policy.
+func MaxPayloadSize(maxBytes int) Policy {
+	return PolicyFunc(func(s *Submission) error {
+		if len(s.Data) > maxBytes {
+			return &PolicyViolationError{Rule: "max-payload-size", Reason: fmt.Sprintf("data is %d bytes, more than %d", len(s.Data), maxBytes)}
+		}
+		return nil
+	})
+}
+
+// AllowedHours refuses submissions outside the daily window from hour start up to hour
+// end, under the rule "allowed-hours". The window wraps midnight when end is not after
+// start, so AllowedHours(22, 6, loc) allows the night.
+//
+// Parameters:
+//   - start: The first hour allowed, 0 to 23.
+//   - end: The hour, 0 to 24, at which submissions stop being allowed.
+//   - loc: The time zone of the hours; nil means UTC.
+//
+// Returns:
+//
+//	The policy.
+func AllowedHours(start, end int, loc *time.Location) Policy {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return PolicyFunc(func(s *Submission) error {
+		hour := s.Time.In(loc).Hour()
+		allowed := hour >= start && hour < end
+		if end <= start {
+			allowed = hour >= start || hour < end
+		}
+		if !allowed {
+			return &PolicyViolationError{Rule: "allowed-hours", Reason: fmt.Sprintf("submissions are allowed from %02d:00 to %02d:00 %s, not at %s", start, end, loc, s.Time.In(loc).Format("15:04"))}
+		}
+		return nil
+	})
+}
+
+// AllowedChains refuses submissions to chains other than chains, under the rule
+// "allowed-chains".
+//
+// Parameters:
+//   - chains: The chain IDs allowed, with or without "0x" prefix.
+//
+// Returns:
+//
+//	The policy.
+func AllowedChains(chains ...string) Policy {
+	allowed := make(map[string]bool, len(chains))
+	for _, chain := range chains {
+		allowed[helpers.HexFix(chain)] = true
+	}
+	return PolicyFunc(func(s *Submission) error {
+		if !allowed[s.Blockchain] {
+			return &PolicyViolationError{Rule: "allowed-chains", Reason: fmt.Sprintf("chain %s is not allowed", s.Blockchain)}
+		}
+		return nil
+	})
+}
+
+// RequiredMetadata refuses submissions whose Submission.Metadata lacks any of fields or
+// has them empty, under the rule "required-metadata".
+//
+// Parameters:
+//   - fields: The metadata keys every submission must carry.
+//
+// Returns:
+//
+//	The policy.
+func RequiredMetadata(fields ...string) Policy {
+	fields = append([]string(nil), fields...)
+	return PolicyFunc(func(s *Submission) error {
+		var missing []string
+		for _, field := range fields {
+			if value, ok := s.Metadata[field]; !ok || value == nil || value == "" {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			return &PolicyViolationError{Rule: "required-metadata", Reason: "missing " + strings.Join(missing, ", ")}
+		}
+		return nil
+	})
+}
+
+// MaxDailyVolume refuses submissions beyond maxSubmissions per day, or beyond maxBytes
+// of data per day, under the rule "max-daily-volume". Days start at midnight in loc.
+// Only submissions accepted by the gateway count. The totals are kept in memory, so
+// share one MaxDailyVolume between the accounts it should limit together.
+//
+// Parameters:
+//   - maxSubmissions: The most submissions per day; zero means unlimited.
+//   - maxBytes: The most bytes of data per day; zero means unlimited.
+//   - loc: The time zone of the day boundary; nil means UTC.
+//
+// Returns:
+//
+//	The policy, which also implements SubmissionObserver.
+func MaxDailyVolume(maxSubmissions int, maxBytes int64, loc *time.Location) Policy {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &dailyVolume{maxCount: maxSubmissions, maxBytes: maxBytes, loc: loc}
+}
+
+// dailyVolume is the policy returned by MaxDailyVolume.
+type dailyVolume struct {
+	maxCount int
+	maxBytes int64
+	loc      *time.Location
+
+	mu    sync.Mutex
+	day   string
+	count int
+	bytes int64
+}
+
+// totals returns the volume already submitted on the day of t.
+func (d *dailyVolume) totals(t time.Time) (int, int64) {
+	if day := t.In(d.loc).Format(time.DateOnly); day != d.day {
+		d.day, d.count, d.bytes = day, 0, 0
+	}
+	return d.count, d.bytes
+}
+
+func (d *dailyVolume) Check(s *Submission) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	count, bytes := d.totals(s.Time)
+	if d.maxCount > 0 && count+1 > d.maxCount {
+		return &PolicyViolationError{Rule: "max-daily-volume", Reason: fmt.Sprintf("%d submissions already made today, the most allowed", count)}
+	}
+	if d.maxBytes > 0 && bytes+int64(len(s.Data)) > d.maxBytes {
+		return &PolicyViolationError{Rule: "max-daily-volume", Reason: fmt.Sprintf("%d bytes would exceed the daily limit of %d, with %d already submitted", len(s.Data), d.maxBytes, bytes)}
+	}
+	return nil
+}
+
+func (d *dailyVolume) Submitted(s *Submission) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.totals(s.Time)
+	d.count++
+	d.bytes += int64(len(s.Data))
+}
+
+// checkPolicy evaluates the account's policy, if any, on a submission of data from
+// the account to the recipient to on chain. It returns the submission, for
+// policySubmitted once the gateway accepts it.
+func (v accountView) checkPolicy(chain, to, data string) (*Submission, error) {
+	if v.policy == nil {
+		return nil, nil
+	}
+	s := newSubmission(v.NetworkNode, chain, v.Address, to, data)
+	s.Label = v.label
+	if err := v.policy.Check(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// policySubmitted tells the account's policy, if it observes submissions, that s was
+// accepted by the gateway.
+func (v accountView) policySubmitted(s *Submission) {
+	if observer, ok := v.policy.(SubmissionObserver); ok && s != nil {
+		observer.Submitted(s)
+	}
+}
@@ -0,0 +1,117 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPolicy(t *testing.T) {
+	var submissions atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":0}}`)
+		default:
+			submissions.Add(1)
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	acc.SetPolicy(AllPolicies(
+		AllowedChains(DefaultChain),
+		MaxPayloadSize(64),
+		RequiredMetadata("department"),
+		MaxDailyVolume(2, 0, nil),
+	))
+
+	violation := func(err error) string {
+		var pv *PolicyViolationError
+		if !errors.As(err, &pv) {
+			return fmt.Sprintf("not a violation: %v", err)
+		}
+		return pv.Rule
+	}
+	submit := func(data string) error {
+		_, err := acc.SubmitCertificateOn(t.Context(), DefaultChain, data, signer)
+		return err
+	}
+
+	if rule := violation(submit(`{"Data":"q1","Metadata":{"quarter":"Q1"}}`)); rule != "required-metadata" {
+		t.Errorf("Expected the missing department to be refused, got %s", rule)
+	}
+	if rule := violation(submit(`{"Data":"` + strings.Repeat("x", 64) + `","Metadata":{"department":"finance"}}`)); rule != "max-payload-size" {
+		t.Errorf("Expected the oversized data to be refused, got %s", rule)
+	}
+	if _, err := acc.SubmitCertificateOn(t.Context(), "0x1234", `{"Metadata":{"department":"finance"}}`, signer); violation(err) != "allowed-chains" {
+		t.Errorf("Expected the other chain to be refused, got %v", err)
+	}
+	if submissions.Load() != 0 {
+		t.Fatalf("Expected nothing to be sent for refused submissions, got %d", submissions.Load())
+	}
+
+	// certtemplate documents carry lowercase metadata.
+	for _, data := range []string{`{"Data":"q1","Metadata":{"department":"finance"}}`, `{"template":"t","metadata":{"department":"legal"}}`} {
+		if err := submit(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rule := violation(submit(`{"Data":"q3","Metadata":{"department":"finance"}}`)); rule != "max-daily-volume" {
+		t.Errorf("Expected the third submission of the day to be refused, got %s", rule)
+	}
+	if submissions.Load() != 2 {
+		t.Errorf("Expected 2 submissions to be sent, got %d", submissions.Load())
+	}
+
+	acc.SetPolicy(nil)
+	if err := submit("anything"); err != nil {
+		t.Errorf("Expected no policy after SetPolicy(nil), got %v", err)
+	}
+}
+
+func TestAllowedHours(t *testing.T) {
+	at := func(hour int) *Submission {
+		return &Submission{Time: time.Date(2024, 1, 2, hour, 30, 0, 0, time.UTC)}
+	}
+	office := AllowedHours(9, 17, nil)
+	night := AllowedHours(22, 6, time.UTC)
+	for hour, expected := range map[int][2]bool{8: {false, false}, 9: {true, false}, 16: {true, false}, 17: {false, false}, 23: {false, true}, 0: {false, true}, 5: {false, true}, 6: {false, false}} {
+		if got := office.Check(at(hour)) == nil; got != expected[0] {
+			t.Errorf("office hours at %02d:30: expected allowed=%v", hour, expected[0])
+		}
+		if got := night.Check(at(hour)) == nil; got != expected[1] {
+			t.Errorf("night hours at %02d:30: expected allowed=%v", hour, expected[1])
+		}
+	}
+}
+
+func TestMaxDailyVolume(t *testing.T) {
+	policy := MaxDailyVolume(0, 10, nil)
+	observer := policy.(SubmissionObserver)
+	day := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	s := &Submission{Data: "123456", Time: day}
+	if err := policy.Check(s); err != nil {
+		t.Fatal(err)
+	}
+	observer.Submitted(s)
+	if err := policy.Check(s); err == nil {
+		t.Error("Expected 12 bytes to exceed the daily limit of 10")
+	}
+	// Checks alone do not count, and the totals reset the next day.
+	if err := policy.Check(&Submission{Data: "1234", Time: day}); err != nil {
+		t.Errorf("Expected 10 bytes to be allowed, got %v", err)
+	}
+	if err := policy.Check(&Submission{Data: "123456", Time: day.Add(24 * time.Hour)}); err != nil {
+		t.Errorf("Expected the limit to reset the next day, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// MaxEnvelopeSize is the largest payload envelope SubmitRawEnvelope accepts, in bytes
+// before hex encoding.
+const MaxEnvelopeSize = 1 << 20
+
+// ErrEnvelopeTooLarge is returned by SubmitRawEnvelope for an envelope larger than
+// MaxEnvelopeSize.
+var ErrEnvelopeTooLarge = newError(CodeInvalidPayload, "payload envelope is too large")
+
+// txTypePattern matches transaction types such as "C_TYPE_CERTIFICATE".
+var txTypePattern = regexp.MustCompile(`^C_TYPE_[A-Z0-9_]+$`)
+
+// SubmitRawEnvelope submits a transaction carrying a payload envelope built outside the
+// SDK, for partners that construct the {"Action": ..., "Data": ...} envelope themselves,
+// possibly in another language. The SDK signs the transaction and allocates its nonce as
+// for SubmitCertificate, but sends the envelope exactly as given: it must already be
+// hex-encoded once, as ParsePayload checks, have an Action, and be at most
+// MaxEnvelopeSize bytes. The account's permissions, chain checks, policy and quota apply
+// to txType and to the decoded contents of the envelope's Data field, and submissions
+// are queued in maintenance windows and degraded mode like certificates. On success the
+// account's LatestTxID is updated and its nonce advanced.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the submission.
+//   - envelopeHex: The hex-encoded envelope, with or without "0x" prefix.
+//   - txType: The transaction type, such as "C_TYPE_CERTIFICATE".
+//   - signer: Signs the transaction; it must hold the account's key.
+//   - opts: Optional settings such as WithRecipient or WithTTL.
+//     WithContentHash does not apply, since the envelope is not built by the SDK.
+//
+// Returns:
+//
+//	The transaction ID, or an error if the envelope or txType is malformed, a check
+//	refuses the submission, or the gateway rejects it. Like SubmitCertificate, a
+//	*DeferredError is returned with the ID of a transaction queued for a maintenance
+//	window.
+func (a *CEPAccount) SubmitRawEnvelope(ctx context.Context, envelopeHex, txType string, signer Signer, opts ...SubmitOption) (string, error) {
+	if signer == nil {
+		return "", ErrSignerRequired
+	}
+	payload, data, err := parseRawEnvelope(envelopeHex, txType)
+	if err != nil {
+		return "", err
+	}
+
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+
+	v := a.view()
+	if v.Address == "" {
+		return "", ErrAccountNotOpen
+	}
+	ctx, cancel := withDeadline(ensureRequestID(ctx), v.deadlines.withDefaults().Submit)
+	defer cancel()
+	cfg, err := newSubmitConfig(v.Address, opts)
+	if err != nil {
+		return "", err
+	}
+	if cfg.hashData {
+		return "", errors.New("WithContentHash does not apply to a raw envelope; add the SHA256 field to the envelope instead")
+	}
+	if err := v.scanSigned(data); err != nil {
+		return "", err
+	}
+	if err := a.checkPermissions(v.Blockchain, txType); err != nil {
+		return "", err
+	}
+	if v.chainCheck {
+		if err := a.validateChain(ctx, v.Blockchain, txType); err != nil {
+			return "", err
+		}
+	}
+	submission, err := v.checkPolicy(v.Blockchain, cfg.to, data)
+	if err != nil {
+		return "", err
+	}
+	if err := a.quota.check(1, int64(len(data))); err != nil {
+		return "", err
+	}
+
+	nonce := v.Nonce
+	tx, err := signTransaction(v.Blockchain, v.Address, v.CodeVersion, nonce, v.timeSource().Now(), txType, payload, signer, cfg)
+	if err != nil {
+		return "", err
+	}
+	err = a.dispatch(ctx, v, tx, cfg, int64(len(data)), submission)
+	if err != nil && !queuedForLater(err) {
+		return "", err
+	}
+	a.applySubmission(v, tx.ID, nonce, true)
+	return tx.ID, err
+}
+
+// parseRawEnvelope checks a raw envelope and transaction type for SubmitRawEnvelope,
+// returning the payload and the decoded contents of its Data field.
+func parseRawEnvelope(envelopeHex, txType string) (Payload, string, error) {
+	if !txTypePattern.MatchString(txType) {
+		return "", "", fmt.Errorf("invalid transaction type %q: expected a type such as %q", txType, certificateTxType)
+	}
+	if size := len(helpers.Strip0x(envelopeHex)) / 2; size > MaxEnvelopeSize {
+		return "", "", fmt.Errorf("%w: %d bytes, more than %d", ErrEnvelopeTooLarge, size, MaxEnvelopeSize)
+	}
+	payload, err := ParsePayload(envelopeHex)
+	if err != nil {
+		return "", "", err
+	}
+	if fields, err := payload.fields(); err != nil || fields.Action == "" {
+		return "", "", fmt.Errorf("payload is not a valid envelope with an Action")
+	}
+	_, data, err := payload.Decode()
+	if err != nil {
+		return "", "", err
+	}
+	return payload, data, nil
+}
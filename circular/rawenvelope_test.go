@@ -0,0 +1,80 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestSubmitRawEnvelope(t *testing.T) {
+	var sent []Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tx Transaction
+		json.NewDecoder(r.Body).Decode(&tx)
+		sent = append(sent, tx)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.Nonce = 5
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	envelope := helpers.StringToHex(`{"Action":"CP_PARTNER","Data":"` + helpers.StringToHex("invoice 42") + `"}`)
+	txID, err := acc.SubmitRawEnvelope(t.Context(), "0x"+envelope, "C_TYPE_PARTNER", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0].ID != txID || sent[0].Payload != envelope || sent[0].Type != "C_TYPE_PARTNER" || sent[0].Nonce != "5" {
+		t.Fatalf("Expected the envelope to be sent as given, got %+v", sent)
+	}
+	if !VerifySignature(signer.PublicKey(), txID, sent[0].Signature) {
+		t.Error("Expected the transaction to be signed")
+	}
+	if state := acc.State(); state.Nonce != 6 || state.LatestTxID != txID {
+		t.Errorf("Expected the nonce to advance, got %+v", state)
+	}
+
+	acc.SetPolicy(MaxPayloadSize(4))
+	testCases := []struct {
+		name     string
+		envelope string
+		txType   string
+		opts     []SubmitOption
+		want     error
+	}{
+		{name: "invalid hex", envelope: "zz", txType: "C_TYPE_PARTNER"},
+		{name: "not an envelope", envelope: helpers.StringToHex("plain text"), txType: "C_TYPE_PARTNER"},
+		{name: "no action", envelope: helpers.StringToHex(`{"Data":"00"}`), txType: "C_TYPE_PARTNER"},
+		{name: "double encoded", envelope: helpers.StringToHex(envelope), txType: "C_TYPE_PARTNER", want: ErrPayloadDoubleEncoded},
+		{name: "too large", envelope: strings.Repeat("00", MaxEnvelopeSize+1), txType: "C_TYPE_PARTNER", want: ErrEnvelopeTooLarge},
+		{name: "invalid type", envelope: envelope, txType: "partner"},
+		{name: "content hash", envelope: envelope, txType: "C_TYPE_PARTNER", opts: []SubmitOption{WithContentHash()}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := acc.SubmitRawEnvelope(t.Context(), tc.envelope, tc.txType, signer, tc.opts...)
+			if err == nil || (tc.want != nil && !errors.Is(err, tc.want)) {
+				t.Errorf("Expected the envelope to be refused with %v, got %v", tc.want, err)
+			}
+		})
+	}
+	var violation *PolicyViolationError
+	if _, err := acc.SubmitRawEnvelope(t.Context(), envelope, "C_TYPE_PARTNER", signer); !errors.As(err, &violation) {
+		t.Errorf("Expected the policy to apply to the envelope's data, got %v", err)
+	}
+	if _, err := acc.SubmitRawEnvelope(t.Context(), envelope, "C_TYPE_PARTNER", nil); !errors.Is(err, ErrSignerRequired) {
+		t.Errorf("Expected ErrSignerRequired, got %v", err)
+	}
+	if len(sent) != 1 || acc.State().Nonce != 6 {
+		t.Errorf("Expected refused envelopes to send nothing, got %d transactions", len(sent))
+	}
+}
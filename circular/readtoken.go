@@ -0,0 +1,159 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ErrReadTokenExpired is returned by a ReadOnlyClient whose token has expired.
+var ErrReadTokenExpired = newError(CodeReadTokenExpired, "read token expired")
+
+// ReadScope limits what a read token grants access to. An empty list places no
+// restriction on that dimension, so an empty scope covers every read the minting
+// account could perform itself.
+type ReadScope struct {
+	TxIDs     []string `json:"TxIDs,omitempty"`     // Transactions whose details and outcomes may be read.
+	Addresses []string `json:"Addresses,omitempty"` // Accounts whose transaction history may be listed.
+}
+
+// ReadToken is a delegated, read-only credential issued by the gateway. It can be
+// handed to a browser or mobile frontend, or to a ReadOnlyClient, to query the gateway
+// directly without access to the account's keys.
+type ReadToken struct {
+	Token     string    `json:"Token"`     // The opaque bearer token.
+	Scope     ReadScope `json:"Scope"`     // What the token grants access to.
+	ExpiresAt time.Time `json:"ExpiresAt"` // When the gateway stops accepting the token.
+}
+
+// Expired reports whether the token has expired at now.
+func (t *ReadToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// MintReadToken asks the gateway to issue a read token for the account, limited to
+// scope and valid for ttl. Gateways that do not support delegated reads reject the
+// request with an *APIError.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - scope: What the token may be used to read.
+//   - ttl: How long the token remains valid; it must be positive.
+//
+// Returns:
+//
+//	The token, or an error if the account is not open, ttl is not positive, or the
+//	gateway refuses to issue the token.
+func (a *CEPAccount) MintReadToken(ctx context.Context, scope ReadScope, ttl time.Duration) (*ReadToken, error) {
+	v := a.view()
+	if v.Address == "" {
+		return nil, ErrAccountNotOpen
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("read token TTL must be positive, got %s", ttl)
+	}
+
+	normalized := ReadScope{}
+	for _, txID := range scope.TxIDs {
+		normalized.TxIDs = append(normalized.TxIDs, helpers.HexFix(txID))
+	}
+	for _, address := range scope.Addresses {
+		normalized.Addresses = append(normalized.Addresses, helpers.HexFix(address))
+	}
+
+	requestData := map[string]interface{}{
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"Address":    helpers.HexFix(v.Address),
+		"Scope":      normalized,
+		"TTL":        fmt.Sprintf("%d", int64(ttl/time.Second)),
+		"Version":    v.CodeVersion,
+	}
+
+	resp, err := a.postNAG(ensureRequestID(ctx), "Circular_MintReadToken_", requestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mint read token: %w", err)
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+
+	var issued struct {
+		Token string `json:"Token"`
+	}
+	if err := json.Unmarshal(resp.Response, &issued); err != nil || issued.Token == "" {
+		return nil, fmt.Errorf("unexpected read token response: %s", truncateBody(resp.Response))
+	}
+	return &ReadToken{Token: issued.Token, Scope: normalized, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+// ReadOnlyClient queries a gateway using a delegated ReadToken instead of an account.
+// It cannot submit transactions.
+type ReadOnlyClient struct {
+	account *CEPAccount
+	token   ReadToken
+}
+
+// NewReadOnlyClient creates a client that reads from the gateway described by profile,
+// presenting token with every request.
+//
+// Parameters:
+//   - profile: The gateway to query.
+//   - blockchain: The blockchain to query; an empty string selects DefaultChain.
+//   - token: The read token minted with MintReadToken.
+//
+// Returns:
+//
+//	The client, or an error if the profile is invalid or the token is empty.
+func NewReadOnlyClient(profile NetworkProfile, blockchain string, token ReadToken) (*ReadOnlyClient, error) {
+	if token.Token == "" {
+		return nil, fmt.Errorf("read token cannot be empty")
+	}
+	account := NewCEPAccount()
+	if err := account.SetNetworkProfile(profile); err != nil {
+		return nil, err
+	}
+	if blockchain != "" {
+		account.SetBlockchain(blockchain)
+	}
+	account.readToken = token.Token
+	return &ReadOnlyClient{account: account, token: token}, nil
+}
+
+// GetTransaction looks up a transaction in the most recent blocks.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - txID: The transaction to look up.
+//
+// Returns:
+//
+//	The raw gateway response, or an error if the token has expired or the request fails.
+func (c *ReadOnlyClient) GetTransaction(ctx context.Context, txID string) (map[string]interface{}, error) {
+	if c.token.Expired(time.Now()) {
+		return nil, ErrReadTokenExpired
+	}
+	return c.account.getTransactionByID(ensureRequestID(ctx), txID, 0, 10)
+}
+
+// WaitForOutcome polls for the final outcome of txID until it is available or ctx is done.
+//
+// Parameters:
+//   - ctx: Controls cancellation and bounds the wait.
+//   - txID: The transaction to wait for.
+//   - interval: The delay between polls.
+//
+// Returns:
+//
+//	The finalized transaction details, or an error if the token has expired or no
+//	outcome was obtained.
+func (c *ReadOnlyClient) WaitForOutcome(ctx context.Context, txID string, interval time.Duration) (map[string]interface{}, error) {
+	if c.token.Expired(time.Now()) {
+		return nil, ErrReadTokenExpired
+	}
+	ctx, cancel := context.WithDeadline(ctx, c.token.ExpiresAt)
+	defer cancel()
+	return c.account.waitForOutcome(ctx, txID, interval, &OutcomeStats{})
+}
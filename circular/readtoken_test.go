@@ -0,0 +1,58 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMintReadTokenAndReadOnlyClient(t *testing.T) {
+	var mintRequest map[string]interface{}
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_MintReadToken_") {
+			json.NewDecoder(r.Body).Decode(&mintRequest)
+			fmt.Fprint(w, `{"Result":200,"Response":{"Token":"tok-123"}}`)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	token, err := acc.MintReadToken(t.Context(), ReadScope{TxIDs: []string{"0xABC1"}}, time.Minute)
+	if err != nil {
+		t.Fatalf("MintReadToken() error = %v", err)
+	}
+	if token.Token != "tok-123" || token.Scope.TxIDs[0] != "abc1" || mintRequest["TTL"] != "60" {
+		t.Errorf("Unexpected token %+v for request %v", token, mintRequest)
+	}
+
+	client, err := NewReadOnlyClient(acc.NetworkProfile(), "", *token)
+	if err != nil {
+		t.Fatalf("NewReadOnlyClient() error = %v", err)
+	}
+	outcome, err := client.WaitForOutcome(t.Context(), "abc1", time.Millisecond)
+	if err != nil || outcome["Status"] != "Executed" {
+		t.Fatalf("WaitForOutcome() = %v, %v", outcome, err)
+	}
+	if authorization != "Bearer tok-123" {
+		t.Errorf("Expected the token to be presented, got %q", authorization)
+	}
+
+	expired := *token
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	client, _ = NewReadOnlyClient(acc.NetworkProfile(), "", expired)
+	if _, err := client.GetTransaction(t.Context(), "abc1"); !errors.Is(err, ErrReadTokenExpired) {
+		t.Errorf("Expected ErrReadTokenExpired, got %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// ReceiptStatus is the client-side lifecycle state of a submitted transaction.
+type ReceiptStatus string
+
+const (
+	ReceiptPending   ReceiptStatus = "Pending"   // Submitted; no final outcome observed yet.
+	ReceiptFinalized ReceiptStatus = "Finalized" // A final outcome was observed by outcome polling.
+	ReceiptExpired   ReceiptStatus = "Expired"   // The receipt's TTL elapsed before an outcome was observed.
+	ReceiptAbandoned ReceiptStatus = "Abandoned" // The submission was given up on with AbandonTransaction.
+	ReceiptQueued    ReceiptStatus = "Queued"    // Signed while the gateway was degraded and not yet broadcast; see SetDegradationPolicy.
+)
+
+var (
+	// ErrReceiptNotFound is returned by a ReceiptStore when no receipt exists for a transaction ID.
+	ErrReceiptNotFound = newError(CodeReceiptNotFound, "receipt not found")
+
+	// ErrTransactionAbandoned is returned by outcome polling stopped by AbandonTransaction.
+	ErrTransactionAbandoned = newError(CodeTransactionAbandoned, "transaction abandoned")
+
+	// ErrTransactionExpired is returned by outcome polling stopped because the receipt's TTL elapsed.
+	ErrTransactionExpired = newError(CodeTransactionExpired, "transaction expired")
+)
+
+// Receipt records a transaction submitted by the account, so it can be tracked,
+// expired, abandoned or resubmitted after the submitting process has moved on.
+type Receipt struct {
+	Transaction  Transaction   // The signed envelope exactly as broadcast.
+	SubmittedAt  time.Time     // When the gateway accepted the transaction.
+	ExpiresAt    time.Time     // When the submission is considered stale; zero means never. See WithTTL.
+	Status       ReceiptStatus // The client-side lifecycle state.
+	FinalStatus  string        // The on-chain `Status` once finalized, e.g. "Executed".
+	PreviousTxID string        // The transaction this one resubmits, if any.
+	Label        string        // The submitting account's label, if any; see SetLabel.
+	Node         string        // The ID of the gateway node that accepted the transaction, if known; see ListNetworkNodes.
+}
+
+// Expired reports whether the receipt has a TTL that has elapsed at now.
+func (r *Receipt) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// ReceiptStore persists receipts keyed by transaction ID. Implementations must be safe
+// for concurrent use.
+type ReceiptStore interface {
+	// SaveReceipt creates or replaces the receipt for receipt.Transaction.ID.
+	SaveReceipt(receipt *Receipt) error
+	// LoadReceipt returns the receipt for txID, or ErrReceiptNotFound.
+	LoadReceipt(txID string) (*Receipt, error)
+}
+
+// ReceiptLister is implemented by receipt stores that can enumerate their receipts,
+// which PendingReceipts requires. Both stores in this package implement it.
+type ReceiptLister interface {
+	// ListReceipts returns every stored receipt.
+	ListReceipts() ([]*Receipt, error)
+}
+
+// MemoryReceiptStore is an in-process ReceiptStore. Receipts are lost when the process exits.
+type MemoryReceiptStore struct {
+	mu       sync.RWMutex
+	receipts map[string]Receipt
+}
+
+// NewMemoryReceiptStore creates an empty MemoryReceiptStore.
+//
+// Returns:
+//
+//	A pointer to a newly initialized MemoryReceiptStore.
+func NewMemoryReceiptStore() *MemoryReceiptStore {
+	return &MemoryReceiptStore{receipts: make(map[string]Receipt)}
+}
+
+// SaveReceipt stores a copy of receipt.
+func (s *MemoryReceiptStore) SaveReceipt(receipt *Receipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[helpers.HexFix(receipt.Transaction.ID)] = *receipt
+	return nil
+}
+
+// LoadReceipt returns a copy of the receipt for txID.
+func (s *MemoryReceiptStore) LoadReceipt(txID string) (*Receipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	receipt, ok := s.receipts[helpers.HexFix(txID)]
+	if !ok {
+		return nil, ErrReceiptNotFound
+	}
+	return &receipt, nil
+}
+
+// ListReceipts returns a copy of every stored receipt.
+func (s *MemoryReceiptStore) ListReceipts() ([]*Receipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	receipts := make([]*Receipt, 0, len(s.receipts))
+	for _, receipt := range s.receipts {
+		receipts = append(receipts, &receipt)
+	}
+	return receipts, nil
+}
+
+// receiptsCollection is the DocumentStore collection receipts are kept in.
+const receiptsCollection = "receipts"
+
+// DocumentReceiptStore is a ReceiptStore that keeps receipts as JSON documents in a
+// storage.DocumentStore, so they can be persisted in files or a database.
+type DocumentReceiptStore struct {
+	docs storage.DocumentStore
+}
+
+// NewDocumentReceiptStore creates a ReceiptStore backed by docs.
+//
+// Parameters:
+//   - docs: The document store to keep receipts in.
+//
+// Returns:
+//
+//	A pointer to a newly initialized DocumentReceiptStore.
+func NewDocumentReceiptStore(docs storage.DocumentStore) *DocumentReceiptStore {
+	return &DocumentReceiptStore{docs: docs}
+}
+
+// SaveReceipt stores receipt as a document keyed by its transaction ID.
+func (s *DocumentReceiptStore) SaveReceipt(receipt *Receipt) error {
+	return s.docs.Save(receiptsCollection, helpers.HexFix(receipt.Transaction.ID), receipt)
+}
+
+// LoadReceipt loads the receipt for txID.
+func (s *DocumentReceiptStore) LoadReceipt(txID string) (*Receipt, error) {
+	receipt := &Receipt{}
+	if err := s.docs.Load(receiptsCollection, helpers.HexFix(txID), receipt); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrReceiptNotFound
+		}
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// ListReceipts loads every receipt in the store.
+func (s *DocumentReceiptStore) ListReceipts() ([]*Receipt, error) {
+	ids, err := s.docs.IDs(receiptsCollection)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make([]*Receipt, 0, len(ids))
+	for _, id := range ids {
+		receipt := &Receipt{}
+		if err := s.docs.Load(receiptsCollection, id, receipt); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue // Deleted since it was listed.
+			}
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// SetReceiptStore records a receipt for every transaction the account submits from now
+// on. Passing nil disables receipt bookkeeping.
+//
+// Parameters:
+//   - store: The store to record receipts in.
+func (a *CEPAccount) SetReceiptStore(store ReceiptStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.receipts = store
+}
+
+// PendingReceipts lists the receipts of transactions still awaiting an outcome, oldest
+// submission first, so that a restarted process can resume tracking them with
+// WaitForOutcomes.
+//
+// Returns:
+//
+//	The pending receipts, or an error if no receipt store is set, the store cannot list
+//	its receipts (see ReceiptLister), or it fails.
+func (a *CEPAccount) PendingReceipts() ([]*Receipt, error) {
+	return a.receiptsWithStatus(ReceiptPending)
+}
+
+// receiptsWithStatus lists the stored receipts with the given status, oldest first.
+func (a *CEPAccount) receiptsWithStatus(status ReceiptStatus) ([]*Receipt, error) {
+	store := a.view().receipts
+	if store == nil {
+		return nil, fmt.Errorf("listing receipts requires a receipt store")
+	}
+	lister, ok := store.(ReceiptLister)
+	if !ok {
+		return nil, fmt.Errorf("receipt store %T cannot list receipts", store)
+	}
+	receipts, err := lister.ListReceipts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receipts: %w", err)
+	}
+	matching := receipts[:0]
+	for _, receipt := range receipts {
+		if receipt.Status == status {
+			matching = append(matching, receipt)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool { return matching[i].SubmittedAt.Before(matching[j].SubmittedAt) })
+	return matching, nil
+}
+
+// WithTTL bounds how long a submission is worth tracking. Once ttl has elapsed since
+// submission the receipt is marked expired and outcome polling for the transaction stops
+// with ErrTransactionExpired. The gateway has no notion of expiry, so a transaction that
+// is still queued may finalize after it has expired locally.
+//
+// Parameters:
+//   - ttl: The lifetime of the submission; zero or negative disables expiry.
+func WithTTL(ttl time.Duration) SubmitOption {
+	return func(c *submitConfig) {
+		c.ttl = ttl
+	}
+}
+
+// AbandonTransaction gives up on a submitted transaction: it marks the transaction's
+// receipt as abandoned, if a receipt store is set, and stops every outcome poll currently
+// waiting on it, which then fail with ErrTransactionAbandoned.
+//
+// Parameters:
+//   - txID: The transaction to abandon.
+//
+// Returns:
+//
+//	An error if the receipt store is set but holds no receipt for txID, or cannot be updated.
+func (a *CEPAccount) AbandonTransaction(txID string) error {
+	defer a.watchers.cancel(txID, ErrTransactionAbandoned)
+
+	store := a.view().receipts
+	if store == nil {
+		return nil
+	}
+	receipt, err := store.LoadReceipt(txID)
+	if err != nil {
+		return fmt.Errorf("failed to abandon transaction %s: %w", txID, err)
+	}
+	receipt.Status = ReceiptAbandoned
+	if err := store.SaveReceipt(receipt); err != nil {
+		return fmt.Errorf("failed to abandon transaction %s: %w", txID, err)
+	}
+	return nil
+}
+
+// Resubmit certifies the data of a previously submitted transaction again, for example
+// after it was dropped, expired or abandoned. The original data and recipient are loaded
+// from the receipt store; the envelope is rebuilt with the account's current nonce (which
+// is refreshed from the NAG first) and a fresh timestamp, and carries a PreviousTxID field
+// linking the new certificate to the old one. The new receipt records the link as well.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - previousTxID: The transaction to resubmit.
+//   - signer: Signs the new transaction; it must hold the account's key.
+//   - opts: Optional settings for the new submission, such as WithTTL.
+//
+// Returns:
+//
+//	The ID of the new transaction, or an error if no receipt store is set, the original
+//	receipt or its payload cannot be read, or the submission fails.
+func (a *CEPAccount) Resubmit(ctx context.Context, previousTxID string, signer Signer, opts ...SubmitOption) (string, error) {
+	store := a.view().receipts
+	if store == nil {
+		return "", fmt.Errorf("resubmission requires a receipt store")
+	}
+	previous, err := store.LoadReceipt(previousTxID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load receipt for %s: %w", previousTxID, err)
+	}
+	data, err := certificateData(previous.Transaction.Payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover data of %s: %w", previousTxID, err)
+	}
+
+	if !a.UpdateAccount() {
+		return "", fmt.Errorf("failed to refresh nonce: %w", a.LastErr())
+	}
+
+	opts = append([]SubmitOption{WithRecipient(previous.Transaction.To)}, opts...)
+	opts = append(opts, withPreviousTxID(previous.Transaction.ID))
+	return a.submitCertificate(ctx, data, signer, opts...)
+}
+
+// certificateData recovers the original certificate data from a hex-encoded
+// CP_CERTIFICATE payload envelope.
+func certificateData(payload string) (string, error) {
+	action, data, err := Payload(payload).Decode()
+	if err != nil {
+		return "", err
+	}
+	if action != certificateAction {
+		return "", fmt.Errorf("unexpected payload action %q", action)
+	}
+	return data, nil
+}
+
+// recordReceipt stores a receipt for a transaction that was just accepted by the gateway.
+// Failures are reported but do not fail the submission, which has already happened.
+func (a *CEPAccount) recordReceipt(tx *Transaction, ttl time.Duration, previousTxID string) {
+	v := a.view()
+	store := v.receipts
+	if store == nil {
+		return
+	}
+	node, _ := a.nodes.lookup(v.NetworkNode, v.NAGURL)
+	receipt := &Receipt{
+		Transaction:  *tx,
+		SubmittedAt:  time.Now(),
+		Status:       ReceiptPending,
+		PreviousTxID: previousTxID,
+		Label:        v.label,
+		Node:         node.ID,
+	}
+	if ttl > 0 {
+		receipt.ExpiresAt = receipt.SubmittedAt.Add(ttl)
+	}
+	if err := store.SaveReceipt(receipt); err != nil {
+		a.logf(LogWarn, "recordReceipt: failed to save receipt for %s: %v\n", tx.ID, err)
+	}
+}
+
+// loadReceipt returns the stored receipt for txID, or nil if there is none.
+func (a *CEPAccount) loadReceipt(txID string) *Receipt {
+	store := a.view().receipts
+	if store == nil {
+		return nil
+	}
+	receipt, err := store.LoadReceipt(txID)
+	if err != nil {
+		return nil
+	}
+	return receipt
+}
+
+// updateReceipt applies update to the stored receipt for txID, if there is one.
+func (a *CEPAccount) updateReceipt(txID string, update func(*Receipt)) {
+	store := a.view().receipts
+	receipt := a.loadReceipt(txID)
+	if store == nil || receipt == nil {
+		return
+	}
+	update(receipt)
+	if err := store.SaveReceipt(receipt); err != nil {
+		a.logf(LogWarn, "updateReceipt: failed to save receipt for %s: %v\n", txID, err)
+	}
+}
@@ -0,0 +1,228 @@
+package circular
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// newReceiptTestServer accepts every transaction and reports every lookup as pending.
+func newReceiptTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Pending"}}`)
+	}))
+}
+
+func TestReceiptRecordedWithTTL(t *testing.T) {
+	server := newReceiptTestServer()
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+
+	acc.SubmitCertificate("data", testPrivateKey, WithTTL(20*time.Millisecond))
+	if acc.GetLastError() != "" {
+		t.Fatalf("Unexpected error: %s", acc.GetLastError())
+	}
+
+	receipt, err := store.LoadReceipt(acc.LatestTxID)
+	if err != nil {
+		t.Fatalf("LoadReceipt() error = %v", err)
+	}
+	if receipt.Status != ReceiptPending || receipt.ExpiresAt.IsZero() {
+		t.Errorf("Unexpected receipt: %+v", receipt)
+	}
+
+	stats := &OutcomeStats{}
+	ctx, stop := acc.watchOutcome(context.Background(), acc.LatestTxID)
+	defer stop()
+	if _, err := acc.pollOutcome(ctx, acc.LatestTxID, time.Millisecond, stats); !errors.Is(err, ErrTransactionExpired) {
+		t.Fatalf("Expected ErrTransactionExpired, got %v", err)
+	}
+	if !receipt.Expired(time.Now()) {
+		t.Error("Expected the receipt to report itself expired")
+	}
+}
+
+func TestAbandonTransaction(t *testing.T) {
+	server := newReceiptTestServer()
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+
+	acc.SubmitCertificate("data", testPrivateKey)
+	txID := acc.LatestTxID
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, stop := acc.watchOutcome(context.Background(), txID)
+		defer stop()
+		_, err := acc.pollOutcome(ctx, txID, time.Millisecond, &OutcomeStats{})
+		done <- err
+	}()
+
+	// Wait until the poll is registered before abandoning it.
+	for deadline := time.Now().Add(time.Second); ; {
+		acc.watchers.mu.Lock()
+		n := len(acc.watchers.entries)
+		acc.watchers.mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := acc.AbandonTransaction(txID); err != nil {
+		t.Fatalf("AbandonTransaction() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTransactionAbandoned) {
+			t.Errorf("Expected ErrTransactionAbandoned, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the poll to stop after abandonment")
+	}
+
+	receipt, _ := store.LoadReceipt(txID)
+	if receipt.Status != ReceiptAbandoned {
+		t.Errorf("Expected receipt status %s, got %s", ReceiptAbandoned, receipt.Status)
+	}
+	if acc.GetTransactionOutcome(txID, 1, 1) != nil || !strings.Contains(acc.GetLastError(), "abandoned") {
+		t.Errorf("Expected polling an abandoned transaction to fail immediately, got %q", acc.GetLastError())
+	}
+
+	if err := acc.AbandonTransaction("0x1234"); !errors.Is(err, ErrReceiptNotFound) {
+		t.Errorf("Expected ErrReceiptNotFound for an unknown transaction, got %v", err)
+	}
+}
+
+func TestResubmit(t *testing.T) {
+	var submitted []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":41}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			var tx map[string]string
+			json.NewDecoder(r.Body).Decode(&tx)
+			submitted = append(submitted, tx)
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	if _, err := acc.Resubmit(context.Background(), "0x1234", signer); !errors.Is(err, ErrReceiptNotFound) {
+		t.Fatalf("Expected ErrReceiptNotFound, got %v", err)
+	}
+
+	acc.SubmitCertificate(`{"doc":1}`, testPrivateKey, WithRecipient("0x0123"))
+	original := acc.LatestTxID
+
+	newID, err := acc.Resubmit(context.Background(), original, signer)
+	if err != nil {
+		t.Fatalf("Resubmit() error = %v", err)
+	}
+	if newID == original {
+		t.Fatal("Expected a new transaction ID")
+	}
+
+	resubmitted := submitted[1]
+	if resubmitted["Nonce"] != "42" {
+		t.Errorf("Expected the refreshed nonce 42, got %s", resubmitted["Nonce"])
+	}
+	if resubmitted["To"] != submitted[0]["To"] {
+		t.Errorf("Expected the original recipient %s, got %s", submitted[0]["To"], resubmitted["To"])
+	}
+	data, err := certificateData(resubmitted["Payload"])
+	if err != nil || data != `{"doc":1}` {
+		t.Errorf("certificateData() = %q, %v; want the original data", data, err)
+	}
+	envelope, _ := hex.DecodeString(resubmitted["Payload"])
+	if !strings.Contains(string(envelope), `"PreviousTxID":"`+original+`"`) {
+		t.Errorf("Expected the envelope to link to %s, got %s", original, envelope)
+	}
+
+	receipt, err := store.LoadReceipt(newID)
+	if err != nil || receipt.PreviousTxID != original {
+		t.Errorf("Expected the new receipt to link to %s, got %+v (%v)", original, receipt, err)
+	}
+}
+
+func TestDocumentReceiptStore(t *testing.T) {
+	kv, err := storage.NewFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+	store := NewDocumentReceiptStore(storage.NewDocumentStore(kv))
+
+	receipt := &Receipt{Transaction: Transaction{ID: "0xABCD", Nonce: "7"}, SubmittedAt: time.Now(), Status: ReceiptPending}
+	if err := store.SaveReceipt(receipt); err != nil {
+		t.Fatalf("SaveReceipt() error = %v", err)
+	}
+	loaded, err := store.LoadReceipt("abcd")
+	if err != nil {
+		t.Fatalf("LoadReceipt() error = %v", err)
+	}
+	if loaded.Transaction.Nonce != "7" || loaded.Status != ReceiptPending || !loaded.SubmittedAt.Equal(receipt.SubmittedAt) {
+		t.Errorf("Unexpected receipt: %+v", loaded)
+	}
+	if _, err := store.LoadReceipt("0x1234"); !errors.Is(err, ErrReceiptNotFound) {
+		t.Errorf("Expected ErrReceiptNotFound, got %v", err)
+	}
+}
+
+func TestPendingReceipts(t *testing.T) {
+	now := time.Now()
+	for name, store := range map[string]ReceiptStore{
+		"memory":   NewMemoryReceiptStore(),
+		"document": NewDocumentReceiptStore(storage.NewDocumentStore(storage.NewMemory())),
+	} {
+		t.Run(name, func(t *testing.T) {
+			store.SaveReceipt(&Receipt{Transaction: Transaction{ID: "02"}, SubmittedAt: now, Status: ReceiptPending})
+			store.SaveReceipt(&Receipt{Transaction: Transaction{ID: "01"}, SubmittedAt: now.Add(-time.Minute), Status: ReceiptPending})
+			store.SaveReceipt(&Receipt{Transaction: Transaction{ID: "03"}, SubmittedAt: now, Status: ReceiptFinalized})
+
+			acc := NewCEPAccount()
+			acc.SetReceiptStore(store)
+			pending, err := acc.PendingReceipts()
+			if err != nil {
+				t.Fatalf("PendingReceipts() error = %v", err)
+			}
+			if len(pending) != 2 || pending[0].Transaction.ID != "01" || pending[1].Transaction.ID != "02" {
+				t.Errorf("Expected the pending receipts oldest first, got %+v", pending)
+			}
+		})
+	}
+
+	if _, err := NewCEPAccount().PendingReceipts(); err == nil {
+		t.Error("Expected an error without a receipt store")
+	}
+}
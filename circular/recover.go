@@ -0,0 +1,152 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// recoverDepth is the number of most recent transactions of an address that
+// RecoverAccountState inspects.
+const recoverDepth = 4 * listPageSize
+
+// RecoveredState is the account state RecoverAccountState rebuilt from the chain.
+type RecoveredState struct {
+	Address    string        // The recovered address.
+	Blockchain string        // The chain the state belongs to.
+	Nonce      int64         // The nonce the next transaction will use, according to the gateway.
+	LatestTxID string        // The most recent transaction sent from the address on the chain; empty if none was found.
+	Pending    []Transaction // The transactions sent from the address that are still "Pending", most recent first.
+	Inspected  int           // The number of transactions of the address inspected.
+}
+
+// RecoverAccountState rebuilds the account's local state for address from chain queries
+// alone, for a worker that lost its state, e.g. after a crash without a nonce store or
+// receipt store. The account is opened with address, and on its Blockchain:
+//   - the nonce is set to the one the gateway expects next, as UpdateAccount does;
+//   - the latest transaction is set to the most recent one sent from address;
+//   - transactions sent from address that are still "Pending" are reported and, with a
+//     receipt store set, get receipts, so that PendingReceipts and outcome polling
+//     track them again.
+//
+// Only the most recent 200 transactions of address are inspected, so older pending
+// transactions are not found, and a transaction accepted moments before the call may
+// not be listed yet. State the chain does not hold is not recovered: transactions queued
+// locally and never broadcast, nonce reservations, deduplication records, and the
+// TTLs and resubmission links of receipts. Receipts already stored are left as they
+// are. Recovery is idempotent and may be repeated, e.g. once outstanding submissions
+// from another process have settled.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the requests.
+//   - address: The account's address.
+//
+// Returns:
+//
+//	The recovered state, or an error if address is invalid, the account has no
+//	network, or a query fails, in which case the account keeps its previous nonce and
+//	latest transaction.
+func (a *CEPAccount) RecoverAccountState(ctx context.Context, address string) (*RecoveredState, error) {
+	if err := validateAddress(address); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	ctx = ensureRequestID(ctx)
+	a.Open(address)
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+
+	v := a.view()
+	if v.NAGURL == "" {
+		return nil, ErrNetworkNotSet
+	}
+	nonce, err := a.fetchNonceOf(ctx, address, v.Blockchain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover nonce: %w", err)
+	}
+	state := &RecoveredState{Address: v.Address, Blockchain: v.Blockchain, Nonce: nonce}
+	var latestNonce int64
+	for entry, err := range a.Transactions(ctx, address) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to recover transactions: %w", err)
+		}
+		state.Inspected++
+		tx := listedTransaction(entry)
+		if sameHex(tx.From, address) && (tx.Blockchain == "" || sameHex(tx.Blockchain, v.Blockchain)) {
+			if state.LatestTxID == "" {
+				state.LatestTxID = tx.ID
+				latestNonce, _ = strconv.ParseInt(tx.Nonce, 10, 64)
+			}
+			if status, _ := jsonx.GetString(entry, "Status"); status == "Pending" {
+				state.Pending = append(state.Pending, tx)
+			}
+		}
+		if state.Inspected >= recoverDepth {
+			break
+		}
+	}
+
+	if err := a.setNonce(v, nonce, "recovery"); err != nil {
+		return nil, err
+	}
+	if state.LatestTxID != "" {
+		a.mu.Lock()
+		current := a.sameSubject(v)
+		if current {
+			a.LatestTxID = state.LatestTxID
+		}
+		a.mu.Unlock()
+		if current {
+			a.recordSubmission(state.LatestTxID, v.Blockchain, latestNonce)
+		}
+	}
+	for _, tx := range state.Pending {
+		a.recoverReceipt(v, tx)
+	}
+	a.logf(LogInfo, "RecoverAccountState: %s recovered with nonce %d, latest transaction %q and %d pending\n", address, nonce, state.LatestTxID, len(state.Pending))
+	return state, nil
+}
+
+// recoverReceipt stores a pending receipt for tx, found by RecoverAccountState, unless
+// the receipt store already holds one.
+func (a *CEPAccount) recoverReceipt(v accountView, tx Transaction) {
+	if v.receipts == nil || a.loadReceipt(tx.ID) != nil {
+		return
+	}
+	submittedAt, err := time.ParseInLocation(helpers.TimestampLayout, tx.Timestamp, time.UTC)
+	if err != nil {
+		submittedAt = time.Now()
+	}
+	receipt := &Receipt{Transaction: tx, SubmittedAt: submittedAt, Status: ReceiptPending, Label: v.label}
+	if err := v.receipts.SaveReceipt(receipt); err != nil {
+		a.logf(LogWarn, "RecoverAccountState: failed to save receipt for %s: %v\n", tx.ID, err)
+	}
+}
+
+// listedTransaction extracts the envelope fields of a transaction as listed by the
+// gateway, accepting its nonce as either a string or a number.
+func listedTransaction(entry map[string]interface{}) Transaction {
+	field := func(name string) string {
+		s, _ := jsonx.GetString(entry, name)
+		return s
+	}
+	tx := Transaction{
+		Blockchain: field("Blockchain"),
+		From:       field("From"),
+		ID:         field("ID"),
+		Nonce:      field("Nonce"),
+		Payload:    field("Payload"),
+		Signature:  field("Signature"),
+		Timestamp:  field("Timestamp"),
+		To:         field("To"),
+		Type:       field("Type"),
+		Version:    field("Version"),
+	}
+	if nonce, ok := jsonx.GetInt(entry, "Nonce"); ok {
+		tx.Nonce = strconv.FormatInt(nonce, 10)
+	}
+	return tx
+}
@@ -0,0 +1,82 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoverAccountState(t *testing.T) {
+	const address = "aa01"
+	listing := []map[string]interface{}{
+		{"ID": "f003", "From": "bb02", "To": address, "Nonce": "9", "Status": "Pending", "Blockchain": "c001"},
+		{"ID": "f002", "From": address, "To": address, "Nonce": 7, "Status": "Pending", "Blockchain": "c001", "Timestamp": "2026:10:01-12:00:00"},
+		{"ID": "f001", "From": address, "To": address, "Nonce": "6", "Status": "Executed", "Blockchain": "c001"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":7}}`)
+		case strings.Contains(r.URL.String(), "Circular_GetTransactionbyAddress_"):
+			var query map[string]string
+			json.NewDecoder(r.Body).Decode(&query)
+			page := listing
+			if query["Start"] != "0" {
+				page = nil
+			}
+			body, _ := json.Marshal(map[string]interface{}{"Result": 200, "Response": page})
+			w.Write(body)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.SetBlockchain("c001")
+	acc.SetLogLevel(LogSilent)
+	acc.SetEventJournal(16, nil)
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+
+	state, err := acc.RecoverAccountState(t.Context(), "0x"+address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Nonce != 8 || state.LatestTxID != "f002" || state.Inspected != 3 {
+		t.Errorf("Unexpected recovered state %+v", state)
+	}
+	if len(state.Pending) != 1 || state.Pending[0].ID != "f002" || state.Pending[0].Nonce != "7" {
+		t.Errorf("Expected only the pending transaction sent by the account, got %+v", state.Pending)
+	}
+	if current := acc.State(); current.Nonce != 8 || current.LatestTxID != "f002" || !sameHex(current.Address, address) {
+		t.Errorf("Expected the recovered state to be applied, got %+v", current)
+	}
+	events, _ := acc.Events()
+	if replayed := ReplayEvents(events); replayed.Nonce != 8 || replayed.LatestTxID != "f002" {
+		t.Errorf("Expected the recovery to be journaled, got %+v", replayed)
+	}
+	pending, err := acc.PendingReceipts()
+	if err != nil || len(pending) != 1 || pending[0].Transaction.ID != "f002" || pending[0].SubmittedAt.Year() != 2026 {
+		t.Errorf("Expected a receipt for the pending transaction, got %+v (%v)", pending, err)
+	}
+
+	// Recovering again leaves the receipt as it is.
+	acc.updateReceipt("f002", func(r *Receipt) { r.PreviousTxID = "f000" })
+	if _, err := acc.RecoverAccountState(t.Context(), address); err != nil {
+		t.Fatal(err)
+	}
+	if receipt := acc.loadReceipt("f002"); receipt == nil || receipt.PreviousTxID != "f000" {
+		t.Errorf("Expected the stored receipt to be kept, got %+v", receipt)
+	}
+
+	unconfigured := NewCEPAccount()
+	unconfigured.Close()
+	if _, err := unconfigured.RecoverAccountState(t.Context(), address); err != ErrNetworkNotSet {
+		t.Errorf("Expected ErrNetworkNotSet, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ReferenceType is the value of the `type` field of a certified payload reference.
+const ReferenceType = "CP_REFERENCE"
+
+// PayloadReference certifies a document kept in external storage, such as an object
+// store, by its URI, size and SHA-256 digest instead of embedding its contents. Large
+// regulated documents are certified this way: the chain holds a small, fixed-size
+// record, and the document itself stays under the storage's retention controls.
+type PayloadReference struct {
+	Type   string `json:"type"`   // Always ReferenceType.
+	URI    string `json:"uri"`    // Where the document is stored, e.g. "s3://bucket/key".
+	Size   int64  `json:"size"`   // Size of the document in bytes.
+	SHA256 string `json:"sha256"` // Lowercase hex SHA-256 digest of the document.
+}
+
+// ReferenceReport is the result of fetching a referenced document and comparing it
+// with the reference certified on chain.
+type ReferenceReport struct {
+	Reference    PayloadReference     // The reference recorded on chain.
+	ActualSize   int64                // Size of the fetched document in bytes.
+	ActualSHA256 string               // Hex SHA-256 digest of the fetched document.
+	Mismatches   []SubmissionMismatch // "Size" and "SHA256" differences; empty if the document matches.
+}
+
+// OK reports whether the fetched document is the one the reference certifies.
+func (r *ReferenceReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// NewPayloadReference hashes the document read from r and returns a reference to it
+// at uri. The document is not uploaded: callers store it at uri themselves.
+//
+// Parameters:
+//   - uri: Where the document is stored; it must have a scheme, e.g. "s3://bucket/key".
+//   - r: The document's contents; it is read to the end.
+//
+// Returns:
+//
+//	The reference, or an error if uri has no scheme or the document cannot be read.
+func NewPayloadReference(uri string, r io.Reader) (*PayloadReference, error) {
+	if _, err := referenceScheme(uri); err != nil {
+		return nil, err
+	}
+	entry, err := digestEntry(uri, r)
+	if err != nil {
+		return nil, err
+	}
+	return &PayloadReference{Type: ReferenceType, URI: uri, Size: entry.Size, SHA256: entry.SHA256}, nil
+}
+
+// JSON returns the serialized reference, the data to pass to SubmitCertificate.
+func (ref *PayloadReference) JSON() (string, error) {
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload reference: %w", err)
+	}
+	return string(data), nil
+}
+
+// Certificate wraps the serialized reference in a CCertificate.
+func (ref *PayloadReference) Certificate() (*CCertificate, error) {
+	data, err := ref.JSON()
+	if err != nil {
+		return nil, err
+	}
+	cert := NewCCertificate()
+	cert.SetData(data)
+	return cert, nil
+}
+
+// ParsePayloadReference decodes a payload reference from certificate data.
+//
+// Parameters:
+//   - data: The JSON reference, as returned by CCertificate.GetData.
+//
+// Returns:
+//
+//	The reference, or an error if data is not a payload reference.
+func ParsePayloadReference(data string) (*PayloadReference, error) {
+	var ref PayloadReference
+	if err := json.Unmarshal([]byte(data), &ref); err != nil {
+		return nil, fmt.Errorf("failed to decode payload reference: %w", err)
+	}
+	if ref.Type != ReferenceType {
+		return nil, fmt.Errorf("unexpected payload reference type: %q", ref.Type)
+	}
+	if ref.URI == "" || ref.SHA256 == "" {
+		return nil, fmt.Errorf("payload reference has no URI or digest")
+	}
+	ref.SHA256 = helpers.HexFix(ref.SHA256)
+	return &ref, nil
+}
+
+// Fetcher retrieves referenced documents by URI. Implementations for object stores,
+// such as S3 or GCS, wrap the store's client; Fetchers combines them by URI scheme.
+type Fetcher interface {
+	Fetch(ctx context.Context, uri string) (io.ReadCloser, error)
+}
+
+// FetcherFunc adapts a function to the Fetcher interface.
+type FetcherFunc func(ctx context.Context, uri string) (io.ReadCloser, error)
+
+// Fetch calls f(ctx, uri).
+func (f FetcherFunc) Fetch(ctx context.Context, uri string) (io.ReadCloser, error) {
+	return f(ctx, uri)
+}
+
+// Fetchers dispatches each URI to the Fetcher registered for its scheme, such as "s3".
+type Fetchers map[string]Fetcher
+
+// DefaultFetchers returns Fetchers for the schemes the SDK supports without further
+// dependencies: "http" and "https", fetched with client, and "file", read from the
+// local filesystem. Add fetchers for other schemes to the returned map.
+//
+// Parameters:
+//   - client: The HTTP client; nil uses http.DefaultClient.
+//
+// Returns:
+//
+//	The fetchers, keyed by scheme.
+func DefaultFetchers(client *http.Client) Fetchers {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	web := httpFetcher{client: client}
+	return Fetchers{"http": web, "https": web, "file": FetcherFunc(fetchFile)}
+}
+
+// Fetch fetches uri with the Fetcher registered for its scheme.
+func (f Fetchers) Fetch(ctx context.Context, uri string) (io.ReadCloser, error) {
+	scheme, err := referenceScheme(uri)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, ok := f[scheme]
+	if !ok {
+		return nil, fmt.Errorf("no fetcher for %q URIs", scheme)
+	}
+	return fetcher.Fetch(ctx, uri)
+}
+
+// VerifyReference checks that a finalized transaction certifies a payload reference
+// and that the document it points to is unchanged: the document is fetched with
+// fetcher and its size and SHA-256 digest compared with those certified.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the fetch.
+//   - outcome: The transaction details, as returned by GetTransactionOutcome.
+//   - fetcher: Retrieves the document, e.g. DefaultFetchers(nil).
+//
+// Returns:
+//
+//	A report describing whether the document matches, or an error if the outcome does
+//	not certify a payload reference or the document cannot be fetched.
+func VerifyReference(ctx context.Context, outcome map[string]interface{}, fetcher Fetcher) (*ReferenceReport, error) {
+	hexPayload, ok := outcome["Payload"].(string)
+	if !ok {
+		return nil, fmt.Errorf("outcome has no payload")
+	}
+	_, data, err := Payload(hexPayload).Decode()
+	if err != nil {
+		return nil, err
+	}
+	ref, err := ParsePayloadReference(data)
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetcher.Fetch(ctx, ref.URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", ref.URI, err)
+	}
+	defer body.Close()
+	actual, err := digestEntry(ref.URI, body)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReferenceReport{Reference: *ref, ActualSize: actual.Size, ActualSHA256: actual.SHA256}
+	if actual.Size != ref.Size {
+		report.Mismatches = append(report.Mismatches, SubmissionMismatch{Field: "Size", Expected: fmt.Sprint(ref.Size), Actual: fmt.Sprint(actual.Size)})
+	}
+	if actual.SHA256 != ref.SHA256 {
+		report.Mismatches = append(report.Mismatches, SubmissionMismatch{Field: "SHA256", Expected: ref.SHA256, Actual: actual.SHA256})
+	}
+	return report, nil
+}
+
+// httpFetcher fetches http and https URIs.
+type httpFetcher struct {
+	client *http.Client
+}
+
+func (f httpFetcher) Fetch(ctx context.Context, uri string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// fetchFile opens the local file a file URI names.
+func fetchFile(ctx context.Context, uri string) (io.ReadCloser, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("file URI has a remote host: %q", u.Host)
+	}
+	return os.Open(u.Path)
+}
+
+// referenceScheme returns the lowercase scheme of uri.
+func referenceScheme(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference URI: %w", err)
+	}
+	if u.Scheme == "" {
+		return "", fmt.Errorf("reference URI %q has no scheme", uri)
+	}
+	return strings.ToLower(u.Scheme), nil
+}
@@ -0,0 +1,81 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyReference(t *testing.T) {
+	document := "quarterly filing"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/filings/q3.pdf" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, document)
+	}))
+	defer server.Close()
+
+	ref, err := NewPayloadReference(server.URL+"/filings/q3.pdf", strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("NewPayloadReference failed: %v", err)
+	}
+	cert, _ := ref.Certificate()
+	outcome := outcomeFor(certificateAction, cert.GetData())
+	fetchers := DefaultFetchers(server.Client())
+
+	report, err := VerifyReference(t.Context(), outcome, fetchers)
+	if err != nil || !report.OK() || report.ActualSize != int64(len(document)) {
+		t.Fatalf("Expected the document to match, got %+v (err %v)", report, err)
+	}
+
+	document = "quarterly filing, amended"
+	report, err = VerifyReference(t.Context(), outcome, fetchers)
+	if err != nil {
+		t.Fatalf("VerifyReference failed: %v", err)
+	}
+	if report.OK() || len(report.Mismatches) != 2 || report.Mismatches[0].Field != "Size" || report.Mismatches[1].Field != "SHA256" {
+		t.Errorf("Expected size and digest mismatches, got %+v", report.Mismatches)
+	}
+
+	missing := *ref
+	missing.URI = server.URL + "/filings/q4.pdf"
+	cert, _ = missing.Certificate()
+	if _, err := VerifyReference(t.Context(), outcomeFor(certificateAction, cert.GetData()), fetchers); err == nil {
+		t.Error("Expected an error for a document that cannot be fetched")
+	}
+	if _, err := VerifyReference(t.Context(), outcomeFor(certificateAction, document), fetchers); err == nil {
+		t.Error("Expected an error for an outcome without a payload reference")
+	}
+}
+
+func TestReferenceFetchers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contract.txt")
+	os.WriteFile(path, []byte("terms"), 0o644)
+
+	fetchers := DefaultFetchers(nil)
+	fetchers["s3"] = FetcherFunc(func(ctx context.Context, uri string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("terms")), nil
+	})
+	for _, uri := range []string{"file://" + filepath.ToSlash(path), "s3://bucket/contract.txt"} {
+		ref, _ := NewPayloadReference(uri, strings.NewReader("terms"))
+		cert, _ := ref.Certificate()
+		if report, err := VerifyReference(t.Context(), outcomeFor(certificateAction, cert.GetData()), fetchers); err != nil || !report.OK() {
+			t.Errorf("Expected %s to verify, got %+v (err %v)", uri, report, err)
+		}
+	}
+
+	if _, err := fetchers.Fetch(t.Context(), "gs://bucket/contract.txt"); err == nil {
+		t.Error("Expected an error for a scheme without a fetcher")
+	}
+	if _, err := NewPayloadReference("contract.txt", strings.NewReader("terms")); err == nil {
+		t.Error("Expected an error for a URI without a scheme")
+	}
+}
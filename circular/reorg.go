@@ -0,0 +1,331 @@
+package circular
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// DefaultRecheckDepth is the number of blocks WatchConfirmations waits for after the
+// block recording a transaction before re-checking it, unless OutcomeRecheck sets one.
+const DefaultRecheckDepth = 6
+
+// OutcomeDifference describes one field in which two records of a transaction differ.
+type OutcomeDifference struct {
+	Field string // The field, as a jsonx path such as "Status" or "Payload".
+	Old   string // The field's value in the earlier record, as JSON; empty if it had none.
+	New   string // The field's value in the later record, as JSON; empty if it has none.
+}
+
+// OutcomeDiff is the result of comparing two records of the same transaction; see
+// CompareOutcomes.
+type OutcomeDiff struct {
+	TxID        string                 // The transaction, from the earlier record's ID.
+	Disappeared bool                   // Whether the later record is missing, e.g. because a reorganization dropped the transaction.
+	Differences []OutcomeDifference    // Every field that differs, ordered by field; empty if the transaction disappeared.
+	Old         map[string]interface{} // The earlier record.
+	New         map[string]interface{} // The later record; nil if the transaction disappeared.
+}
+
+// Changed reports whether the later record differs from the earlier one or is missing.
+func (d *OutcomeDiff) Changed() bool {
+	return d.Disappeared || len(d.Differences) > 0
+}
+
+// CompareOutcomes compares two records of a transaction, such as the outcome first
+// obtained for it and the record the gateway returns later. Nested objects, such as a
+// decoded payload, are compared field by field; other values are equal if they encode
+// to the same JSON.
+//
+// Parameters:
+//   - old: The earlier record, as returned by WaitForOutcome or FindTransaction.
+//   - current: The later record; nil or empty if the gateway no longer knows the
+//     transaction.
+//
+// Returns:
+//
+//	The differences between the records.
+func CompareOutcomes(old, current map[string]interface{}) *OutcomeDiff {
+	diff := &OutcomeDiff{Old: old, New: current, Disappeared: len(current) == 0}
+	diff.TxID, _ = jsonx.GetString(old, "ID")
+	if diff.Disappeared {
+		diff.New = nil
+		return diff
+	}
+	diff.Differences = compareFields("", old, current, nil)
+	return diff
+}
+
+// compareFields appends the differences between the fields of old and current to diffs,
+// naming each field below prefix.
+func compareFields(prefix string, old, current map[string]interface{}, diffs []OutcomeDifference) []OutcomeDifference {
+	keys := slices.Collect(maps.Keys(old))
+	for key := range current {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		before, inOld := old[key]
+		after, inCurrent := current[key]
+		beforeMap, ok1 := before.(map[string]interface{})
+		afterMap, ok2 := after.(map[string]interface{})
+		if ok1 && ok2 {
+			diffs = compareFields(field, beforeMap, afterMap, diffs)
+			continue
+		}
+		if encoded, encodedAfter := encodeField(before, inOld), encodeField(after, inCurrent); encoded != encodedAfter {
+			diffs = append(diffs, OutcomeDifference{Field: field, Old: encoded, New: encodedAfter})
+		}
+	}
+	return diffs
+}
+
+// encodeField returns value as JSON, or an empty string if the field is absent.
+func encodeField(value interface{}, present bool) string {
+	if !present {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// OutcomeRecheck configures the re-checks of confirmed transactions made by
+// WatchConfirmations.
+type OutcomeRecheck struct {
+	Depth    int64             // Blocks that must follow the block recording a transaction before it is re-checked; zero means DefaultRecheckDepth.
+	Interval time.Duration     // How often the watch looks for transactions due a re-check; zero means the account's IntervalSec.
+	OnChange func(OutcomeDiff) // Called from the watch's goroutine for each transaction that changed or disappeared; may be nil.
+}
+
+// WatchConfirmations re-checks the transactions confirmed through the account after the
+// chain has grown past them, instead of trusting their first confirmation: a
+// reorganization may drop a transaction, or record it differently. Every transaction
+// whose final outcome the account obtains from the gateway while the watch runs is
+// looked up again, bypassing the outcome cache, once the chain holds the block Depth
+// blocks after the one recording it, and compared with its first record using
+// CompareOutcomes. When the record changed or disappeared:
+//   - the change is logged and journaled as an EventReorg;
+//   - the outcome cache holds the new record, or forgets a transaction that
+//     disappeared or is pending again;
+//   - the transaction's receipt, if any, takes the new final status, or is pending
+//     again;
+//   - OnChange is called.
+//
+// Each transaction is re-checked once. Outcomes served from the outcome cache and
+// records without a BlockID are not re-checked. Failed lookups are logged and retried
+// at the next interval; transactions still awaiting a re-check when ctx is done are
+// dropped. Only one watch may run at a time.
+//
+// Parameters:
+//   - ctx: Stops the watch when done.
+//   - recheck: The depth, interval and callback of the re-checks.
+//
+// Returns:
+//
+//	An error if the interval is not positive or a watch is already running, in which
+//	case nothing is watched.
+func (a *CEPAccount) WatchConfirmations(ctx context.Context, recheck OutcomeRecheck) error {
+	interval := recheck.Interval
+	if interval == 0 {
+		interval = time.Duration(a.view().IntervalSec) * time.Second
+	}
+	if interval <= 0 {
+		return fmt.Errorf("recheck interval must be positive, got %s", interval)
+	}
+	if recheck.Depth <= 0 {
+		recheck.Depth = DefaultRecheckDepth
+	}
+	if !a.rechecks.start() {
+		return errors.New("a confirmation watch is already running")
+	}
+
+	go func() {
+		defer a.rechecks.stop()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var reached int64 // The highest block known to exist.
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			reached = a.recheckDue(ensureRequestID(ctx), recheck, reached)
+		}
+	}()
+	return nil
+}
+
+// recheckDue re-checks the transactions awaiting a re-check whose depth the chain has
+// reached, given that it holds block reached, in the order of their blocks.
+//
+// Returns:
+//
+//	The highest block now known to exist.
+func (a *CEPAccount) recheckDue(ctx context.Context, recheck OutcomeRecheck, reached int64) int64 {
+	for _, entry := range a.rechecks.snapshot() {
+		if due := entry.block + recheck.Depth; due > reached {
+			if _, err := a.GetBlock(ctx, due); err != nil {
+				// Later transactions are due at the same block or later, so none of
+				// them is due either.
+				a.logf(LogDebug, "WatchConfirmations: block %d not available yet: %v\n", due, err)
+				return reached
+			}
+			reached = due
+		}
+		current, err := a.findTransaction(ctx, entry.txID)
+		if errors.Is(err, ErrTransactionNotFound) {
+			current, err = nil, nil
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				a.logf(LogWarn, "WatchConfirmations: failed to re-check %s: %v\n", entry.txID, err)
+			}
+			continue
+		}
+		a.rechecks.remove(entry.txID)
+		var old map[string]interface{}
+		if err := json.Unmarshal(entry.outcome, &old); err != nil {
+			continue
+		}
+		diff := CompareOutcomes(old, current)
+		if diff.TxID == "" {
+			diff.TxID = entry.txID
+		}
+		if diff.Changed() {
+			a.reportReorg(diff, recheck.OnChange)
+		}
+	}
+	return reached
+}
+
+// reportReorg brings the account's record of a re-checked transaction in line with the
+// chain, and reports the change.
+func (a *CEPAccount) reportReorg(diff *OutcomeDiff, onChange func(OutcomeDiff)) {
+	status, _ := jsonx.GetString(diff.New, "Status")
+	if diff.Disappeared {
+		a.logf(LogWarn, "WatchConfirmations: confirmed transaction %s disappeared from the chain\n", diff.TxID)
+	} else {
+		a.logf(LogWarn, "WatchConfirmations: the record of confirmed transaction %s changed in %d fields\n", diff.TxID, len(diff.Differences))
+	}
+	final := !diff.Disappeared && status != "Pending"
+	if final {
+		a.cacheOutcome(diff.TxID, diff.New)
+	} else {
+		a.forgetOutcome(diff.TxID)
+	}
+	a.updateReceipt(diff.TxID, func(r *Receipt) {
+		if final {
+			r.Status, r.FinalStatus = ReceiptFinalized, status
+		} else {
+			r.Status, r.FinalStatus = ReceiptPending, ""
+		}
+	})
+	a.recordEvent(AccountEvent{Type: EventReorg, TxID: helpers.HexFix(diff.TxID), Status: status})
+	if onChange != nil {
+		onChange(*diff)
+	}
+}
+
+// outcomeBlock returns the number of the block recording outcome, which gateways give
+// as either a number or a string.
+func outcomeBlock(outcome map[string]interface{}) (int64, bool) {
+	if block, ok := jsonx.GetInt(outcome, "BlockID"); ok {
+		return block, true
+	}
+	s, _ := jsonx.GetString(outcome, "BlockID")
+	block, err := strconv.ParseInt(s, 10, 64)
+	return block, err == nil
+}
+
+// pendingRecheck is a confirmed transaction awaiting its re-check.
+type pendingRecheck struct {
+	txID    string
+	block   int64           // The block that recorded the transaction.
+	outcome json.RawMessage // The record first obtained, encoded so that callers never share it.
+}
+
+// recheckQueue holds the confirmed transactions awaiting a re-check while a
+// WatchConfirmations watch runs.
+type recheckQueue struct {
+	mu       sync.Mutex
+	watching bool
+	pending  []pendingRecheck // In the order of their blocks.
+}
+
+// start marks a watch as running, unless one already is.
+func (q *recheckQueue) start() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.watching {
+		return false
+	}
+	q.watching = true
+	return true
+}
+
+// stop marks the watch as stopped and drops the transactions awaiting a re-check.
+func (q *recheckQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.watching, q.pending = false, nil
+}
+
+// add queues the final outcome of txID for a re-check if a watch is running and the
+// outcome names its block.
+func (q *recheckQueue) add(txID string, outcome map[string]interface{}) {
+	block, ok := outcomeBlock(outcome)
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(outcome)
+	if err != nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.watching {
+		return
+	}
+	txID = helpers.HexFix(txID)
+	if slices.ContainsFunc(q.pending, func(p pendingRecheck) bool { return p.txID == txID }) {
+		return
+	}
+	i, _ := slices.BinarySearchFunc(q.pending, block+1, func(p pendingRecheck, block int64) int {
+		return cmp.Compare(p.block, block)
+	})
+	q.pending = slices.Insert(q.pending, i, pendingRecheck{txID: txID, block: block, outcome: data})
+}
+
+// remove drops txID from the queue.
+func (q *recheckQueue) remove(txID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = slices.DeleteFunc(q.pending, func(p pendingRecheck) bool { return p.txID == txID })
+}
+
+// snapshot returns the transactions awaiting a re-check, in the order of their blocks.
+func (q *recheckQueue) snapshot() []pendingRecheck {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.pending)
+}
@@ -0,0 +1,169 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompareOutcomes(t *testing.T) {
+	old := map[string]interface{}{
+		"ID":      "aa01",
+		"Status":  "Executed",
+		"BlockID": "10",
+		"Payload": map[string]interface{}{"Action": "CP_CERTIFICATE", "Data": "6869"},
+	}
+	if diff := CompareOutcomes(old, old); diff.Changed() || diff.TxID != "aa01" {
+		t.Errorf("Expected identical records to compare equal, got %+v", diff)
+	}
+
+	current := map[string]interface{}{
+		"ID":      "aa01",
+		"Status":  "Failed",
+		"BlockID": "10",
+		"Payload": map[string]interface{}{"Action": "CP_CERTIFICATE", "Data": "6870"},
+		"Fee":     1.5,
+	}
+	diff := CompareOutcomes(old, current)
+	want := []OutcomeDifference{
+		{Field: "Fee", Old: "", New: "1.5"},
+		{Field: "Payload.Data", Old: `"6869"`, New: `"6870"`},
+		{Field: "Status", Old: `"Executed"`, New: `"Failed"`},
+	}
+	if diff.Disappeared || fmt.Sprint(diff.Differences) != fmt.Sprint(want) {
+		t.Errorf("Expected differences %v, got %+v", want, diff)
+	}
+
+	if diff := CompareOutcomes(old, nil); !diff.Disappeared || !diff.Changed() || diff.New != nil {
+		t.Errorf("Expected a missing record to be reported as disappeared, got %+v", diff)
+	}
+}
+
+// reorgGateway serves blocks up to a height and transaction records that tests may
+// change or remove, as a reorganization would.
+type reorgGateway struct {
+	mu      sync.Mutex
+	height  int64
+	records map[string]map[string]interface{}
+}
+
+func (g *reorgGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var query map[string]string
+	json.NewDecoder(r.Body).Decode(&query)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case strings.Contains(r.URL.String(), "Circular_GetBlock_"):
+		if block, _ := strconv.ParseInt(query["BlockNumber"], 10, 64); block > g.height {
+			fmt.Fprint(w, `{"Result":108,"Response":"Block Not Found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":{}}`)
+	case strings.Contains(r.URL.String(), "Circular_GetTransactionbyID_"):
+		record, ok := g.records[query["ID"]]
+		if !ok {
+			fmt.Fprint(w, `{"Result":118,"Response":"Transaction Not Found"}`)
+			return
+		}
+		body, _ := json.Marshal(map[string]interface{}{"Result": 200, "Response": record})
+		w.Write(body)
+	}
+}
+
+func TestWatchConfirmations(t *testing.T) {
+	gateway := &reorgGateway{height: 11, records: map[string]map[string]interface{}{
+		"aa01": {"ID": "aa01", "Status": "Executed", "BlockID": "10"},
+		"aa02": {"ID": "aa02", "Status": "Executed", "BlockID": 11},
+		"aa03": {"ID": "aa03", "Status": "Executed", "BlockID": "10"},
+	}}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.SetLogLevel(LogSilent)
+	acc.SetEventJournal(16, nil)
+	acc.SetOutcomeCache(0, nil)
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	for _, txID := range []string{"aa01", "aa02"} {
+		store.SaveReceipt(&Receipt{Transaction: Transaction{ID: txID}, Status: ReceiptPending})
+	}
+
+	changes := make(chan OutcomeDiff, 3)
+	recheck := OutcomeRecheck{Depth: 2, Interval: 5 * time.Millisecond, OnChange: func(diff OutcomeDiff) { changes <- diff }}
+	if err := acc.WatchConfirmations(t.Context(), recheck); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.WatchConfirmations(t.Context(), recheck); err == nil {
+		t.Error("Expected a second watch to be refused")
+	}
+	for _, txID := range []string{"aa01", "aa02", "aa03"} {
+		if outcome := acc.GetTransactionOutcome(txID, 5, 0); outcome == nil {
+			t.Fatalf("Expected an outcome for %s: %v", txID, acc.GetLastError())
+		}
+	}
+
+	// The chain reorganizes before the transactions are deep enough to be re-checked.
+	time.Sleep(20 * time.Millisecond)
+	if len(changes) != 0 {
+		t.Fatal("Expected no re-check before the chain is deep enough")
+	}
+	gateway.mu.Lock()
+	delete(gateway.records, "aa01")
+	gateway.records["aa02"] = map[string]interface{}{"ID": "aa02", "Status": "Failed", "BlockID": 12}
+	gateway.height = 13
+	gateway.mu.Unlock()
+
+	var got []OutcomeDiff
+	for len(got) < 2 {
+		select {
+		case diff := <-changes:
+			got = append(got, diff)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected two changed transactions, got %+v", got)
+		}
+	}
+	if got[0].TxID != "aa01" || !got[0].Disappeared {
+		t.Errorf("Expected aa01 to have disappeared, got %+v", got[0])
+	}
+	if got[1].TxID != "aa02" || got[1].Disappeared || len(got[1].Differences) != 2 || got[1].Differences[1].Field != "Status" {
+		t.Errorf("Expected aa02 to have moved and failed, got %+v", got[1])
+	}
+	if pending := acc.rechecks.snapshot(); len(pending) != 0 {
+		t.Errorf("Expected every transaction to be re-checked once, got %+v", pending)
+	}
+
+	if receipt := acc.loadReceipt("aa01"); receipt.Status != ReceiptPending || receipt.FinalStatus != "" {
+		t.Errorf("Expected the receipt of aa01 to be pending again, got %+v", receipt)
+	}
+	if receipt := acc.loadReceipt("aa02"); receipt.Status != ReceiptFinalized || receipt.FinalStatus != "Failed" {
+		t.Errorf("Expected the receipt of aa02 to take the new status, got %+v", receipt)
+	}
+	if _, ok := acc.cachedOutcome("aa01"); ok {
+		t.Error("Expected the outcome of aa01 to be forgotten")
+	}
+	if outcome, _ := acc.cachedOutcome("aa02"); outcome["Status"] != "Failed" {
+		t.Errorf("Expected the new outcome of aa02 to be cached, got %v", outcome)
+	}
+	events, _ := acc.Events()
+	var reorgs []string
+	for _, event := range events {
+		if event.Type == EventReorg {
+			reorgs = append(reorgs, event.TxID+":"+event.Status)
+		}
+	}
+	if fmt.Sprint(reorgs) != "[aa01: aa02:Failed]" {
+		t.Errorf("Expected the changes to be journaled, got %v", reorgs)
+	}
+
+	if err := NewCEPAccount().WatchConfirmations(t.Context(), OutcomeRecheck{Interval: -time.Second}); err == nil {
+		t.Error("Expected a negative interval to be refused")
+	}
+}
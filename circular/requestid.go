@@ -0,0 +1,53 @@
+package circular
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDHeader is the HTTP header used to send the client-side request ID to the NAG.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key under which the correlation ID is stored.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id as the correlation ID for every NAG
+// call made with it. Use it to tie SDK calls to an ID already assigned by an upstream
+// service (e.g., an incoming HTTP request ID); otherwise a fresh ID is generated per operation.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - id: The correlation ID to propagate.
+//
+// Returns:
+//
+//	A derived context carrying the correlation ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the correlation ID carried by ctx, or an empty string if none is set.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ensureRequestID returns ctx unchanged if it already carries a correlation ID,
+// and otherwise a derived context with a newly generated one. Public operations call
+// it once on entry so every NAG call belonging to the same operation shares an ID.
+func ensureRequestID(ctx context.Context) context.Context {
+	if RequestIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithRequestID(ctx, newRequestID())
+}
+
+// newRequestID generates a random 128-bit identifier encoded as hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDPropagation(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(RequestIDHeader))
+		fmt.Fprint(w, `{"Result":108,"Response":"Wallet Not Found"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	ctx := WithRequestID(context.Background(), "upstream-42")
+	_, err := acc.getWallet(ctx, acc.Address)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got: %v", err)
+	}
+	if apiErr.CorrelationID != "upstream-42" {
+		t.Errorf("CorrelationID = %q, want upstream-42", apiErr.CorrelationID)
+	}
+
+	// Without an upstream ID a fresh one is generated for each operation.
+	acc.getWallet(context.Background(), acc.Address)
+	acc.getWallet(context.Background(), acc.Address)
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(received))
+	}
+	if received[0] != "upstream-42" {
+		t.Errorf("Expected supplied ID to be sent, got %q", received[0])
+	}
+	if received[1] == "" || received[1] == received[2] {
+		t.Errorf("Expected distinct generated IDs, got %q and %q", received[1], received[2])
+	}
+}
+
+func TestEnsureRequestID(t *testing.T) {
+	ctx := ensureRequestID(context.Background())
+	id := RequestIDFromContext(ctx)
+	if len(id) != 32 {
+		t.Errorf("Expected a 32-character generated ID, got %q", id)
+	}
+	if RequestIDFromContext(ensureRequestID(ctx)) != id {
+		t.Error("Expected an existing ID to be preserved")
+	}
+}
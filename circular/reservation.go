@@ -0,0 +1,188 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// reservationsCollection is the DocumentStore collection nonce reservations are journaled in.
+const reservationsCollection = "nonce-reservations"
+
+// NonceReservation is a contiguous range of the account's nonces set aside for a system
+// that builds and broadcasts transactions out of band, e.g. with SubmitWithPrecomputedID
+// on another account instance or with a different SDK.
+type NonceReservation struct {
+	ID         string    // Identifies the reservation for ReleaseNonces.
+	Blockchain string    // The blockchain the nonces are valid on.
+	Start      int64     // The first reserved nonce.
+	Count      int       // The number of reserved nonces.
+	ReservedAt time.Time // When the range was reserved.
+}
+
+// End returns the nonce after the last one reserved.
+func (r *NonceReservation) End() int64 {
+	return r.Start + int64(r.Count)
+}
+
+// NonceResync is the result of reconciling the account's nonce with the gateway.
+type NonceResync struct {
+	Previous   int64              // The account's nonce before the resync.
+	Nonce      int64              // The next nonce according to the gateway, now the account's nonce.
+	Unreleased []NonceReservation // Reservations that were never released, now discarded.
+}
+
+// SetNonceJournal keeps the journal of outstanding nonce reservations in docs, so that
+// reservations survive a restart and can be reconciled by ResyncNonce. Without a
+// journal set, reservations are journaled in memory.
+//
+// Parameters:
+//   - docs: The document store to journal reservations in.
+func (a *CEPAccount) SetNonceJournal(docs storage.DocumentStore) {
+	a.nonces.mu.Lock()
+	defer a.nonces.mu.Unlock()
+	a.nonces.docs = docs
+}
+
+// nonceJournal holds the document store reservations are journaled in.
+type nonceJournal struct {
+	mu   sync.Mutex
+	docs storage.DocumentStore
+}
+
+// store returns the journal's document store, creating an in-memory one on first use.
+func (j *nonceJournal) store() storage.DocumentStore {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.docs == nil {
+		j.docs = storage.NewDocumentStore(storage.NewMemory())
+	}
+	return j.docs
+}
+
+// ReserveNonces atomically sets aside the next n nonces of the account's Blockchain for
+// use outside this account, and journals the reservation until it is released.
+// Submissions through the account continue after the reserved range. The account's
+// nonce must be current, e.g. after UpdateAccount.
+//
+// Parameters:
+//   - n: The number of nonces to reserve.
+//
+// Returns:
+//
+//	The reservation, or an error if the account is not open, n is not positive, or the
+//	reservation cannot be journaled, in which case no nonces are reserved.
+func (a *CEPAccount) ReserveNonces(n int) (*NonceReservation, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of nonces to reserve must be positive, got %d", n)
+	}
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+
+	v := a.view()
+	if v.Address == "" {
+		return nil, ErrAccountNotOpen
+	}
+	reservation := &NonceReservation{
+		ID:         newRequestID(),
+		Blockchain: v.Blockchain,
+		Start:      v.Nonce,
+		Count:      n,
+		ReservedAt: time.Now(),
+	}
+	if err := a.nonces.store().Save(reservationsCollection, reservation.ID, reservation); err != nil {
+		return nil, fmt.Errorf("failed to journal nonce reservation: %w", err)
+	}
+
+	a.nwatch.use(reservation.Blockchain, reservation.End())
+	if err := a.setNonce(v, reservation.End(), "reservation"); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// ReleaseNonces removes a reservation from the journal once its holder is done with it,
+// whether or not it used every nonce.
+//
+// Parameters:
+//   - id: The ID of the reservation.
+//
+// Returns:
+//
+//	An error if the journal cannot be updated.
+func (a *CEPAccount) ReleaseNonces(id string) error {
+	if err := a.nonces.store().Delete(reservationsCollection, id); err != nil {
+		return fmt.Errorf("failed to release nonce reservation %s: %w", id, err)
+	}
+	return nil
+}
+
+// UnreleasedNonces lists the journaled reservations that have not been released,
+// ordered by their first nonce.
+//
+// Returns:
+//
+//	The outstanding reservations, or an error if the journal cannot be read.
+func (a *CEPAccount) UnreleasedNonces() ([]NonceReservation, error) {
+	journal := a.nonces.store()
+	ids, err := journal.IDs(reservationsCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nonce journal: %w", err)
+	}
+	reservations := make([]NonceReservation, 0, len(ids))
+	for _, id := range ids {
+		var reservation NonceReservation
+		if err := journal.Load(reservationsCollection, id, &reservation); err != nil {
+			return nil, fmt.Errorf("failed to read nonce reservation %s: %w", id, err)
+		}
+		reservations = append(reservations, reservation)
+	}
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].Start < reservations[j].Start })
+	return reservations, nil
+}
+
+// ResyncNonce reconciles the account's nonce with the gateway after out-of-band use:
+// the account's nonce is reset to the next nonce the gateway expects, and reservations
+// that were never released are discarded from the journal and reported. Nonces of those
+// reservations that were not used are thereby handed out again, closing the gap their
+// holders left. Call it once the holders of outstanding reservations have finished or
+// are known to have failed.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//
+// Returns:
+//
+//	The reconciliation, or an error if the account is not open, the nonce cannot be
+//	fetched, or the journal cannot be updated.
+func (a *CEPAccount) ResyncNonce(ctx context.Context) (*NonceResync, error) {
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+
+	v := a.view()
+	if v.Address == "" {
+		return nil, ErrAccountNotOpen
+	}
+	nonce, err := a.fetchNonce(ensureRequestID(ctx), v.Blockchain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resync nonce: %w", err)
+	}
+	unreleased, err := a.UnreleasedNonces()
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range unreleased {
+		if err := a.ReleaseNonces(reservation.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.setNonce(v, nonce, "resync"); err != nil {
+		return nil, err
+	}
+	return &NonceResync{Previous: v.Nonce, Nonce: nonce, Unreleased: unreleased}, nil
+}
@@ -0,0 +1,100 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+func TestReserveNonces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.String(), "Circular_GetWalletNonce_") {
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":12}}`)
+			return
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.Nonce = 10
+
+	var mu sync.Mutex
+	var reservations []*NonceReservation
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := acc.ReserveNonces(5)
+			if err != nil {
+				t.Errorf("ReserveNonces() error = %v", err)
+				return
+			}
+			mu.Lock()
+			reservations = append(reservations, reservation)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if acc.State().Nonce != 25 {
+		t.Fatalf("Expected submissions to continue after the reserved ranges, got nonce %d", acc.State().Nonce)
+	}
+	unreleased, err := acc.UnreleasedNonces()
+	if err != nil || len(unreleased) != 3 {
+		t.Fatalf("Expected 3 unreleased reservations, got %v, %v", unreleased, err)
+	}
+	for i, reservation := range unreleased {
+		if reservation.Start != int64(10+5*i) || reservation.End() != int64(15+5*i) {
+			t.Errorf("Expected contiguous, non-overlapping ranges, got %+v", unreleased)
+		}
+	}
+
+	if err := acc.ReleaseNonces(unreleased[0].ID); err != nil {
+		t.Fatalf("ReleaseNonces() error = %v", err)
+	}
+	resync, err := acc.ResyncNonce(t.Context())
+	if err != nil {
+		t.Fatalf("ResyncNonce() error = %v", err)
+	}
+	if resync.Previous != 25 || resync.Nonce != 13 || len(resync.Unreleased) != 2 {
+		t.Errorf("Unexpected resync: %+v", resync)
+	}
+	if acc.State().Nonce != 13 {
+		t.Errorf("Expected the account nonce to follow the gateway, got %d", acc.State().Nonce)
+	}
+	if left, _ := acc.UnreleasedNonces(); len(left) != 0 {
+		t.Errorf("Expected the journal to be reconciled, got %v", left)
+	}
+
+	if _, err := acc.ReserveNonces(0); err == nil {
+		t.Error("Expected an error when reserving no nonces")
+	}
+}
+
+func TestNonceJournalPersists(t *testing.T) {
+	docs := storage.NewDocumentStore(storage.NewMemory())
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	acc.SetNonceJournal(docs)
+	reservation, err := acc.ReserveNonces(2)
+	if err != nil {
+		t.Fatalf("ReserveNonces() error = %v", err)
+	}
+
+	restarted := NewCEPAccount()
+	restarted.SetNonceJournal(docs)
+	unreleased, err := restarted.UnreleasedNonces()
+	if err != nil || len(unreleased) != 1 || unreleased[0].ID != reservation.ID {
+		t.Errorf("Expected the reservation to survive a restart, got %v, %v", unreleased, err)
+	}
+}
@@ -0,0 +1,129 @@
+package circular
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseSize is the largest gateway response body an account reads unless
+// SetMaxResponseSize sets another limit.
+const DefaultMaxResponseSize = 4 << 20
+
+// ErrResponseTooLarge is returned when a gateway response body exceeds the account's
+// size limit; see SetMaxResponseSize. The rest of the body is not read.
+var ErrResponseTooLarge = newError(CodeResponseTooLarge, "response body exceeds the size limit")
+
+// SetMaxResponseSize limits the size of the response bodies the account reads from its
+// gateway and nodes, so that a misbehaving gateway returning an enormous body cannot
+// exhaust the service's memory. Calls whose response exceeds the limit fail with
+// ErrResponseTooLarge. Streamed downloads (see GetTransactionData) are not limited.
+//
+// Parameters:
+//   - n: The largest body to read, in bytes; zero restores DefaultMaxResponseSize and a
+//     negative value removes the limit.
+func (a *CEPAccount) SetMaxResponseSize(n int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxResponse = n
+}
+
+// MaxResponseSize returns the largest response body the account reads.
+//
+// Returns:
+//
+//	The limit in bytes, or a negative value if there is none.
+func (a *CEPAccount) MaxResponseSize() int64 {
+	return a.view().responseLimit()
+}
+
+// responseLimit returns the response size limit of the view.
+func (v accountView) responseLimit() int64 {
+	if v.maxResponse == 0 {
+		return DefaultMaxResponseSize
+	}
+	return v.maxResponse
+}
+
+// limitedBody reads at most n bytes of r, failing with ErrResponseTooLarge on any more.
+// Unlike io.LimitReader, it reports the excess rather than silently truncating, so an
+// oversized body is never decoded as if it were complete.
+type limitedBody struct {
+	r io.Reader
+	n int64 // Bytes left to read; negative means unlimited.
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return l.r.Read(p)
+	}
+	if l.n == 0 {
+		// Distinguish a body of exactly the limit from a longer one.
+		var probe [1]byte
+		if n, err := l.r.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// readBody reads all of r, up to limit bytes (negative for no limit).
+//
+// Returns:
+//
+//	The body, or an error wrapping ErrResponseTooLarge if it exceeds the limit.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(&limitedBody{r: r, n: limit})
+	if err != nil {
+		return nil, fmt.Errorf("%w (limit %d bytes)", err, limit)
+	}
+	return body, nil
+}
+
+// decodeBody decodes the JSON value of r, up to limit bytes (negative for no limit), as
+// it arrives rather than reading the whole body first. The value is returned raw, as
+// the account keeps gateway envelopes verbatim for logs and errors; on failure, prefix
+// holds the start of the body for the error message.
+//
+// Returns:
+//
+//	The raw value, or an error wrapping ErrResponseTooLarge if the body exceeds the
+//	limit, or a decoding error if it is not a single JSON value.
+func decodeBody(r io.Reader, limit int64) (raw json.RawMessage, prefix []byte, err error) {
+	start := &prefixWriter{}
+	decoder := json.NewDecoder(io.TeeReader(&limitedBody{r: r, n: limit}, start))
+	if err = decoder.Decode(&raw); err == nil {
+		// Anything after the value must be whitespace, as for json.Unmarshal.
+		if _, err = decoder.Token(); err == io.EOF {
+			return raw, nil, nil
+		}
+		if err == nil {
+			err = errors.New("invalid data after top-level value")
+		}
+	}
+	if errors.Is(err, ErrResponseTooLarge) {
+		err = fmt.Errorf("%w (limit %d bytes)", ErrResponseTooLarge, limit)
+	}
+	return nil, start.buf.Bytes(), err
+}
+
+// prefixWriter keeps the first maxErrorBodyLen+1 bytes written to it, enough for
+// truncateBody to mark the cut.
+type prefixWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	if room := maxErrorBodyLen + 1 - w.buf.Len(); room > 0 {
+		w.buf.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,68 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	name := `{"Result":200,"Response":{"Name":"` + strings.Repeat("x", 1000) + `"}}`
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		fmt.Fprint(w, name)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.SetLogLevel(LogSilent)
+	if acc.MaxResponseSize() != DefaultMaxResponseSize {
+		t.Errorf("Expected the default limit, got %d", acc.MaxResponseSize())
+	}
+	if _, err := acc.GetChainInfo(t.Context(), "0xaa"); err != nil {
+		t.Fatalf("Expected a response within the default limit to be read, got %v", err)
+	}
+
+	acc.SetMaxResponseSize(int64(len(name)))
+	if _, err := acc.GetChainInfo(t.Context(), "0xaa"); err != nil {
+		t.Errorf("Expected a response of exactly the limit to be read, got %v", err)
+	}
+	acc.SetMaxResponseSize(int64(len(name) - 1))
+	_, err := acc.GetChainInfo(t.Context(), "0xaa")
+	if !errors.Is(err, ErrResponseTooLarge) || ErrorCodeOf(err) != CodeResponseTooLarge {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	status.Store(http.StatusBadGateway)
+	if _, err := acc.GetChainInfo(t.Context(), "0xaa"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected error bodies to be limited too, got %v", err)
+	}
+
+	status.Store(http.StatusOK)
+	acc.SetMaxResponseSize(-1)
+	if _, err := acc.GetChainInfo(t.Context(), "0xaa"); err != nil {
+		t.Errorf("Expected no limit, got %v", err)
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	raw, _, err := decodeBody(strings.NewReader(" {\"Result\":200}\n"), 100)
+	if err != nil || string(raw) != `{"Result":200}` {
+		t.Errorf("decodeBody() = %s, %v", raw, err)
+	}
+	for _, body := range []string{`{"Result":200} trailing`, `<html>Bad Gateway</html>`, ``} {
+		if _, prefix, err := decodeBody(strings.NewReader(body), 100); err == nil || string(prefix) != body {
+			t.Errorf("Expected %q to be refused with its prefix, got %q, %v", body, prefix, err)
+		}
+	}
+	_, prefix, _ := decodeBody(strings.NewReader(`"`+strings.Repeat("x", 2*maxErrorBodyLen)), -1)
+	if len(prefix) != maxErrorBodyLen+1 {
+		t.Errorf("Expected the prefix to be bounded, got %d bytes", len(prefix))
+	}
+}
@@ -0,0 +1,92 @@
+package circular
+
+import (
+	"maps"
+	"strconv"
+	"sync"
+)
+
+// ResultCode is the `Result` code of a NAG response envelope, which reports the outcome
+// of a gateway call independently of its HTTP status. Gateways are free to return codes
+// not listed here; they keep their numeric value. Calls through a NodeClient report
+// JSON-RPC error codes, such as JSONRPCMethodNotFound, the same way.
+type ResultCode int
+
+// NAG result codes.
+const (
+	ResultOK                  ResultCode = 200 // The call succeeded.
+	ResultRejected            ResultCode = 108 // The gateway refused the request, e.g. an invalid transaction or signature, or an unknown wallet.
+	ResultWalletExists        ResultCode = 110 // The wallet is already registered.
+	ResultInvalidBlockchain   ResultCode = 114 // The gateway does not serve the requested blockchain.
+	ResultInsufficientBalance ResultCode = 115 // The sender cannot cover the transaction's fee; see IsInsufficientBalance.
+	ResultTransactionNotFound ResultCode = 118 // The gateway does not know the transaction.
+	ResultNotFound            ResultCode = 404 // The requested resource does not exist.
+	ResultThrottled           ResultCode = 429 // The caller is being rate limited; see SetRetryPolicy.
+)
+
+// resultCodes holds the name and description of each known result code.
+var resultCodes = map[ResultCode]struct{ name, description string }{
+	ResultOK:                  {"OK", "success"},
+	ResultRejected:            {"Rejected", "request rejected"},
+	ResultWalletExists:        {"WalletExists", "wallet already exists"},
+	ResultInvalidBlockchain:   {"InvalidBlockchain", "invalid blockchain"},
+	ResultInsufficientBalance: {"InsufficientBalance", "insufficient balance"},
+	ResultTransactionNotFound: {"TransactionNotFound", "transaction not found"},
+	ResultNotFound:            {"NotFound", "not found"},
+	ResultThrottled:           {"Throttled", "too many requests"},
+}
+
+// String returns the name of the code, such as "InsufficientBalance", for logs and
+// metrics labels, or its number if the code is not known.
+func (c ResultCode) String() string {
+	if known, ok := resultCodes[c]; ok {
+		return known.name
+	}
+	return strconv.Itoa(int(c))
+}
+
+// Description returns a short description of the code, such as "insufficient balance",
+// for error messages.
+//
+// Returns:
+//
+//	The description, or "result code <n>" if the code is not known.
+func (c ResultCode) Description() string {
+	if known, ok := resultCodes[c]; ok {
+		return known.description
+	}
+	return "result code " + strconv.Itoa(int(c))
+}
+
+// Known reports whether the code is one of the package's ResultCode constants.
+func (c ResultCode) Known() bool {
+	_, ok := resultCodes[c]
+	return ok
+}
+
+// resultCounter counts the result codes other than ResultOK the account's gateway
+// calls returned, for Stats and WriteSLAMetrics.
+type resultCounter struct {
+	mu     sync.Mutex
+	counts map[ResultCode]int64
+}
+
+// record counts code, unless the call succeeded or the response carried no code.
+func (c *resultCounter) record(code ResultCode) {
+	if code == ResultOK || code == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[ResultCode]int64)
+	}
+	c.counts[code]++
+}
+
+// snapshot returns a copy of the counts.
+func (c *resultCounter) snapshot() map[ResultCode]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.counts)
+}
@@ -0,0 +1,66 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResultCode(t *testing.T) {
+	if ResultInsufficientBalance.String() != "InsufficientBalance" || ResultInsufficientBalance.Description() != "insufficient balance" {
+		t.Errorf("Unexpected names %q, %q", ResultInsufficientBalance, ResultInsufficientBalance.Description())
+	}
+	if ResultCode(999).String() != "999" || ResultCode(999).Description() != "result code 999" || ResultCode(999).Known() {
+		t.Errorf("Expected an unknown code to keep its number, got %q", ResultCode(999))
+	}
+	for code, known := range resultCodes {
+		if known.name == "" || known.description == "" {
+			t.Errorf("Expected code %d to have a name and description", code)
+		}
+	}
+
+	err := &APIError{Endpoint: "Circular_GetWalletNonce_", HTTPStatus: 200, ResultCode: 114, Message: "Invalid Blockchain"}
+	if got := err.Error(); got != "Circular_GetWalletNonce_: Invalid Blockchain (HTTP 200, result 114 InvalidBlockchain)" {
+		t.Errorf("Unexpected error message %q", got)
+	}
+	if err.Result() != ResultInvalidBlockchain {
+		t.Errorf("Result() = %v", err.Result())
+	}
+	if got := (&APIError{HTTPStatus: 502}).Error(); !strings.Contains(got, "result 0)") {
+		t.Errorf("Expected no name for a missing code, got %q", got)
+	}
+}
+
+func TestResultCodeMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":115,"Response":"Insufficient Balance"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = "testnet"
+	acc.SetLogLevel(LogSilent)
+	acc.Open("0xabcdef")
+	for range 2 {
+		if acc.UpdateAccount() || !IsInsufficientBalance(acc.LastErr()) {
+			t.Fatalf("Expected an insufficient balance, got %v", acc.LastErr())
+		}
+	}
+	var apiErr *APIError
+	if !errors.As(acc.LastErr(), &apiErr) || apiErr.Result() != ResultInsufficientBalance {
+		t.Errorf("Expected the result code on the error, got %v", acc.LastErr())
+	}
+
+	if results := acc.Stats().Results; results[ResultInsufficientBalance] != 2 || len(results) != 1 {
+		t.Errorf("Unexpected result counts %v", results)
+	}
+	var metrics strings.Builder
+	acc.WriteSLAMetrics(&metrics)
+	if want := `circular_gateway_results{network="testnet",result="InsufficientBalance",code="115"} 2`; !strings.Contains(metrics.String(), want) {
+		t.Errorf("Expected %s in the metrics, got:\n%s", want, metrics.String())
+	}
+}
@@ -0,0 +1,272 @@
+package circular
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how NAG calls are retried when the gateway signals backpressure,
+// i.e. an HTTP 429 or 503 status or ResultThrottled. Only throttled calls are
+// retried: the gateway has not processed them, so repeating them cannot duplicate a
+// transaction. A Retry-After header, when present, takes precedence over the backoff.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts per call, including the first; values below 2 disable retries.
+	BaseDelay   time.Duration // Delay before the first retry; doubled on each subsequent retry.
+	MaxDelay    time.Duration // Upper bound on any single pause, including Retry-After; zero means unbounded.
+}
+
+// DefaultRetryPolicy returns a policy of 4 attempts with exponential backoff from
+// 500ms, with pauses capped at 30 seconds.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+}
+
+// delay returns the pause before retry number attempt (starting at 1).
+func (p *RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	d := retryAfter
+	if d <= 0 {
+		d = p.BaseDelay << (attempt - 1)
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// SetRetryPolicy configures retries of throttled NAG calls. Passing nil disables retries,
+// which is the default.
+//
+// Parameters:
+//   - policy: The retry policy to apply to subsequent calls.
+func (a *CEPAccount) SetRetryPolicy(policy *RetryPolicy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.retryPolicy = policy
+}
+
+// Backpressure is a snapshot of the throttling signals the account has received from
+// the gateway, intended for producers that want to slow down before they are rejected.
+type Backpressure struct {
+	InFlight       int           // NAG calls currently in progress, including those waiting out a throttle.
+	Throttled      bool          // Whether the account is currently pausing calls at the gateway's request.
+	CurrentDelay   time.Duration // The most recent pause applied, or zero once a call succeeds unthrottled.
+	RetryAt        time.Time     // When calls will resume if Throttled is true.
+	ThrottleEvents int64         // Total number of throttled responses received.
+}
+
+// Backpressure reports the current backpressure state of the account.
+// It is safe to call concurrently with NAG operations.
+func (a *CEPAccount) Backpressure() Backpressure {
+	a.pressure.mu.Lock()
+	defer a.pressure.mu.Unlock()
+	snapshot := Backpressure{
+		InFlight:       a.pressure.inFlight,
+		CurrentDelay:   a.pressure.currentDelay,
+		RetryAt:        a.pressure.retryAt,
+		ThrottleEvents: a.pressure.events,
+	}
+	snapshot.Throttled = time.Now().Before(a.pressure.retryAt)
+	return snapshot
+}
+
+// backpressureState tracks throttling across all NAG calls made by an account.
+type backpressureState struct {
+	mu           sync.Mutex
+	inFlight     int
+	currentDelay time.Duration
+	retryAt      time.Time
+	events       int64
+}
+
+func (s *backpressureState) enter() {
+	s.mu.Lock()
+	s.inFlight++
+	s.mu.Unlock()
+}
+
+func (s *backpressureState) leave() {
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+}
+
+// throttle records a throttled response and pauses all calls for d.
+func (s *backpressureState) throttle(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events++
+	s.currentDelay = d
+	if until := time.Now().Add(d); until.After(s.retryAt) {
+		s.retryAt = until
+	}
+}
+
+// recover clears the current delay after an unthrottled response.
+func (s *backpressureState) recover() {
+	s.mu.Lock()
+	s.currentDelay = 0
+	s.mu.Unlock()
+}
+
+// wait blocks until any active throttle pause has elapsed or ctx is done.
+func (s *backpressureState) wait(ctx context.Context) error {
+	s.mu.Lock()
+	pause := time.Until(s.retryAt)
+	s.mu.Unlock()
+	if pause <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimiter caps the combined rate of NAG calls of the accounts sharing it, e.g. all
+// accounts acting for one customer; see SetRateLimiter.
+type RateLimiter struct {
+	limiter rateLimiter
+}
+
+// NewRateLimiter creates a limiter allowing at most perSecond calls per second.
+//
+// Parameters:
+//   - perSecond: The maximum rate; zero or negative means unlimited.
+//
+// Returns:
+//
+//	A new RateLimiter.
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	l := &RateLimiter{}
+	l.limiter.setRate(perSecond)
+	return l
+}
+
+// SetRate changes the limit for calls started from now on.
+//
+// Parameters:
+//   - perSecond: The maximum rate; zero or negative means unlimited.
+func (l *RateLimiter) SetRate(perSecond float64) {
+	l.limiter.setRate(perSecond)
+}
+
+// SetRateLimiter makes the account's NAG calls also wait for limiter, which other
+// accounts may share, in addition to the account's own RateLimit. Passing nil removes
+// the shared limit.
+//
+// Parameters:
+//   - limiter: The shared limiter to wait for.
+func (a *CEPAccount) SetRateLimiter(limiter *RateLimiter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.shared = limiter
+}
+
+// waitRateLimits blocks until both the account's own and its shared rate limit permit
+// the next call, or ctx is done. Calls wait in the order of their CallOptions priority.
+func (a *CEPAccount) waitRateLimits(ctx context.Context, v accountView) error {
+	priority := CallOptionsFromContext(ctx).Priority
+	if err := a.limiter.wait(ctx, priority); err != nil {
+		return err
+	}
+	if v.shared != nil {
+		return v.shared.limiter.wait(ctx, priority)
+	}
+	return nil
+}
+
+// rateLimiter spaces out NAG calls to at most a configured rate. The zero value
+// imposes no limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	waiting  map[Priority]int // Calls waiting for a slot, by priority.
+}
+
+// setRate limits calls to perSecond; zero or negative removes the limit.
+func (l *rateLimiter) setRate(perSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if perSecond <= 0 {
+		l.interval = 0
+		return
+	}
+	l.interval = time.Duration(float64(time.Second) / perSecond)
+}
+
+// wait blocks until the next call is permitted or ctx is done. A call whose slot is due
+// keeps waiting while a call of higher priority waits, so that it takes the slot first.
+func (l *rateLimiter) wait(ctx context.Context, priority Priority) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.waiting == nil {
+		l.waiting = make(map[Priority]int)
+	}
+	l.waiting[priority]++
+	defer func() { l.waiting[priority]-- }()
+
+	for l.interval != 0 {
+		now := time.Now()
+		pause := l.next.Sub(now)
+		if pause <= 0 && !l.outranked(priority) {
+			l.next = now.Add(l.interval)
+			return nil
+		}
+		if pause <= 0 {
+			// The due slot is left to the higher priority call, which is about to wake.
+			pause = min(l.interval, time.Millisecond)
+		}
+		l.mu.Unlock()
+		timer := time.NewTimer(pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.mu.Lock()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		l.mu.Lock()
+	}
+	return nil
+}
+
+// outranked reports whether a call of higher priority than priority is waiting; l.mu
+// must be held.
+func (l *rateLimiter) outranked(priority Priority) bool {
+	for p, n := range l.waiting {
+		if p > priority && n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isThrottled reports whether a response signals backpressure.
+func isThrottled(status int, result ResultCode) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable || result == ResultThrottled
+}
+
+// parseRetryAfter interprets a Retry-After header given either in seconds or as an HTTP
+// date. It returns zero if the header is absent or malformed.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
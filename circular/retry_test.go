@@ -0,0 +1,137 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryOnThrottle(t *testing.T) {
+	testCases := []struct {
+		name             string
+		policy           *RetryPolicy
+		throttledReplies int
+		expectSuccess    bool
+		expectedCalls    int
+	}{
+		{name: "no policy", policy: nil, throttledReplies: 1, expectSuccess: false, expectedCalls: 1},
+		{name: "recovers", policy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, throttledReplies: 2, expectSuccess: true, expectedCalls: 3},
+		{name: "exhausted", policy: &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}, throttledReplies: 5, expectSuccess: false, expectedCalls: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tc.throttledReplies {
+					if calls%2 == 1 {
+						w.Header().Set("Retry-After", "0")
+						w.WriteHeader(http.StatusTooManyRequests)
+						fmt.Fprint(w, "slow down")
+					} else {
+						fmt.Fprint(w, `{"Result":429,"Response":"Too many requests"}`)
+					}
+					return
+				}
+				fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+			}))
+			defer server.Close()
+
+			acc := NewCEPAccount()
+			acc.NAGURL = server.URL + "/?cep="
+			acc.Open("0xabcdef")
+			acc.SetRetryPolicy(tc.policy)
+
+			ok := acc.UpdateAccount()
+			if ok != tc.expectSuccess {
+				t.Fatalf("UpdateAccount() = %v, want %v (last error: %s)", ok, tc.expectSuccess, acc.GetLastError())
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tc.expectedCalls, calls)
+			}
+			if !ok {
+				var apiErr *APIError
+				if !errors.As(acc.LastErr(), &apiErr) {
+					t.Errorf("Expected the final throttled response as an APIError, got %v", acc.LastErr())
+				}
+			}
+
+			bp := acc.Backpressure()
+			if int(bp.ThrottleEvents) != min(tc.throttledReplies, tc.expectedCalls) {
+				t.Errorf("Expected %d throttle events, got %d", min(tc.throttledReplies, tc.expectedCalls), bp.ThrottleEvents)
+			}
+			if bp.InFlight != 0 {
+				t.Errorf("Expected no in-flight calls after completion, got %d", bp.InFlight)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	if d := p.delay(1, 0); d != 100*time.Millisecond {
+		t.Errorf("delay(1) = %s", d)
+	}
+	if d := p.delay(2, 0); d != 200*time.Millisecond {
+		t.Errorf("delay(2) = %s", d)
+	}
+	if d := p.delay(3, 0); d != 300*time.Millisecond {
+		t.Errorf("Expected delay to be capped, got %s", d)
+	}
+	if d := p.delay(1, 250*time.Millisecond); d != 250*time.Millisecond {
+		t.Errorf("Expected Retry-After to take precedence, got %s", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("7"); d != 7*time.Second {
+		t.Errorf("parseRetryAfter(7) = %s", d)
+	}
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(future); d <= 8*time.Second || d > 10*time.Second {
+		t.Errorf("parseRetryAfter(date) = %s", d)
+	}
+	if d := parseRetryAfter("soon"); d != 0 {
+		t.Errorf("Expected malformed header to be ignored, got %s", d)
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(20)
+	accounts := make([]*CEPAccount, 2)
+	for i := range accounts {
+		accounts[i] = NewCEPAccount()
+		accounts[i].NAGURL = server.URL + "/?cep="
+		accounts[i].Open("0xabcdef")
+		accounts[i].SetRateLimiter(limiter)
+	}
+
+	start := time.Now()
+	for i := range 4 {
+		if _, err := accounts[i%2].GetBlock(t.Context(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Four calls at 20 per second are spaced 50ms apart, whichever account makes them.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the accounts to share the limit, 4 calls took %s", elapsed)
+	}
+
+	limiter.SetRate(0)
+	start = time.Now()
+	for range 4 {
+		accounts[0].GetBlock(t.Context(), 1)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected no limit after SetRate(0), 4 calls took %s", elapsed)
+	}
+}
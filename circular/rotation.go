@@ -0,0 +1,176 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// KeyRotationType is the value of the `Type` field in a key rotation attestation.
+const KeyRotationType = "CP_KEY_ROTATION"
+
+// KeyRotation is the attestation certified on-chain when an account rotates its signing key.
+// The Circular Protocol has no native key-rotation transaction, so the rotation is recorded
+// as a regular certificate: the transaction itself is signed with the old key (proving the
+// rotation was authorized by the current key holder), and NewKeySignature is produced by
+// the new key (proving possession of the replacement key).
+type KeyRotation struct {
+	Type            string `json:"Type"`            // Always KeyRotationType.
+	Address         string `json:"Address"`         // The account whose key is being rotated.
+	OldPublicKey    string `json:"OldPublicKey"`    // The public key being retired.
+	NewPublicKey    string `json:"NewPublicKey"`    // The public key replacing it.
+	Timestamp       string `json:"Timestamp"`       // The time the rotation was requested, in "YYYY:MM:DD-HH:MM:SS" format.
+	NewKeySignature string `json:"NewKeySignature"` // Signature of SigningMessage() produced by the new key.
+}
+
+// SigningMessage returns the message the new key signs to prove possession.
+// It binds the address, both public keys and the timestamp together so the
+// attestation cannot be replayed for a different account or key pair.
+func (r *KeyRotation) SigningMessage() string {
+	return helpers.HexFix(r.Address) + helpers.HexFix(r.OldPublicKey) + helpers.HexFix(r.NewPublicKey) + r.Timestamp
+}
+
+// Verify checks that the attestation is well formed and that NewKeySignature
+// was produced by the private key matching NewPublicKey.
+//
+// Returns:
+//
+//	An error describing the first problem found, or nil if the attestation is valid.
+func (r *KeyRotation) Verify() error {
+	if r.Type != KeyRotationType {
+		return fmt.Errorf("unexpected attestation type: %q", r.Type)
+	}
+	if r.Address == "" || r.OldPublicKey == "" || r.NewPublicKey == "" {
+		return fmt.Errorf("key rotation attestation is incomplete")
+	}
+	if !VerifySignature(r.NewPublicKey, r.SigningMessage(), r.NewKeySignature) {
+		return fmt.Errorf("new key signature does not match new public key")
+	}
+	return nil
+}
+
+// ParseKeyRotation decodes a key rotation attestation from the data of a certificate,
+// as returned by CCertificate.GetData or recovered from a transaction payload.
+//
+// Parameters:
+//   - data: The JSON attestation.
+//
+// Returns:
+//
+//	The decoded KeyRotation, or an error if data is not a well-formed attestation.
+func ParseKeyRotation(data string) (*KeyRotation, error) {
+	var rotation KeyRotation
+	if err := json.Unmarshal([]byte(data), &rotation); err != nil {
+		return nil, fmt.Errorf("failed to decode key rotation attestation: %w", err)
+	}
+	if rotation.Type != KeyRotationType {
+		return nil, fmt.Errorf("unexpected attestation type: %q", rotation.Type)
+	}
+	return &rotation, nil
+}
+
+// KeyStore persists the signing key of an account across processes, so that the key
+// RotateKey switches to is the one loaded next time; see SetKeyStore.
+type KeyStore interface {
+	// SaveKey stores the key of signer as the key of address, replacing the one stored
+	// before.
+	SaveKey(address string, signer Signer) error
+}
+
+// SetKeyStore makes RotateKey save the new key to store once the rotation is recorded.
+// Passing nil leaves persisting keys to the caller.
+//
+// Parameters:
+//   - store: The store holding the account's key.
+func (a *CEPAccount) SetKeyStore(store KeyStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys = store
+}
+
+// RotateKey retires the account's current signing key in favour of a new one.
+// It certifies a KeyRotation attestation signed by oldSigner and counter-signed by
+// newSigner, and once the rotation is recorded re-points the account's `PublicKey` and
+// Signer at the new key together, before any other submission of the account is
+// signed, and saves the new key to the KeyStore, if one is set.
+//
+// A rotation submitted during a maintenance window and queued for broadcast (see
+// DeferredError) counts as recorded: the attestation is already signed and is
+// broadcast ahead of anything the new key signs, so the account switches keys at once
+// and RotateKey returns the queued transaction's ID along with the DeferredError.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the submission request.
+//   - oldSigner: The signer for the key currently associated with the account.
+//   - newSigner: The signer for the replacement key.
+//
+// Returns:
+//
+//	The transaction ID of the certified attestation, or an error if the account is not
+//	open, the old key does not match the account's registered public key, either signer
+//	fails, or the submission is rejected. If the rotation was queued or the new key
+//	could not be saved, the ID is returned along with the error.
+func (a *CEPAccount) RotateKey(ctx context.Context, oldSigner Signer, newSigner Signer) (string, error) {
+	// Holding submitMu throughout keeps other submissions from being signed between
+	// the attestation and the switch, and concurrent rotations from both passing the
+	// old key check.
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+	v := a.view()
+	if v.Address == "" {
+		return "", ErrAccountNotOpen
+	}
+	if oldSigner == nil || newSigner == nil {
+		return "", fmt.Errorf("both old and new signers are required")
+	}
+	if v.PublicKey != "" && helpers.HexFix(v.PublicKey) != helpers.HexFix(oldSigner.PublicKey()) {
+		return "", fmt.Errorf("old signer does not match the account's public key")
+	}
+	if helpers.HexFix(oldSigner.PublicKey()) == helpers.HexFix(newSigner.PublicKey()) {
+		return "", fmt.Errorf("new key must differ from the current key")
+	}
+
+	rotation := &KeyRotation{
+		Type:         KeyRotationType,
+		Address:      helpers.HexFix(v.Address),
+		OldPublicKey: helpers.HexFix(oldSigner.PublicKey()),
+		NewPublicKey: helpers.HexFix(newSigner.PublicKey()),
+		Timestamp:    v.timeSource().Now().UTC().Format(helpers.TimestampLayout),
+	}
+	signature, err := newSigner.Sign(rotation.SigningMessage())
+	if err != nil {
+		return "", fmt.Errorf("failed to sign rotation with new key: %w", err)
+	}
+	rotation.NewKeySignature = signature
+
+	data, err := json.Marshal(rotation)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal key rotation attestation: %w", err)
+	}
+
+	txID, _, err := a.certifyLocked(ctx, v, string(data), oldSigner, []SubmitOption{withoutSchemaValidation()})
+	if err != nil && !queuedForLater(err) {
+		return "", fmt.Errorf("key rotation failed: %w", err)
+	}
+
+	a.mu.Lock()
+	if !sameHex(a.Address, v.Address) {
+		a.mu.Unlock()
+		return txID, errors.Join(err, errAccountChanged)
+	}
+	a.PublicKey = rotation.NewPublicKey
+	if a.signer != nil {
+		a.signer = newSigner
+	}
+	keys := a.keys
+	a.mu.Unlock()
+	if keys != nil {
+		if saveErr := keys.SaveKey(rotation.Address, newSigner); saveErr != nil {
+			err = errors.Join(err, fmt.Errorf("key rotated by %s but not saved to the key store: %w", txID, saveErr))
+		}
+	}
+	return txID, err
+}
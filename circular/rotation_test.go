@@ -0,0 +1,142 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestRotateKey(t *testing.T) {
+	oldSigner, _ := NewPrivateKeySigner(testPrivateKey)
+	newSigner, _ := NewPrivateKeySigner("0x2d3c1b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e1d2c")
+
+	var submitted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &submitted); err != nil {
+			t.Errorf("Failed to decode submitted transaction: %v", err)
+		}
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc, _ := NewAccount(WithSigner(oldSigner))
+	acc.NAGURL = server.URL + "/?cep="
+	keys := &keyRecorder{}
+	acc.SetKeyStore(keys)
+
+	txID, err := acc.RotateKey(context.Background(), oldSigner, newSigner)
+	if err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	if txID != submitted["ID"] || acc.LatestTxID != txID {
+		t.Errorf("Expected returned and latest TxID to match submitted ID %s, got %s / %s", submitted["ID"], txID, acc.LatestTxID)
+	}
+	if acc.PublicKey != newSigner.PublicKey() || acc.Signer() != newSigner {
+		t.Errorf("Expected account and its signer to be re-pointed at the new key")
+	}
+	if keys.saved[acc.Address] != newSigner {
+		t.Errorf("Expected the new key to be saved to the key store, got %v", keys.saved)
+	}
+	if !VerifySignature(oldSigner.PublicKey(), submitted["ID"], submitted["Signature"]) {
+		t.Error("Expected the rotation transaction to be signed by the old key")
+	}
+
+	var envelope map[string]string
+	json.Unmarshal([]byte(helpers.HexToString(submitted["Payload"])), &envelope)
+	rotation, err := ParseKeyRotation(helpers.HexToString(envelope["Data"]))
+	if err != nil {
+		t.Fatalf("Failed to parse attestation: %v", err)
+	}
+	if err := rotation.Verify(); err != nil {
+		t.Errorf("Expected attestation to verify, got: %v", err)
+	}
+}
+
+func TestRotateKeyRejectsMismatchedOldKey(t *testing.T) {
+	oldSigner, _ := NewPrivateKeySigner(testPrivateKey)
+	newSigner, _ := NewPrivateKeySigner("0x2d3c1b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e1d2c")
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	acc.PublicKey = newSigner.PublicKey()
+
+	if _, err := acc.RotateKey(context.Background(), oldSigner, newSigner); err == nil {
+		t.Error("Expected an error when the old signer does not match the account key")
+	}
+}
+
+// keyRecorder is a KeyStore recording the signers saved to it.
+type keyRecorder struct {
+	saved map[string]Signer
+	err   error
+}
+
+func (k *keyRecorder) SaveKey(address string, signer Signer) error {
+	if k.err != nil {
+		return k.err
+	}
+	if k.saved == nil {
+		k.saved = map[string]Signer{}
+	}
+	k.saved[address] = signer
+	return nil
+}
+
+func TestRotateKeyQueuedDuringMaintenance(t *testing.T) {
+	oldSigner, _ := NewPrivateKeySigner(testPrivateKey)
+	newSigner, _ := NewPrivateKeySigner("0x2d3c1b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e1d2c")
+	server := newReceiptTestServer()
+	defer server.Close()
+
+	acc, _ := NewAccount(WithSigner(oldSigner))
+	acc.NAGURL = server.URL + "/?cep="
+	store := NewMemoryReceiptStore()
+	acc.SetReceiptStore(store)
+	acc.SetClock(circulartest.NewFakeClock(time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC)))
+	window, _ := ParseMaintenanceWindow("0 2 * * *", time.Hour, nil)
+	acc.SetMaintenanceWindows(window)
+	keys := &keyRecorder{}
+	acc.SetKeyStore(keys)
+
+	var deferred *DeferredError
+	txID, err := acc.RotateKey(t.Context(), oldSigner, newSigner)
+	if !errors.As(err, &deferred) || !deferred.Queued || txID == "" || txID != deferred.TxID {
+		t.Fatalf("Expected the rotation to be queued, got %q, %v", txID, err)
+	}
+	if receipt, err := store.LoadReceipt(txID); err != nil || receipt.Status != ReceiptQueued {
+		t.Errorf("Expected a queued receipt for the rotation, got %+v, %v", receipt, err)
+	}
+	if acc.PublicKey != newSigner.PublicKey() || acc.Signer() != newSigner || keys.saved[acc.Address] != newSigner {
+		t.Error("Expected a queued rotation to switch and save the key")
+	}
+}
+
+func TestRotateKeyReportsUnsavedKey(t *testing.T) {
+	oldSigner, _ := NewPrivateKeySigner(testPrivateKey)
+	newSigner, _ := NewPrivateKeySigner("0x2d3c1b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e1d2c")
+	server := newReceiptTestServer()
+	defer server.Close()
+
+	acc, _ := NewAccount(WithSigner(oldSigner))
+	acc.NAGURL = server.URL + "/?cep="
+	failure := errors.New("disk full")
+	acc.SetKeyStore(&keyRecorder{err: failure})
+
+	txID, err := acc.RotateKey(t.Context(), oldSigner, newSigner)
+	if !errors.Is(err, failure) || txID == "" || txID != acc.LatestTxID {
+		t.Fatalf("Expected the rotation's ID with the failure to save, got %q, %v", txID, err)
+	}
+	if acc.Signer() != newSigner {
+		t.Error("Expected the account to use the recorded new key despite the failure to save it")
+	}
+}
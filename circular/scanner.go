@@ -0,0 +1,188 @@
+package circular
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrPIIRedactionRefused is returned when the account's scanner would redact the data
+// of a transaction built outside the SDK.
+var ErrPIIRedactionRefused = newError(CodePIIDetected, "scanner found personal data to redact")
+
+// Scanner inspects data before it is certified, so that personal data is not published
+// by accident to a ledger that can never forget it; see SetScanner. Implementations
+// must be safe for concurrent use.
+type Scanner interface {
+	// Scan returns the data to certify in place of data: data itself if nothing was
+	// found, or a redacted copy. It returns an error, usually a *PIIError, to block the
+	// submission instead.
+	Scan(data string) (string, error)
+}
+
+// ScannerFunc adapts a function to the Scanner interface.
+type ScannerFunc func(data string) (string, error)
+
+// Scan implements Scanner.
+func (f ScannerFunc) Scan(data string) (string, error) {
+	return f(data)
+}
+
+// PIIFinding is one piece of personal data found by a scanner.
+type PIIFinding struct {
+	Kind   string // The kind of data, e.g. "email" or "ssn".
+	Offset int    // The byte offset of the data in the scanned text.
+	Length int    // The length of the data in bytes.
+}
+
+// PIIError is returned when a Scanner blocks a submission for containing personal data.
+// It lists where the data was found but not the data itself, so that it can be logged.
+type PIIError struct {
+	Findings []PIIFinding
+}
+
+func (e *PIIError) Error() string {
+	counts := make(map[string]int)
+	for _, finding := range e.Findings {
+		counts[finding.Kind]++
+	}
+	kinds := make([]string, 0, len(counts))
+	for kind, n := range counts {
+		kinds = append(kinds, fmt.Sprintf("%d %s", n, kind))
+	}
+	sort.Strings(kinds)
+	return "data contains personal data: " + strings.Join(kinds, ", ")
+}
+
+// Code returns CodePIIDetected.
+func (e *PIIError) Code() ErrorCode {
+	return CodePIIDetected
+}
+
+// PIIPattern is a kind of personal data a RegexScanner looks for.
+type PIIPattern struct {
+	Kind    string         // Names the kind in findings, e.g. "email".
+	Pattern *regexp.Regexp // Matches the data.
+}
+
+// DefaultPIIPatterns returns the patterns of NewRegexScanner: email addresses ("email")
+// and US Social Security numbers written as 123-45-6789 ("ssn").
+func DefaultPIIPatterns() []PIIPattern {
+	return []PIIPattern{
+		{Kind: "email", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+		{Kind: "ssn", Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	}
+}
+
+// DefaultRedaction replaces personal data redacted by a RegexScanner without its own
+// Replacement.
+const DefaultRedaction = "[REDACTED]"
+
+// RegexScanner is a Scanner matching regular expressions, for simple deployments that
+// have no data loss prevention service to call. It blocks data that matches any of its
+// patterns, or with Redact set replaces the matches. Regular expressions only catch
+// data written in the expected form; they are a safety net, not a guarantee.
+type RegexScanner struct {
+	Patterns    []PIIPattern // The kinds of data to look for.
+	Redact      bool         // Replace matches rather than blocking the submission.
+	Replacement string       // Replaces each match when redacting; DefaultRedaction if empty.
+}
+
+// NewRegexScanner creates a RegexScanner with the DefaultPIIPatterns.
+//
+// Parameters:
+//   - redact: Whether to redact matches rather than block the submission.
+//
+// Returns:
+//
+//	The scanner.
+func NewRegexScanner(redact bool) *RegexScanner {
+	return &RegexScanner{Patterns: DefaultPIIPatterns(), Redact: redact}
+}
+
+// Find returns the personal data in data, in order of offset. Overlapping matches of
+// different patterns are reported once, as the earliest and longest.
+func (s *RegexScanner) Find(data string) []PIIFinding {
+	var findings []PIIFinding
+	for _, p := range s.Patterns {
+		for _, loc := range p.Pattern.FindAllStringIndex(data, -1) {
+			findings = append(findings, PIIFinding{Kind: p.Kind, Offset: loc[0], Length: loc[1] - loc[0]})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Offset != findings[j].Offset {
+			return findings[i].Offset < findings[j].Offset
+		}
+		return findings[i].Length > findings[j].Length
+	})
+	kept := findings[:0]
+	end := 0
+	for _, f := range findings {
+		if f.Offset >= end {
+			kept = append(kept, f)
+			end = f.Offset + f.Length
+		}
+	}
+	return kept
+}
+
+// Scan implements Scanner.
+func (s *RegexScanner) Scan(data string) (string, error) {
+	findings := s.Find(data)
+	if len(findings) == 0 {
+		return data, nil
+	}
+	if !s.Redact {
+		return "", &PIIError{Findings: findings}
+	}
+	replacement := s.Replacement
+	if replacement == "" {
+		replacement = DefaultRedaction
+	}
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.WriteString(data[last:f.Offset])
+		b.WriteString(replacement)
+		last = f.Offset + f.Length
+	}
+	b.WriteString(data[last:])
+	return b.String(), nil
+}
+
+// SetScanner installs a scanner that inspects the data of every certificate before it
+// is submitted, ahead of deduplication, schema validation and policies, which all see
+// the scanned data. A scanner that redacts changes what is certified; one that returns
+// an error blocks the submission, leaving the nonce unchanged. Transactions signed
+// outside the SDK (SubmitWithPrecomputedID and SubmitRawEnvelope) cannot be redacted,
+// so they are blocked if the scanner would change their data.
+//
+// Parameters:
+//   - scanner: The scanner, such as NewRegexScanner(false); nil turns scanning off.
+func (a *CEPAccount) SetScanner(scanner Scanner) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scanner = scanner
+}
+
+// scan applies the account's scanner, if any, to data about to be certified.
+func (v accountView) scan(data string) (string, error) {
+	if v.scanner == nil {
+		return data, nil
+	}
+	return v.scanner.Scan(data)
+}
+
+// scanSigned applies the account's scanner to the data of a transaction that is already
+// built and cannot be redacted, refusing it if the scanner would change it.
+func (v accountView) scanSigned(data string) error {
+	scanned, err := v.scan(data)
+	if err != nil {
+		return err
+	}
+	if scanned != data {
+		return fmt.Errorf("%w: the transaction was built outside the SDK and cannot be redacted", ErrPIIRedactionRefused)
+	}
+	return nil
+}
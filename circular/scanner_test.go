@@ -0,0 +1,78 @@
+package circular
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestRegexScanner(t *testing.T) {
+	data := `{"customer":"jane.doe@example.com","ssn":"123-45-6789","order":"2024-01-15"}`
+
+	blocking := NewRegexScanner(false)
+	_, err := blocking.Scan(data)
+	var pii *PIIError
+	if !errors.As(err, &pii) || len(pii.Findings) != 2 || ErrorCodeOf(err) != CodePIIDetected {
+		t.Fatalf("Expected an email and an SSN to block the data, got %v", err)
+	}
+	if f := pii.Findings[0]; f.Kind != "email" || data[f.Offset:f.Offset+f.Length] != "jane.doe@example.com" {
+		t.Errorf("Expected the email first, got %+v", f)
+	}
+	if err.Error() != "data contains personal data: 1 email, 1 ssn" {
+		t.Errorf("Unexpected message: %v", err)
+	}
+
+	redacted, err := NewRegexScanner(true).Scan(data)
+	if err != nil || redacted != `{"customer":"[REDACTED]","ssn":"[REDACTED]","order":"2024-01-15"}` {
+		t.Errorf("Expected both to be redacted, got %q, %v", redacted, err)
+	}
+	if clean, err := blocking.Scan("invoice 42"); clean != "invoice 42" || err != nil {
+		t.Errorf("Expected clean data to pass unchanged, got %q, %v", clean, err)
+	}
+}
+
+func TestSetScanner(t *testing.T) {
+	var sent []Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tx Transaction
+		json.NewDecoder(r.Body).Decode(&tx)
+		sent = append(sent, tx)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	acc.SetScanner(NewRegexScanner(false))
+	if _, err := acc.submitCertificate(t.Context(), "contact jane@example.com", signer); ErrorCodeOf(err) != CodePIIDetected {
+		t.Fatalf("Expected the submission to be blocked, got %v", err)
+	}
+	if len(sent) != 0 || acc.Nonce != 0 {
+		t.Fatalf("Expected nothing to be sent, got %d transactions and nonce %d", len(sent), acc.Nonce)
+	}
+
+	acc.SetScanner(NewRegexScanner(true))
+	if _, err := acc.submitCertificate(t.Context(), "contact jane@example.com", signer); err != nil {
+		t.Fatal(err)
+	}
+	if _, data, _ := Payload(sent[0].Payload).Decode(); data != "contact [REDACTED]" {
+		t.Errorf("Expected the redacted data to be certified, got %q", data)
+	}
+
+	// Envelopes built outside the SDK cannot be redacted.
+	envelope := helpers.StringToHex(`{"Action":"CP_CERTIFICATE","Data":"` + helpers.StringToHex("jane@example.com") + `"}`)
+	if _, err := acc.SubmitRawEnvelope(t.Context(), envelope, certificateTxType, signer); !errors.Is(err, ErrPIIRedactionRefused) {
+		t.Errorf("Expected ErrPIIRedactionRefused, got %v", err)
+	}
+	if len(sent) != 1 {
+		t.Errorf("Expected the envelope not to be sent, got %d transactions", len(sent))
+	}
+}
@@ -0,0 +1,309 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// SchemaRegistry holds JSON Schemas keyed by certificate Action (e.g., "CP_CERTIFICATE").
+// When attached to an account with SetSchemaRegistry, certificate data is validated against
+// the schema registered for its Action before it is signed and submitted, so malformed
+// payloads are rejected locally instead of being permanently recorded on-chain.
+//
+// The registry implements the commonly used subset of JSON Schema: type, enum, const,
+// properties, required, additionalProperties (boolean form), items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum and maximum. Unsupported keywords are ignored.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*jsonSchema
+}
+
+// SchemaViolation describes a single way in which data failed to match its schema.
+type SchemaViolation struct {
+	Path    string // JSON path of the offending value, e.g. "$.items[2].name".
+	Message string // Human-readable description of the failure.
+}
+
+// SchemaValidationError is returned when certificate data does not conform to the schema
+// registered for its Action. It lists every violation found, not just the first.
+type SchemaValidationError struct {
+	Action     string            // The Action whose schema was applied.
+	Violations []SchemaViolation // All violations found, in document order.
+}
+
+func (e *SchemaValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Path + ": " + v.Message
+	}
+	return fmt.Sprintf("payload does not match schema for %s: %s", e.Action, strings.Join(parts, "; "))
+}
+
+// Code returns CodeSchemaInvalid.
+func (e *SchemaValidationError) Code() ErrorCode {
+	return CodeSchemaInvalid
+}
+
+// jsonSchema is the parsed form of the supported JSON Schema subset.
+type jsonSchema struct {
+	Type                 interface{}            `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Const                interface{}            `json:"const"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+
+	hasConst bool
+	pattern  *regexp.Regexp
+}
+
+// NewSchemaRegistry creates an empty SchemaRegistry.
+//
+// Returns:
+//
+//	A pointer to a newly initialized SchemaRegistry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]*jsonSchema)}
+}
+
+// Register parses schemaJSON and associates it with action, replacing any schema
+// previously registered for it.
+//
+// Parameters:
+//   - action: The certificate Action the schema applies to (e.g., "CP_CERTIFICATE").
+//   - schemaJSON: The JSON Schema document.
+//
+// Returns:
+//
+//	An error if the schema is not valid JSON or contains an invalid pattern.
+func (r *SchemaRegistry) Register(action string, schemaJSON []byte) error {
+	var schema jsonSchema
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		return fmt.Errorf("failed to parse schema for %s: %w", action, err)
+	}
+	var raw map[string]interface{}
+	json.Unmarshal(schemaJSON, &raw)
+	if err := schema.compile(raw); err != nil {
+		return fmt.Errorf("invalid schema for %s: %w", action, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[action] = &schema
+	return nil
+}
+
+// Validate checks data (a JSON document) against the schema registered for action.
+// If no schema is registered for action, validation trivially succeeds.
+//
+// Parameters:
+//   - action: The certificate Action whose schema should be applied.
+//   - data: The JSON document to validate.
+//
+// Returns:
+//
+//	nil if data is valid, or a *SchemaValidationError listing every violation.
+func (r *SchemaRegistry) Validate(action string, data string) error {
+	r.mu.RLock()
+	schema, ok := r.schemas[action]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return &SchemaValidationError{
+			Action:     action,
+			Violations: []SchemaViolation{{Path: "$", Message: fmt.Sprintf("data is not valid JSON: %v", err)}},
+		}
+	}
+
+	var violations []SchemaViolation
+	schema.validate("$", value, &violations)
+	if len(violations) > 0 {
+		return &SchemaValidationError{Action: action, Violations: violations}
+	}
+	return nil
+}
+
+// SetSchemaRegistry enables schema validation of certificate data before submission.
+// Passing nil disables validation.
+//
+// Parameters:
+//   - registry: The registry to validate against.
+func (a *CEPAccount) SetSchemaRegistry(registry *SchemaRegistry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.schemas = registry
+}
+
+// compile precompiles patterns and records whether "const" was present, recursively.
+func (s *jsonSchema) compile(raw map[string]interface{}) error {
+	if raw != nil {
+		_, s.hasConst = raw["const"]
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	rawProps, _ := raw["properties"].(map[string]interface{})
+	for name, prop := range s.Properties {
+		rawProp, _ := rawProps[name].(map[string]interface{})
+		if err := prop.compile(rawProp); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		rawItems, _ := raw["items"].(map[string]interface{})
+		if err := s.Items.compile(rawItems); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate appends every violation of value against s to violations.
+func (s *jsonSchema) validate(path string, value interface{}, violations *[]SchemaViolation) {
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != nil && !matchesType(s.Type, value) {
+		fail("expected type %v, got %s", s.Type, jsonTypeOf(value))
+		return
+	}
+	if s.hasConst && !reflect.DeepEqual(s.Const, value) {
+		fail("expected constant value %v", s.Const)
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, candidate := range s.Enum {
+			if reflect.DeepEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %v is not one of %v", value, s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("string shorter than minimum length %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("string longer than maximum length %d", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("string does not match pattern %q", s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("value %v is less than minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("value %v is greater than maximum %v", v, *s.Maximum)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("array has fewer than %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("array has more than %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unexpected property %q", name)
+				}
+				continue
+			}
+			prop.validate(path+"."+name, v[name], violations)
+		}
+	}
+}
+
+// matchesType reports whether value satisfies a "type" keyword, which may be a single
+// type name or a list of them.
+func matchesType(schemaType interface{}, value interface{}) bool {
+	switch t := schemaType.(type) {
+	case string:
+		return typeMatches(t, value)
+	case []interface{}:
+		for _, candidate := range t {
+			if name, ok := candidate.(string); ok && typeMatches(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func typeMatches(name string, value interface{}) bool {
+	actual := jsonTypeOf(value)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+// jsonTypeOf returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
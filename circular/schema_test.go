@@ -0,0 +1,92 @@
+package circular
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"required": ["invoice", "amount"],
+	"additionalProperties": false,
+	"properties": {
+		"invoice": {"type": "string", "pattern": "^INV-[0-9]+$"},
+		"amount": {"type": "number", "minimum": 0},
+		"currency": {"enum": ["USD", "EUR"]},
+		"lines": {"type": "array", "maxItems": 2, "items": {"type": "string", "minLength": 1}}
+	}
+}`
+
+func TestSchemaRegistryValidate(t *testing.T) {
+	registry := NewSchemaRegistry()
+	if err := registry.Register("CP_CERTIFICATE", []byte(invoiceSchema)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	testCases := []struct {
+		name               string
+		data               string
+		expectedViolations []string
+	}{
+		{name: "valid", data: `{"invoice":"INV-1","amount":10,"currency":"USD","lines":["a"]}`},
+		{name: "not json", data: `hello`, expectedViolations: []string{"$"}},
+		{name: "wrong root type", data: `[1,2]`, expectedViolations: []string{"$"}},
+		{name: "missing required", data: `{"invoice":"INV-1"}`, expectedViolations: []string{"$"}},
+		{
+			name:               "multiple violations",
+			data:               `{"invoice":"X","amount":-1,"currency":"GBP","lines":["","b","c"],"extra":true}`,
+			expectedViolations: []string{"$.amount", "$.currency", "$", "$.invoice", "$.lines", "$.lines[0]"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := registry.Validate("CP_CERTIFICATE", tc.data)
+			if len(tc.expectedViolations) == 0 {
+				if err != nil {
+					t.Errorf("Expected data to be valid, got: %v", err)
+				}
+				return
+			}
+
+			var validationErr *SchemaValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a SchemaValidationError, got: %v", err)
+			}
+			paths := map[string]bool{}
+			for _, v := range validationErr.Violations {
+				paths[v.Path] = true
+			}
+			for _, path := range tc.expectedViolations {
+				if !paths[path] {
+					t.Errorf("Expected a violation at %s, got: %v", path, validationErr.Violations)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaRegistryUnregisteredAction(t *testing.T) {
+	registry := NewSchemaRegistry()
+	if err := registry.Validate("CP_CERTIFICATE", "anything"); err != nil {
+		t.Errorf("Expected no validation without a registered schema, got: %v", err)
+	}
+	if err := registry.Register("CP_CERTIFICATE", []byte(`{"pattern":"("}`)); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestSubmitCertificateValidatesSchema(t *testing.T) {
+	registry := NewSchemaRegistry()
+	registry.Register("CP_CERTIFICATE", []byte(invoiceSchema))
+
+	acc := NewCEPAccount()
+	acc.Open("0xabcdef")
+	acc.SetSchemaRegistry(registry)
+
+	acc.SubmitCertificate(`{"invoice":"bad"}`, testPrivateKey)
+	if !strings.Contains(acc.GetLastError(), "does not match schema") {
+		t.Errorf("Expected a schema validation error, got: %q", acc.GetLastError())
+	}
+}
@@ -0,0 +1,128 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchSigner is a Signer that signs many messages in one call, such as a PrivateKeySigner
+// spreading the work across CPUs or a remote signing service with a batch endpoint.
+// SignBatch uses it when a signer implements it.
+type BatchSigner interface {
+	Signer
+
+	// SignBatch signs each message as Sign would, returning the signatures in the order
+	// of messages. It stops early, with an error, if ctx is done or a message cannot be
+	// signed.
+	SignBatch(ctx context.Context, messages []string) ([]string, error)
+}
+
+// SignBatch signs each message with signer: with its own SignBatch if it is a
+// BatchSigner, and otherwise with a SigningPool using one worker per CPU, in which
+// case signer must be safe for concurrent use.
+//
+// Parameters:
+//   - ctx: Stops the signing of messages not yet started when done.
+//   - signer: Holds the signing key.
+//   - messages: The messages to sign, such as transaction IDs.
+//
+// Returns:
+//
+//	The signatures in the order of messages, or an error if ctx is done or a message
+//	cannot be signed.
+func SignBatch(ctx context.Context, signer Signer, messages []string) ([]string, error) {
+	if signer == nil {
+		return nil, ErrSignerRequired
+	}
+	if batch, ok := signer.(BatchSigner); ok {
+		return batch.SignBatch(ctx, messages)
+	}
+	return NewSigningPool(signer, 0).SignBatch(ctx, messages)
+}
+
+// SigningPool parallelizes the signing of batches of messages with a Signer across a
+// fixed number of workers. Workers take the next message as they finish the last, so
+// a batch needs no memory beyond its signatures whatever its size. A SigningPool is
+// itself a BatchSigner, and can be passed wherever a Signer is expected.
+type SigningPool struct {
+	signer  Signer
+	workers int
+}
+
+// NewSigningPool creates a pool signing with signer on workers goroutines. signer must
+// be safe for concurrent use; PrivateKeySigner is.
+//
+// Parameters:
+//   - signer: Holds the signing key.
+//   - workers: The number of messages signed at once; zero or negative means one per
+//     CPU, as reported by runtime.GOMAXPROCS.
+//
+// Returns:
+//
+//	A new SigningPool.
+func NewSigningPool(signer Signer, workers int) *SigningPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &SigningPool{signer: signer, workers: workers}
+}
+
+// PublicKey returns the public key of the pool's signer.
+func (p *SigningPool) PublicKey() string {
+	return p.signer.PublicKey()
+}
+
+// Sign signs a single message with the pool's signer.
+func (p *SigningPool) Sign(message string) (string, error) {
+	return p.signer.Sign(message)
+}
+
+// SignBatch signs messages on the pool's workers; see BatchSigner.
+func (p *SigningPool) SignBatch(ctx context.Context, messages []string) ([]string, error) {
+	signatures := make([]string, len(messages))
+	var next atomic.Int64
+	var failed atomic.Bool
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() { firstErr = err })
+		failed.Store(true)
+	}
+
+	var wg sync.WaitGroup
+	for range min(p.workers, len(messages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1)) - 1
+				if i >= len(messages) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					fail(err)
+					return
+				}
+				signature, err := p.signer.Sign(messages[i])
+				if err != nil {
+					fail(fmt.Errorf("failed to sign message %d: %w", i, err))
+					return
+				}
+				signatures[i] = signature
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return signatures, nil
+}
+
+// SignBatch signs messages on one worker per CPU; see BatchSigner.
+func (s *PrivateKeySigner) SignBatch(ctx context.Context, messages []string) ([]string, error) {
+	return NewSigningPool(s, 0).SignBatch(ctx, messages)
+}
@@ -0,0 +1,92 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// plainSigner hides the SignBatch method of the signer it wraps, failing on one message.
+type plainSigner struct {
+	Signer
+	fail string
+}
+
+func (s plainSigner) Sign(message string) (string, error) {
+	if message == s.fail {
+		return "", errors.New("key unavailable")
+	}
+	return s.Signer.Sign(message)
+}
+
+func batchMessages(n int) []string {
+	messages := make([]string, n)
+	for i := range messages {
+		messages[i] = fmt.Sprintf("%064x", i)
+	}
+	return messages
+}
+
+func TestSignBatch(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	messages := batchMessages(100)
+
+	for _, s := range []Signer{signer, plainSigner{Signer: signer}, NewSigningPool(signer, 3)} {
+		signatures, err := SignBatch(t.Context(), s, messages)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, message := range messages {
+			want, _ := signer.Sign(message)
+			if signatures[i] != want || !VerifySignature(signer.PublicKey(), message, signatures[i]) {
+				t.Fatalf("%T: expected signature %d to be over its message", s, i)
+			}
+		}
+	}
+
+	if _, err := SignBatch(t.Context(), plainSigner{Signer: signer, fail: messages[42]}, messages); err == nil || err.Error() != "failed to sign message 42: key unavailable" {
+		t.Errorf("Expected the failed message to be reported, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := signer.SignBatch(ctx, messages); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled batch to fail, got %v", err)
+	}
+	if signatures, err := signer.SignBatch(t.Context(), nil); err != nil || len(signatures) != 0 {
+		t.Errorf("Expected an empty batch to succeed, got %v, %v", signatures, err)
+	}
+	if _, err := SignBatch(t.Context(), nil, messages); !errors.Is(err, ErrSignerRequired) {
+		t.Errorf("Expected ErrSignerRequired, got %v", err)
+	}
+}
+
+// BenchmarkSignBatch signs batches of 1024 transaction IDs with doubling numbers of
+// workers up to the number of CPUs; the signatures per second grow with the workers.
+func BenchmarkSignBatch(b *testing.B) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	messages := batchMessages(1024)
+	cpus := runtime.GOMAXPROCS(0)
+	for workers := 1; workers <= cpus; workers = min(2*workers, max(cpus, workers+1)) {
+		pool := NewSigningPool(signer, workers)
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := pool.SignBatch(b.Context(), messages); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*len(messages))/b.Elapsed().Seconds(), "signatures/s")
+		})
+	}
+}
+
+// BenchmarkSign is the sequential baseline for BenchmarkSignBatch.
+func BenchmarkSign(b *testing.B) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	message := batchMessages(1)[0]
+	for b.Loop() {
+		signer.Sign(message)
+	}
+}
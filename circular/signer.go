@@ -0,0 +1,142 @@
+package circular
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// Signer abstracts the private key operations needed to authorize transactions.
+// Implementations may hold the key in memory, delegate to an HSM, or call out to a
+// remote signing service; the account never needs direct access to key material.
+type Signer interface {
+	// PublicKey returns the hex-encoded, uncompressed secp256k1 public key of the signer.
+	PublicKey() string
+	// Sign hashes the message with SHA-256 and returns the hex-encoded DER signature.
+	Sign(message string) (string, error)
+}
+
+// Zeroizer is implemented by Signers that can wipe the key material they hold, such as
+// PrivateKeySigner. Close calls it when given WithZeroizeKeys.
+type Zeroizer interface {
+	// Zeroize overwrites the key material; the Signer cannot sign afterwards.
+	Zeroize()
+}
+
+// ErrKeyZeroized is returned by Sign once the signer's key has been zeroized.
+var ErrKeyZeroized = newError(CodeSignerRequired, "private key has been zeroized")
+
+// PrivateKeySigner is a Signer backed by an in-memory secp256k1 private key.
+type PrivateKeySigner struct {
+	mu  sync.RWMutex
+	key *secp256k1.PrivateKey // Nil once zeroized.
+}
+
+// NewPrivateKeySigner creates a Signer from a hexadecimal private key.
+// The key may be supplied with or without a "0x" prefix.
+//
+// Parameters:
+//   - privateKeyHex: The 32-byte private key in hexadecimal format.
+//
+// Returns:
+//
+//	A ready-to-use PrivateKeySigner, or an error if the key is not valid hex
+//	or does not have the expected length.
+func NewPrivateKeySigner(privateKeyHex string) (*PrivateKeySigner, error) {
+	privateKeyBytes, err := hex.DecodeString(helpers.HexFix(privateKeyHex))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key hex string: %w", err)
+	}
+	if len(privateKeyBytes) != secp256k1.PrivKeyBytesLen {
+		return nil, fmt.Errorf("invalid private key length: expected %d bytes, got %d", secp256k1.PrivKeyBytesLen, len(privateKeyBytes))
+	}
+	return &PrivateKeySigner{key: secp256k1.PrivKeyFromBytes(privateKeyBytes)}, nil
+}
+
+// GeneratePrivateKey creates a new random secp256k1 private key.
+//
+// Returns:
+//
+//	The 32-byte private key in hexadecimal format, without "0x" prefix, ready for
+//	NewPrivateKeySigner, or an error if the system's random source fails.
+func GeneratePrivateKey() (string, error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate private key: %w", err)
+	}
+	return hex.EncodeToString(key.Serialize()), nil
+}
+
+// PublicKey returns the hex-encoded, uncompressed public key derived from the private key.
+// It is empty once the key has been zeroized.
+func (s *PrivateKeySigner) PublicKey() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.key == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.key.PubKey().SerializeUncompressed())
+}
+
+// Sign generates an RFC 6979 deterministic ECDSA signature over sha256(message). The
+// message is signed verbatim, so a transaction ID must be passed in its canonical
+// lowercase, unprefixed form.
+func (s *PrivateKeySigner) Sign(message string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.key == nil {
+		return "", ErrKeyZeroized
+	}
+	hash := sha256.Sum256([]byte(message))
+	signature := ecdsa.Sign(s.key, hash[:])
+	return hex.EncodeToString(signature.Serialize()), nil
+}
+
+// Zeroize overwrites the private key in memory and drops it; Sign fails with
+// ErrKeyZeroized afterwards. Zeroizing twice is harmless.
+func (s *PrivateKeySigner) Zeroize() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key != nil {
+		s.key.Zero()
+		s.key = nil
+	}
+}
+
+// VerifySignature checks that signatureHex is a valid DER-encoded signature over
+// sha256(message) produced by the private key matching publicKeyHex.
+//
+// Parameters:
+//   - publicKeyHex: The signer's public key in hexadecimal format (compressed or uncompressed).
+//   - message: The message that was signed.
+//   - signatureHex: The DER-encoded signature in hexadecimal format.
+//
+// Returns:
+//
+//	`true` if the signature is valid, and `false` if it is invalid or any input cannot be decoded.
+func VerifySignature(publicKeyHex string, message string, signatureHex string) bool {
+	publicKeyBytes, err := hex.DecodeString(helpers.HexFix(publicKeyHex))
+	if err != nil {
+		return false
+	}
+	publicKey, err := secp256k1.ParsePubKey(publicKeyBytes)
+	if err != nil {
+		return false
+	}
+	signatureBytes, err := hex.DecodeString(helpers.HexFix(signatureHex))
+	if err != nil {
+		return false
+	}
+	signature, err := ecdsa.ParseDERSignature(signatureBytes)
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256([]byte(message))
+	return signature.Verify(hash[:], publicKey)
+}
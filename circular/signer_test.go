@@ -0,0 +1,79 @@
+package circular
+
+import (
+	"strings"
+	"testing"
+)
+
+const testPrivateKey = "0x1c7a1a6f9a1b0b4b2b8d25d22c1f1e53f0ad1ad7f3b0a5fd44b99a0b33e97a01"
+
+func TestNewPrivateKeySigner(t *testing.T) {
+	testCases := []struct {
+		name        string
+		key         string
+		expectError bool
+	}{
+		{name: "prefixed", key: testPrivateKey, expectError: false},
+		{name: "unprefixed", key: strings.TrimPrefix(testPrivateKey, "0x"), expectError: false},
+		{name: "invalid hex", key: "zz", expectError: true},
+		{name: "wrong length", key: "0102", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := NewPrivateKeySigner(tc.key)
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(signer.PublicKey()) != 130 {
+				t.Errorf("Expected a 65-byte uncompressed public key, but got %s", signer.PublicKey())
+			}
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	signer, err := NewPrivateKeySigner(testPrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	signature, err := signer.Sign("message")
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	again, _ := signer.Sign("message")
+	if signature != again {
+		t.Error("Expected deterministic signatures for the same message")
+	}
+	if !VerifySignature(signer.PublicKey(), "message", signature) {
+		t.Error("Expected signature to verify")
+	}
+	if VerifySignature(signer.PublicKey(), "other message", signature) {
+		t.Error("Expected signature over a different message to be rejected")
+	}
+	if VerifySignature("not-hex", "message", signature) {
+		t.Error("Expected invalid public key to be rejected")
+	}
+}
+
+func TestGeneratePrivateKey(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("GeneratePrivateKey() error = %v", err)
+	}
+	other, _ := GeneratePrivateKey()
+	if len(key) != 64 || key == other {
+		t.Errorf("Expected distinct 32-byte hex keys, got %s and %s", key, other)
+	}
+	if _, err := NewPrivateKeySigner(key); err != nil {
+		t.Errorf("Expected a usable key, got %v", err)
+	}
+}
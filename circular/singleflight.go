@@ -0,0 +1,67 @@
+package circular
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls with the same key into one execution whose
+// result every caller receives, so that a burst of goroutines refreshing the nonce
+// makes a single request to the gateway. The zero value is ready to use.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is an execution in progress, or just completed, in a flightGroup.
+type flight struct {
+	done chan struct{} // Closed when err is set.
+	err  error
+}
+
+// do runs fn once for all callers that ask for key while it runs. fn runs on its own
+// goroutine with the values and deadline of the ctx that started it, but not its
+// cancellation, so a caller that gives up, such as a goroutine of an errgroup cancelled
+// by a sibling's failure, does not fail the callers still waiting.
+//
+// Parameters:
+//   - ctx: Stops this caller's wait when done; also supplies fn's values if it starts fn.
+//   - key: Identifies the work; callers with equal keys share one execution.
+//   - fn: The work.
+//
+// Returns:
+//
+//	Whether the execution was started by another caller, and fn's error, or ctx's error
+//	if ctx is done first.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) error) (shared bool, err error) {
+	g.mu.Lock()
+	f, shared := g.flights[key]
+	if !shared {
+		if g.flights == nil {
+			g.flights = make(map[string]*flight)
+		}
+		f = &flight{done: make(chan struct{})}
+		g.flights[key] = f
+		go func() {
+			fctx := context.WithoutCancel(ctx)
+			if deadline, ok := ctx.Deadline(); ok {
+				var cancel context.CancelFunc
+				fctx, cancel = context.WithDeadline(fctx, deadline)
+				defer cancel()
+			}
+			f.err = fn(fctx)
+			g.mu.Lock()
+			delete(g.flights, key)
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return shared, f.err
+	case <-ctx.Done():
+		return shared, ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUpdateAccountCoalesced(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":9}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	const n = 20
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !acc.UpdateAccount() {
+				failed.Add(1)
+			}
+		}()
+	}
+	// Give every goroutine time to join the fetch before the gateway answers.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected %d concurrent updates to share 1 request, got %d", n, got)
+	}
+	if failed.Load() != 0 || acc.Nonce != 10 {
+		t.Errorf("Expected every update to succeed with nonce 10, got %d failures and nonce %d", failed.Load(), acc.Nonce)
+	}
+
+	// Once the fetch completes, the next update makes a request of its own.
+	if !acc.UpdateAccount() || calls.Load() != 2 {
+		t.Errorf("Expected a later update to fetch again, got %d requests", calls.Load())
+	}
+}
+
+func TestUpdateAccountOnCancelledCaller(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":3}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancelled := make(chan error)
+	go func() { cancelled <- acc.UpdateAccountOn(ctx, "0x1234") }()
+	waiting := make(chan error)
+	go func() { waiting <- acc.UpdateAccountOn(t.Context(), "0x1234") }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to stop waiting, got %v", err)
+	}
+	close(release)
+	if err := <-waiting; err != nil {
+		t.Errorf("Expected the other caller to receive the nonce, got %v", err)
+	}
+	if state, ok := acc.ChainState("0x1234"); !ok || state.Nonce != 4 || calls.Load() != 1 {
+		t.Errorf("Expected nonce 4 from one request, got %+v after %d requests", state, calls.Load())
+	}
+}
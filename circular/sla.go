@@ -0,0 +1,307 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of SLAConfig.
+const (
+	DefaultSLAWindow  = 15 * time.Minute // How far back confirmation samples are kept.
+	DefaultSLASamples = 1000             // The most samples kept per network.
+)
+
+// SLAConfig configures the tracking of confirmation times and success rates enabled
+// by SetSLATracking.
+type SLAConfig struct {
+	Window     time.Duration // Samples older than this are dropped; DefaultSLAWindow if zero.
+	MaxSamples int           // The most recent samples kept per network; DefaultSLASamples if zero.
+	Objective  *SLAObjective // The objective each network is checked against; may be nil.
+}
+
+// SLAObjective is a service level objective for transaction confirmation, such as
+// "95% of transactions confirmed within 30s, and 99% executed".
+type SLAObjective struct {
+	Percentile  float64       // The share of confirmations, between 0 and 1, that must be within MaxLatency.
+	MaxLatency  time.Duration // The confirmation time the Percentile must stay within.
+	SuccessRate float64       // The minimum share, between 0 and 1, of outcomes that must be executed.
+}
+
+// NetworkSLA summarizes the confirmations on one network within the SLA window.
+// Confirmation time is measured from the start of outcome polling, which SubmitAndWait
+// begins as soon as the transaction is accepted.
+type NetworkSLA struct {
+	Samples      int           // Outcomes waited for within the window.
+	Executed     int           // Outcomes finalized with status "Executed".
+	Failed       int           // Outcomes finalized with another status, or not obtained in time.
+	SuccessRate  float64       // Executed divided by Samples.
+	P50          time.Duration // Median confirmation time of the executed transactions.
+	P90          time.Duration // 90th percentile confirmation time.
+	P95          time.Duration // 95th percentile confirmation time.
+	P99          time.Duration // 99th percentile confirmation time.
+	ObjectiveMet bool          // Whether the window meets SLAConfig.Objective; true without an objective.
+}
+
+// AccountStats is a snapshot of an account's activity, for export as metrics.
+type AccountStats struct {
+	Polling      map[string]PollingStats // Outcome polling totals, by network; see PollingStats.
+	SLA          map[string]NetworkSLA   // Rolling confirmation statistics, by network; empty unless SetSLATracking is on.
+	Backpressure Backpressure            // Gateway throttling; see Backpressure.
+	NonceWatch   NonceWatchStats         // Nonce watch totals; see WatchNonce.
+	Label        string                  // The account's label, if any; see SetLabel.
+	Nodes        []NodeStats             // Call totals of the network's known gateway nodes; see NodeStats.
+	Results      map[ResultCode]int64    // Gateway calls answered with a result code other than ResultOK, by code.
+}
+
+// SetSLATracking turns the tracking of confirmation times and success rates on or off.
+// While on, every outcome the account waits for is sampled under its network (the
+// NetworkNode, or the NAG URL when no network node is set), and Stats and
+// WriteSLAMetrics report rolling percentiles and success rates over the recent samples.
+// Outcomes served from the outcome cache and waits abandoned by the caller are not
+// sampled. Changing the configuration keeps the samples already taken.
+//
+// Parameters:
+//   - cfg: The window, sample limit and objective; nil turns tracking off and discards
+//     the samples.
+func (a *CEPAccount) SetSLATracking(cfg *SLAConfig) {
+	a.sla.mu.Lock()
+	defer a.sla.mu.Unlock()
+	if cfg == nil {
+		a.sla.cfg = nil
+		a.sla.samples = nil
+		return
+	}
+	c := *cfg
+	if c.Window <= 0 {
+		c.Window = DefaultSLAWindow
+	}
+	if c.MaxSamples <= 0 {
+		c.MaxSamples = DefaultSLASamples
+	}
+	a.sla.cfg = &c
+}
+
+// Stats returns a snapshot of the account's activity: outcome polling totals, rolling
+// SLA statistics, backpressure and nonce watch totals. The result is a copy and safe
+// to retain.
+func (a *CEPAccount) Stats() AccountStats {
+	return AccountStats{
+		Polling:      a.PollingStats(),
+		SLA:          a.sla.snapshot(time.Now()),
+		Backpressure: a.Backpressure(),
+		NonceWatch:   a.NonceWatchStats(),
+		Label:        a.Label(),
+		Nodes:        a.NodeStats(),
+		Results:      a.results.snapshot(),
+	}
+}
+
+// WriteSLAMetrics writes the account's rolling SLA statistics in the Prometheus text
+// exposition format, for serving from a metrics endpoint or a textfile collector:
+// circular_confirmation_seconds (a summary with quantile labels),
+// circular_outcome_success_ratio, circular_outcomes (by result) and, with an objective,
+// circular_sla_objective_met, each labelled with the network and, if the account has a
+// label, with it as account. Once the network's gateway nodes are known,
+// circular_node_calls (by result) and circular_node_active are written for each node,
+// labelled with its ID as node. Once a gateway call has failed with a NAG result code,
+// circular_gateway_results counts the failures by code, labelled with the code's name as
+// result and its number as code.
+//
+// Parameters:
+//   - w: The writer to write to.
+//
+// Returns:
+//
+//	An error if writing fails.
+func (a *CEPAccount) WriteSLAMetrics(w io.Writer) error {
+	stats := a.sla.snapshot(time.Now())
+	networks := make([]string, 0, len(stats))
+	for network := range stats {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	a.sla.mu.Lock()
+	objective := a.sla.cfg != nil && a.sla.cfg.Objective != nil
+	a.sla.mu.Unlock()
+	label := a.Label()
+	// labels returns the label set of the network's series, with extra labels appended.
+	labels := func(network string, extra ...string) string {
+		set := fmt.Sprintf("network=%q", network)
+		if label != "" {
+			set += fmt.Sprintf(",account=%q", label)
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			set += fmt.Sprintf(",%s=%q", extra[i], extra[i+1])
+		}
+		return set
+	}
+
+	var b strings.Builder
+	b.WriteString("# HELP circular_confirmation_seconds Time from the start of outcome polling to execution, over the SLA window.\n")
+	b.WriteString("# TYPE circular_confirmation_seconds summary\n")
+	for _, network := range networks {
+		s := stats[network]
+		for _, q := range []struct {
+			quantile string
+			value    time.Duration
+		}{{"0.5", s.P50}, {"0.9", s.P90}, {"0.95", s.P95}, {"0.99", s.P99}} {
+			fmt.Fprintf(&b, "circular_confirmation_seconds{%s} %g\n", labels(network, "quantile", q.quantile), q.value.Seconds())
+		}
+		fmt.Fprintf(&b, "circular_confirmation_seconds_count{%s} %d\n", labels(network), s.Executed)
+	}
+	b.WriteString("# HELP circular_outcome_success_ratio Share of outcomes executed, over the SLA window.\n")
+	b.WriteString("# TYPE circular_outcome_success_ratio gauge\n")
+	for _, network := range networks {
+		fmt.Fprintf(&b, "circular_outcome_success_ratio{%s} %g\n", labels(network), stats[network].SuccessRate)
+	}
+	b.WriteString("# HELP circular_outcomes Outcomes waited for over the SLA window, by result.\n")
+	b.WriteString("# TYPE circular_outcomes gauge\n")
+	for _, network := range networks {
+		fmt.Fprintf(&b, "circular_outcomes{%s} %d\n", labels(network, "result", "executed"), stats[network].Executed)
+		fmt.Fprintf(&b, "circular_outcomes{%s} %d\n", labels(network, "result", "failed"), stats[network].Failed)
+	}
+	if objective {
+		b.WriteString("# HELP circular_sla_objective_met Whether the SLA window meets the configured objective.\n")
+		b.WriteString("# TYPE circular_sla_objective_met gauge\n")
+		for _, network := range networks {
+			met := 0
+			if stats[network].ObjectiveMet {
+				met = 1
+			}
+			fmt.Fprintf(&b, "circular_sla_objective_met{%s} %d\n", labels(network), met)
+		}
+	}
+	if nodes := a.NodeStats(); len(nodes) > 0 {
+		network := a.view().NetworkNode
+		b.WriteString("# HELP circular_node_calls NAG calls made through each gateway node, by result.\n")
+		b.WriteString("# TYPE circular_node_calls counter\n")
+		for _, node := range nodes {
+			fmt.Fprintf(&b, "circular_node_calls{%s} %d\n", labels(network, "node", node.Node.ID, "result", "ok"), node.Calls-node.Failures)
+			fmt.Fprintf(&b, "circular_node_calls{%s} %d\n", labels(network, "node", node.Node.ID, "result", "failed"), node.Failures)
+		}
+		b.WriteString("# HELP circular_node_active Whether the account's NAG calls go through the gateway node.\n")
+		b.WriteString("# TYPE circular_node_active gauge\n")
+		for _, node := range nodes {
+			active := 0
+			if node.Active {
+				active = 1
+			}
+			fmt.Fprintf(&b, "circular_node_active{%s} %d\n", labels(network, "node", node.Node.ID), active)
+		}
+	}
+	if results := a.results.snapshot(); len(results) > 0 {
+		network := a.view().NetworkNode
+		codes := slices.Sorted(maps.Keys(results))
+		b.WriteString("# HELP circular_gateway_results NAG calls answered with a result code other than OK, by result code.\n")
+		b.WriteString("# TYPE circular_gateway_results counter\n")
+		for _, code := range codes {
+			fmt.Fprintf(&b, "circular_gateway_results{%s} %d\n", labels(network, "result", code.String(), "code", strconv.Itoa(int(code))), results[code])
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sampleOutcome records in the SLA tracker the result of waiting for an outcome on the
+// account's network: the outcome's status if it was obtained, or the error otherwise.
+func (a *CEPAccount) sampleOutcome(status string, wait time.Duration, err error) {
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, ErrTransactionAbandoned)) {
+		// The caller gave up; the gateway is not at fault.
+		return
+	}
+	a.sla.record(a.networkLabel(), slaSample{at: time.Now(), wait: wait, executed: err == nil && status == "Executed"})
+}
+
+// slaSample is one outcome sampled by an slaTracker.
+type slaSample struct {
+	at       time.Time
+	wait     time.Duration
+	executed bool
+}
+
+// slaTracker keeps recent outcome samples per network. It is safe for concurrent use.
+type slaTracker struct {
+	mu      sync.Mutex
+	cfg     *SLAConfig // nil while tracking is off.
+	samples map[string][]slaSample
+}
+
+// record adds a sample on network, if tracking is on.
+func (t *slaTracker) record(network string, s slaSample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cfg == nil {
+		return
+	}
+	if t.samples == nil {
+		t.samples = make(map[string][]slaSample)
+	}
+	samples := append(t.samples[network], s)
+	if len(samples) > t.cfg.MaxSamples {
+		samples = append(samples[:0], samples[len(samples)-t.cfg.MaxSamples:]...)
+	}
+	t.samples[network] = samples
+}
+
+// snapshot summarizes the samples within the window ending at now, by network.
+func (t *slaTracker) snapshot(now time.Time) map[string]NetworkSLA {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]NetworkSLA)
+	if t.cfg == nil {
+		return out
+	}
+	for network, samples := range t.samples {
+		// Samples are in time order; drop those that have left the window.
+		i := sort.Search(len(samples), func(i int) bool { return now.Sub(samples[i].at) <= t.cfg.Window })
+		samples = samples[i:]
+		t.samples[network] = samples
+		if len(samples) == 0 {
+			delete(t.samples, network)
+			continue
+		}
+		out[network] = summarize(samples, t.cfg.Objective)
+	}
+	return out
+}
+
+// summarize computes the statistics of samples against objective, which may be nil.
+func summarize(samples []slaSample, objective *SLAObjective) NetworkSLA {
+	s := NetworkSLA{Samples: len(samples)}
+	var waits []time.Duration
+	for _, sample := range samples {
+		if sample.executed {
+			waits = append(waits, sample.wait)
+		}
+	}
+	slices.Sort(waits)
+	s.Executed = len(waits)
+	s.Failed = s.Samples - s.Executed
+	s.SuccessRate = float64(s.Executed) / float64(s.Samples)
+	s.P50 = percentile(waits, 0.5)
+	s.P90 = percentile(waits, 0.9)
+	s.P95 = percentile(waits, 0.95)
+	s.P99 = percentile(waits, 0.99)
+	s.ObjectiveMet = objective == nil ||
+		s.SuccessRate >= objective.SuccessRate && percentile(waits, objective.Percentile) <= objective.MaxLatency
+	return s
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted, or zero if it is empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
@@ -0,0 +1,114 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSLATracking(t *testing.T) {
+	status := "Executed"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprintf(w, `{"Result":200,"Response":{"Status":%q}}`, status)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.NetworkNode = "testnet"
+	acc.Open("0xabcdef")
+	acc.SetAdaptivePolling(&AdaptivePolling{MinInterval: time.Millisecond})
+	acc.polling.record(acc.networkLabel(), &OutcomeStats{Finalized: true, TotalWait: time.Millisecond})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	// Nothing is sampled until tracking is on.
+	if _, err := acc.SubmitAndWait(t.Context(), "first", signer); err != nil {
+		t.Fatal(err)
+	}
+	if sla := acc.Stats().SLA; len(sla) != 0 {
+		t.Errorf("Expected no samples while tracking is off, got %+v", sla)
+	}
+
+	acc.SetSLATracking(&SLAConfig{Objective: &SLAObjective{Percentile: 0.95, MaxLatency: time.Minute, SuccessRate: 0.9}})
+	for i := range 3 {
+		if _, err := acc.SubmitAndWait(t.Context(), fmt.Sprint("executed ", i), signer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status = "Failed"
+	if _, err := acc.SubmitAndWait(t.Context(), "failed", signer); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := acc.Stats()
+	sla := stats.SLA["testnet"]
+	if sla.Samples != 4 || sla.Executed != 3 || sla.Failed != 1 || sla.SuccessRate != 0.75 {
+		t.Errorf("Expected 3 of 4 outcomes executed, got %+v", sla)
+	}
+	if sla.P50 <= 0 || sla.P99 < sla.P50 || sla.ObjectiveMet {
+		t.Errorf("Expected percentiles and a missed success objective, got %+v", sla)
+	}
+	if stats.Polling["testnet"].Outcomes < 5 {
+		t.Errorf("Expected the polling totals in the stats, got %+v", stats.Polling)
+	}
+
+	var b strings.Builder
+	if err := acc.WriteSLAMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE circular_confirmation_seconds summary",
+		`circular_confirmation_seconds_count{network="testnet"} 3`,
+		`circular_outcome_success_ratio{network="testnet"} 0.75`,
+		`circular_outcomes{network="testnet",result="failed"} 1`,
+		`circular_sla_objective_met{network="testnet"} 0`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, b.String())
+		}
+	}
+
+	acc.SetSLATracking(nil)
+	if sla := acc.Stats().SLA; len(sla) != 0 {
+		t.Errorf("Expected turning tracking off to discard the samples, got %+v", sla)
+	}
+}
+
+func TestSLAWindow(t *testing.T) {
+	var tracker slaTracker
+	tracker.cfg = &SLAConfig{Window: time.Minute, MaxSamples: 3}
+	now := time.Now()
+	for i, wait := range []time.Duration{50, 10, 20, 30, 40} {
+		tracker.record("mainnet", slaSample{at: now.Add(time.Duration(i-4) * 35 * time.Second), wait: wait * time.Millisecond, executed: true})
+	}
+	// Only 3 samples are kept, and the oldest of those has left the window.
+	sla := tracker.snapshot(now)["mainnet"]
+	if sla.Samples != 2 || sla.P50 != 30*time.Millisecond || sla.P99 != 40*time.Millisecond || !sla.ObjectiveMet {
+		t.Errorf("Expected the 2 samples in the window, got %+v", sla)
+	}
+	if got := tracker.snapshot(now.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("Expected every sample to expire, got %+v", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, expected := range map[float64]time.Duration{0: 1, 0.5: 5, 0.9: 9, 0.95: 10, 1: 10} {
+		if got := percentile(sorted, p); got != expected {
+			t.Errorf("percentile(%g) = %d, expected %d", p, got, expected)
+		}
+	}
+	if percentile(nil, 0.5) != 0 {
+		t.Error("Expected zero for no samples")
+	}
+}
@@ -0,0 +1,114 @@
+package circular
+
+import (
+	"net/http"
+	"time"
+)
+
+// AccountState is a snapshot of an account's exported fields, taken atomically.
+type AccountState struct {
+	Address     string // The blockchain address of the account.
+	PublicKey   string // The public key associated with the account.
+	CodeVersion string // The version of the client library being used.
+	LastError   string // The last encountered error message.
+	NAGURL      string // The URL of the Network Access Gateway.
+	NetworkNode string // Identifier for the network being used.
+	Blockchain  string // The identifier of the blockchain being interacted with.
+	LatestTxID  string // The ID of the most recently submitted transaction.
+	Nonce       int64  // The nonce the next transaction will use.
+	IntervalSec int    // The polling interval in seconds for outcome checks.
+	NetworkURL  string // The base URL for discovering network access gateways.
+}
+
+// State returns a consistent snapshot of the account's exported fields. Unlike reading
+// the fields directly, it is safe to call while other goroutines use the account.
+//
+// Returns:
+//
+//	A copy of the exported fields, all taken at the same instant.
+func (a *CEPAccount) State() AccountState {
+	return a.view().AccountState
+}
+
+// accountView is a consistent copy of the account fields an operation reads. Operations
+// take one view up front rather than reading fields as they go, so that a concurrent
+// SetNetwork or Close cannot leave a request half addressed to the old configuration.
+type accountView struct {
+	AccountState
+
+	permissions *AccountPermissions
+	schemas     *SchemaRegistry
+	retryPolicy *RetryPolicy
+	appName     string
+	userAgent   string
+	adaptive    *AdaptivePolling
+	receipts    ReceiptStore
+	devMode     bool
+	nagPath     string
+	nagProto    string
+	readToken   string
+	chainCheck  bool
+	logLevel    LogLevel
+	logger      Logger
+	notFound    time.Duration
+	guard       Guard
+	policy      Policy
+	node        *NodeClient
+	client      *http.Client
+	shared      *RateLimiter
+	deadlines   Deadlines
+	clock       Clock
+	label       string
+	scanner     Scanner
+	signer      Signer
+	maxResponse int64
+	session     uint64
+}
+
+// view returns a consistent copy of the account's fields under the read lock.
+func (a *CEPAccount) view() accountView {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return accountView{
+		AccountState: AccountState{
+			Address:     a.Address,
+			PublicKey:   a.PublicKey,
+			CodeVersion: a.CodeVersion,
+			LastError:   a.LastError,
+			NAGURL:      a.NAGURL,
+			NetworkNode: a.NetworkNode,
+			Blockchain:  a.Blockchain,
+			LatestTxID:  a.LatestTxID,
+			Nonce:       a.Nonce,
+			IntervalSec: a.IntervalSec,
+			NetworkURL:  a.NetworkURL,
+		},
+		permissions: a.permissions,
+		schemas:     a.schemas,
+		retryPolicy: a.retryPolicy,
+		appName:     a.appName,
+		userAgent:   a.userAgent,
+		adaptive:    a.adaptive,
+		receipts:    a.receipts,
+		devMode:     a.devMode,
+		nagPath:     a.nagPath,
+		nagProto:    a.nagProto,
+		readToken:   a.readToken,
+		chainCheck:  a.chainCheck,
+		logLevel:    a.logLevel,
+		logger:      a.logger,
+		notFound:    a.notFound,
+		guard:       a.guard,
+		policy:      a.policy,
+		node:        a.node,
+		client:      a.client,
+		shared:      a.shared,
+		deadlines:   a.deadlines,
+		clock:       a.clock,
+		label:       a.label,
+		scanner:     a.scanner,
+		signer:      a.signer,
+		maxResponse: a.maxResponse,
+		session:     a.session,
+	}
+}
@@ -0,0 +1,75 @@
+package circular
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAccountIsSafeForConcurrentUse(t *testing.T) {
+	var mu sync.Mutex
+	var nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			mu.Lock()
+			nonces = append(nonces, req["Nonce"])
+			mu.Unlock()
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		case strings.Contains(r.URL.String(), "Circular_GetTransactionbyID_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":0}}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	if !acc.UpdateAccount() {
+		t.Fatalf("UpdateAccount() failed: %s", acc.GetLastError())
+	}
+
+	const submissions = 10
+	var wg sync.WaitGroup
+	for i := 0; i < submissions; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			acc.SubmitCertificate("data", testPrivateKey)
+		}()
+		go func() {
+			defer wg.Done()
+			acc.GetTransaction("1", "0x01")
+			_ = acc.State()
+			_ = acc.GetLastError()
+		}()
+		go func() {
+			defer wg.Done()
+			name := []string{"alpha", "beta"}[i%2]
+			if err := acc.SetNetworkProfile(NetworkProfile{Name: name, BaseURL: server.URL + "/?cep="}); err != nil {
+				t.Errorf("SetNetworkProfile() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(nonces) != submissions {
+		t.Fatalf("Expected %d submissions, got %d (last error: %s)", submissions, len(nonces), acc.GetLastError())
+	}
+	for i, nonce := range nonces {
+		if want := fmt.Sprintf("%d", i+1); nonce != want {
+			t.Fatalf("Expected each submission to use the next nonce, got %v", nonces)
+		}
+	}
+	if state := acc.State(); state.Nonce != submissions+1 || state.LatestTxID == "" {
+		t.Errorf("Unexpected state after submissions: %+v", state)
+	}
+}
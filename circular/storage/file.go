@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// File is a KV that keeps each value in its own file in a directory, so state survives
+// restarts without a database. Keys are path-escaped to form file names, and values are
+// replaced atomically by writing a temporary file and renaming it into place.
+type File struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewFile creates a File store in dir, creating the directory if needed.
+//
+// Parameters:
+//   - dir: The directory holding the values.
+//
+// Returns:
+//
+//	The store, or an error if the directory cannot be created.
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("storage: failed to create %s: %w", dir, err)
+	}
+	return &File{dir: dir}, nil
+}
+
+func (f *File) path(key string) string {
+	return filepath.Join(f.dir, fileName(key))
+}
+
+// fileName returns the name of the file holding key. url.PathEscape leaves "." and
+// "..", which name the directory itself and its parent, unchanged, so their dots are
+// escaped as well; url.PathUnescape restores them.
+func fileName(key string) string {
+	if key == "." || key == ".." {
+		return strings.ReplaceAll(key, ".", "%2E")
+	}
+	return url.PathEscape(key)
+}
+
+// Get reads the value stored under key.
+func (f *File) Get(key string) ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	value, err := os.ReadFile(f.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to read %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// Put writes value under key, replacing any previous value atomically.
+func (f *File) Put(key string, value []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the file holding key.
+func (f *File) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete %q: %w", key, err)
+	}
+	return nil
+}
+
+// Keys returns the keys beginning with prefix, in ascending order.
+func (f *File) Keys(prefix string) ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to list %s: %w", f.dir, err)
+	}
+	var keys []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			continue
+		}
+		key, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
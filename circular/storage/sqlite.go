@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// tableNamePattern restricts table names, which cannot be passed as query parameters.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLite is a KV stored in a single table of an SQLite database. The SDK does not
+// import a driver: open db with the driver of your choice (e.g. mattn/go-sqlite3 or
+// modernc.org/sqlite) and pass it in. The same statements work unchanged on other
+// databases that accept "?" placeholders and ON CONFLICT upserts.
+type SQLite struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLite creates the table if it does not exist and returns a KV backed by it.
+//
+// Parameters:
+//   - db: An open SQLite database.
+//   - table: The table to store values in; it must be a plain SQL identifier.
+//
+// Returns:
+//
+//	The store, or an error if the table name is invalid or the table cannot be created.
+func NewSQLite(db *sql.DB, table string) (*SQLite, error) {
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("storage: invalid table name %q", table)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (key TEXT PRIMARY KEY, value BLOB NOT NULL)`); err != nil {
+		return nil, fmt.Errorf("storage: failed to create table %s: %w", table, err)
+	}
+	return &SQLite{db: db, table: table}, nil
+}
+
+// Get returns the value stored under key.
+func (s *SQLite) Get(key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRow(`SELECT value FROM `+s.table+` WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to read %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// Put creates or replaces the row for key.
+func (s *SQLite) Put(key string, value []byte) error {
+	_, err := s.db.Exec(`INSERT INTO `+s.table+` (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+	if err != nil {
+		return fmt.Errorf("storage: failed to write %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the row for key.
+func (s *SQLite) Delete(key string) error {
+	if _, err := s.db.Exec(`DELETE FROM `+s.table+` WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("storage: failed to delete %q: %w", key, err)
+	}
+	return nil
+}
+
+// Keys returns the keys beginning with prefix, in ascending order. The prefix is
+// matched as a range of keys rather than with substr, which counts characters where
+// len(prefix) counts bytes.
+func (s *SQLite) Keys(prefix string) ([]string, error) {
+	query, args := `SELECT key FROM `+s.table+` WHERE key >= ?`, []any{prefix}
+	if end, ok := prefixEnd(prefix); ok {
+		query += ` AND key < ?`
+		args = append(args, end)
+	}
+	rows, err := s.db.Query(query+` ORDER BY key`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to list keys: %w", err)
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("storage: failed to list keys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// prefixEnd returns the least string greater than every string beginning with prefix,
+// in byte order, as SQLite compares text by default; ok is false if there is none.
+func prefixEnd(prefix string) (end string, ok bool) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			return prefix[:i] + string([]byte{prefix[i] + 1}), true
+		}
+	}
+	return "", false
+}
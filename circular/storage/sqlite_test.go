@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The module imports no SQLite driver, so the tests run SQLite through its command-line
+// shell with sqliteShell, a database/sql driver that inlines the arguments of each
+// statement as SQL literals. They are skipped where the shell is not installed.
+func init() {
+	sql.Register("sqlite3-shell", sqliteShell{})
+}
+
+func TestSQLite(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+	db, err := sql.Open("sqlite3-shell", filepath.Join(t.TempDir(), "kv.db"))
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+	if _, err := NewSQLite(db, "bad name"); err == nil {
+		t.Error("Expected an invalid table name to be refused")
+	}
+	kv, err := NewSQLite(db, "kv")
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	testKV(t, kv)
+
+	if err := kv.Put("\xff\xff", []byte("x")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if keys, err := kv.Keys("\xff"); err != nil || len(keys) != 1 || keys[0] != "\xff\xff" {
+		t.Errorf("Expected a prefix with no upper bound to match, got %q, %v", keys, err)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	for prefix, want := range map[string]string{"b/": "b0", "é": "ê", "a\xff": "b", "\xff\xff": ""} {
+		if end, ok := prefixEnd(prefix); end != want || ok != (want != "") {
+			t.Errorf("prefixEnd(%q) = %q, %v; want %q", prefix, end, ok, want)
+		}
+	}
+}
+
+// sqliteShell is a database/sql driver running each statement with the sqlite3 shell.
+type sqliteShell struct{}
+
+func (sqliteShell) Open(path string) (driver.Conn, error) { return shellConn(path), nil }
+
+type shellConn string
+
+func (c shellConn) Prepare(query string) (driver.Stmt, error) {
+	return shellStmt{path: string(c), query: query}, nil
+}
+
+func (shellConn) Close() error { return nil }
+
+func (shellConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions are not supported") }
+
+type shellStmt struct {
+	path, query string
+}
+
+func (shellStmt) Close() error  { return nil }
+func (shellStmt) NumInput() int { return -1 }
+
+func (s shellStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.run(args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s shellStmt) Query(args []driver.Value) (driver.Rows, error) {
+	out, err := s.run(args)
+	if err != nil {
+		return nil, err
+	}
+	rows := &shellRows{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		value, err := parseLiteral(line)
+		if err != nil {
+			return nil, err
+		}
+		rows.values = append(rows.values, value)
+	}
+	return rows, nil
+}
+
+// run executes the statement with args inlined, printing each row as an SQL literal.
+// The statements of SQLite select at most one column, so each line holds one value.
+func (s shellStmt) run(args []driver.Value) ([]byte, error) {
+	var query strings.Builder
+	for _, part := range strings.Split(s.query, "?") {
+		query.WriteString(part)
+		if len(args) > 0 {
+			query.WriteString(literal(args[0]))
+			args = args[1:]
+		}
+	}
+	cmd := exec.Command("sqlite3", "-bail", "-cmd", ".mode quote", s.path)
+	cmd.Stdin = strings.NewReader(query.String() + ";\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil || stderr.Len() > 0 {
+		return nil, fmt.Errorf("sqlite3: %v: %s", err, stderr.String())
+	}
+	return out, nil
+}
+
+// literal formats v as SQL; strings become text, byte slices blobs.
+func literal(v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		// CAST keeps bytes that are not valid UTF-8 in the text.
+		return "CAST(X'" + hex.EncodeToString([]byte(v)) + "' AS TEXT)"
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		panic(fmt.Sprintf("unsupported argument %T", v))
+	}
+}
+
+// parseLiteral decodes a value printed by the shell's quote mode.
+func parseLiteral(s string) (driver.Value, error) {
+	switch {
+	case strings.HasPrefix(s, "X'"):
+		return hex.DecodeString(strings.TrimSuffix(s[2:], "'"))
+	case strings.HasPrefix(s, "'"):
+		return strings.ReplaceAll(strings.TrimSuffix(s[1:], "'"), "''", "'"), nil
+	}
+	return nil, fmt.Errorf("unexpected value %s", s)
+}
+
+type shellRows struct {
+	values []driver.Value
+}
+
+func (*shellRows) Columns() []string { return []string{"value"} }
+func (*shellRows) Close() error      { return nil }
+
+func (r *shellRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
@@ -0,0 +1,220 @@
+// Package storage defines the persistence interfaces shared by the SDK's stateful
+// subsystems, such as the receipt store, so that enterprises can back them with their
+// own databases. KV is a minimal byte-oriented store; DocumentStore layers named
+// collections of JSON documents on top of it. Memory, file and SQLite backed KV
+// implementations are provided.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when no value is stored under a key.
+var ErrNotFound = errors.New("storage: not found")
+
+// KV stores opaque values by string key. Implementations must be safe for concurrent use.
+type KV interface {
+	// Get returns the value stored under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Put creates or replaces the value stored under key.
+	Put(key string, value []byte) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// Keys returns every key beginning with prefix, in ascending order.
+	Keys(prefix string) ([]string, error)
+}
+
+// DocumentStore stores JSON documents by collection and ID. Implementations must be
+// safe for concurrent use.
+type DocumentStore interface {
+	// Save encodes doc as JSON and stores it as collection/id, replacing any previous version.
+	Save(collection, id string, doc interface{}) error
+	// Load decodes the document collection/id into doc, or returns ErrNotFound.
+	Load(collection, id string, doc interface{}) error
+	// Delete removes the document collection/id. Deleting a missing document is not an error.
+	Delete(collection, id string) error
+	// IDs returns the IDs of every document in collection, in ascending order.
+	IDs(collection string) ([]string, error)
+}
+
+// NewDocumentStore returns a DocumentStore that keeps each document in kv under the
+// key "<collection>/<id>".
+//
+// Parameters:
+//   - kv: The store holding the encoded documents.
+//
+// Returns:
+//
+//	A DocumentStore backed by kv.
+func NewDocumentStore(kv KV) DocumentStore {
+	return &kvDocumentStore{kv: kv}
+}
+
+type kvDocumentStore struct {
+	kv KV
+}
+
+func documentKey(collection, id string) (string, error) {
+	if collection == "" || strings.Contains(collection, "/") {
+		return "", fmt.Errorf("storage: invalid collection name %q", collection)
+	}
+	if id == "" {
+		return "", fmt.Errorf("storage: document ID cannot be empty")
+	}
+	return collection + "/" + id, nil
+}
+
+func (s *kvDocumentStore) Save(collection, id string, doc interface{}) error {
+	key, err := documentKey(collection, id)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("storage: failed to encode %s: %w", key, err)
+	}
+	return s.kv.Put(key, value)
+}
+
+func (s *kvDocumentStore) Load(collection, id string, doc interface{}) error {
+	key, err := documentKey(collection, id)
+	if err != nil {
+		return err
+	}
+	value, err := s.kv.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(value, doc); err != nil {
+		return fmt.Errorf("storage: failed to decode %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *kvDocumentStore) Delete(collection, id string) error {
+	key, err := documentKey(collection, id)
+	if err != nil {
+		return err
+	}
+	return s.kv.Delete(key)
+}
+
+func (s *kvDocumentStore) IDs(collection string) ([]string, error) {
+	prefix, err := documentKey(collection, "-")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSuffix(prefix, "-")
+	keys, err := s.kv.Keys(prefix)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = strings.TrimPrefix(key, prefix)
+	}
+	return ids, nil
+}
+
+// Namespace returns a KV that keeps its keys in kv under prefix, isolated from the rest
+// of kv, so that several users can share one store. Keys are returned without the prefix.
+//
+// Parameters:
+//   - kv: The store holding the namespace.
+//   - prefix: The prefix of the namespace's keys, e.g. "tenants/acme/".
+//
+// Returns:
+//
+//	A KV limited to the namespace.
+func Namespace(kv KV, prefix string) KV {
+	return &namespace{kv: kv, prefix: prefix}
+}
+
+type namespace struct {
+	kv     KV
+	prefix string
+}
+
+func (n *namespace) Get(key string) ([]byte, error) {
+	return n.kv.Get(n.prefix + key)
+}
+
+func (n *namespace) Put(key string, value []byte) error {
+	return n.kv.Put(n.prefix+key, value)
+}
+
+func (n *namespace) Delete(key string) error {
+	return n.kv.Delete(n.prefix + key)
+}
+
+func (n *namespace) Keys(prefix string) ([]string, error) {
+	keys, err := n.kv.Keys(n.prefix + prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, n.prefix)
+	}
+	return keys, nil
+}
+
+// Memory is an in-process KV. Values are lost when the process exits.
+type Memory struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+// NewMemory creates an empty Memory store.
+//
+// Returns:
+//
+//	A pointer to a newly initialized Memory store.
+func NewMemory() *Memory {
+	return &Memory{values: make(map[string][]byte)}
+}
+
+// Get returns a copy of the value stored under key.
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Put stores a copy of value under key.
+func (m *Memory) Put(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete removes key.
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+// Keys returns the keys beginning with prefix, in ascending order.
+func (m *Memory) Keys(prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []string
+	for key := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testKV exercises the KV contract against kv.
+func testKV(t *testing.T, kv KV) {
+	if _, err := kv.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	for _, key := range []string{"b/2", "a/1", "b/1", "c"} {
+		if err := kv.Put(key, []byte(key)); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+	if err := kv.Put("c", []byte("replaced")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if value, err := kv.Get("c"); err != nil || string(value) != "replaced" {
+		t.Errorf("Expected the replaced value, got %q (%v)", value, err)
+	}
+	if keys, _ := kv.Keys("b/"); !reflect.DeepEqual(keys, []string{"b/1", "b/2"}) {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+	if err := kv.Delete("b/1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := kv.Delete("b/1"); err != nil {
+		t.Errorf("Expected deleting a missing key to succeed, got %v", err)
+	}
+	if keys, _ := kv.Keys(""); !reflect.DeepEqual(keys, []string{"a/1", "b/2", "c"}) {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+
+	// Prefixes are matched byte for byte, and keys that mean something in paths are
+	// ordinary keys.
+	for _, key := range []string{"é/1", "e/1", "éa", ".", ".."} {
+		if err := kv.Put(key, []byte(key)); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+	if keys, _ := kv.Keys("é/"); !reflect.DeepEqual(keys, []string{"é/1"}) {
+		t.Errorf("Unexpected keys with a multi-byte prefix: %v", keys)
+	}
+	if keys, _ := kv.Keys("."); !reflect.DeepEqual(keys, []string{".", ".."}) {
+		t.Errorf("Unexpected dot keys: %v", keys)
+	}
+	for _, key := range []string{".", ".."} {
+		if value, err := kv.Get(key); err != nil || string(value) != key {
+			t.Errorf("Get(%q) = %q, %v", key, value, err)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testKV(t, NewMemory())
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	kv, err := NewFile(dir)
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+	testKV(t, kv)
+
+	for _, name := range []string{"%2E", "%2E%2E"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected a dot key to be stored inside the directory: %v", err)
+		}
+	}
+
+	reopened, _ := NewFile(dir)
+	if value, err := reopened.Get("a/1"); err != nil || string(value) != "a/1" {
+		t.Errorf("Expected values to persist, got %q (%v)", value, err)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	kv := NewMemory()
+	kv.Put("tenants/a", []byte("outside"))
+	testKV(t, Namespace(kv, "tenants/acme/"))
+
+	want := []string{"tenants/a", "tenants/acme/.", "tenants/acme/..", "tenants/acme/a/1", "tenants/acme/b/2",
+		"tenants/acme/c", "tenants/acme/e/1", "tenants/acme/é/1", "tenants/acme/éa"}
+	if keys, _ := kv.Keys(""); !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected the namespace's keys under its prefix, got %v", keys)
+	}
+}
+
+func TestDocumentStore(t *testing.T) {
+	type doc struct {
+		Name string `json:"name"`
+	}
+	docs := NewDocumentStore(NewMemory())
+
+	if err := docs.Save("receipts", "abc", doc{Name: "first"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	docs.Save("other", "xyz", doc{Name: "second"})
+
+	var loaded doc
+	if err := docs.Load("receipts", "abc", &loaded); err != nil || loaded.Name != "first" {
+		t.Errorf("Unexpected document %+v (%v)", loaded, err)
+	}
+	if ids, _ := docs.IDs("receipts"); !reflect.DeepEqual(ids, []string{"abc"}) {
+		t.Errorf("Unexpected IDs: %v", ids)
+	}
+	if err := docs.Load("receipts", "missing", &loaded); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := docs.Save("a/b", "abc", doc{}); err == nil {
+		t.Error("Expected a collection name containing a slash to be rejected")
+	}
+}
@@ -0,0 +1,216 @@
+package circular
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// GetTransactionData writes the certificate data of transaction txID to w without
+// holding the transaction in memory. The gateway's response is decoded as it arrives:
+// the hex-encoded payload envelope is unwrapped and its hex-encoded Data field is
+// decoded straight into w, so multi-megabyte certificates cost only a small buffer.
+// The transaction is searched for in the most recent blocks, as by outcome polling.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - txID: The transaction whose data to fetch.
+//   - w: Receives the decoded certificate data.
+//
+// Returns:
+//
+//	The number of bytes written to w, and an error if the request fails, the response
+//	holds no payload (e.g. the transaction was not found), or the payload is malformed.
+//	On error, w may have received part of the data, or all of it if only recording the
+//	read in the access log failed; see SetAccessLog.
+func (a *CEPAccount) GetTransactionData(ctx context.Context, txID string, w io.Writer) (int64, error) {
+	ctx = ensureRequestID(ctx)
+	v := a.view()
+	requestData := map[string]string{
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"ID":         helpers.HexFix(txID),
+		"Start":      "0",
+		"End":        "10",
+		"Version":    v.CodeVersion,
+	}
+
+	body, err := a.postNAGStream(ctx, "Circular_GetTransactionbyID_", requestData)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	payload, err := newHexFieldReader(bufio.NewReader(body), "Payload")
+	if err != nil {
+		return 0, fmt.Errorf("transaction %s has no payload in the response: %w", txID, err)
+	}
+	data, err := newHexFieldReader(bufio.NewReader(hex.NewDecoder(payload)), "Data")
+	if err != nil {
+		return 0, fmt.Errorf("transaction %s payload has no data: %w", txID, err)
+	}
+	n, err := io.Copy(w, hex.NewDecoder(data))
+	if err != nil {
+		return n, fmt.Errorf("failed to decode data of transaction %s: %w", txID, err)
+	}
+	return n, a.recordAccess(ctx, "GetTransactionData", txID, n)
+}
+
+// postNAGStream sends requestData to a NAG endpoint and returns the response body
+// unread. It honours any active backpressure pause but does not retry. The caller
+// must close the body.
+func (a *CEPAccount) postNAGStream(ctx context.Context, endpoint string, requestData interface{}) (io.ReadCloser, error) {
+	v := a.view()
+	if v.NAGURL == "" {
+		return nil, ErrNetworkNotSet
+	}
+
+	jsonData, err := json.Marshal(requestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	ctx = ensureRequestID(ctx)
+	requestID := RequestIDFromContext(ctx)
+	url, jsonData, err := v.requestTarget(endpoint, requestID, jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.checkCompatibility(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.pressure.wait(ctx); err != nil {
+		return nil, fmt.Errorf("gave up waiting for gateway backpressure (request %s): %w", requestID, err)
+	}
+	if err := a.waitRateLimits(ctx, v); err != nil {
+		return nil, fmt.Errorf("gave up waiting for the rate limit (request %s): %w", requestID, err)
+	}
+	req, err := a.newNAGRequest(ctx, url, jsonData, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logCall(ctx, LogDebug, "%s [%s]: Request URL: %s (streaming)\n", endpoint, requestID, url)
+
+	resp, err := v.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed (request %s): %w", requestID, err)
+	}
+
+	a.logCall(ctx, LogDebug, "%s [%s]: Response Status: %s\n", endpoint, requestID, resp.Status)
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen+1))
+		return nil, &APIError{
+			Endpoint:      endpoint,
+			HTTPStatus:    resp.StatusCode,
+			Body:          truncateBody(body),
+			RequestID:     resp.Header.Get(RequestIDHeader),
+			CorrelationID: requestID,
+		}
+	}
+	return resp.Body, nil
+}
+
+// hexFieldReader yields the raw contents of a JSON string value holding hex, read
+// directly from the underlying stream. Hex never needs escaping, so the value ends at
+// the first quote.
+type hexFieldReader struct {
+	r    *bufio.Reader
+	done bool
+}
+
+// newHexFieldReader advances r past the first occurrence of the key `"<key>":"` and
+// returns a reader over the string value that follows, without any "0x" prefix.
+func newHexFieldReader(r *bufio.Reader, key string) (*hexFieldReader, error) {
+	pattern := []byte(`"` + key + `"`)
+	matched := 0
+	for matched < len(pattern) {
+		b, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("field %q not found", key)
+			}
+			return nil, err
+		}
+		switch {
+		case b == pattern[matched]:
+			matched++
+		case b == pattern[0]:
+			matched = 1
+		default:
+			matched = 0
+		}
+	}
+
+	for _, want := range []byte{':', '"'} {
+		b, err := skipSpace(r)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", key, err)
+		}
+		if b != want {
+			return nil, fmt.Errorf("field %q is not a string", key)
+		}
+	}
+
+	if prefix, err := r.Peek(2); err == nil && (string(prefix) == "0x" || string(prefix) == "0X") {
+		r.Discard(2)
+	}
+	return &hexFieldReader{r: r}, nil
+}
+
+// Read implements io.Reader.
+func (f *hexFieldReader) Read(p []byte) (int, error) {
+	if f.done {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) {
+		b, err := f.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+		if b == '"' {
+			f.done = true
+			if n == 0 {
+				return 0, io.EOF
+			}
+			return n, nil
+		}
+		if b == '\\' {
+			return n, fmt.Errorf("unexpected escape sequence in hex value")
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
+
+// skipSpace returns the next non-whitespace byte of r.
+func skipSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return b, nil
+	}
+}
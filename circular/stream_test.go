@@ -0,0 +1,53 @@
+package circular
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestGetTransactionDataStreamsPayload(t *testing.T) {
+	data := strings.Repeat("large certificate content ", 40000)
+	envelope, _ := CanonicalJSON(map[string]string{"Action": certificateAction, "Data": helpers.StringToHex(data)})
+	payload := helpers.StringToHex(string(envelope))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Result":200,"Response":{"ID":"abc","Status":"Executed","Payload": "0x%s","Timestamp":"2024:01:02-03:04:05"}}`, payload)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+
+	var out bytes.Buffer
+	n, err := acc.GetTransactionData(t.Context(), "abc", &out)
+	if err != nil {
+		t.Fatalf("GetTransactionData() error = %v", err)
+	}
+	if n != int64(len(data)) || out.String() != data {
+		t.Errorf("Expected %d bytes of certificate data, got %d", len(data), n)
+	}
+}
+
+func TestGetTransactionDataNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":118,"Response":"Transaction Not Found"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+
+	var out bytes.Buffer
+	if _, err := acc.GetTransactionData(t.Context(), "abc", &out); err == nil {
+		t.Error("Expected an error for a response without a payload")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing to be written, got %q", out.String())
+	}
+}
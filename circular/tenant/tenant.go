@@ -0,0 +1,394 @@
+// Package tenant lets one process certify on behalf of many isolated tenants, such as
+// the customers of a SaaS. A Manager holds each tenant's accounts, its keystores and
+// persisted state in a namespace of a shared storage.KV, a rate limit shared by all of
+// its accounts, and the labels its statistics are reported under.
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/keystore"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// ErrUnknownTenant is returned for a tenant ID that has not been added to the Manager.
+var ErrUnknownTenant = errors.New("tenant: unknown tenant")
+
+// Config describes a tenant.
+type Config struct {
+	ID        string                           // Identifies the tenant; letters, digits, '-' and '_' only.
+	Network   *circular.NetworkProfile         // The tenant's gateway; nil keeps the accounts' default network.
+	RateLimit float64                          // Maximum NAG calls per second across the tenant's accounts; zero means unlimited.
+	Labels    map[string]string                // Metrics labels reported with the tenant's Stats.
+	Setup     func(*circular.CEPAccount) error // Applies further settings to each new account; may be nil.
+}
+
+// Validate checks that the tenant's ID, network and rate limit are usable.
+func (c *Config) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("tenant: ID must not be empty")
+	}
+	for _, r := range c.ID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("tenant: invalid character %q in ID %q", r, c.ID)
+		}
+	}
+	if c.Network != nil {
+		if err := c.Network.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.RateLimit < 0 {
+		return fmt.Errorf("tenant: rate limit cannot be negative, got %g", c.RateLimit)
+	}
+	return nil
+}
+
+// Stats is a snapshot of a tenant's activity, for export as metrics.
+type Stats struct {
+	Tenant         string                // The tenant's ID.
+	Labels         map[string]string     // The tenant's metrics labels.
+	Accounts       int                   // The tenant's open accounts.
+	InFlight       int                   // NAG calls currently in progress across the accounts.
+	ThrottleEvents int64                 // Throttled responses received across the accounts.
+	Polling        circular.PollingStats // Outcome polling across the accounts and their networks.
+}
+
+// Manager holds the tenants of a process. It is safe for concurrent use.
+type Manager struct {
+	kv storage.KV
+
+	mu      sync.Mutex
+	tenants map[string]*Tenant
+}
+
+// NewManager creates a Manager keeping each tenant's data in kv under "tenants/<ID>/".
+//
+// Parameters:
+//   - kv: The store shared by all tenants.
+//
+// Returns:
+//
+//	A Manager with no tenants.
+func NewManager(kv storage.KV) *Manager {
+	return &Manager{kv: kv, tenants: make(map[string]*Tenant)}
+}
+
+// Add registers a tenant. Data a tenant with the same ID stored earlier, such as its
+// keystores, is available to it again.
+//
+// Parameters:
+//   - cfg: The tenant to add.
+//
+// Returns:
+//
+//	The tenant, or an error if cfg is invalid or a tenant with its ID already exists.
+func (m *Manager) Add(cfg Config) (*Tenant, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tenants[cfg.ID]; ok {
+		return nil, fmt.Errorf("tenant: %s already exists", cfg.ID)
+	}
+	labels := make(map[string]string, len(cfg.Labels))
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+	cfg.Labels = labels
+	t := &Tenant{
+		cfg:      cfg,
+		kv:       storage.Namespace(m.kv, "tenants/"+cfg.ID+"/"),
+		limiter:  circular.NewRateLimiter(cfg.RateLimit),
+		accounts: make(map[string]*circular.CEPAccount),
+	}
+	m.tenants[cfg.ID] = t
+	return t, nil
+}
+
+// Tenant returns the tenant with the given ID.
+//
+// Parameters:
+//   - id: The tenant's ID.
+//
+// Returns:
+//
+//	The tenant, or ErrUnknownTenant.
+func (m *Manager) Tenant(id string) (*Tenant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+// Remove closes a tenant's accounts and unregisters it. Its stored data is kept, so
+// that adding the tenant again restores its keystores and receipts.
+//
+// Parameters:
+//   - id: The tenant's ID.
+//
+// Returns:
+//
+//	ErrUnknownTenant if there is no such tenant, or the errors of closing its accounts,
+//	which are closed and unregistered regardless.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	t, ok := m.tenants[id]
+	delete(m.tenants, id)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+	}
+	return t.close()
+}
+
+// IDs returns the IDs of the registered tenants, in ascending order.
+func (m *Manager) IDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Stats returns a snapshot of each tenant's activity, ordered by tenant ID.
+func (m *Manager) Stats() []Stats {
+	ids := m.IDs()
+	stats := make([]Stats, 0, len(ids))
+	for _, id := range ids {
+		if t, err := m.Tenant(id); err == nil {
+			stats = append(stats, t.Stats())
+		}
+	}
+	return stats
+}
+
+// Tenant is one tenant of a Manager. It is safe for concurrent use.
+type Tenant struct {
+	cfg     Config
+	kv      storage.KV
+	limiter *circular.RateLimiter
+
+	mu       sync.Mutex
+	accounts map[string]*circular.CEPAccount
+	closed   bool
+}
+
+// ID returns the tenant's ID.
+func (t *Tenant) ID() string {
+	return t.cfg.ID
+}
+
+// Context returns a copy of ctx whose NAG calls carry the tenant's ID, keeping any
+// other CallOptions ctx carries, so that the gateway and the accounts' logs can
+// attribute them to the tenant.
+//
+// Parameters:
+//   - ctx: The parent context.
+//
+// Returns:
+//
+//	A derived context carrying the tenant ID.
+func (t *Tenant) Context(ctx context.Context) context.Context {
+	opts := circular.CallOptionsFromContext(ctx)
+	opts.TenantID = t.cfg.ID
+	return circular.WithCallOptions(ctx, opts)
+}
+
+// Store returns the tenant's namespace of the Manager's store, for state of the tenant
+// kept outside the SDK. The keys "accounts/" and "keys/" are used by the tenant itself.
+func (t *Tenant) Store() storage.KV {
+	return t.kv
+}
+
+// SetRateLimit changes the limit on NAG calls across the tenant's accounts.
+//
+// Parameters:
+//   - perSecond: The maximum rate; zero means unlimited.
+//
+// Returns:
+//
+//	An error if the rate is negative, in which case the limit is unchanged.
+func (t *Tenant) SetRateLimit(perSecond float64) error {
+	if perSecond < 0 {
+		return fmt.Errorf("tenant: rate limit cannot be negative, got %g", perSecond)
+	}
+	t.limiter.SetRate(perSecond)
+	return nil
+}
+
+// Account returns the tenant's account for address, creating and opening it on first
+// use. A new account uses the tenant's network and rate limit, keeps its receipts and
+// nonce reservations in the tenant's store, and is then passed to the tenant's Setup.
+//
+// Parameters:
+//   - address: The account's address.
+//
+// Returns:
+//
+//	The account, or an error if the tenant was removed, the address is invalid, or the
+//	account cannot be set up.
+func (t *Tenant) Account(address string) (*circular.CEPAccount, error) {
+	key, err := addressKey(address)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, fmt.Errorf("%w: %s was removed", ErrUnknownTenant, t.cfg.ID)
+	}
+	if acc, ok := t.accounts[key]; ok {
+		return acc, nil
+	}
+
+	acc := circular.NewCEPAccount()
+	if t.cfg.Network != nil {
+		if err := acc.SetNetworkProfile(*t.cfg.Network); err != nil {
+			return nil, err
+		}
+	}
+	acc.Open(address)
+	acc.SetRateLimiter(t.limiter)
+	docs := storage.NewDocumentStore(storage.Namespace(t.kv, "accounts/"+key+"/"))
+	acc.SetReceiptStore(circular.NewDocumentReceiptStore(docs))
+	acc.SetNonceJournal(docs)
+	if t.cfg.Setup != nil {
+		if err := t.cfg.Setup(acc); err != nil {
+			return nil, fmt.Errorf("tenant: failed to set up account %s of %s: %w", address, t.cfg.ID, err)
+		}
+	}
+	t.accounts[key] = acc
+	return acc, nil
+}
+
+// SaveKeystore stores a keystore in the tenant's namespace, replacing any earlier
+// keystore for the same address.
+//
+// Parameters:
+//   - ks: The keystore to store.
+//
+// Returns:
+//
+//	An error if the keystore has no valid address or cannot be stored.
+func (t *Tenant) SaveKeystore(ks *keystore.Keystore) error {
+	key, err := addressKey(ks.Address)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(ks)
+	if err != nil {
+		return fmt.Errorf("tenant: %w", err)
+	}
+	if err := t.kv.Put("keys/"+key, data); err != nil {
+		return fmt.Errorf("tenant: failed to store keystore for %s: %w", ks.Address, err)
+	}
+	return nil
+}
+
+// Keystore returns the tenant's keystore for address. Keystores of other tenants are
+// never returned.
+//
+// Parameters:
+//   - address: The address the keystore belongs to.
+//
+// Returns:
+//
+//	The keystore, or an error wrapping storage.ErrNotFound if the tenant has none for
+//	address.
+func (t *Tenant) Keystore(address string) (*keystore.Keystore, error) {
+	key, err := addressKey(address)
+	if err != nil {
+		return nil, err
+	}
+	data, err := t.kv.Get("keys/" + key)
+	if err != nil {
+		return nil, fmt.Errorf("tenant: no keystore for %s in %s: %w", address, t.cfg.ID, err)
+	}
+	return keystore.Parse(data)
+}
+
+// Signer decrypts the tenant's keystore for address.
+//
+// Parameters:
+//   - address: The address to sign for.
+//   - password: The keystore's password.
+//
+// Returns:
+//
+//	A signer holding the key, or an error if there is no keystore for address or the
+//	password does not open it.
+func (t *Tenant) Signer(address, password string) (*circular.PrivateKeySigner, error) {
+	ks, err := t.Keystore(address)
+	if err != nil {
+		return nil, err
+	}
+	return ks.Decrypt(password)
+}
+
+// Stats returns a snapshot of the tenant's activity.
+func (t *Tenant) Stats() Stats {
+	labels := make(map[string]string, len(t.cfg.Labels))
+	for k, v := range t.cfg.Labels {
+		labels[k] = v
+	}
+	stats := Stats{Tenant: t.cfg.ID, Labels: labels}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats.Accounts = len(t.accounts)
+	for _, acc := range t.accounts {
+		pressure := acc.Backpressure()
+		stats.InFlight += pressure.InFlight
+		stats.ThrottleEvents += pressure.ThrottleEvents
+		for _, polling := range acc.PollingStats() {
+			stats.Polling.Outcomes += polling.Outcomes
+			stats.Polling.Failures += polling.Failures
+			stats.Polling.Attempts += polling.Attempts
+			stats.Polling.TotalWait += polling.TotalWait
+			stats.Polling.MaxWait = max(stats.Polling.MaxWait, polling.MaxWait)
+		}
+	}
+	return stats
+}
+
+// close closes the tenant's accounts and refuses new ones.
+//
+// Returns:
+//
+//	The errors of closing the accounts, joined, or nil.
+func (t *Tenant) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var errs []error
+	for _, acc := range t.accounts {
+		errs = append(errs, acc.Close())
+	}
+	t.accounts = nil
+	t.closed = true
+	return errors.Join(errs...)
+}
+
+// addressKey returns the storage key of an address, which must be hexadecimal.
+func addressKey(address string) (string, error) {
+	key := helpers.HexFix(address)
+	if key == "" || strings.Trim(key, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("tenant: invalid address %q", address)
+	}
+	return key, nil
+}
@@ -0,0 +1,121 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/keystore"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+const testPrivateKey = "1c7a1a6f9a1b0b4b2b8d25d22c1f1e53f0ad1ad7f3b0a5fd44b99a0b33e97a01"
+
+func TestManager(t *testing.T) {
+	var lastTenant atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastTenant.Store(r.Header.Get(circular.TenantIDHeader))
+		fmt.Fprint(w, `{"Result":200,"Response":{"Block":{"BlockID":"1"}}}`)
+	}))
+	defer server.Close()
+	network := &circular.NetworkProfile{Name: "testnet", BaseURL: server.URL + "/?cep="}
+
+	kv := storage.NewMemory()
+	manager := NewManager(kv)
+	acme, err := manager.Add(Config{ID: "acme", Network: network, RateLimit: 20, Labels: map[string]string{"plan": "gold"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	globex, err := manager.Add(Config{ID: "globex", Network: network})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Add(Config{ID: "acme"}); err == nil {
+		t.Error("Expected a duplicate tenant ID to be rejected")
+	}
+	if _, err := manager.Add(Config{ID: "../acme"}); err == nil {
+		t.Error("Expected an ID escaping the namespace to be rejected")
+	}
+
+	// Keystores are visible only to the tenant that stored them.
+	ks, err := keystore.Encrypt(testPrivateKey, "secret", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := acme.SaveKeystore(ks); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acme.Signer(ks.Address, "secret"); err != nil {
+		t.Errorf("Expected the tenant's keystore to open, got: %v", err)
+	}
+	if _, err := globex.Keystore(ks.Address); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected another tenant's keystore to be hidden, got: %v", err)
+	}
+
+	// The same address gives each tenant its own account.
+	acmeAccount, err := acme.Account(ks.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globexAccount, _ := globex.Account(ks.Address)
+	if acmeAccount == globexAccount {
+		t.Fatal("Expected tenants not to share accounts")
+	}
+	if again, _ := acme.Account("0x" + ks.Address); again != acmeAccount {
+		t.Error("Expected the tenant's account to be reused")
+	}
+	if acmeAccount.State().NAGURL != network.BaseURL {
+		t.Errorf("Expected the tenant's network, got %s", acmeAccount.State().NAGURL)
+	}
+
+	// The rate limit applies to the tenant's accounts together, not to other tenants.
+	other, _ := acme.Account("0xabcdef")
+	start := time.Now()
+	for i := range 4 {
+		acc := acmeAccount
+		if i%2 == 1 {
+			acc = other
+		}
+		if _, err := acc.GetBlock(t.Context(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the tenant's accounts to share its rate limit, 4 calls took %s", elapsed)
+	}
+	start = time.Now()
+	for range 4 {
+		globexAccount.GetBlock(globex.Context(t.Context()), 1)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected other tenants to be unaffected, 4 calls took %s", elapsed)
+	}
+	if got := lastTenant.Load(); got != "globex" {
+		t.Errorf("Expected calls made with the tenant's context to carry its ID, got %q", got)
+	}
+
+	stats := manager.Stats()
+	if len(stats) != 2 || stats[0].Tenant != "acme" || stats[0].Accounts != 2 || stats[0].Labels["plan"] != "gold" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Removing a tenant closes its accounts but keeps its data.
+	if err := manager.Remove("acme"); err != nil {
+		t.Fatal(err)
+	}
+	if acmeAccount.State().Address != "" {
+		t.Error("Expected the removed tenant's accounts to be closed")
+	}
+	if _, err := manager.Tenant("acme"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("Expected ErrUnknownTenant, got %v", err)
+	}
+	restored, _ := manager.Add(Config{ID: "acme"})
+	if _, err := restored.Keystore(ks.Address); err != nil {
+		t.Errorf("Expected the keystore to survive removal, got: %v", err)
+	}
+}
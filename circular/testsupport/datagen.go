@@ -0,0 +1,114 @@
+package testsupport
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// payloadAlphabet is the characters of the text Payload generates.
+const payloadAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 .,:;-_/"
+
+// DataGenerator produces test data from a seeded pseudo-random source, so that a test
+// generates the same data on every run and a failure can be replayed from its seed.
+// Values from Unique, UniqueHex and Address are never repeated by one generator,
+// whatever the seed. It is not suitable for keys or any other secret, and it is safe
+// for concurrent use, although concurrent callers then share one sequence.
+type DataGenerator struct {
+	seed uint64
+
+	mu   sync.Mutex
+	rng  *rand.Rand
+	next uint64 // The sequence number of the next unique value.
+}
+
+// NewDataGenerator creates a DataGenerator whose data is determined by seed.
+//
+// Parameters:
+//   - seed: Selects the sequence of generated data; log it to replay a failure.
+//
+// Returns:
+//
+//	A DataGenerator at the start of the sequence.
+func NewDataGenerator(seed uint64) *DataGenerator {
+	return &DataGenerator{seed: seed, rng: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+// Seed returns the seed the generator was created with.
+func (g *DataGenerator) Seed() uint64 {
+	return g.seed
+}
+
+// Bytes returns n pseudo-random bytes.
+func (g *DataGenerator) Bytes(n int) []byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(g.rng.Uint32())
+	}
+	return b
+}
+
+// Hex returns n pseudo-random bytes encoded as 2n lowercase hexadecimal characters,
+// without "0x" prefix.
+func (g *DataGenerator) Hex(n int) string {
+	return hex.EncodeToString(g.Bytes(n))
+}
+
+// Payload returns size bytes of printable ASCII text, suitable as certificate data of
+// an exact size, such as one at or just over a MaxPayloadSize policy limit.
+func (g *DataGenerator) Payload(size int) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = payloadAlphabet[g.rng.IntN(len(payloadAlphabet))]
+	}
+	return string(b)
+}
+
+// Unique returns prefix followed by a value the generator has not returned before,
+// such as an idempotency key or a tenant name.
+func (g *DataGenerator) Unique(prefix string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next++
+	return fmt.Sprintf("%s%d-%08x", prefix, g.next, g.rng.Uint32())
+}
+
+// UniqueHex returns n bytes as hexadecimal that the generator has not returned before
+// from UniqueHex or Address. The first eight bytes are a sequence number, so n must be
+// at least 8.
+func (g *DataGenerator) UniqueHex(n int) string {
+	if n < 8 {
+		panic(fmt.Sprintf("testsupport: UniqueHex needs at least 8 bytes, got %d", n))
+	}
+	g.mu.Lock()
+	g.next++
+	sequence := g.next
+	g.mu.Unlock()
+	return fmt.Sprintf("%016x", sequence) + g.Hex(n-8)
+}
+
+// Address returns a unique, well-formed 32-byte account address with "0x" prefix.
+func (g *DataGenerator) Address() string {
+	return "0x" + g.UniqueHex(32)
+}
+
+// InvalidHex returns malformed hexadecimal strings, for tests of the validation of
+// addresses, chain IDs and transaction IDs: an empty string, a bare "0x" prefix,
+// non-hexadecimal characters, embedded whitespace and a doubled prefix. Each is derived
+// from n valid bytes. An odd number of digits is not among them, since the SDK pads it
+// with a leading zero.
+func (g *DataGenerator) InvalidHex(n int) []string {
+	valid := g.Hex(max(n, 1))
+	return []string{
+		"",
+		"0x",
+		"zz" + valid[2:],
+		valid[:len(valid)/2] + " " + valid[len(valid)/2:],
+		"0x0x" + valid,
+	}
+}
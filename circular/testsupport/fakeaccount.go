@@ -0,0 +1,203 @@
+package testsupport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+// FakeSubmission is one certificate submitted to a FakeAccount.
+type FakeSubmission struct {
+	TxID string // The transaction ID the fake assigned.
+	Data string // The certified data.
+}
+
+// FakeAccount is an in-memory circular.Account for unit tests of code that uses the
+// SDK through that interface. Submissions succeed with a deterministic transaction ID
+// and finalize at once with status "Executed", unless the test says otherwise with
+// FailSubmissions or SetOutcome. It is safe for concurrent use.
+type FakeAccount struct {
+	mu          sync.Mutex
+	state       circular.AccountState
+	lastErr     error
+	submitErr   error
+	submissions []FakeSubmission
+	outcomes    map[string]map[string]interface{}
+}
+
+var _ circular.Account = (*FakeAccount)(nil)
+
+// NewFakeAccount creates a FakeAccount that is not yet open, on the SDK's default chain.
+//
+// Returns:
+//
+//	The fake.
+func NewFakeAccount() *FakeAccount {
+	return &FakeAccount{
+		state:    circular.AccountState{Blockchain: circular.DefaultChain, CodeVersion: circular.LibVersion},
+		outcomes: make(map[string]map[string]interface{}),
+	}
+}
+
+// Open implements circular.Account; it fails with circular.ErrInvalidAddress for an
+// empty address.
+func (f *FakeAccount) Open(address string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if address == "" {
+		f.setError(circular.ErrInvalidAddress)
+		return false
+	}
+	f.state.Address = address
+	return true
+}
+
+// SetNetwork implements circular.Account, returning a placeholder gateway URL.
+func (f *FakeAccount) SetNetwork(network string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.NetworkNode = network
+	f.state.NAGURL = "fake://" + network + "/?cep="
+	return f.state.NAGURL
+}
+
+// UpdateAccount implements circular.Account; the nonce is left unchanged.
+func (f *FakeAccount) UpdateAccount() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state.Address == "" {
+		f.setError(circular.ErrAccountNotOpen)
+		return false
+	}
+	return true
+}
+
+// SubmitCertificate implements circular.Account. The key is not used; the options are
+// ignored.
+func (f *FakeAccount) SubmitCertificate(pdata string, privateKeyHex string, opts ...circular.SubmitOption) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.state.Address == "":
+		f.setError(circular.ErrAccountNotOpen)
+		return
+	case f.submitErr != nil:
+		f.setError(f.submitErr)
+		return
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s:%d:%s", f.state.Address, f.state.Nonce, pdata))
+	txID := hex.EncodeToString(sum[:])
+	f.submissions = append(f.submissions, FakeSubmission{TxID: txID, Data: pdata})
+	if _, ok := f.outcomes[txID]; !ok {
+		f.outcomes[txID] = map[string]interface{}{"ID": txID, "Status": "Executed", "BlockID": "fake-block"}
+	}
+	f.state.LatestTxID = txID
+	f.state.Nonce++
+}
+
+// GetTransaction implements circular.Account, answering as a gateway would: with
+// circular.ResultOK and the transaction's outcome, or circular.ResultTransactionNotFound
+// if it is unknown.
+func (f *FakeAccount) GetTransaction(blockID string, transactionID string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	outcome, ok := f.outcomes[normalizeTxID(transactionID)]
+	if !ok {
+		return map[string]interface{}{"Result": float64(circular.ResultTransactionNotFound), "Response": "Transaction Not Found"}
+	}
+	return map[string]interface{}{"Result": float64(circular.ResultOK), "Response": copyOutcome(outcome)}
+}
+
+// WaitForOutcome implements circular.Account, returning the transaction's outcome at
+// once, or circular.ErrTransactionNotFound if it is unknown.
+func (f *FakeAccount) WaitForOutcome(ctx context.Context, txID string) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	outcome, ok := f.outcomes[normalizeTxID(txID)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", circular.ErrTransactionNotFound, txID)
+	}
+	return copyOutcome(outcome), nil
+}
+
+// State implements circular.Account.
+func (f *FakeAccount) State() circular.AccountState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// LastErr implements circular.Account.
+func (f *FakeAccount) LastErr() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastErr
+}
+
+// Close implements circular.Account, forgetting the address and network. It ignores
+// opts and never fails.
+func (f *FakeAccount) Close(opts ...circular.CloseOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = circular.AccountState{}
+	return nil
+}
+
+// FailSubmissions makes every later SubmitCertificate fail with err, as recorded by
+// LastErr, until it is called with nil.
+//
+// Parameters:
+//   - err: The error submissions fail with, such as an *circular.APIError; nil lets
+//     them succeed again.
+func (f *FakeAccount) FailSubmissions(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitErr = err
+}
+
+// SetOutcome sets the outcome WaitForOutcome and GetTransaction return for txID, for
+// testing failed transactions or ones submitted elsewhere.
+//
+// Parameters:
+//   - txID: The transaction, with or without "0x" prefix.
+//   - outcome: Its finalized details, such as {"Status": "Failed"}.
+func (f *FakeAccount) SetOutcome(txID string, outcome map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outcomes[normalizeTxID(txID)] = copyOutcome(outcome)
+}
+
+// Submissions returns the certificates submitted so far, oldest first.
+func (f *FakeAccount) Submissions() []FakeSubmission {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeSubmission(nil), f.submissions...)
+}
+
+// setError records err as the last error; f.mu must be held.
+func (f *FakeAccount) setError(err error) {
+	f.lastErr = err
+	f.state.LastError = err.Error()
+}
+
+// normalizeTxID lowercases txID and strips its "0x" prefix.
+func normalizeTxID(txID string) string {
+	return strings.TrimPrefix(strings.ToLower(txID), "0x")
+}
+
+// copyOutcome returns a shallow copy of outcome, so callers cannot change the fake's.
+func copyOutcome(outcome map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(outcome))
+	for key, value := range outcome {
+		c[key] = value
+	}
+	return c
+}
@@ -0,0 +1,206 @@
+// Package testsupport provides assertable fakes for integration tests of code that uses
+// the circular package: a Logger capturing what an account logs, a Recorder listing
+// every NAG call a fake gateway receives, a FakeAccount standing in for the account
+// itself behind circular.Account, and a DataGenerator of reproducible test data. Unlike
+// circulartest it depends on the circular package, so the circular package's own tests
+// cannot use it.
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+// LogEntry is one message captured by a Logger.
+type LogEntry struct {
+	Level   circular.LogLevel // The level the message was logged at.
+	Message string            // The message, without a trailing newline.
+}
+
+// Logger is a circular.Logger that keeps every message for inspection. Install it with
+// CEPAccount.SetLogger. The zero value is ready to use and safe for concurrent use.
+type Logger struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// Log implements circular.Logger.
+func (l *Logger) Log(level circular.LogLevel, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, LogEntry{Level: level, Message: message})
+}
+
+// Entries returns the captured messages, oldest first.
+func (l *Logger) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LogEntry(nil), l.entries...)
+}
+
+// Messages returns the captured messages logged at level or above, oldest first.
+//
+// Parameters:
+//   - level: The least severe level to include.
+//
+// Returns:
+//
+//	The messages.
+func (l *Logger) Messages(level circular.LogLevel) []string {
+	var messages []string
+	for _, entry := range l.Entries() {
+		if entry.Level >= level {
+			messages = append(messages, entry.Message)
+		}
+	}
+	return messages
+}
+
+// Count returns the number of captured messages containing substr.
+//
+// Parameters:
+//   - substr: The text to look for.
+//
+// Returns:
+//
+//	The number of matching messages, at any level.
+func (l *Logger) Count(substr string) int {
+	n := 0
+	for _, entry := range l.Entries() {
+		if strings.Contains(entry.Message, substr) {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset discards the captured messages.
+func (l *Logger) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
+
+// Call is one NAG call received by a Recorder. The calls of a JSON-RPC batch are
+// recorded individually.
+type Call struct {
+	Endpoint  string                 // The NAG operation, such as "Circular_GetWalletNonce_".
+	RequestID string                 // The call's correlation ID, from circular.RequestIDHeader.
+	Attempt   int                    // 1 for the first call to Endpoint with RequestID, 2 for its first retry, and so on.
+	Header    http.Header            // The request headers.
+	Body      []byte                 // The request body as sent.
+	Payload   map[string]interface{} // The operation's JSON parameters, unwrapped from any JSON-RPC envelope; nil if they are not a JSON object.
+}
+
+// Recorder is an http.Handler that records each NAG call before passing it to the
+// handler faking the gateway. Serve it with httptest.NewServer. It is safe for
+// concurrent use.
+type Recorder struct {
+	handler http.Handler
+
+	mu       sync.Mutex
+	calls    []Call
+	attempts map[string]int
+}
+
+// NewRecorder creates a Recorder in front of handler.
+//
+// Parameters:
+//   - handler: Answers the calls, as the faked gateway would.
+//
+// Returns:
+//
+//	A Recorder with no calls recorded.
+func NewRecorder(handler http.Handler) *Recorder {
+	return &Recorder{handler: handler, attempts: make(map[string]int)}
+}
+
+// endpointPattern matches NAG operation names in URLs.
+var endpointPattern = regexp.MustCompile(`Circular_[A-Za-z]+_`)
+
+// ServeHTTP implements http.Handler.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	r.record(req, body)
+	r.handler.ServeHTTP(w, req)
+}
+
+// rpcCall is the part of a JSON-RPC request a Recorder inspects.
+type rpcCall struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// record adds the calls in a request with the given body.
+func (r *Recorder) record(req *http.Request, body []byte) {
+	requestID := req.Header.Get(circular.RequestIDHeader)
+	var batch []rpcCall
+	var single rpcCall
+	switch {
+	case json.Unmarshal(body, &batch) == nil:
+	case json.Unmarshal(body, &single) == nil && single.JSONRPC != "":
+		batch = []rpcCall{single}
+	default:
+		// A plain NAG call names its operation in the URL.
+		batch = []rpcCall{{Method: endpointPattern.FindString(req.URL.String()), Params: body}}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, call := range batch {
+		var payload map[string]interface{}
+		json.Unmarshal(call.Params, &payload)
+		key := call.Method + "\x00" + requestID
+		r.attempts[key]++
+		r.calls = append(r.calls, Call{
+			Endpoint:  call.Method,
+			RequestID: requestID,
+			Attempt:   r.attempts[key],
+			Header:    req.Header.Clone(),
+			Body:      body,
+			Payload:   payload,
+		})
+	}
+}
+
+// Calls returns the recorded calls in the order they were received.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the recorded calls to endpoint in the order they were received.
+//
+// Parameters:
+//   - endpoint: The NAG operation, such as "Circular_AddTransaction_".
+//
+// Returns:
+//
+//	The calls, including retries.
+func (r *Recorder) CallsTo(endpoint string) []Call {
+	var calls []Call
+	for _, call := range r.Calls() {
+		if call.Endpoint == endpoint {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset discards the recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+	r.attempts = make(map[string]int)
+}
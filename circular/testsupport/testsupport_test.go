@@ -0,0 +1,198 @@
+package testsupport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+const testPrivateKey = "0x1111111111111111111111111111111111111111111111111111111111111111"
+
+func TestRecorderAndLogger(t *testing.T) {
+	var throttled atomic.Int32
+	recorder := NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			if throttled.Add(1) <= 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		}
+	}))
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	logger := &Logger{}
+	acc := circular.NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetLogger(logger)
+	acc.SetRetryPolicy(&circular.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastError)
+	}
+	nonceCalls := recorder.CallsTo("Circular_GetWalletNonce_")
+	if len(nonceCalls) != 3 {
+		t.Fatalf("Expected the nonce to be fetched in 3 attempts, got %d", len(nonceCalls))
+	}
+	for i, call := range nonceCalls {
+		if call.Attempt != i+1 || call.RequestID != nonceCalls[0].RequestID || call.RequestID == "" {
+			t.Errorf("Expected attempt %d of request %s, got %d of %s", i+1, nonceCalls[0].RequestID, call.Attempt, call.RequestID)
+		}
+		if call.Payload["Address"] != "abcdef" {
+			t.Errorf("Expected the account's address in the payload, got %v", call.Payload)
+		}
+	}
+	if n := logger.Count("Throttled by gateway"); n != 2 {
+		t.Errorf("Expected 2 retries to be logged, got %d in %v", n, logger.Messages(circular.LogDebug))
+	}
+	for _, message := range logger.Messages(circular.LogInfo) {
+		if strings.HasSuffix(message, "\n") {
+			t.Errorf("Expected messages without trailing newline, got %q", message)
+		}
+	}
+
+	signer, _ := circular.NewPrivateKeySigner(testPrivateKey)
+	nonce := acc.Nonce
+	if _, err := acc.SubmitCertificateOn(t.Context(), acc.Blockchain, "report", signer); err != nil {
+		t.Fatal(err)
+	}
+	submissions := recorder.CallsTo("Circular_AddTransaction_")
+	if len(submissions) != 1 || submissions[0].Attempt != 1 || submissions[0].Payload["Nonce"] != fmt.Sprint(nonce) {
+		t.Errorf("Expected one submission with nonce %d, got %+v", nonce, submissions)
+	}
+	if got := submissions[0].Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected a JSON submission, got %q", got)
+	}
+
+	recorder.Reset()
+	logger.Reset()
+	if len(recorder.Calls()) != 0 || len(logger.Entries()) != 0 {
+		t.Error("Expected Reset to discard the recorded calls and messages")
+	}
+}
+
+func TestRecorderJSONRPC(t *testing.T) {
+	recorder := NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID string `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"result":{"Nonce":1}}`, req.ID)
+	}))
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	acc := circular.NewCEPAccount()
+	if err := acc.SetNetworkProfile(circular.NetworkProfile{Name: "rpc", BaseURL: server.URL, Protocol: circular.ProtocolJSONRPC}); err != nil {
+		t.Fatal(err)
+	}
+	acc.Open("0xabcdef")
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastError)
+	}
+	calls := recorder.Calls()
+	if len(calls) != 1 || calls[0].Endpoint != "Circular_GetWalletNonce_" || calls[0].Payload["Address"] != "abcdef" {
+		t.Errorf("Expected the JSON-RPC call to be unwrapped, got %+v", calls)
+	}
+}
+
+// certifyAll is code under test that uses the SDK through circular.Account.
+func certifyAll(ctx context.Context, acc circular.Account, docs []string) ([]string, error) {
+	var statuses []string
+	for _, doc := range docs {
+		acc.SubmitCertificate(doc, testPrivateKey)
+		if err := acc.LastErr(); err != nil {
+			return statuses, err
+		}
+		outcome, err := acc.WaitForOutcome(ctx, acc.State().LatestTxID)
+		if err != nil {
+			return statuses, err
+		}
+		statuses = append(statuses, outcome["Status"].(string))
+	}
+	return statuses, nil
+}
+
+func TestFakeAccount(t *testing.T) {
+	closed := NewFakeAccount()
+	if closed.SubmitCertificate("x", testPrivateKey); !errors.Is(closed.LastErr(), circular.ErrAccountNotOpen) {
+		t.Errorf("Expected submission to need an open account, got %v", closed.LastErr())
+	}
+
+	fake := NewFakeAccount()
+	fake.Open("0xabcdef")
+	fake.SetNetwork("testnet")
+
+	statuses, err := certifyAll(t.Context(), fake, []string{"a", "b"})
+	if err != nil || len(statuses) != 2 || statuses[0] != "Executed" {
+		t.Fatalf("Expected two executed certificates, got %v, %v", statuses, err)
+	}
+	submissions := fake.Submissions()
+	if len(submissions) != 2 || submissions[1].Data != "b" || submissions[0].TxID == submissions[1].TxID || fake.State().Nonce != 2 {
+		t.Errorf("Unexpected submissions %+v", submissions)
+	}
+	if tx := fake.GetTransaction("", "0x"+submissions[0].TxID); tx["Result"] != float64(200) {
+		t.Errorf("Expected the submitted transaction to be found, got %v", tx)
+	}
+	if tx := fake.GetTransaction("", "ffff"); !circular.IsTransactionNotFound(tx) {
+		t.Errorf("Expected an unknown transaction to be not found, got %v", tx)
+	}
+
+	fake.SetOutcome(submissions[1].TxID, map[string]interface{}{"Status": "Failed"})
+	if outcome, _ := fake.WaitForOutcome(t.Context(), submissions[1].TxID); outcome["Status"] != "Failed" {
+		t.Errorf("Expected the outcome set by the test, got %v", outcome)
+	}
+	rejected := &circular.APIError{Endpoint: "Circular_AddTransaction_", HTTPStatus: 200, ResultCode: 108}
+	fake.FailSubmissions(rejected)
+	if _, err := certifyAll(t.Context(), fake, []string{"c"}); !errors.As(err, &rejected) {
+		t.Errorf("Expected the injected failure, got %v", err)
+	}
+}
+
+func TestDataGenerator(t *testing.T) {
+	a, b := NewDataGenerator(42), NewDataGenerator(42)
+	if a.Payload(100) != b.Payload(100) || a.Hex(16) != b.Hex(16) || a.Address() != b.Address() {
+		t.Error("Expected generators with the same seed to produce the same data")
+	}
+	if NewDataGenerator(43).Hex(16) == NewDataGenerator(42).Hex(16) {
+		t.Error("Expected generators with different seeds to produce different data")
+	}
+	if payload := a.Payload(64); len(payload) != 64 {
+		t.Errorf("Expected a 64-byte payload, got %d bytes", len(payload))
+	}
+
+	seen := make(map[string]bool)
+	for range 1000 {
+		for _, value := range []string{a.Unique("key-"), a.UniqueHex(8), a.Address()} {
+			if seen[value] {
+				t.Fatalf("Expected unique values, got %q twice", value)
+			}
+			seen[value] = true
+		}
+	}
+	if _, err := circular.NewAccount(circular.WithAddress(a.Address())); err != nil {
+		t.Errorf("Expected a generated address to be valid, got: %v", err)
+	}
+	for _, invalid := range a.InvalidHex(32) {
+		if invalid == "" {
+			continue // Leaves the address unset.
+		}
+		if _, err := circular.NewAccount(circular.WithAddress(invalid)); !errors.Is(err, circular.ErrInvalidAddress) {
+			t.Errorf("Expected %q to be refused as an address, got: %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package circular
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// Transaction is the signed envelope submitted to the NAG's Circular_AddTransaction_ endpoint.
+// All fields are strings, exactly as the gateway expects them on the wire.
+type Transaction struct {
+	Blockchain string `json:"Blockchain"` // The blockchain identifier, without "0x" prefix.
+	From       string `json:"From"`       // The sender address, without "0x" prefix.
+	ID         string `json:"ID"`         // The transaction ID; see ComputeTransactionID.
+	Nonce      string `json:"Nonce"`      // The sender's nonce in decimal.
+	Payload    string `json:"Payload"`    // The hex-encoded payload envelope; see Payload.
+	Signature  string `json:"Signature"`  // The hex-encoded DER signature over ID.
+	Timestamp  string `json:"Timestamp"`  // The UTC timestamp in "YYYY:MM:DD-HH:MM:SS" format.
+	To         string `json:"To"`         // The recipient address, without "0x" prefix.
+	Type       string `json:"Type"`       // The transaction type, e.g. "C_TYPE_CERTIFICATE".
+	Version    string `json:"Version"`    // The client library version.
+}
+
+// EnvelopeParams are the inputs of a certificate submission, for BuildEnvelopeForTest.
+type EnvelopeParams struct {
+	Blockchain string    // The chain submitted to.
+	Address    string    // The submitting account's address.
+	Nonce      int64     // The nonce to use.
+	Data       string    // The certified data, as passed to SubmitCertificate.
+	Version    string    // The client library version recorded; LibVersion if empty.
+	Timestamp  time.Time // The time the transaction is stamped with; the current time if zero.
+}
+
+// BuildEnvelopeForTest returns the Circular_AddTransaction_ request body that
+// submitting p.Data would send, built by the same code as SubmitCertificate but
+// without sending anything. It exists so that tests can compare envelopes byte for byte
+// against golden files: the gateway is sensitive to field order, the casing of field
+// names and the hexing of addresses and data. Set p.Timestamp for a reproducible
+// result; signatures are deterministic (RFC 6979).
+//
+// Parameters:
+//   - p: The submission's inputs.
+//   - signer: Signs the transaction.
+//   - opts: Optional settings for the submission, as for SubmitCertificate.
+//
+// Returns:
+//
+//	The JSON request body, or an error if an option is invalid or signing fails.
+func BuildEnvelopeForTest(p EnvelopeParams, signer Signer, opts ...SubmitOption) ([]byte, error) {
+	cfg, err := newSubmitConfig(p.Address, opts)
+	if err != nil {
+		return nil, err
+	}
+	version := p.Version
+	if version == "" {
+		version = LibVersion
+	}
+	at := p.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	tx, err := buildCertificate(p.Blockchain, p.Address, version, p.Nonce, at, p.Data, signer, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tx)
+}
+
+// ComputeTransactionID derives a transaction ID as the hex SHA-256 digest of the
+// concatenation blockchain + from + to + payload + nonce + timestamp, with addresses
+// normalized by helpers.HexFix and the payload, whose case is significant, only stripped
+// of any "0x" prefix by helpers.Strip0x. External signing pipelines must use the same
+// derivation for their IDs to be accepted by SubmitWithPrecomputedID, and sign the ID
+// in its canonical form as returned here, since signatures cover the message verbatim.
+//
+// Parameters:
+//   - blockchain: The blockchain identifier.
+//   - from: The sender address.
+//   - to: The recipient address.
+//   - payload: The hex-encoded payload envelope.
+//   - nonce: The sender's nonce in decimal.
+//   - timestamp: The UTC timestamp in "YYYY:MM:DD-HH:MM:SS" format.
+//
+// Returns:
+//
+//	The lowercase hex transaction ID.
+func ComputeTransactionID(blockchain, from, to, payload, nonce, timestamp string) string {
+	strToHash := helpers.HexFix(blockchain) + helpers.HexFix(from) + helpers.HexFix(to) + helpers.Strip0x(payload) + nonce + timestamp
+	hash := sha256.Sum256([]byte(strToHash))
+	return hex.EncodeToString(hash[:])
+}
+
+// PrecomputedTransaction carries the parts of a transaction built and signed outside
+// the SDK, for example by a dedicated signing service.
+type PrecomputedTransaction struct {
+	ID        string // The transaction ID computed with ComputeTransactionID.
+	Payload   string // The hex-encoded payload envelope that was hashed into ID; see ParsePayload.
+	Signature string // The hex-encoded DER signature over ID.
+	Timestamp string // The timestamp that was hashed into ID.
+	Nonce     int64  // The nonce that was hashed into ID.
+	To        string // The recipient hashed into ID; defaults to the account's own address.
+	PublicKey string // The signer's public key; defaults to the account's PublicKey.
+}
+
+// SubmitWithPrecomputedID submits a transaction whose ID and signature were produced
+// outside the SDK. Before anything is sent, the parts are checked for internal
+// consistency: the payload must be a hex-encoded JSON envelope with an Action, the
+// timestamp must be well formed, the ID must match ComputeTransactionID over the
+// account's blockchain and address and the supplied fields, and the signature must
+// verify against the signer's public key. The transaction is subject to the quota and
+// queued in maintenance windows and degraded mode like certificates. On success the
+// account's `LatestTxID` is updated and its nonce advanced past tx.Nonce.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the submission request.
+//   - tx: The precomputed transaction parts.
+//
+// Returns:
+//
+//	The transaction ID, or an error if validation fails or the submission is rejected.
+//	Like SubmitCertificate, a *DeferredError is returned with the ID of a transaction
+//	queued for a maintenance window.
+func (a *CEPAccount) SubmitWithPrecomputedID(ctx context.Context, tx PrecomputedTransaction) (string, error) {
+	a.submitMu.Lock()
+	defer a.submitMu.Unlock()
+
+	v := a.view()
+	if v.Address == "" {
+		return "", ErrAccountNotOpen
+	}
+	ctx, cancel := withDeadline(ctx, v.deadlines.withDefaults().Submit)
+	defer cancel()
+
+	to := tx.To
+	if to == "" {
+		to = v.Address
+	}
+	if err := validateAddress(to); err != nil {
+		return "", fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	payload, err := ParsePayload(tx.Payload)
+	if err != nil {
+		return "", err
+	}
+	envelope, _ := payload.Envelope()
+	var payloadObject struct {
+		Action string `json:"Action"`
+	}
+	if err := json.Unmarshal(envelope, &payloadObject); err != nil || payloadObject.Action == "" {
+		return "", fmt.Errorf("payload is not a valid envelope with an Action")
+	}
+	_, data, err := payload.Decode()
+	if err != nil {
+		return "", err
+	}
+	if err := v.scanSigned(data); err != nil {
+		return "", err
+	}
+	submission, err := v.checkPolicy(v.Blockchain, to, data)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := time.Parse(helpers.TimestampLayout, tx.Timestamp); err != nil {
+		return "", fmt.Errorf("invalid timestamp %q: %w", tx.Timestamp, err)
+	}
+
+	nonce := fmt.Sprintf("%d", tx.Nonce)
+	expectedID := ComputeTransactionID(v.Blockchain, v.Address, to, string(payload), nonce, tx.Timestamp)
+	if helpers.HexFix(tx.ID) != expectedID {
+		return "", fmt.Errorf("transaction ID does not match its contents: expected %s", expectedID)
+	}
+
+	publicKey := tx.PublicKey
+	if publicKey == "" {
+		publicKey = v.PublicKey
+	}
+	if publicKey == "" {
+		return "", fmt.Errorf("a public key is required to verify the signature")
+	}
+	if !VerifySignature(publicKey, expectedID, tx.Signature) {
+		return "", fmt.Errorf("signature does not verify against the signer's public key")
+	}
+
+	if err := a.checkPermissions(v.Blockchain, certificateTxType); err != nil {
+		return "", err
+	}
+	if err := a.quota.check(1, int64(len(data))); err != nil {
+		return "", err
+	}
+
+	transaction := &Transaction{
+		Blockchain: helpers.HexFix(v.Blockchain),
+		From:       helpers.HexFix(v.Address),
+		ID:         expectedID,
+		Nonce:      nonce,
+		Payload:    string(payload),
+		Signature:  helpers.HexFix(tx.Signature),
+		Timestamp:  tx.Timestamp,
+		To:         helpers.HexFix(to),
+		Type:       certificateTxType,
+		Version:    v.CodeVersion,
+	}
+	err = a.dispatch(ctx, v, transaction, &submitConfig{to: to}, int64(len(data)), submission)
+	if err != nil && !queuedForLater(err) {
+		return "", err
+	}
+	a.applySubmission(v, expectedID, tx.Nonce, true)
+	return expectedID, err
+}
+
+// broadcastTransaction sends a signed transaction to the NAG and checks the result.
+// In dev mode on devnet, a transaction rejected for insufficient balance is retried
+// once after requesting test funds.
+func (a *CEPAccount) broadcastTransaction(ctx context.Context, tx *Transaction) error {
+	a.inflight.Add(1)
+	defer a.inflight.Add(-1)
+	err := a.sendTransaction(ctx, tx)
+	if v := a.view(); v.devMode && v.NetworkNode == devnetNetwork && IsInsufficientBalance(err) {
+		a.logf(LogInfo, "broadcastTransaction: insufficient balance on %s, requesting test funds\n", devnetNetwork)
+		if fundErr := a.RequestTestFunds(ctx); fundErr != nil {
+			return fmt.Errorf("%w (automatic top-up failed: %v)", err, fundErr)
+		}
+		err = a.sendTransaction(ctx, tx)
+	}
+	return err
+}
+
+// sendTransaction performs a single Circular_AddTransaction_ call for tx.
+func (a *CEPAccount) sendTransaction(ctx context.Context, tx *Transaction) error {
+	sending, failed := "failed to submit certificate", "certificate submission failed"
+	if tx.Type == registerWalletTxType {
+		// A registration comes from the new wallet, so its nonce is not the account's.
+		sending, failed = "failed to register wallet", "wallet registration failed"
+	} else {
+		// Recorded before sending, so that a nonce watch never sees the nonce advance first.
+		a.nwatch.useTxNonce(tx)
+	}
+	resp, err := a.postNAG(ctx, "Circular_AddTransaction_", tx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sending, err)
+	}
+
+	if resp.Result == ResultInsufficientBalance {
+		return resp.resultError(a.insufficientBalanceMessage())
+	}
+	if resp.Result != ResultOK {
+		// Extract the error message from the response if available
+		if errMsg := resp.message(); errMsg != "" {
+			return resp.resultError(fmt.Sprintf("%s: %s", failed, errMsg))
+		}
+		return resp.resultError(failed + " with non-200 result code")
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package circular
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/circulartest"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestComputeTransactionID(t *testing.T) {
+	id := ComputeTransactionID("0xAB", "0xCD", "cd", "7B7D", "1", "2024:01:02-03:04:05")
+	if id != ComputeTransactionID("ab", "cd", "0xCD", "7B7D", "1", "2024:01:02-03:04:05") {
+		t.Error("Expected address normalization to make IDs independent of prefix and case")
+	}
+	if id != ComputeTransactionID("ab", "cd", "cd", "0x7B7D", "1", "2024:01:02-03:04:05") {
+		t.Error("Expected a payload prefix not to change the ID")
+	}
+	if id == ComputeTransactionID("ab", "cd", "cd", "7b7d", "1", "2024:01:02-03:04:05") {
+		t.Error("Expected the payload to be hashed verbatim, case included")
+	}
+	if len(id) != 64 {
+		t.Errorf("Expected a 64-character hex ID, got %q", id)
+	}
+}
+
+func TestSubmitWithPrecomputedID(t *testing.T) {
+	var submitted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &submitted)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.PublicKey = signer.PublicKey()
+
+	// Build the transaction the way an external signing service would.
+	payload := helpers.StringToHex(`{"Action":"CP_CERTIFICATE","Data":"6869"}`)
+	timestamp := "2024:01:02-03:04:05"
+	id := ComputeTransactionID(acc.Blockchain, acc.Address, acc.Address, payload, "7", timestamp)
+	signature, _ := signer.Sign(id)
+	valid := PrecomputedTransaction{ID: id, Payload: payload, Signature: signature, Timestamp: timestamp, Nonce: 7}
+
+	testCases := []struct {
+		name        string
+		mutate      func(tx *PrecomputedTransaction)
+		expectedErr string
+	}{
+		{name: "valid", mutate: func(tx *PrecomputedTransaction) {}},
+		{name: "prefixed hex", mutate: func(tx *PrecomputedTransaction) {
+			tx.ID = "0X" + strings.ToUpper(id)
+			tx.Payload = "0x" + payload
+			tx.Signature = "0x" + strings.ToUpper(signature)
+			tx.PublicKey = "0x" + signer.PublicKey()
+		}},
+		{name: "tampered payload", mutate: func(tx *PrecomputedTransaction) {
+			tx.Payload = helpers.StringToHex(`{"Action":"CP_CERTIFICATE","Data":"00"}`)
+		}, expectedErr: "does not match"},
+		{name: "wrong nonce", mutate: func(tx *PrecomputedTransaction) { tx.Nonce = 8 }, expectedErr: "does not match"},
+		{name: "bad payload", mutate: func(tx *PrecomputedTransaction) { tx.Payload = "zz" }, expectedErr: "not valid hex"},
+		{name: "double encoded payload", mutate: func(tx *PrecomputedTransaction) { tx.Payload = helpers.StringToHex(payload) }, expectedErr: "hex-encoded twice"},
+		{name: "payload without action", mutate: func(tx *PrecomputedTransaction) { tx.Payload = helpers.StringToHex(`{}`) }, expectedErr: "valid envelope"},
+		{name: "bad timestamp", mutate: func(tx *PrecomputedTransaction) { tx.Timestamp = "yesterday" }, expectedErr: "invalid timestamp"},
+		{name: "foreign signature", mutate: func(tx *PrecomputedTransaction) { tx.Signature, _ = signer.Sign("something else") }, expectedErr: "signature does not verify"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			submitted = nil
+			tx := valid
+			tc.mutate(&tx)
+
+			txID, err := acc.SubmitWithPrecomputedID(context.Background(), tx)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectedErr, err)
+				}
+				if submitted != nil {
+					t.Error("Expected nothing to be sent for an inconsistent transaction")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if txID != id || submitted["ID"] != id || submitted["Signature"] != signature || submitted["Payload"] != payload || submitted["Nonce"] != "7" {
+				t.Errorf("Unexpected submitted transaction: %v", submitted)
+			}
+			if acc.Nonce != 8 || acc.LatestTxID != id {
+				t.Errorf("Expected account state to advance, got nonce %d and latest %s", acc.Nonce, acc.LatestTxID)
+			}
+		})
+	}
+}
+
+var updateEnvelopes = flag.Bool("update-envelopes", false, "rewrite the golden envelopes in testdata/envelopes from the current sources")
+
+// TestEnvelopeGolden fails when the request body of a certificate submission changes.
+// The gateway is sensitive to field order, field name casing and hexing, so changes
+// must be deliberate: review the diff, then record the new envelopes with
+//
+//	go test ./circular -run TestEnvelopeGolden -update-envelopes
+func TestEnvelopeGolden(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	params := EnvelopeParams{Blockchain: DefaultChain, Address: "0xabcdef", Nonce: 7, Data: "Hello World", Version: "1.0.13", Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	with := func(change func(*EnvelopeParams)) EnvelopeParams {
+		p := params
+		change(&p)
+		return p
+	}
+
+	cases := []struct {
+		name   string
+		params EnvelopeParams
+		opts   []SubmitOption
+	}{
+		{"text", params, nil},
+		{"json", with(func(p *EnvelopeParams) { p.Data = `{"test":"data"}` }), nil},
+		{"unicode", with(func(p *EnvelopeParams) { p.Data = "Grüße, 世界\n" }), nil},
+		{"empty", with(func(p *EnvelopeParams) { p.Data = "" }), nil},
+		{"uppercase-prefixed-address", with(func(p *EnvelopeParams) {
+			p.Address = "0XABCDEF"
+			p.Blockchain = "0x" + strings.ToUpper(helpers.HexFix(DefaultChain))
+		}), nil},
+		{"recipient", params, []SubmitOption{WithRecipient("0x123456")}},
+		{"content-hash", params, []SubmitOption{WithContentHash()}},
+		{"fixed-nonce", with(func(p *EnvelopeParams) { p.Nonce = 42 }), nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body, err := BuildEnvelopeForTest(c.params, signer, c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, body, "", "  "); err != nil {
+				t.Fatal(err)
+			}
+			indented.WriteByte('\n')
+
+			golden := filepath.Join("testdata", "envelopes", c.name+".json")
+			if *updateEnvelopes {
+				if err := os.WriteFile(golden, indented.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read the golden envelope: %v (record it with -update-envelopes)", err)
+			}
+			if !bytes.Equal(indented.Bytes(), expected) {
+				t.Errorf("Envelope differs from %s:\n got: %s\nwant: %s", golden, indented.Bytes(), expected)
+			}
+		})
+	}
+}
+
+func TestBuildEnvelopeMatchesSubmission(t *testing.T) {
+	var sent []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.String(), "Circular_AddTransaction_") {
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":2}}`)
+			return
+		}
+		sent, _ = io.ReadAll(r.Body)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	if !acc.UpdateAccount() {
+		t.Fatal(acc.LastErr())
+	}
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	acc.SetClock(circulartest.NewFakeClock(stamp))
+	if _, err := acc.SubmitCertificateOn(t.Context(), DefaultChain, "report", signer); err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := BuildEnvelopeForTest(EnvelopeParams{Blockchain: DefaultChain, Address: "0xabcdef", Nonce: 3, Data: "report", Timestamp: stamp}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(built, sent) {
+		t.Errorf("Expected the hook to build the body SubmitCertificate sends:\n got: %s\nwant: %s", built, sent)
+	}
+}
@@ -0,0 +1,182 @@
+package circular
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// QuotaUsage is the consumption of one account quota.
+type QuotaUsage struct {
+	Limit int64 `json:"Limit"` // The quota; zero means the gateway does not limit this dimension.
+	Used  int64 `json:"Used"`  // How much has been consumed in the current period.
+}
+
+// Limited reports whether the gateway enforces this quota.
+func (q QuotaUsage) Limited() bool {
+	return q.Limit > 0
+}
+
+// Remaining returns how much of a limited quota is left, never less than zero.
+func (q QuotaUsage) Remaining() int64 {
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// Usage is the account's consumption of its gateway quotas.
+type Usage struct {
+	Submissions QuotaUsage `json:"Submissions"` // Transactions submitted in the current period.
+	Bytes       QuotaUsage `json:"Bytes"`       // Certificate data bytes submitted in the current period.
+	ResetAt     time.Time  `json:"ResetAt"`     // When the period ends and usage resets; zero if not reported.
+}
+
+// QuotaError is returned when a submission is refused client-side because it would
+// exceed the account's remaining quota; see SetQuotaAware.
+type QuotaError struct {
+	Quota     string    // The exhausted quota: "submissions" or "bytes".
+	Limit     int64     // The quota.
+	Used      int64     // The usage known to the client.
+	Requested int64     // What the refused operation needed.
+	ResetAt   time.Time // When the quota resets, if known.
+}
+
+func (e *QuotaError) Error() string {
+	msg := fmt.Sprintf("%s quota exceeded: %d requested, %d of %d remaining", e.Quota, e.Requested, max(e.Limit-e.Used, 0), e.Limit)
+	if !e.ResetAt.IsZero() {
+		msg += fmt.Sprintf(" until %s", e.ResetAt.Format(time.RFC3339))
+	}
+	return msg
+}
+
+// Code returns CodeQuotaExceeded.
+func (e *QuotaError) Code() ErrorCode {
+	return CodeQuotaExceeded
+}
+
+// GetUsage fetches the account's quota usage from the gateway. The result is cached on
+// the account and, once SetQuotaAware is enabled, kept up to date locally as
+// certificates are submitted. Gateways that do not limit accounts reject the call.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//
+// Returns:
+//
+//	The account's usage, or an error if the account is not open or the gateway does not
+//	report usage.
+func (a *CEPAccount) GetUsage(ctx context.Context) (*Usage, error) {
+	v := a.view()
+	if v.Address == "" {
+		return nil, ErrAccountNotOpen
+	}
+	requestData := map[string]string{
+		"Address":    helpers.HexFix(v.Address),
+		"Blockchain": helpers.HexFix(v.Blockchain),
+		"Version":    v.CodeVersion,
+	}
+	resp, err := a.postNAG(ensureRequestID(ctx), "Circular_GetAccountUsage_", requestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	if err := resp.resultError(""); err != nil {
+		return nil, err
+	}
+	usage := &Usage{}
+	if err := json.Unmarshal(resp.Response, usage); err != nil {
+		return nil, fmt.Errorf("unexpected usage response format: %w", err)
+	}
+	a.quota.set(usage)
+	copied := *usage
+	return &copied, nil
+}
+
+// SetQuotaAware makes the account respect its gateway quotas. When enabled,
+// SubmitCertificates refreshes the usage with GetUsage and refuses a batch that would
+// not fit in the remaining quota before submitting any of it, rather than being cut off
+// part way; every submission is also checked against the usage last fetched, counted
+// down locally, and refused with a *QuotaError once a quota is used up until it resets.
+//
+// Parameters:
+//   - enabled: Whether to enforce quotas client-side.
+func (a *CEPAccount) SetQuotaAware(enabled bool) {
+	a.quota.mu.Lock()
+	defer a.quota.mu.Unlock()
+	a.quota.enabled = enabled
+}
+
+// quotaTracker holds the usage last fetched with GetUsage, counted down as
+// certificates are submitted.
+type quotaTracker struct {
+	mu      sync.Mutex
+	enabled bool
+	usage   *Usage
+}
+
+// set replaces the tracked usage.
+func (q *quotaTracker) set(usage *Usage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	copied := *usage
+	q.usage = &copied
+}
+
+// active reports whether quotas are enforced.
+func (q *quotaTracker) active() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enabled
+}
+
+// check returns a *QuotaError if submitting count certificates of size bytes in total
+// would exceed the tracked usage. Nothing is checked until usage has been fetched.
+func (q *quotaTracker) check(count, size int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.enabled || q.usage == nil {
+		return nil
+	}
+	usage := q.usage
+	if !usage.ResetAt.IsZero() && !time.Now().Before(usage.ResetAt) {
+		// A new period has begun; its limits are assumed unchanged.
+		usage.Submissions.Used, usage.Bytes.Used, usage.ResetAt = 0, 0, time.Time{}
+	}
+	if usage.Submissions.Limited() && count > usage.Submissions.Remaining() {
+		return &QuotaError{Quota: "submissions", Limit: usage.Submissions.Limit, Used: usage.Submissions.Used, Requested: count, ResetAt: usage.ResetAt}
+	}
+	if usage.Bytes.Limited() && size > usage.Bytes.Remaining() {
+		return &QuotaError{Quota: "bytes", Limit: usage.Bytes.Limit, Used: usage.Bytes.Used, Requested: size, ResetAt: usage.ResetAt}
+	}
+	return nil
+}
+
+// consume counts a submitted certificate of size bytes against the tracked usage.
+func (q *quotaTracker) consume(size int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.usage != nil {
+		q.usage.Submissions.Used++
+		q.usage.Bytes.Used += size
+	}
+}
+
+// checkBatchQuota refreshes the account's usage and refuses data as a whole if it
+// would not fit in the remaining quota, when quotas are enforced.
+func (a *CEPAccount) checkBatchQuota(ctx context.Context, data []string) error {
+	if !a.quota.active() {
+		return nil
+	}
+	if _, err := a.GetUsage(ctx); err != nil {
+		return err
+	}
+	var size int64
+	for _, pdata := range data {
+		size += int64(len(pdata))
+	}
+	return a.quota.check(int64(len(data)), size)
+}
@@ -0,0 +1,74 @@
+package circular
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestQuotaAwareSubmissions(t *testing.T) {
+	var added atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetAccountUsage_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Submissions":{"Limit":10,"Used":7},"Bytes":{"Limit":0,"Used":512},"ResetAt":"2099-01-01T00:00:00Z"}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			added.Add(1)
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		}
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	usage, err := acc.GetUsage(t.Context())
+	if err != nil {
+		t.Fatalf("GetUsage() error = %v", err)
+	}
+	if usage.Submissions.Remaining() != 3 || usage.Bytes.Limited() || usage.ResetAt.Year() != 2099 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	// Without quota awareness nothing is refused client-side.
+	if _, err := acc.SubmitCertificates(t.Context(), []string{"a", "b", "c", "d"}, signer); err != nil {
+		t.Fatalf("SubmitCertificates() error = %v", err)
+	}
+
+	acc.SetQuotaAware(true)
+	added.Store(0)
+	result, err := acc.SubmitCertificates(t.Context(), []string{"a", "b", "c", "d"}, signer)
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.Quota != "submissions" || quotaErr.Requested != 4 {
+		t.Fatalf("Expected a submissions QuotaError, got %v", err)
+	}
+	if added.Load() != 0 || len(result.Failed) != 4 {
+		t.Errorf("Expected the whole batch to be refused before submission, got %d submitted", added.Load())
+	}
+
+	if _, err := acc.SubmitCertificates(t.Context(), []string{"a", "b", "c"}, signer); err != nil {
+		t.Fatalf("Expected a batch within the quota to succeed, got %v", err)
+	}
+	if _, err := acc.submitCertificate(t.Context(), "e", signer); !errors.As(err, &quotaErr) {
+		t.Errorf("Expected the locally counted quota to refuse another submission, got %v", err)
+	}
+}
+
+func TestQuotaTrackerResets(t *testing.T) {
+	var q quotaTracker
+	q.enabled = true
+	q.set(&Usage{Bytes: QuotaUsage{Limit: 10, Used: 10}})
+	if err := q.check(1, 1); err == nil {
+		t.Fatal("Expected the bytes quota to be exhausted")
+	}
+	q.usage.ResetAt = q.usage.ResetAt.AddDate(2000, 0, 0) // Long past.
+	if err := q.check(1, 10); err != nil {
+		t.Errorf("Expected the quota to reset after ResetAt, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package circular
+
+import (
+	"runtime"
+	"strings"
+)
+
+// libraryName identifies this SDK in User-Agent strings.
+const libraryName = "circular-enterprise-apis-go"
+
+// ClientNameHeader carries the application name configured with SetApplicationName.
+const ClientNameHeader = "X-Client-Name"
+
+// DefaultUserAgent returns the User-Agent sent when none is configured, in the form
+// "circular-enterprise-apis-go/<LibVersion> (<Go version>; <OS>/<arch>)".
+func DefaultUserAgent() string {
+	return libraryName + "/" + LibVersion + " (" + runtime.Version() + "; " + runtime.GOOS + "/" + runtime.GOARCH + ")"
+}
+
+// SetApplicationName identifies the calling application to the gateway. The name
+// (optionally including a version, e.g. "invoice-service/2.3") is prepended to the
+// default User-Agent and sent in the X-Client-Name header, so gateway operators can
+// attribute traffic to the service that produced it.
+//
+// Parameters:
+//   - name: The application name; an empty string removes it.
+func (a *CEPAccount) SetApplicationName(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.appName = strings.TrimSpace(name)
+}
+
+// SetUserAgent replaces the User-Agent sent with every request. An empty string
+// restores the default, including any application name.
+//
+// Parameters:
+//   - userAgent: The User-Agent header value.
+func (a *CEPAccount) SetUserAgent(userAgent string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.userAgent = userAgent
+}
+
+// UserAgent returns the User-Agent the account sends with its requests.
+func (a *CEPAccount) UserAgent() string {
+	return a.view().userAgentHeader()
+}
+
+// userAgentHeader returns the User-Agent for the viewed configuration.
+func (v accountView) userAgentHeader() string {
+	if v.userAgent != "" {
+		return v.userAgent
+	}
+	if v.appName != "" {
+		return v.appName + " " + DefaultUserAgent()
+	}
+	return DefaultUserAgent()
+}
@@ -0,0 +1,43 @@
+package circular
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	var userAgent, clientName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		clientName = r.Header.Get(ClientNameHeader)
+		fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":1}}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+
+	acc.UpdateAccount()
+	if userAgent != DefaultUserAgent() || !strings.HasPrefix(userAgent, "circular-enterprise-apis-go/"+LibVersion) {
+		t.Errorf("Expected the default User-Agent, got %q", userAgent)
+	}
+	if clientName != "" {
+		t.Errorf("Expected no client name by default, got %q", clientName)
+	}
+
+	acc.SetApplicationName("invoice-service/2.3")
+	acc.UpdateAccount()
+	if userAgent != "invoice-service/2.3 "+DefaultUserAgent() || clientName != "invoice-service/2.3" {
+		t.Errorf("Expected the application to be identified, got %q / %q", userAgent, clientName)
+	}
+
+	acc.SetUserAgent("custom/1.0")
+	acc.UpdateAccount()
+	if userAgent != "custom/1.0" {
+		t.Errorf("Expected the override to be sent, got %q", userAgent)
+	}
+}
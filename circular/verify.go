@@ -0,0 +1,224 @@
+package circular
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// Ways in which certified data can correspond to the original data; see SubmissionReport.
+const (
+	MatchedByData   = "data"   // The certified data is the original data.
+	MatchedBySHA256 = "sha256" // The certified data is the hex SHA-256 digest of the original data.
+)
+
+// SubmissionMismatch describes one way a recorded transaction differs from the submission.
+type SubmissionMismatch struct {
+	Field    string // The payload field that differs, e.g. "Action" or "Data".
+	Expected string // What the submission should have recorded.
+	Actual   string // What the transaction actually records.
+}
+
+// SubmissionReport is the result of comparing a transaction outcome with the data that
+// was submitted.
+type SubmissionReport struct {
+	Action         string               // The payload Action recorded on chain.
+	MatchedBy      string               // MatchedByData or MatchedBySHA256; empty if the data does not match.
+	ExpectedSHA256 string               // Hex SHA-256 digest of the original data.
+	ActualSHA256   string               // Hex SHA-256 digest of the certified data.
+	ContentSHA256  string               // The digest embedded in the envelope by WithContentHash; empty if none.
+	Mismatches     []SubmissionMismatch // Every difference found; empty if the outcome matches.
+}
+
+// OK reports whether the outcome records the submitted data unchanged.
+func (r *SubmissionReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// VerifyOutcomeMatchesSubmission checks that a finalized transaction certifies
+// originalData. The outcome's hex payload envelope is decoded and its data compared to
+// originalData; because pipelines often certify a digest in place of the content, the
+// data also matches when it is the hex SHA-256 digest of originalData. A digest embedded
+// with WithContentHash must be that of originalData.
+//
+// Parameters:
+//   - outcome: The transaction details, as returned by GetTransactionOutcome.
+//   - originalData: The data that was passed to SubmitCertificate.
+//
+// Returns:
+//
+//	A report describing whether and how the data matches, or an error if the outcome
+//	carries no decodable payload.
+func VerifyOutcomeMatchesSubmission(outcome map[string]interface{}, originalData string) (*SubmissionReport, error) {
+	digest := sha256.Sum256([]byte(originalData))
+	return verifyOutcome(outcome, hex.EncodeToString(digest[:]), &originalData)
+}
+
+// VerifyOutcomeDigest checks that a finalized transaction certifies data whose SHA-256
+// digest is sha256Hex, for verifiers that hold only the digest. The certificate matches
+// if its data has that digest, or is the digest itself; a digest embedded with
+// WithContentHash must equal it.
+//
+// Parameters:
+//   - outcome: The transaction details, as returned by GetTransactionOutcome.
+//   - sha256Hex: The hex SHA-256 digest of the original data, with or without prefix.
+//
+// Returns:
+//
+//	A report describing whether and how the data matches, or an error if the outcome
+//	carries no decodable payload.
+func VerifyOutcomeDigest(outcome map[string]interface{}, sha256Hex string) (*SubmissionReport, error) {
+	return verifyOutcome(outcome, helpers.HexFix(sha256Hex), nil)
+}
+
+// verifyOutcome compares the certificate in outcome with data whose digest is
+// expectedSHA256 and, when known, whose contents are originalData.
+func verifyOutcome(outcome map[string]interface{}, expectedSHA256 string, originalData *string) (*SubmissionReport, error) {
+	hexPayload, ok := outcome["Payload"].(string)
+	if !ok {
+		return nil, fmt.Errorf("outcome has no payload")
+	}
+	payload := Payload(hexPayload)
+	action, data, err := payload.Decode()
+	if err != nil {
+		return nil, err
+	}
+	contentHash, _ := payload.ContentHash()
+
+	actualDigest := sha256.Sum256([]byte(data))
+	report := &SubmissionReport{
+		Action:         action,
+		ExpectedSHA256: expectedSHA256,
+		ActualSHA256:   hex.EncodeToString(actualDigest[:]),
+		ContentSHA256:  contentHash,
+	}
+
+	if action != certificateAction {
+		report.Mismatches = append(report.Mismatches, SubmissionMismatch{Field: "Action", Expected: certificateAction, Actual: action})
+	}
+	switch {
+	case originalData != nil && data == *originalData, originalData == nil && report.ActualSHA256 == expectedSHA256:
+		report.MatchedBy = MatchedByData
+	case helpers.HexFix(data) == expectedSHA256:
+		report.MatchedBy = MatchedBySHA256
+	default:
+		report.Mismatches = append(report.Mismatches, SubmissionMismatch{Field: "Data", Expected: expectedSHA256, Actual: report.ActualSHA256})
+	}
+	if contentHash != "" && contentHash != expectedSHA256 {
+		report.Mismatches = append(report.Mismatches, SubmissionMismatch{Field: "SHA256", Expected: expectedSHA256, Actual: contentHash})
+	}
+	return report, nil
+}
+
+// Sources of the public key a transaction signature is verified against; see SignatureReport.
+const (
+	KeySourceRecord   = "record"   // The PublicKey field of the transaction record itself.
+	KeySourceRegistry = "registry" // The key registered on chain for the sending address.
+)
+
+// SignatureReport is the result of verifying the signature of a recorded transaction.
+type SignatureReport struct {
+	TxID              string // The signed transaction ID.
+	Signer            string // The address that sent the transaction.
+	PublicKey         string // The key the signature was verified against.
+	KeySource         string // KeySourceRecord or KeySourceRegistry.
+	RecordKeyMismatch bool   // The record carries a PublicKey that differs from the registered key.
+	Valid             bool   // Whether the signature verifies against PublicKey.
+}
+
+// VerifyTransactionSignature checks that a recorded transaction was signed by the key in
+// its own PublicKey field. This proves only that the record is self-consistent; use
+// CEPAccount.VerifyTransactionSignatureOnChain to establish that the key belongs to the
+// sender.
+//
+// Parameters:
+//   - outcome: The transaction details, as returned by GetTransactionOutcome.
+//
+// Returns:
+//
+//	A report of the verification, or an error if the record lacks the ID, sender,
+//	signature or public key needed to verify it.
+func VerifyTransactionSignature(outcome map[string]interface{}) (*SignatureReport, error) {
+	report, err := newSignatureReport(outcome)
+	if err != nil {
+		return nil, err
+	}
+	recordKey, _ := jsonx.GetString(outcome, "PublicKey")
+	if recordKey == "" {
+		return nil, fmt.Errorf("transaction %s record has no public key", report.TxID)
+	}
+	signature, _ := jsonx.GetString(outcome, "Signature")
+	report.PublicKey = helpers.HexFix(recordKey)
+	report.KeySource = KeySourceRecord
+	report.Valid = VerifySignature(report.PublicKey, report.TxID, signature)
+	return report, nil
+}
+
+// VerifyTransactionSignatureOnChain checks a recorded transaction's signature against
+// the public key registered on chain for its sender, rather than trusting the key the
+// record carries, closing the loop on end-to-end authenticity checks.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the registry lookup.
+//   - outcome: The transaction details, as returned by GetTransactionOutcome.
+//
+// Returns:
+//
+//	A report of the verification, or an error if the record lacks the ID, sender or
+//	signature, or the sender's registered key cannot be resolved.
+func (a *CEPAccount) VerifyTransactionSignatureOnChain(ctx context.Context, outcome map[string]interface{}) (*SignatureReport, error) {
+	report, err := newSignatureReport(outcome)
+	if err != nil {
+		return nil, err
+	}
+	registered, err := a.GetRegisteredPublicKey(ctx, report.Signer)
+	if err != nil {
+		return nil, err
+	}
+	signature, _ := jsonx.GetString(outcome, "Signature")
+	report.PublicKey = registered
+	report.KeySource = KeySourceRegistry
+	if recordKey, _ := jsonx.GetString(outcome, "PublicKey"); recordKey != "" {
+		report.RecordKeyMismatch = helpers.HexFix(recordKey) != registered
+	}
+	report.Valid = VerifySignature(registered, report.TxID, signature)
+	return report, nil
+}
+
+// GetRegisteredPublicKey resolves the public key registered on chain for address, as
+// recorded in its wallet on the account's blockchain.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - address: The account whose key to resolve.
+//
+// Returns:
+//
+//	The registered public key without "0x" prefix, or an error if the wallet cannot be
+//	retrieved or has no public key.
+func (a *CEPAccount) GetRegisteredPublicKey(ctx context.Context, address string) (string, error) {
+	wallet, err := a.getWallet(ensureRequestID(ctx), address)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve public key of %s: %w", address, err)
+	}
+	publicKey, _ := jsonx.GetString(wallet, "PublicKey")
+	if publicKey == "" {
+		return "", fmt.Errorf("no public key is registered for %s", address)
+	}
+	return helpers.HexFix(publicKey), nil
+}
+
+// newSignatureReport starts a report from the fields every signature check needs.
+func newSignatureReport(outcome map[string]interface{}) (*SignatureReport, error) {
+	txID, _ := jsonx.GetString(outcome, "ID")
+	signer, _ := jsonx.GetString(outcome, "From")
+	signature, _ := jsonx.GetString(outcome, "Signature")
+	if txID == "" || signer == "" || signature == "" {
+		return nil, fmt.Errorf("transaction record lacks the ID, sender or signature needed for verification")
+	}
+	return &SignatureReport{TxID: helpers.HexFix(txID), Signer: helpers.HexFix(signer)}, nil
+}
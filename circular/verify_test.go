@@ -0,0 +1,162 @@
+package circular
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// outcomeFor builds a finalized outcome whose payload certifies data under action.
+func outcomeFor(action string, data string) map[string]interface{} {
+	envelope, _ := CanonicalJSON(map[string]string{"Action": action, "Data": helpers.StringToHex(data)})
+	return map[string]interface{}{"Status": "Executed", "Payload": helpers.StringToHex(string(envelope))}
+}
+
+func TestVerifyOutcomeMatchesSubmission(t *testing.T) {
+	digest := sha256.Sum256([]byte("original"))
+
+	testCases := []struct {
+		name      string
+		outcome   map[string]interface{}
+		matchedBy string
+		ok        bool
+	}{
+		{name: "data", outcome: outcomeFor(certificateAction, "original"), matchedBy: MatchedByData, ok: true},
+		{name: "digest", outcome: outcomeFor(certificateAction, hex.EncodeToString(digest[:])), matchedBy: MatchedBySHA256, ok: true},
+		{name: "different data", outcome: outcomeFor(certificateAction, "tampered"), ok: false},
+		{name: "different action", outcome: outcomeFor("CP_OTHER", "original"), matchedBy: MatchedByData, ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := VerifyOutcomeMatchesSubmission(tc.outcome, "original")
+			if err != nil {
+				t.Fatalf("VerifyOutcomeMatchesSubmission() error = %v", err)
+			}
+			if report.OK() != tc.ok || report.MatchedBy != tc.matchedBy {
+				t.Errorf("Unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	var sent Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	digest := sha256.Sum256([]byte("original"))
+	digestHex := hex.EncodeToString(digest[:])
+
+	if _, err := acc.submitCertificate(t.Context(), "original", signer); err != nil {
+		t.Fatal(err)
+	}
+	if hash, err := Payload(sent.Payload).ContentHash(); err != nil || hash != "" {
+		t.Errorf("Expected no content hash by default, got %q, %v", hash, err)
+	}
+
+	if _, err := acc.submitCertificate(t.Context(), "original", signer, WithContentHash()); err != nil {
+		t.Fatal(err)
+	}
+	if hash, err := Payload(sent.Payload).ContentHash(); err != nil || hash != digestHex {
+		t.Errorf("Expected the content hash %s, got %q, %v", digestHex, hash, err)
+	}
+	outcome := map[string]interface{}{"Status": "Executed", "Payload": sent.Payload}
+	report, err := VerifyOutcomeMatchesSubmission(outcome, "original")
+	if err != nil || !report.OK() || report.MatchedBy != MatchedByData || report.ContentSHA256 != digestHex {
+		t.Errorf("Expected the full data to verify, got %+v, %v", report, err)
+	}
+	report, err = VerifyOutcomeDigest(outcome, "0x"+strings.ToUpper(digestHex))
+	if err != nil || !report.OK() || report.MatchedBy != MatchedByData {
+		t.Errorf("Expected the digest to verify against the full-data certificate, got %+v, %v", report, err)
+	}
+
+	// A digest-only certificate verifies against the digest too.
+	if report, _ := VerifyOutcomeDigest(outcomeFor(certificateAction, digestHex), digestHex); !report.OK() || report.MatchedBy != MatchedBySHA256 {
+		t.Errorf("Expected a digest-only certificate to verify, got %+v", report)
+	}
+
+	// An embedded hash that disagrees with the data is reported.
+	envelope, _ := CanonicalJSON(map[string]string{"Action": certificateAction, "Data": helpers.StringToHex("original"), "SHA256": strings.Repeat("0", 64)})
+	forged := map[string]interface{}{"Status": "Executed", "Payload": helpers.StringToHex(string(envelope))}
+	report, _ = VerifyOutcomeMatchesSubmission(forged, "original")
+	if report.OK() || len(report.Mismatches) != 1 || report.Mismatches[0].Field != "SHA256" {
+		t.Errorf("Expected a SHA256 mismatch, got %+v", report)
+	}
+}
+
+func TestVerifyOutcomeWithoutPayload(t *testing.T) {
+	if _, err := VerifyOutcomeMatchesSubmission(map[string]interface{}{"Status": "Executed"}, "original"); err == nil {
+		t.Error("Expected an error for an outcome without a payload")
+	}
+}
+
+// signedOutcome builds a transaction record signed by signer and carrying recordKey.
+func signedOutcome(t *testing.T, signer Signer, recordKey string) map[string]interface{} {
+	txID := ComputeTransactionID(DefaultChain, "0xabcdef", "0xabcdef", "00", "1", "2025:01:01-00:00:00")
+	signature, err := signer.Sign(txID)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return map[string]interface{}{"ID": txID, "From": "0xabcdef", "Signature": signature, "PublicKey": recordKey}
+}
+
+func TestVerifyTransactionSignature(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	other, _ := NewPrivateKeySigner("0x" + strings.Repeat("11", 32))
+
+	report, err := VerifyTransactionSignature(signedOutcome(t, signer, signer.PublicKey()))
+	if err != nil || !report.Valid || report.KeySource != KeySourceRecord {
+		t.Errorf("Expected a valid signature against the record key, got %+v, %v", report, err)
+	}
+	report, err = VerifyTransactionSignature(signedOutcome(t, signer, other.PublicKey()))
+	if err != nil || report.Valid {
+		t.Errorf("Expected an invalid signature against a foreign record key, got %+v, %v", report, err)
+	}
+	if _, err := VerifyTransactionSignature(signedOutcome(t, signer, "")); err == nil {
+		t.Error("Expected an error for a record without a public key")
+	}
+}
+
+func TestVerifyTransactionSignatureOnChain(t *testing.T) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	impostor, _ := NewPrivateKeySigner("0x" + strings.Repeat("11", 32))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Result":200,"Response":{"Address":"abcdef","PublicKey":"0x%s"}}`, signer.PublicKey())
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+
+	report, err := acc.VerifyTransactionSignatureOnChain(t.Context(), signedOutcome(t, signer, signer.PublicKey()))
+	if err != nil || !report.Valid || report.KeySource != KeySourceRegistry || report.RecordKeyMismatch {
+		t.Errorf("Expected a valid signature against the registered key, got %+v, %v", report, err)
+	}
+
+	// A forged record signed by another key and carrying that key is self-consistent,
+	// but fails against the registry.
+	forged := signedOutcome(t, impostor, impostor.PublicKey())
+	if report, err := VerifyTransactionSignature(forged); err != nil || !report.Valid {
+		t.Fatalf("Expected the forged record to be self-consistent, got %+v, %v", report, err)
+	}
+	report, err = acc.VerifyTransactionSignatureOnChain(t.Context(), forged)
+	if err != nil || report.Valid || !report.RecordKeyMismatch {
+		t.Errorf("Expected the forged record to fail against the registered key, got %+v, %v", report, err)
+	}
+}
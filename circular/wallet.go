@@ -0,0 +1,99 @@
+package circular
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+const (
+	// registerWalletTxType is the transaction type that registers a new wallet.
+	registerWalletTxType = "C_TYPE_REGISTERWALLET"
+
+	// registerWalletAction is the payload Action of a wallet registration.
+	registerWalletAction = "CP_REGISTERWALLET"
+)
+
+// AddressFromPublicKey derives the blockchain address of a public key: the hex SHA-256
+// digest of the key's hex encoding, without "0x" prefix.
+//
+// Parameters:
+//   - publicKey: The public key in hexadecimal format.
+//
+// Returns:
+//
+//	The address owned by the key.
+func AddressFromPublicKey(publicKey string) string {
+	hash := sha256.Sum256([]byte(helpers.HexFix(publicKey)))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateAccount registers a new wallet for signer's key on the account's blockchain, so
+// that the key can be used with Open and SubmitCertificate. Registration is a
+// C_TYPE_REGISTERWALLET transaction from the new address to itself, with nonce 0,
+// carrying the public key. The account's own address, if any, is not involved, and the
+// account is left unchanged; open it with the returned address to use the new wallet.
+// The registration counts against the account's quota and is queued in maintenance
+// windows and degraded mode like a certificate.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the request.
+//   - signer: Holds the key of the new wallet.
+//
+// Returns:
+//
+//	The new wallet's address, or an error if signing fails or the gateway rejects the
+//	registration, e.g. because the wallet already exists. Like SubmitCertificate, a
+//	*DeferredError is returned with the address if the registration was queued for a
+//	maintenance window.
+func (a *CEPAccount) CreateAccount(ctx context.Context, signer Signer) (string, error) {
+	if signer == nil {
+		return "", ErrSignerRequired
+	}
+	v := a.view()
+	publicKey := helpers.HexFix(signer.PublicKey())
+	address := AddressFromPublicKey(publicKey)
+
+	envelope, err := CanonicalJSON(map[string]string{
+		"Action":    registerWalletAction,
+		"PublicKey": publicKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode payload: %w", err)
+	}
+	payload, err := EncodePayload(envelope)
+	if err != nil {
+		return "", err
+	}
+	timestamp := v.timeSource().Now().UTC().Format(helpers.TimestampLayout)
+	id := ComputeTransactionID(v.Blockchain, address, address, string(payload), "0", timestamp)
+
+	signature, err := signer.Sign(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign wallet registration: %w", err)
+	}
+
+	tx := &Transaction{
+		Blockchain: helpers.HexFix(v.Blockchain),
+		From:       address,
+		ID:         id,
+		Nonce:      "0",
+		Payload:    string(payload),
+		Signature:  signature,
+		Timestamp:  timestamp,
+		To:         address,
+		Type:       registerWalletTxType,
+		Version:    v.CodeVersion,
+	}
+	if err := a.quota.check(1, 0); err != nil {
+		return "", err
+	}
+	err = a.dispatch(ensureRequestID(ctx), v, tx, &submitConfig{to: address}, 0, nil)
+	if err != nil && !queuedForLater(err) {
+		return "", err
+	}
+	return address, err
+}
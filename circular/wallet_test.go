@@ -0,0 +1,67 @@
+package circular
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateAccount(t *testing.T) {
+	var sent Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	address, err := acc.CreateAccount(t.Context(), signer)
+	if err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+	if address != AddressFromPublicKey(signer.PublicKey()) || len(address) != 64 {
+		t.Errorf("Unexpected address %q", address)
+	}
+	if sent.Type != registerWalletTxType || sent.From != address || sent.To != address || sent.Nonce != "0" {
+		t.Errorf("Unexpected registration transaction: %+v", sent)
+	}
+	if want := ComputeTransactionID(DefaultChain, address, address, sent.Payload, "0", sent.Timestamp); sent.ID != want {
+		t.Errorf("Expected ID %s, got %s", want, sent.ID)
+	}
+	if !VerifySignature(signer.PublicKey(), sent.ID, sent.Signature) {
+		t.Error("Expected the registration to be signed by the new key")
+	}
+	envelope, _ := hex.DecodeString(sent.Payload)
+	var payload map[string]string
+	json.Unmarshal(envelope, &payload)
+	if payload["Action"] != registerWalletAction || payload["PublicKey"] != signer.PublicKey() {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+	if acc.State().Address != "" {
+		t.Error("Expected CreateAccount to leave the account unopened")
+	}
+}
+
+func TestCreateAccountRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Result":110,"Response":"Wallet Already Exists"}`)
+	}))
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	_, err := acc.CreateAccount(t.Context(), signer)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ResultCode != 110 {
+		t.Errorf("Expected an APIError with result 110, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package circular
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	var methods []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = ""
+	if _, err := acc.Warmup(t.Context()); !errors.Is(err, ErrNetworkNotSet) {
+		t.Errorf("Expected ErrNetworkNotSet, got %v", err)
+	}
+
+	acc.NAGURL = server.URL + "/?cep="
+	acc.client = server.Client()
+	report, err := acc.Warmup(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Reused || report.Protocol != "HTTP/2.0" || report.TLSVersion != "TLS 1.3" || report.TLSHandshake <= 0 || report.RemoteAddr != server.Listener.Addr().String() {
+		t.Errorf("Expected a new HTTP/2 connection over TLS 1.3, got %+v", report)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("Expected a single HEAD request, got %v", methods)
+	}
+
+	// The connection is pooled for the calls that follow.
+	if report, err := acc.Warmup(t.Context()); err != nil || !report.Reused || report.TLSHandshake != 0 {
+		t.Errorf("Expected the warmed connection to be reused, got %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,176 @@
+package circular
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+// ErrWatcherCanceled is returned by outcome polls stopped with CancelWatcher.
+var ErrWatcherCanceled = newError(CodeCanceled, "outcome watcher cancelled")
+
+// WatcherInfo describes an outcome poll in flight; see ListWatchers.
+type WatcherInfo struct {
+	TxID      string    // The transaction being waited for.
+	Owner     string    // The owner label set with WithWatcherOwner; empty if none.
+	RequestID string    // The correlation ID shared by the poll's NAG calls.
+	Started   time.Time // When the poll started.
+}
+
+// watcherOwnerKey is the context key under which the owner label is stored.
+type watcherOwnerKey struct{}
+
+// WithWatcherOwner returns a copy of ctx that labels the outcome polls started with it,
+// such as those of WaitForOutcome, SubmitAndWait or SubmitCertificateAsync, with owner,
+// so that ListWatchers can tell which component started each of them.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - owner: The label, e.g. the name of a job or request handler.
+//
+// Returns:
+//
+//	A derived context carrying the label.
+func WithWatcherOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, watcherOwnerKey{}, owner)
+}
+
+// ListWatchers returns the outcome polls the account has in flight, oldest first. A
+// poll leaves the list as soon as it ends or its context is done, even if the goroutine
+// running it has not yet returned.
+//
+// Returns:
+//
+//	A snapshot of the polls, safe to retain.
+func (a *CEPAccount) ListWatchers() []WatcherInfo {
+	return a.watchers.list()
+}
+
+// CancelWatcher stops every outcome poll waiting for txID, which then fail with
+// ErrWatcherCanceled. Unlike AbandonTransaction, it leaves the transaction's receipt
+// untouched, so the outcome can be awaited again later.
+//
+// Parameters:
+//   - txID: The transaction whose polls to stop.
+//
+// Returns:
+//
+//	The number of polls stopped.
+func (a *CEPAccount) CancelWatcher(txID string) int {
+	return a.watchers.cancel(txID, ErrWatcherCanceled)
+}
+
+// watchOutcome derives the context an outcome poll for txID runs under and registers
+// the poll. The context is cancelled by AbandonTransaction and CancelWatcher and, when
+// the transaction's receipt carries a TTL, when the TTL elapses. The poll is
+// unregistered as soon as the context is done, so that one stuck in a call that does
+// not honour its context is not listed forever. The returned function must be called
+// when polling ends.
+func (a *CEPAccount) watchOutcome(ctx context.Context, txID string) (context.Context, func()) {
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	stopDeadline := func() {}
+	if receipt := a.loadReceipt(txID); receipt != nil && !receipt.ExpiresAt.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, receipt.ExpiresAt, ErrTransactionExpired)
+		stopDeadline = cancel
+	}
+	owner, _ := ctx.Value(watcherOwnerKey{}).(string)
+	id := a.watchers.add(WatcherInfo{
+		TxID:      helpers.HexFix(txID),
+		Owner:     owner,
+		RequestID: RequestIDFromContext(ctx),
+		Started:   a.view().timeSource().Now(),
+	}, cancelCause)
+	stopReaping := context.AfterFunc(ctx, func() { a.watchers.remove(txID, id) })
+	return ctx, func() {
+		stopReaping()
+		a.watchers.remove(txID, id)
+		stopDeadline()
+		cancelCause(nil)
+	}
+}
+
+// watcherRegistry tracks the in-flight outcome polls by transaction ID.
+type watcherRegistry struct {
+	mu      sync.Mutex
+	nextID  int
+	entries map[string]map[int]*watcherEntry
+}
+
+// watcherEntry is a registered outcome poll.
+type watcherEntry struct {
+	info   WatcherInfo
+	cancel context.CancelCauseFunc
+}
+
+func (w *watcherRegistry) add(info WatcherInfo, cancel context.CancelCauseFunc) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.entries == nil {
+		w.entries = make(map[string]map[int]*watcherEntry)
+	}
+	if w.entries[info.TxID] == nil {
+		w.entries[info.TxID] = make(map[int]*watcherEntry)
+	}
+	w.nextID++
+	w.entries[info.TxID][w.nextID] = &watcherEntry{info: info, cancel: cancel}
+	return w.nextID
+}
+
+func (w *watcherRegistry) remove(txID string, id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := helpers.HexFix(txID)
+	delete(w.entries[key], id)
+	if len(w.entries[key]) == 0 {
+		delete(w.entries, key)
+	}
+}
+
+// cancel stops every watcher of txID with cause and returns how many there were.
+func (w *watcherRegistry) cancel(txID string, cause error) int {
+	w.mu.Lock()
+	watchers := w.entries[helpers.HexFix(txID)]
+	delete(w.entries, helpers.HexFix(txID))
+	w.mu.Unlock()
+	for _, watcher := range watchers {
+		watcher.cancel(cause)
+	}
+	return len(watchers)
+}
+
+// cancelAll stops every watcher with cause and returns how many there were.
+func (w *watcherRegistry) cancelAll(cause error) int {
+	w.mu.Lock()
+	entries := w.entries
+	w.entries = nil
+	w.mu.Unlock()
+	n := 0
+	for _, watchers := range entries {
+		for _, watcher := range watchers {
+			watcher.cancel(cause)
+			n++
+		}
+	}
+	return n
+}
+
+// list returns the registered watchers, oldest first.
+func (w *watcherRegistry) list() []WatcherInfo {
+	w.mu.Lock()
+	var infos []WatcherInfo
+	for _, watchers := range w.entries {
+		for _, watcher := range watchers {
+			infos = append(infos, watcher.info)
+		}
+	}
+	w.mu.Unlock()
+	slices.SortFunc(infos, func(x, y WatcherInfo) int {
+		return cmp.Or(x.Started.Compare(y.Started), cmp.Compare(x.TxID, y.TxID))
+	})
+	return infos
+}
@@ -0,0 +1,82 @@
+package circular
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitForWatchers waits until the account has n outcome polls in flight.
+func waitForWatchers(t *testing.T, acc *CEPAccount, n int) []WatcherInfo {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); ; {
+		watchers := acc.ListWatchers()
+		if len(watchers) == n {
+			return watchers
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d watchers, got %+v", n, watchers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCancelWatcher(t *testing.T) {
+	server := newReceiptTestServer()
+	defer server.Close()
+
+	acc := NewCEPAccount()
+	acc.NAGURL = server.URL + "/?cep="
+	acc.Open("0xabcdef")
+	acc.SetReceiptStore(NewMemoryReceiptStore())
+	acc.SubmitCertificate("data", testPrivateKey)
+	txID := acc.LatestTxID
+
+	done := make(chan error, 1)
+	go func() {
+		ctx := WithRequestID(WithWatcherOwner(context.Background(), "billing"), "req-1")
+		_, err := acc.WaitForOutcome(ctx, txID)
+		done <- err
+	}()
+
+	watchers := waitForWatchers(t, acc, 1)
+	if w := watchers[0]; w.TxID != txID || w.Owner != "billing" || w.RequestID != "req-1" || w.Started.IsZero() {
+		t.Errorf("Unexpected watcher %+v", w)
+	}
+	if n := acc.CancelWatcher(txID); n != 1 {
+		t.Errorf("CancelWatcher() = %d, want 1", n)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrWatcherCanceled) {
+			t.Errorf("Expected ErrWatcherCanceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the poll to stop after CancelWatcher")
+	}
+	if n := acc.CancelWatcher(txID); n != 0 {
+		t.Errorf("Expected no watchers left, CancelWatcher() = %d", n)
+	}
+	if receipt := acc.loadReceipt(txID); receipt.Status != ReceiptPending {
+		t.Errorf("Expected the receipt to stay pending, got %s", receipt.Status)
+	}
+}
+
+func TestWatcherRemovedWhenContextDone(t *testing.T) {
+	acc := NewCEPAccount()
+	ctx, cancel := context.WithCancel(context.Background())
+	// The poll never calls its stop function, as one stuck in a gateway call would not.
+	acc.watchOutcome(ctx, "0x01")
+	_, stop := acc.watchOutcome(context.Background(), "0x02")
+	defer stop()
+
+	watchers := waitForWatchers(t, acc, 2)
+	if watchers[0].TxID != "01" || watchers[1].TxID != "02" {
+		t.Errorf("Expected the watchers oldest first, got %+v", watchers)
+	}
+	cancel()
+	if watchers := waitForWatchers(t, acc, 1); watchers[0].TxID != "02" {
+		t.Errorf("Expected only the live watcher to remain, got %+v", watchers)
+	}
+}
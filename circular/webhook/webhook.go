@@ -0,0 +1,177 @@
+// Package webhook defines the events the SDK's tools post to webhooks, such as those of
+// "circular-cli watch --webhook", and verifies their signatures. It depends on the
+// standard library alone, so that services receiving the events can validate them
+// without importing the SDK.
+//
+// A signed request carries a Circular-Signature header of the form
+//
+//	t=1760000000,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+//
+// where t is the Unix time of signing and v1 the hex HMAC-SHA256, under the shared
+// secret, of t, a period and the request body. A header may carry several v1 values,
+// e.g. while the secret is being rotated; the signature is valid if any of them is.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the HTTP header that carries an event's signature.
+const SignatureHeader = "Circular-Signature"
+
+// DefaultTolerance is how far from the current time a signature's timestamp may be
+// before VerifySignature rejects it as a replay.
+const DefaultTolerance = 5 * time.Minute
+
+// MaxBodySize is the largest request body ReadEvent accepts.
+const MaxBodySize = 1 << 20
+
+var (
+	// ErrInvalidSignature is returned when a request is unsigned, its signature header is
+	// malformed, or no signature in it matches the body and secret.
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+	// ErrSignatureExpired is returned when a signature is valid but its timestamp is
+	// outside the tolerance, as for a replayed request.
+	ErrSignatureExpired = errors.New("webhook: signature timestamp outside tolerance")
+)
+
+// Event reports a change in the state of a transaction tracked by the SDK's tools.
+type Event struct {
+	Time        time.Time // When the change was observed.
+	TxID        string    // The transaction.
+	Status      string    // The transaction's receipt status, e.g. "Finalized", "Expired", "Abandoned", or "Failed" if tracking stopped with an error.
+	FinalStatus string    `json:",omitempty"` // The on-chain status, once finalized, e.g. "Executed".
+	Error       string    `json:",omitempty"` // Why tracking stopped, unless finalized.
+	Code        string    `json:",omitempty"` // The SDK error code of Error, e.g. "CIRC-2003".
+}
+
+// Sign returns the signature header value for body, signed with secret at time at.
+//
+// Parameters:
+//   - body: The request body to sign.
+//   - secret: The secret shared with the receiver.
+//   - at: The time of signing, usually time.Now().
+//
+// Returns:
+//
+//	The value of the SignatureHeader.
+func Sign(body []byte, secret string, at time.Time) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(t, body, secret))
+}
+
+// VerifySignature checks that header, the value of the SignatureHeader of a request,
+// signs body with secret, and that it was made within DefaultTolerance of now.
+//
+// Parameters:
+//   - header: The value of the request's SignatureHeader.
+//   - body: The request body, exactly as received.
+//   - secret: The secret shared with the sender.
+//
+// Returns:
+//
+//	Nil if the signature is valid, ErrInvalidSignature if it is not, or
+//	ErrSignatureExpired if it is too old or too far in the future.
+func VerifySignature(header string, body []byte, secret string) error {
+	return VerifySignatureAt(header, body, secret, time.Now(), DefaultTolerance)
+}
+
+// VerifySignatureAt is VerifySignature with the current time and tolerance given, for
+// receivers that need a different tolerance and for tests.
+//
+// Parameters:
+//   - header: The value of the request's SignatureHeader.
+//   - body: The request body, exactly as received.
+//   - secret: The secret shared with the sender.
+//   - now: The time to check the signature's timestamp against.
+//   - tolerance: How far the timestamp may be from now; zero or negative disables the
+//     check.
+//
+// Returns:
+//
+//	Nil if the signature is valid, ErrInvalidSignature if it is not, or
+//	ErrSignatureExpired if its timestamp is outside the tolerance.
+func VerifySignatureAt(header string, body []byte, secret string, now time.Time, tolerance time.Duration) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no secret to verify with", ErrInvalidSignature)
+	}
+	var t string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	signedAt, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, SignatureHeader)
+	}
+
+	expected := mac(t, body, secret)
+	valid := false
+	for _, signature := range signatures {
+		valid = hmac.Equal(signature, expected) || valid
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(signedAt, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// ReadEvent reads the body of a webhook request, verifies its signature with secret as
+// VerifySignature does, and decodes the event it carries.
+//
+// Parameters:
+//   - r: The incoming request; its body is consumed.
+//   - secret: The secret shared with the sender.
+//
+// Returns:
+//
+//	The event, or an error if the body cannot be read or exceeds MaxBodySize, the
+//	signature is not valid, or the body is not an event.
+func ReadEvent(r *http.Request, secret string) (*Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to read body: %w", err)
+	}
+	if len(body) > MaxBodySize {
+		return nil, fmt.Errorf("webhook: body exceeds %d bytes", MaxBodySize)
+	}
+	if err := VerifySignature(r.Header.Get(SignatureHeader), body, secret); err != nil {
+		return nil, err
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("webhook: invalid event: %w", err)
+	}
+	return &event, nil
+}
+
+// mac returns the HMAC-SHA256 of the signed content for timestamp t and body.
+func mac(t string, body []byte, secret string) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(t))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
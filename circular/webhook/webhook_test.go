@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"TxID":"aa11","Status":"Finalized"}`)
+	now := time.Now()
+	header := Sign(body, "whsec", now)
+
+	if err := VerifySignature(header, body, "whsec"); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := VerifySignature(header, []byte(`{"TxID":"bb22"}`), "whsec"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a tampered body to be refused, got %v", err)
+	}
+	if err := VerifySignature(header, body, "other"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected the wrong secret to be refused, got %v", err)
+	}
+	for _, malformed := range []string{"", "v1=00", "t=1", "t=x," + strings.Split(header, ",")[1]} {
+		if err := VerifySignature(malformed, body, "whsec"); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected %q to be refused, got %v", malformed, err)
+		}
+	}
+	if err := VerifySignature(header, body, ""); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected an empty secret to be refused, got %v", err)
+	}
+
+	// A header signed with both the old and the new secret verifies under either.
+	rotated := header + ",v1=" + strings.TrimPrefix(strings.Split(Sign(body, "next", now), ",")[1], "v1=")
+	if err := VerifySignature(rotated, body, "next"); err != nil {
+		t.Errorf("Expected any matching signature to be accepted, got %v", err)
+	}
+
+	old := Sign(body, "whsec", now.Add(-time.Hour))
+	if err := VerifySignature(old, body, "whsec"); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("Expected an old signature to be refused, got %v", err)
+	}
+	if err := VerifySignatureAt(old, body, "whsec", now, 0); err != nil {
+		t.Errorf("Expected no tolerance to disable the check, got %v", err)
+	}
+}
+
+func TestReadEvent(t *testing.T) {
+	body := `{"Time":"2026-01-02T03:04:05Z","TxID":"aa11","Status":"Expired","Error":"expired","Code":"CIRC-2003"}`
+	r := httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	r.Header.Set(SignatureHeader, Sign([]byte(body), "whsec", time.Now()))
+	event, err := ReadEvent(r, "whsec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.TxID != "aa11" || event.Status != "Expired" || event.Code != "CIRC-2003" || event.Time.Year() != 2026 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	r = httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	if _, err := ReadEvent(r, "whsec"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected an unsigned request to be refused, got %v", err)
+	}
+}
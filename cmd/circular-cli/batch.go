@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// batchManifest is the file read by "cert submit-batch":
+//
+//	defaults:
+//	  metadata:
+//	    department: finance
+//	items:
+//	  - file: reports/q1.pdf       # relative to the manifest
+//	    metadata:
+//	      quarter: Q1
+//	  - id: greeting               # names the item in the report
+//	    data: hello
+type batchManifest struct {
+	Defaults struct {
+		Metadata map[string]string `json:"metadata"` // Metadata of every item, unless the item overrides it.
+	} `json:"defaults"`
+	Items []batchManifestItem `json:"items"`
+}
+
+// batchManifestItem is one certificate of a manifest; exactly one of File and Data is set.
+type batchManifestItem struct {
+	ID       string            `json:"id"`       // Names the item in the report; defaults to File.
+	File     string            `json:"file"`     // A file whose contents are certified.
+	Data     string            `json:"data"`     // Inline data to certify.
+	Metadata map[string]string `json:"metadata"` // Metadata certified with the data.
+}
+
+// batchReportRow is the result of one manifest item.
+type batchReportRow struct {
+	Index  int    // The position of the item in the manifest.
+	ID     string // The item's ID or file.
+	TxID   string // The submitted transaction, if submission succeeded.
+	Status string // "Submitted", "Failed", or the transaction's final status with --wait.
+	Error  string // Why submission or waiting failed.
+	Code   string // The ErrorCode of Error.
+}
+
+// Statuses of a batchReportRow besides the transaction's own status.
+const (
+	statusSubmitted = "Submitted"
+	statusFailed    = "Failed"
+)
+
+func setupCertSubmitBatch(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	wait := fs.Bool("wait", false, "wait for every transaction to be finalized")
+	concurrency := fs.Int("concurrency", 8, "number of transactions to wait for at once")
+	retries := fs.Int("retries", -1, "retries of each failed gateway call (default: the SDK's retry policy)")
+	report := fs.String("report", "", "write a results report to this file")
+	reportFormat := fs.String("report-format", "", "report format: csv or json (default: from the report file's extension)")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a manifest file")
+		}
+		if *concurrency < 1 {
+			return nil, usagef("--concurrency must be at least 1")
+		}
+		format, err := reportFormatOf(*report, *reportFormat)
+		if err != nil {
+			return nil, err
+		}
+		ids, data, err := loadBatchManifest(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		acc, err := env.openAccount()
+		if err != nil {
+			return nil, err
+		}
+		signer, err := env.signer()
+		if err != nil {
+			return nil, err
+		}
+		if *retries >= 0 {
+			policy := circular.DefaultRetryPolicy()
+			policy.MaxAttempts = *retries + 1
+			acc.SetRetryPolicy(policy)
+		}
+		if err := env.syncNonce(ctx, acc); err != nil {
+			return nil, err
+		}
+
+		rows := make([]batchReportRow, len(data))
+		for i := range rows {
+			rows[i] = batchReportRow{Index: i, ID: ids[i]}
+		}
+		var failures []error
+		submitted, _ := acc.SubmitCertificates(ctx, data, signer)
+		for _, failure := range submitted.Failed {
+			rows[failure.Index].Status = statusFailed
+			rows[failure.Index].Error = failure.Err.Error()
+			rows[failure.Index].Code = string(circular.ErrorCodeOf(failure.Err))
+			failures = append(failures, &circular.BatchItemError{Index: failure.Index, Key: ids[failure.Index], Err: failure.Err})
+		}
+		var txIDs []string
+		for _, item := range submitted.Succeeded {
+			rows[item.Index].TxID = item.Value
+			rows[item.Index].Status = statusSubmitted
+			txIDs = append(txIDs, item.Value)
+		}
+		if *wait {
+			failures = append(failures, waitForRows(ctx, acc, rows, *concurrency)...)
+		}
+
+		if *report != "" {
+			if err := writeReport(*report, format, rows); err != nil {
+				return nil, err
+			}
+		}
+		res := &result{TxIDs: txIDs, Value: rows}
+		if len(failures) > 0 {
+			return res, &circular.MultiError{Errors: failures}
+		}
+		return res, nil
+	}
+}
+
+// loadBatchManifest reads a manifest and returns the ID and data to certify of each item.
+func loadBatchManifest(path string) ([]string, []string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := parseYAML(raw)
+	if err != nil {
+		return nil, nil, usagef("invalid manifest %s: %v", path, err)
+	}
+	encoded, _ := json.Marshal(doc)
+	decoder := json.NewDecoder(strings.NewReader(string(encoded)))
+	decoder.DisallowUnknownFields()
+	var manifest batchManifest
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, nil, usagef("invalid manifest %s: %v", path, err)
+	}
+	if len(manifest.Items) == 0 {
+		return nil, nil, usagef("manifest %s has no items", path)
+	}
+
+	ids := make([]string, len(manifest.Items))
+	data := make([]string, len(manifest.Items))
+	for i, item := range manifest.Items {
+		ids[i] = item.ID
+		content := item.Data
+		switch {
+		case (item.File == "") == (item.Data == ""):
+			return nil, nil, usagef("manifest %s: item %d must have either file or data", path, i)
+		case item.File != "":
+			if ids[i] == "" {
+				ids[i] = item.File
+			}
+			file := item.File
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			contents, err := os.ReadFile(file)
+			if err != nil {
+				return nil, nil, fmt.Errorf("manifest %s: item %d: %w", path, i, err)
+			}
+			content = string(contents)
+		}
+		if ids[i] == "" {
+			ids[i] = "item " + strconv.Itoa(i)
+		}
+
+		metadata := make(map[string]string, len(manifest.Defaults.Metadata)+len(item.Metadata))
+		for key, value := range manifest.Defaults.Metadata {
+			metadata[key] = value
+		}
+		for key, value := range item.Metadata {
+			metadata[key] = value
+		}
+		if data[i], err = certificateWithMetadata(content, metadata); err != nil {
+			return nil, nil, fmt.Errorf("manifest %s: item %d: %w", path, i, err)
+		}
+	}
+	return ids, data, nil
+}
+
+// certificateWithMetadata returns the data to certify for content: content itself, or,
+// with metadata, the canonical JSON document {"Data": content, "Metadata": metadata}.
+func certificateWithMetadata(content string, metadata map[string]string) (string, error) {
+	if len(metadata) == 0 {
+		return content, nil
+	}
+	doc, err := circular.CanonicalJSON(map[string]interface{}{"Data": content, "Metadata": metadata})
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+// waitForRows waits for the submitted transactions of rows, at most concurrency at a
+// time, recording each outcome, and returns the failures.
+func waitForRows(ctx context.Context, acc *circular.CEPAccount, rows []batchReportRow, concurrency int) []error {
+	var mu sync.Mutex
+	var failures []error
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i := range rows {
+		if rows[i].TxID == "" {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(row *batchReportRow) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			outcomes, err := acc.WaitForOutcomes(ctx, []string{row.TxID})
+			if err != nil {
+				row.Status = statusFailed
+				row.Error = outcomes.Failed[0].Err.Error()
+				row.Code = string(circular.ErrorCodeOf(outcomes.Failed[0].Err))
+				mu.Lock()
+				failures = append(failures, &circular.BatchItemError{Index: row.Index, Key: row.ID, Err: outcomes.Failed[0].Err})
+				mu.Unlock()
+				return
+			}
+			row.Status, _ = jsonx.GetString(outcomes.Succeeded[0].Value, "Status")
+		}(&rows[i])
+	}
+	wg.Wait()
+	return failures
+}
+
+// reportFormatOf returns the report format given with --report-format, or implied by
+// the report file's extension.
+func reportFormatOf(path, format string) (string, error) {
+	if format == "" {
+		format = formatJSON
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			format = "csv"
+		}
+	}
+	if format != "csv" && format != formatJSON {
+		return "", usagef("unknown report format %q; use csv or json", format)
+	}
+	return format, nil
+}
+
+// writeReport writes rows to path as CSV or JSON.
+func writeReport(path, format string, rows []batchReportRow) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if format == "csv" {
+		err = writeCSVReport(f, rows)
+	} else {
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(rows)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// writeCSVReport writes rows to w as CSV with a header line.
+func writeCSVReport(w io.Writer, rows []batchReportRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Index", "ID", "TxID", "Status", "Error", "Code"})
+	for _, row := range rows {
+		cw.Write([]string{strconv.Itoa(row.Index), row.ID, row.TxID, row.Status, row.Error, row.Code})
+	}
+	cw.Flush()
+	return cw.Error()
+}
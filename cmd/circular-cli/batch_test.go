@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestCertSubmitBatch(t *testing.T) {
+	var payloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":1}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			var tx circular.Transaction
+			json.NewDecoder(r.Body).Decode(&tx)
+			payloads = append(payloads, tx.Payload)
+			if strings.Contains(helpers.HexToString(tx.Payload), helpers.StringToHex("reject me")) {
+				fmt.Fprint(w, `{"Result":108,"Response":"Invalid Transaction"}`)
+				return
+			}
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("CIRCULAR_PRIVATE_KEY", testPrivateKey)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("file contents"), 0o644)
+	manifest := filepath.Join(dir, "manifest.yaml")
+	os.WriteFile(manifest, []byte(`
+defaults:
+  metadata:
+    department: finance
+items:
+  - file: a.txt
+    metadata:
+      quarter: Q1
+  - id: second
+    data: reject me
+`), 0o644)
+	report := filepath.Join(dir, "report.csv")
+
+	code, stdout, stderr := runCLI(t, "--quiet", "--nag", server.URL+"/?cep=", "--address", "0xabcdef",
+		"cert", "submit-batch", "--wait", "--retries", "0", "--report", report, manifest)
+	if code != exitRejected {
+		t.Fatalf("Expected exit status %d for a rejected item, got %d: %s", exitRejected, code, stderr)
+	}
+	if lines := strings.Fields(stdout); len(lines) != 1 || len(lines[0]) != 64 {
+		t.Errorf("Expected the one transaction ID, got %q", stdout)
+	}
+	if len(payloads) != 2 || !strings.Contains(helpers.HexToString(payloads[0]), helpers.StringToHex(`{"Data":"file contents","Metadata":{"department":"finance","quarter":"Q1"}}`)) {
+		t.Errorf("Expected the file to be certified with its merged metadata")
+	}
+
+	f, _ := os.Open(report)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("Expected a header and two rows, got %v, %v", rows, err)
+	}
+	if rows[1][1] != "a.txt" || rows[1][3] != "Executed" || rows[2][1] != "second" || rows[2][3] != statusFailed || rows[2][4] == "" {
+		t.Errorf("Unexpected report: %v", rows)
+	}
+}
+
+func TestLoadBatchManifestRejectsInvalidItems(t *testing.T) {
+	dir := t.TempDir()
+	for _, doc := range []string{"items: []", "items:\n  - id: x", "items:\n  - data: x\n    file: y", "items:\n  - data: x\n    colour: red"} {
+		path := filepath.Join(dir, "manifest.yaml")
+		os.WriteFile(path, []byte(doc), 0o644)
+		if _, _, err := loadBatchManifest(path); err == nil {
+			t.Errorf("Expected an error for %q", doc)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/keystore"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+)
+
+// options holds the global flags, which are accepted before or after the command name.
+type options struct {
+	output   string
+	quiet    bool
+	verbose  bool
+	network  string
+	nag      string
+	chain    string
+	address  string
+	keystore string
+	timeout  time.Duration
+	mainnet  bool
+	nonces   string
+	nonceAge time.Duration
+	profile  string
+
+	set map[string]bool // The flags given on the command line, by name.
+}
+
+// defaultOptions returns the global flags' defaults.
+func defaultOptions() *options {
+	return &options{output: formatTable, timeout: time.Minute, nonceAge: 10 * time.Minute}
+}
+
+// register adds the global flags to fs, defaulting to their current values so that
+// flags parsed before the command name are kept.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "output", o.output, "output format: json, yaml or table")
+	fs.BoolVar(&o.quiet, "quiet", o.quiet, "print only the transaction ID")
+	fs.BoolVar(&o.verbose, "verbose", o.verbose, "log SDK activity to standard error")
+	fs.StringVar(&o.network, "network", o.network, "network to discover the gateway of, e.g. testnet")
+	fs.StringVar(&o.nag, "nag", o.nag, "gateway URL to use instead of network discovery")
+	fs.StringVar(&o.chain, "chain", o.chain, "blockchain ID (default: the SDK's default chain)")
+	fs.StringVar(&o.address, "address", o.address, "account address (default: $CIRCULAR_ADDRESS, or the keystore's)")
+	fs.StringVar(&o.keystore, "keystore", o.keystore, "sign with the key in this keystore instead of $CIRCULAR_PRIVATE_KEY")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "time limit for the command")
+	fs.BoolVar(&o.mainnet, "allow-mainnet", o.mainnet, "permit writes on mainnet (also $CIRCULAR_ALLOW_MAINNET)")
+	fs.StringVar(&o.nonces, "nonce-store", o.nonces, "persist the nonce in this directory and reuse it across runs")
+	fs.DurationVar(&o.nonceAge, "nonce-max-age", o.nonceAge, "how long a persisted nonce is used without asking the gateway")
+	fs.StringVar(&o.profile, "profile", o.profile, "take unset global flags from this profile; see config set-profile")
+}
+
+// command is a CLI subcommand.
+type command struct {
+	name    string // The words that select the command, e.g. "cert submit".
+	args    string // The synopsis of the positional arguments.
+	summary string // A one-line description.
+	daemon  bool   // Whether the command runs until interrupted, ignoring --timeout.
+
+	// setup registers the command's flags on fs and returns the function that runs it
+	// with the positional arguments.
+	setup func(fs *flag.FlagSet) func(ctx context.Context, env *env, args []string) (*result, error)
+}
+
+// commands lists the CLI's subcommands.
+var commands = []*command{
+	{name: "cert submit", args: "[data]", summary: "Submit a certificate", setup: setupCertSubmit},
+	{name: "cert submit-batch", args: "<manifest.yaml>", summary: "Submit the certificates listed in a manifest", setup: setupCertSubmitBatch},
+	{name: "cert import", args: "<dataset.csv>", summary: "Certify the rows of a CSV dataset, with checkpointing", setup: setupCertImport},
+	{name: "cert verify", summary: "Verify a certificate's ID, signature and, optionally, the file it certifies", setup: setupCertVerify},
+	{name: "tx get", args: "<txid>", summary: "Show a transaction", setup: setupTxGet},
+	{name: "tx outcome", args: "<txid>", summary: "Wait for a transaction to be finalized", setup: setupTxOutcome},
+	{name: "account nonce", summary: "Show the account's next nonce", setup: setupAccountNonce},
+	{name: "watch", summary: "Track the pending transactions of a receipt store until interrupted", setup: setupWatch, daemon: true},
+	{name: "keys new", args: "<keystore>", summary: "Generate a key and encrypt it to a new keystore file", setup: setupKeysNew},
+	{name: "keys inspect", args: "<keystore>", summary: "Show a keystore's address and check its password", setup: setupKeysInspect},
+	{name: "config set-profile", args: "<name>", summary: "Save the given global flags as a named profile", setup: setupConfigSetProfile},
+	{name: "config profiles", summary: "List the saved profiles", setup: setupConfigProfiles},
+}
+
+// usageError reports an invalid command line.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usagef returns a usageError with a formatted message.
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// findCommand returns the command named by the leading words of args and the remaining
+// arguments.
+func findCommand(args []string) (*command, []string) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):]
+		}
+	}
+	return nil, args
+}
+
+// printUsage writes the list of commands and global flags to w.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: circular-cli [global flags] <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-28s %s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	fs := flag.NewFlagSet("circular-cli", flag.ContinueOnError)
+	fs.SetOutput(w)
+	defaultOptions().register(fs)
+	fs.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use --describe-commands to print the commands and flags as JSON.")
+}
+
+// execute parses args, runs the selected command and prints its result to stdout.
+func execute(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	opts := defaultOptions()
+	global := flag.NewFlagSet("circular-cli", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	opts.register(global)
+	describeCommands := global.Bool("describe-commands", false, "")
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printUsage(stderr)
+			return nil
+		}
+		return usagef("%v", err)
+	}
+	if *describeCommands {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(describe())
+	}
+
+	cmd, rest := findCommand(global.Args())
+	if cmd == nil {
+		printUsage(stderr)
+		if len(global.Args()) == 0 {
+			return usagef("no command given")
+		}
+		return usagef("unknown command %q", strings.Join(global.Args(), " "))
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: circular-cli %s [flags] %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	opts.register(fs)
+	runCmd := cmd.setup(fs)
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usagef("%v", err)
+	}
+	opts.set = explicitFlags(global, fs)
+	if opts.profile != "" {
+		if err := applyProfile(fs, opts); err != nil {
+			return err
+		}
+	}
+	if !isFormat(opts.output) {
+		return usagef("unknown output format %q; use json, yaml or table", opts.output)
+	}
+
+	if opts.timeout > 0 && !cmd.daemon {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	res, err := runCmd(ctx, &env{opts: opts, stdout: stdout, stderr: stderr}, fs.Args())
+	if res != nil {
+		if printErr := printResult(stdout, opts, res); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+	return err
+}
+
+// env gives commands access to the account and key described by the global flags and
+// the environment.
+type env struct {
+	opts   *options
+	stdout io.Writer     // Receives the output of commands that stream it.
+	stderr io.Writer     // Receives prompts.
+	input  *bufio.Reader // Answers prompts; standard input unless set.
+	acc    *circular.CEPAccount
+	ks     *keystore.Keystore
+}
+
+// account returns the account, configured from the global flags on first use.
+func (e *env) account() (*circular.CEPAccount, error) {
+	if e.acc != nil {
+		return e.acc, nil
+	}
+	acc := circular.NewCEPAccount()
+	if !e.opts.verbose {
+		acc.SetLogLevel(circular.LogSilent)
+	}
+	switch {
+	case e.opts.nag != "":
+		name := e.opts.network
+		if name == "" {
+			name = "custom"
+		}
+		if err := acc.SetNetworkProfile(circular.NetworkProfile{Name: name, BaseURL: e.opts.nag}); err != nil {
+			return nil, usagef("invalid --nag: %v", err)
+		}
+	case e.opts.network != "":
+		if acc.SetNetwork(e.opts.network) == "" {
+			return nil, acc.LastErr()
+		}
+	}
+	acc.SetGuard(circular.Guard{AllowMainnet: e.opts.mainnet})
+	if e.opts.chain != "" {
+		acc.SetBlockchain(e.opts.chain)
+	}
+	address := e.opts.address
+	if address == "" {
+		address = os.Getenv("CIRCULAR_ADDRESS")
+	}
+	if address == "" && e.opts.keystore != "" {
+		ks, err := e.keystore()
+		if err != nil {
+			return nil, err
+		}
+		address = ks.Address
+	}
+	if address != "" && !acc.Open(address) {
+		return nil, usagef("invalid account address: %s", acc.GetLastError())
+	}
+	e.acc = acc
+	return acc, nil
+}
+
+// openAccount returns the account, requiring that an address was given.
+func (e *env) openAccount() (*circular.CEPAccount, error) {
+	acc, err := e.account()
+	if err != nil {
+		return nil, err
+	}
+	if acc.State().Address == "" {
+		return nil, usagef("no account address; use --address or set CIRCULAR_ADDRESS")
+	}
+	return acc, nil
+}
+
+// syncNonce brings the account's nonce up to date before submitting: from the nonce
+// store given with --nonce-store when it holds a fresh nonce, and from the gateway
+// otherwise.
+func (e *env) syncNonce(ctx context.Context, acc *circular.CEPAccount) error {
+	if e.opts.nonces == "" {
+		_, err := acc.ResyncNonce(ctx)
+		return err
+	}
+	kv, err := storage.NewFile(e.opts.nonces)
+	if err != nil {
+		return err
+	}
+	acc.SetNonceStore(storage.NewDocumentStore(kv), e.opts.nonceAge)
+	_, err = acc.RestoreNonce(ctx)
+	return err
+}
+
+// keystore returns the keystore given with --keystore.
+func (e *env) keystore() (*keystore.Keystore, error) {
+	if e.ks == nil {
+		ks, err := keystore.Load(e.opts.keystore)
+		if err != nil {
+			return nil, err
+		}
+		e.ks = ks
+	}
+	return e.ks, nil
+}
+
+// signer returns a signer for the key in the --keystore, or in CIRCULAR_PRIVATE_KEY.
+func (e *env) signer() (circular.Signer, error) {
+	if e.opts.keystore != "" {
+		ks, err := e.keystore()
+		if err != nil {
+			return nil, err
+		}
+		password, err := e.password("", false)
+		if err != nil {
+			return nil, err
+		}
+		return ks.Decrypt(password)
+	}
+	key := os.Getenv("CIRCULAR_PRIVATE_KEY")
+	if key == "" {
+		return nil, usagef("no private key; set CIRCULAR_PRIVATE_KEY")
+	}
+	signer, err := circular.NewPrivateKeySigner(key)
+	if err != nil {
+		return nil, usagef("invalid CIRCULAR_PRIVATE_KEY: %v", err)
+	}
+	return signer, nil
+}
+
+func setupCertSubmit(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	file := fs.String("file", "", "read the certificate data from a file (- for standard input)")
+	wait := fs.Bool("wait", false, "wait for the transaction to be finalized")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		data, err := certificateData(*file, args)
+		if err != nil {
+			return nil, err
+		}
+		acc, err := env.openAccount()
+		if err != nil {
+			return nil, err
+		}
+		signer, err := env.signer()
+		if err != nil {
+			return nil, err
+		}
+		if err := env.syncNonce(ctx, acc); err != nil {
+			return nil, err
+		}
+
+		if *wait {
+			submitted, err := acc.SubmitAndWait(ctx, data, signer)
+			if submitted == nil {
+				return nil, err
+			}
+			return &result{TxID: submitted.TxID, Value: map[string]interface{}{
+				"TxID":    submitted.TxID,
+				"Outcome": submitted.Outcome,
+			}}, err
+		}
+		batch, err := acc.SubmitCertificates(ctx, []string{data}, signer)
+		if err != nil {
+			return nil, batch.Failed[0].Err
+		}
+		txID := batch.Succeeded[0].Value
+		return &result{TxID: txID, Value: map[string]interface{}{"TxID": txID}}, nil
+	}
+}
+
+// certificateData returns the data to certify: the single positional argument, or the
+// contents of file.
+func certificateData(file string, args []string) (string, error) {
+	switch {
+	case file != "" && len(args) > 0:
+		return "", usagef("give the data as an argument or with --file, not both")
+	case file == "-":
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	case file != "":
+		data, err := os.ReadFile(file)
+		return string(data), err
+	case len(args) == 1:
+		return args[0], nil
+	default:
+		return "", usagef("expected the certificate data as a single argument or --file")
+	}
+}
+
+func setupTxGet(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a transaction ID")
+		}
+		acc, err := env.account()
+		if err != nil {
+			return nil, err
+		}
+		batch, err := acc.GetTransactions(ctx, args)
+		if err != nil {
+			return nil, batch.Failed[0].Err
+		}
+		return &result{TxID: args[0], Value: transaction(batch.Succeeded[0].Value)}, nil
+	}
+}
+
+func setupTxOutcome(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a transaction ID")
+		}
+		acc, err := env.account()
+		if err != nil {
+			return nil, err
+		}
+		batch, err := acc.WaitForOutcomes(ctx, args)
+		if err != nil {
+			return nil, batch.Failed[0].Err
+		}
+		return &result{TxID: args[0], Value: transaction(batch.Succeeded[0].Value)}, nil
+	}
+}
+
+// transaction returns the transaction details from a gateway response, dropping the
+// envelope's result code.
+func transaction(response map[string]interface{}) interface{} {
+	if details, ok := response["Response"].(map[string]interface{}); ok {
+		return details
+	}
+	return response
+}
+
+func setupAccountNonce(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 0 {
+			return nil, usagef("unexpected arguments: %s", strings.Join(args, " "))
+		}
+		acc, err := env.openAccount()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := acc.ResyncNonce(ctx); err != nil {
+			return nil, err
+		}
+		state := acc.State()
+		return &result{Value: map[string]interface{}{
+			"Address":    state.Address,
+			"Blockchain": state.Blockchain,
+			"Nonce":      state.Nonce,
+		}}, nil
+	}
+}
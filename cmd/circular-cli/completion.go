@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The completion command describes the command list it belongs to, so it is added once
+// the list is initialized.
+func init() {
+	commands = append(commands, &command{name: "completion", args: "bash|zsh|fish", summary: "Print a shell completion script", setup: setupCompletion})
+}
+
+// cliDescription is the machine-readable description printed by --describe-commands.
+type cliDescription struct {
+	Name        string               `json:"name"`
+	GlobalFlags []flagDescription    `json:"globalFlags"`
+	Commands    []commandDescription `json:"commands"`
+	ExitCodes   map[string]int       `json:"exitCodes"`
+}
+
+// commandDescription describes one command.
+type commandDescription struct {
+	Name    string            `json:"name"`           // The words that select the command.
+	Args    string            `json:"args,omitempty"` // The synopsis of the positional arguments.
+	Summary string            `json:"summary"`
+	Flags   []flagDescription `json:"flags"` // The command's own flags; the global flags also apply.
+}
+
+// flagDescription describes one flag.
+type flagDescription struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"` // "bool", "string", "int", "float", "duration" or "value".
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+}
+
+// describe returns the description of the CLI's commands and flags.
+func describe() *cliDescription {
+	global := flag.NewFlagSet("circular-cli", flag.ContinueOnError)
+	defaultOptions().register(global)
+	desc := &cliDescription{
+		Name:        "circular-cli",
+		GlobalFlags: describeFlags(global),
+		ExitCodes: map[string]int{
+			"ok":       exitOK,
+			"failure":  exitFailure,
+			"usage":    exitUsage,
+			"timeout":  exitTimeout,
+			"rejected": exitRejected,
+			"network":  exitNetwork,
+			"mismatch": exitMismatch,
+		},
+	}
+	for _, cmd := range commands {
+		fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		cmd.setup(fs)
+		desc.Commands = append(desc.Commands, commandDescription{
+			Name:    cmd.name,
+			Args:    cmd.args,
+			Summary: cmd.summary,
+			Flags:   describeFlags(fs),
+		})
+	}
+	return desc
+}
+
+// describeFlags describes the flags defined in fs, in name order.
+func describeFlags(fs *flag.FlagSet) []flagDescription {
+	flags := []flagDescription{}
+	fs.VisitAll(func(f *flag.Flag) {
+		kind, usage := flag.UnquoteUsage(f)
+		if kind == "" {
+			kind = "bool"
+		}
+		flags = append(flags, flagDescription{Name: f.Name, Type: kind, Default: f.DefValue, Usage: usage})
+	})
+	return flags
+}
+
+// argWords returns the fixed choices of a command's argument synopsis such as
+// "bash|zsh|fish", or nil if the argument is free-form.
+func argWords(args string) []string {
+	if !strings.Contains(args, "|") || strings.ContainsAny(args, "<>[] ") {
+		return nil
+	}
+	return strings.Split(args, "|")
+}
+
+// flagNames returns the flags as "--name" words.
+func flagNames(flags []flagDescription) []string {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = "--" + f.Name
+	}
+	return names
+}
+
+// completionTree groups command words: the top-level words, and the second words of
+// each top-level word that has them.
+func completionTree(desc *cliDescription) ([]string, map[string][]string) {
+	var top []string
+	sub := map[string][]string{}
+	for _, cmd := range desc.Commands {
+		words := strings.Fields(cmd.Name)
+		if _, seen := sub[words[0]]; !seen {
+			top = append(top, words[0])
+			sub[words[0]] = nil
+		}
+		if len(words) > 1 {
+			sub[words[0]] = append(sub[words[0]], words[1])
+		}
+	}
+	return top, sub
+}
+
+// commandsByLength returns the commands with the longest names first, so that shell
+// patterns match the most specific command.
+func commandsByLength(desc *cliDescription) []commandDescription {
+	cmds := append([]commandDescription(nil), desc.Commands...)
+	sort.SliceStable(cmds, func(i, j int) bool { return len(cmds[i].Name) > len(cmds[j].Name) })
+	return cmds
+}
+
+// writeBashCompletion writes a bash completion script for desc to w.
+func writeBashCompletion(w io.Writer, desc *cliDescription) {
+	global := strings.Join(flagNames(desc.GlobalFlags), " ")
+	top, sub := completionTree(desc)
+
+	fmt.Fprintln(w, "# bash completion for circular-cli; load with: source <(circular-cli completion bash)")
+	fmt.Fprintln(w, "_circular_cli() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintln(w, `    local words=" ${COMP_WORDS[*]:1:COMP_CWORD-1} " opts`)
+	fmt.Fprintf(w, "    if [[ \"$prev\" == --output ]]; then\n        COMPREPLY=($(compgen -W \"%s %s %s\" -- \"$cur\"))\n        return\n    fi\n", formatJSON, formatYAML, formatTable)
+	fmt.Fprintln(w, `    case "$words" in`)
+	for _, cmd := range commandsByLength(desc) {
+		words := append(argWords(cmd.Args), flagNames(cmd.Flags)...)
+		fmt.Fprintf(w, "    *\" %s \"*) opts=%q ;;\n", cmd.Name, strings.Join(append(words, global), " "))
+	}
+	for _, word := range top {
+		if len(sub[word]) > 0 {
+			fmt.Fprintf(w, "    *\" %s \"*) opts=%q ;;\n", word, strings.Join(sub[word], " "))
+		}
+	}
+	fmt.Fprintf(w, "    *) opts=%q ;;\n", strings.Join(append(top, global), " "))
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, `    COMPREPLY=($(compgen -W "$opts" -- "$cur"))`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o default -F _circular_cli circular-cli")
+}
+
+// writeZshCompletion writes a zsh completion script for desc to w.
+func writeZshCompletion(w io.Writer, desc *cliDescription) {
+	global := strings.Join(flagNames(desc.GlobalFlags), " ")
+	top, sub := completionTree(desc)
+
+	fmt.Fprintln(w, "#compdef circular-cli")
+	fmt.Fprintln(w, "# zsh completion for circular-cli; load with: source <(circular-cli completion zsh)")
+	fmt.Fprintln(w, "_circular_cli() {")
+	fmt.Fprintln(w, `    local line=" ${words[2,CURRENT-1]} "`)
+	fmt.Fprintln(w, "    local -a opts")
+	fmt.Fprintf(w, "    if [[ \"${words[CURRENT-1]}\" == --output ]]; then\n        compadd -- %s %s %s\n        return\n    fi\n", formatJSON, formatYAML, formatTable)
+	fmt.Fprintln(w, `    case "$line" in`)
+	for _, cmd := range commandsByLength(desc) {
+		words := append(argWords(cmd.Args), flagNames(cmd.Flags)...)
+		fmt.Fprintf(w, "    *\" %s \"*) opts=(%s %s) ;;\n", cmd.Name, strings.Join(words, " "), global)
+	}
+	for _, word := range top {
+		if len(sub[word]) > 0 {
+			fmt.Fprintf(w, "    *\" %s \"*) opts=(%s) ;;\n", word, strings.Join(sub[word], " "))
+		}
+	}
+	fmt.Fprintf(w, "    *) opts=(%s %s) ;;\n", strings.Join(top, " "), global)
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "    compadd -- $opts")
+	fmt.Fprintln(w, "    _files")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "compdef _circular_cli circular-cli")
+}
+
+// writeFishCompletion writes a fish completion script for desc to w.
+func writeFishCompletion(w io.Writer, desc *cliDescription) {
+	top, sub := completionTree(desc)
+	topList := strings.Join(top, " ")
+
+	fmt.Fprintln(w, "# fish completion for circular-cli; load with: circular-cli completion fish | source")
+	fmt.Fprintf(w, "complete -c circular-cli -n %s -f -a %s\n", fishQuote("not __fish_seen_subcommand_from "+topList), fishQuote(topList))
+	for _, word := range top {
+		if len(sub[word]) == 0 {
+			continue
+		}
+		subList := strings.Join(sub[word], " ")
+		condition := fmt.Sprintf("__fish_seen_subcommand_from %s; and not __fish_seen_subcommand_from %s", word, subList)
+		fmt.Fprintf(w, "complete -c circular-cli -n %s -f -a %s\n", fishQuote(condition), fishQuote(subList))
+	}
+	for _, f := range desc.GlobalFlags {
+		fmt.Fprintln(w, fishFlag("", f))
+	}
+	for _, cmd := range desc.Commands {
+		words := strings.Fields(cmd.Name)
+		condition := "__fish_seen_subcommand_from " + words[0]
+		if len(words) > 1 {
+			condition += "; and __fish_seen_subcommand_from " + words[1]
+		}
+		if choices := argWords(cmd.Args); choices != nil {
+			fmt.Fprintf(w, "complete -c circular-cli -n %s -f -a %s\n", fishQuote(condition), fishQuote(strings.Join(choices, " ")))
+		}
+		for _, f := range cmd.Flags {
+			fmt.Fprintln(w, fishFlag(condition, f))
+		}
+	}
+}
+
+// fishFlag returns the fish complete command for flag f, offered when condition holds.
+func fishFlag(condition string, f flagDescription) string {
+	line := "complete -c circular-cli"
+	if condition != "" {
+		line += " -n " + fishQuote(condition)
+	}
+	line += fmt.Sprintf(" -l %s -d %s", f.Name, fishQuote(f.Usage))
+	switch {
+	case f.Name == "output":
+		line += " -x -a " + fishQuote(strings.Join([]string{formatJSON, formatYAML, formatTable}, " "))
+	case f.Type != "bool":
+		line += " -r"
+	}
+	return line
+}
+
+// fishQuote quotes s for fish, which expands variables inside double quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func setupCompletion(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a shell: bash, zsh or fish")
+		}
+		var b strings.Builder
+		switch args[0] {
+		case "bash":
+			writeBashCompletion(&b, describe())
+		case "zsh":
+			writeZshCompletion(&b, describe())
+		case "fish":
+			writeFishCompletion(&b, describe())
+		default:
+			return nil, usagef("unsupported shell %q; use bash, zsh or fish", args[0])
+		}
+		return &result{Text: b.String()}, nil
+	}
+}
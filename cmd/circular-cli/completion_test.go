@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescribeCommands(t *testing.T) {
+	code, stdout, _ := runCLI(t, "--describe-commands")
+	var desc cliDescription
+	if code != exitOK || json.Unmarshal([]byte(stdout), &desc) != nil {
+		t.Fatalf("Expected a JSON description, got %d %q", code, stdout)
+	}
+	if desc.ExitCodes["rejected"] != exitRejected || len(desc.GlobalFlags) == 0 {
+		t.Errorf("Unexpected description: %+v", desc)
+	}
+	var batch *commandDescription
+	for i := range desc.Commands {
+		if desc.Commands[i].Name == "cert submit-batch" {
+			batch = &desc.Commands[i]
+		}
+	}
+	if batch == nil {
+		t.Fatal("Expected cert submit-batch to be described")
+	}
+	want := flagDescription{Name: "concurrency", Type: "int", Default: "8", Usage: "number of transactions to wait for at once"}
+	if batch.Flags[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, batch.Flags[0])
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	code, script, _ := runCLI(t, "completion", "bash")
+	if code != exitOK {
+		t.Fatalf("Expected exit status 0, got %d", code)
+	}
+	path := filepath.Join(t.TempDir(), "completion.bash")
+	os.WriteFile(path, []byte(script), 0o644)
+
+	complete := func(words ...string) string {
+		cmd := exec.Command(bash, "-c", `source "$0"; COMP_WORDS=("$@"); COMP_CWORD=$(($#-1)); _circular_cli; echo "${COMPREPLY[*]}"`, path)
+		cmd.Args = append(cmd.Args, append([]string{"circular-cli"}, words...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash failed: %v: %s", err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if got := complete("cert", "sub"); got != "submit submit-batch" {
+		t.Errorf("Expected the cert subcommands, got %q", got)
+	}
+	if got := complete("--output", "json", "cert", "submit-batch", "--re"); got != "--report --report-format --retries" {
+		t.Errorf("Expected the submit-batch flags, got %q", got)
+	}
+	if got := complete("--output", "y"); got != "yaml" {
+		t.Errorf("Expected the output formats, got %q", got)
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range []string{"zsh", "fish"} {
+		code, script, _ := runCLI(t, "completion", shell)
+		if code != exitOK || !strings.Contains(script, "submit-batch") {
+			t.Errorf("Expected a %s script, got %d", shell, code)
+		}
+	}
+	if code, _, _ := runCLI(t, "completion", "powershell"); code != exitUsage {
+		t.Errorf("Expected exit status %d for an unsupported shell, got %d", exitUsage, code)
+	}
+}
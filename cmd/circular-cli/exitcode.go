@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+// Exit statuses; see the package documentation.
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitTimeout  = 3
+	exitRejected = 4
+	exitNetwork  = 5
+	exitMismatch = 6
+)
+
+// exitCode maps err to the exit status for its class of failure.
+//
+// Parameters:
+//   - err: The error a command failed with, or nil.
+//
+// Returns:
+//
+//	exitTimeout when a deadline passed or a transaction expired, exitRejected when the
+//	gateway refused the request or the transaction, exitNetwork when the gateway could
+//	not be reached, was overloaded or failed, exitMismatch when a certificate failed
+//	verification, exitUsage for an invalid command line and exitFailure otherwise.
+func exitCode(err error) int {
+	var usageErr *usageError
+	var verifyErr *verificationError
+	var apiErr *circular.APIError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &verifyErr):
+		return exitMismatch
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, circular.ErrTransactionExpired):
+		return exitTimeout
+	case errors.As(err, &apiErr):
+		if apiErr.HTTPStatus >= http.StatusInternalServerError || apiErr.HTTPStatus == http.StatusTooManyRequests {
+			return exitNetwork
+		}
+		return exitRejected
+	case errors.Is(err, circular.ErrTransactionNotFound), errors.Is(err, circular.ErrTransactionAbandoned):
+		return exitRejected
+	case errors.As(err, &netErr):
+		return exitNetwork
+	default:
+		return exitFailure
+	}
+}
+
+// printError writes err to w for the user, with its ErrorCode unless it is a usage error.
+func printError(w io.Writer, err error) {
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(w, "circular-cli: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "circular-cli: %v [%s]\n", err, circular.ErrorCodeOf(err))
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+// statusSkipped marks the rows of an import that were not submitted, because they
+// precede --from-row or the import stopped before reaching them.
+const statusSkipped = "Skipped"
+
+// importCheckpoint is the progress of "cert import", saved after every chunk so that an
+// interrupted import resumes where it stopped.
+type importCheckpoint struct {
+	Dataset string           `json:"dataset"` // The absolute path of the imported CSV file.
+	NextRow int              `json:"nextRow"` // The first row not yet submitted, counting from 1.
+	Rows    []batchReportRow `json:"rows"`    // The results of the rows submitted so far.
+}
+
+// importSummary reconciles an import with its dataset.
+type importSummary struct {
+	Rows      int            // The data rows in the dataset.
+	Submitted int            // Rows accepted by the gateway, in this run or earlier ones.
+	Failed    int            // Rows whose submission or outcome failed.
+	Skipped   int            // Rows not submitted.
+	Statuses  map[string]int // The number of rows in each status.
+}
+
+// importReport is the result of "cert import".
+type importReport struct {
+	Dataset string
+	Summary importSummary
+	Rows    []batchReportRow
+}
+
+func setupCertImport(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	dataColumn := fs.String("data-column", "data", "column holding the document or digest to certify")
+	fileColumn := fs.String("file-column", "", "column holding a file, relative to the dataset, whose contents are certified instead")
+	idColumn := fs.String("id-column", "id", "column naming each row in the report (default: the row number if absent)")
+	metadataColumns := fs.String("metadata-columns", "", "comma-separated columns certified as metadata with the data")
+	fromRow := fs.Int("from-row", 0, "first row to submit, counting from 1 after the header (default: 1, or where the checkpoint stopped)")
+	checkpoint := fs.String("checkpoint", "", "save progress to this file after every chunk, and resume from it if it exists")
+	chunk := fs.Int("chunk", 100, "number of rows submitted between checkpoints")
+	wait := fs.Bool("wait", false, "wait for every transaction to be finalized")
+	concurrency := fs.Int("concurrency", 8, "number of transactions to wait for at once")
+	report := fs.String("report", "", "write a reconciliation report of every row to this file")
+	reportFormat := fs.String("report-format", "", "report format: csv or json (default: from the report file's extension)")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a CSV dataset")
+		}
+		if strings.EqualFold(filepath.Ext(args[0]), ".parquet") {
+			return nil, usagef("Parquet datasets are not supported; export the dataset to CSV")
+		}
+		if *chunk < 1 || *concurrency < 1 {
+			return nil, usagef("--chunk and --concurrency must be at least 1")
+		}
+		format, err := reportFormatOf(*report, *reportFormat)
+		if err != nil {
+			return nil, err
+		}
+		columns := importColumns{data: *dataColumn, file: *fileColumn, id: *idColumn}
+		if *metadataColumns != "" {
+			columns.metadata = strings.Split(*metadataColumns, ",")
+		}
+		ids, data, err := loadImportDataset(args[0], columns)
+		if err != nil {
+			return nil, err
+		}
+		dataset, err := filepath.Abs(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		state := &importCheckpoint{Dataset: dataset, NextRow: 1}
+		if *checkpoint != "" {
+			if state, err = loadImportCheckpoint(*checkpoint, dataset); err != nil {
+				return nil, err
+			}
+		}
+		if *fromRow > 0 {
+			state.NextRow = *fromRow
+		}
+
+		acc, err := env.openAccount()
+		if err != nil {
+			return nil, err
+		}
+		signer, err := env.signer()
+		if err != nil {
+			return nil, err
+		}
+		if err := env.syncNonce(ctx, acc); err != nil {
+			return nil, err
+		}
+
+		// The errors of this run, which keep their classification for the exit status;
+		// failures of earlier runs are known only by their message.
+		errs := make(map[int]error)
+		for start := state.NextRow; start <= len(data) && ctx.Err() == nil; start += *chunk {
+			end := min(start+*chunk, len(data)+1)
+			submitted, _ := acc.SubmitCertificates(ctx, data[start-1:end-1], signer)
+			rows := make([]batchReportRow, end-start)
+			for i := range rows {
+				rows[i] = batchReportRow{Index: start + i, ID: ids[start-1+i]}
+			}
+			for _, item := range submitted.Succeeded {
+				rows[item.Index].TxID = item.Value
+				rows[item.Index].Status = statusSubmitted
+			}
+			for _, failure := range submitted.Failed {
+				if errors.Is(failure.Err, context.Canceled) || errors.Is(failure.Err, context.DeadlineExceeded) {
+					// Interrupted, not failed: resume from the first row cut short.
+					end = min(end, start+failure.Index)
+					continue
+				}
+				rows[failure.Index].Status = statusFailed
+				rows[failure.Index].Error = failure.Err.Error()
+				rows[failure.Index].Code = string(circular.ErrorCodeOf(failure.Err))
+				errs[start+failure.Index] = failure.Err
+			}
+			state.Rows = mergeImportRows(state.Rows, rows[:end-start])
+			state.NextRow = end
+			if *checkpoint != "" {
+				if err := saveImportCheckpoint(*checkpoint, state); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if *wait {
+			for _, failure := range waitForRows(ctx, acc, state.Rows, *concurrency) {
+				var itemErr *circular.BatchItemError
+				if errors.As(failure, &itemErr) {
+					errs[itemErr.Index] = itemErr.Err
+				}
+			}
+			if *checkpoint != "" {
+				if err := saveImportCheckpoint(*checkpoint, state); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		res := reconcileImport(dataset, ids, state.Rows)
+		if *report != "" {
+			if err := writeReport(*report, format, res.Rows); err != nil {
+				return nil, err
+			}
+		}
+		var txIDs []string
+		var failures []error
+		for _, row := range state.Rows {
+			if row.TxID != "" && row.Status != statusFailed {
+				txIDs = append(txIDs, row.TxID)
+			}
+			if row.Status == statusFailed {
+				err, ok := errs[row.Index]
+				if !ok {
+					err = &recordedError{msg: row.Error, code: circular.ErrorCode(row.Code)}
+				}
+				failures = append(failures, &circular.BatchItemError{Index: row.Index, Key: row.ID, Err: err})
+			}
+		}
+		out := &result{TxIDs: txIDs, Value: res}
+		if err := ctx.Err(); err != nil {
+			return out, fmt.Errorf("import stopped at row %d: %w", state.NextRow, err)
+		}
+		if len(failures) > 0 {
+			return out, &circular.MultiError{Errors: failures}
+		}
+		return out, nil
+	}
+}
+
+// recordedError is a failure of an earlier run, known from its checkpoint.
+type recordedError struct {
+	msg  string
+	code circular.ErrorCode
+}
+
+func (e *recordedError) Error() string {
+	return e.msg
+}
+
+// Code returns the code recorded with the failure.
+func (e *recordedError) Code() circular.ErrorCode {
+	return e.code
+}
+
+// importColumns names the columns of an import dataset.
+type importColumns struct {
+	data     string   // The data to certify, unless file is set.
+	file     string   // A file whose contents are certified.
+	id       string   // The row's name in the report; optional.
+	metadata []string // Columns certified as metadata.
+}
+
+// loadImportDataset reads a CSV file with a header line and returns the ID and data to
+// certify of each data row.
+func loadImportDataset(path string, columns importColumns) ([]string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, nil, usagef("invalid dataset %s: %v", path, err)
+	}
+	if len(records) < 2 {
+		return nil, nil, usagef("dataset %s has no rows", path)
+	}
+	index := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		index[strings.TrimSpace(name)] = i
+	}
+	content := columns.data
+	if columns.file != "" {
+		content = columns.file
+	}
+	for _, name := range append([]string{content}, columns.metadata...) {
+		if _, ok := index[name]; !ok {
+			return nil, nil, usagef("dataset %s has no column %q", path, name)
+		}
+	}
+
+	rows := records[1:]
+	ids := make([]string, len(rows))
+	data := make([]string, len(rows))
+	for i, record := range rows {
+		ids[i] = strconv.Itoa(i + 1)
+		if col, ok := index[columns.id]; ok && record[col] != "" {
+			ids[i] = record[col]
+		}
+		value := record[index[content]]
+		if columns.file != "" {
+			file := value
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			contents, err := os.ReadFile(file)
+			if err != nil {
+				return nil, nil, fmt.Errorf("dataset %s: row %d: %w", path, i+1, err)
+			}
+			value = string(contents)
+		}
+		metadata := make(map[string]string, len(columns.metadata))
+		for _, name := range columns.metadata {
+			metadata[name] = record[index[name]]
+		}
+		if data[i], err = certificateWithMetadata(value, metadata); err != nil {
+			return nil, nil, fmt.Errorf("dataset %s: row %d: %w", path, i+1, err)
+		}
+	}
+	return ids, data, nil
+}
+
+// loadImportCheckpoint reads the checkpoint at path, or returns a fresh one if the file
+// does not exist. It refuses a checkpoint of another dataset.
+func loadImportCheckpoint(path, dataset string) (*importCheckpoint, error) {
+	state := &importCheckpoint{Dataset: dataset, NextRow: 1}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, state); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if state.Dataset != dataset {
+		return nil, usagef("checkpoint %s belongs to dataset %s", path, state.Dataset)
+	}
+	return state, nil
+}
+
+// saveImportCheckpoint replaces the checkpoint at path with state.
+func saveImportCheckpoint(path string, state *importCheckpoint) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// mergeImportRows returns rows with the results in latest added, replacing earlier
+// results of the same rows, in row order.
+func mergeImportRows(rows, latest []batchReportRow) []batchReportRow {
+	byIndex := make(map[int]batchReportRow, len(rows)+len(latest))
+	for _, row := range append(rows, latest...) {
+		byIndex[row.Index] = row
+	}
+	merged := make([]batchReportRow, 0, len(byIndex))
+	for _, row := range byIndex {
+		merged = append(merged, row)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Index < merged[j].Index })
+	return merged
+}
+
+// reconcileImport returns the report of an import of the dataset with the given row
+// IDs, given the results of the rows submitted: every row appears, those without a
+// result as skipped.
+func reconcileImport(dataset string, ids []string, submitted []batchReportRow) *importReport {
+	byIndex := make(map[int]batchReportRow, len(submitted))
+	for _, row := range submitted {
+		byIndex[row.Index] = row
+	}
+	report := &importReport{Dataset: dataset, Summary: importSummary{Rows: len(ids), Statuses: make(map[string]int)}}
+	for i, id := range ids {
+		row, ok := byIndex[i+1]
+		if !ok {
+			row = batchReportRow{Index: i + 1, ID: id, Status: statusSkipped}
+		}
+		switch {
+		case row.Status == statusSkipped:
+			report.Summary.Skipped++
+		case row.Status == statusFailed:
+			report.Summary.Failed++
+			if row.TxID != "" {
+				report.Summary.Submitted++
+			}
+		default:
+			report.Summary.Submitted++
+		}
+		report.Summary.Statuses[row.Status]++
+		report.Rows = append(report.Rows, row)
+	}
+	return report
+}
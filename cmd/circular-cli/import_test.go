@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+)
+
+func TestCertImportResumesFromCheckpoint(t *testing.T) {
+	var payloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":1}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			var tx circular.Transaction
+			json.NewDecoder(r.Body).Decode(&tx)
+			payloads = append(payloads, helpers.HexToString(tx.Payload))
+			if strings.Contains(payloads[len(payloads)-1], helpers.StringToHex("reject me")) {
+				fmt.Fprint(w, `{"Result":108,"Response":"Invalid Transaction"}`)
+				return
+			}
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"Status":"Executed"}}`)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("CIRCULAR_PRIVATE_KEY", testPrivateKey)
+
+	dir := t.TempDir()
+	dataset := filepath.Join(dir, "history.csv")
+	os.WriteFile(dataset, []byte("id,digest,dept\ndoc-1,aa11,finance\ndoc-2,reject me,finance\ndoc-3,cc33,legal\n"), 0o644)
+	checkpoint := filepath.Join(dir, "import.json")
+	report := filepath.Join(dir, "report.csv")
+	importArgs := func(extra ...string) []string {
+		args := []string{"--quiet", "--nag", server.URL + "/?cep=", "--address", "0xabcdef", "cert", "import",
+			"--data-column", "digest", "--metadata-columns", "dept", "--checkpoint", checkpoint, "--chunk", "2", "--report", report}
+		return append(append(args, extra...), dataset)
+	}
+
+	// Start at row 2, as if row 1 had been certified by another tool.
+	code, stdout, stderr := runCLI(t, importArgs("--from-row", "2")...)
+	if code != exitRejected {
+		t.Fatalf("Expected exit status %d for a rejected row, got %d: %s", exitRejected, code, stderr)
+	}
+	if len(payloads) != 2 || !strings.Contains(payloads[1], helpers.StringToHex(`{"Data":"cc33","Metadata":{"dept":"legal"}}`)) {
+		t.Errorf("Expected rows 2 and 3 to be certified with their metadata, got %d submissions", len(payloads))
+	}
+	if lines := strings.Fields(stdout); len(lines) != 1 {
+		t.Errorf("Expected the one transaction ID, got %q", stdout)
+	}
+	var state importCheckpoint
+	raw, _ := os.ReadFile(checkpoint)
+	if err := json.Unmarshal(raw, &state); err != nil || state.NextRow != 4 || len(state.Rows) != 2 {
+		t.Fatalf("Expected a checkpoint past the last row, got %+v, %v", state, err)
+	}
+
+	// Resuming finds nothing left to submit but still reconciles every row; the earlier
+	// rejection is known by its message and code.
+	code, _, stderr = runCLI(t, importArgs("--wait")...)
+	if code != exitFailure || len(payloads) != 2 || !strings.Contains(stderr, "["+string(circular.CodeRejected)+"]") {
+		t.Fatalf("Expected no new submissions, got %d (exit %d: %s)", len(payloads), code, stderr)
+	}
+	f, _ := os.Open(report)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("Expected a header and three rows, got %v, %v", rows, err)
+	}
+	if rows[1][1] != "doc-1" || rows[1][3] != statusSkipped || rows[2][3] != statusFailed || rows[3][1] != "doc-3" || rows[3][3] != "Executed" {
+		t.Errorf("Unexpected report: %v", rows)
+	}
+}
+
+func TestCertImportRejectsInvalidDatasets(t *testing.T) {
+	dir := t.TempDir()
+	for name, doc := range map[string]string{"empty.csv": "data\n", "columns.csv": "id,text\n1,x\n", "data.parquet": "PAR1"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(doc), 0o644)
+		if code, _, _ := runCLI(t, "--address", "0xabcdef", "cert", "import", path); code != exitUsage {
+			t.Errorf("Expected a usage error for %s, got exit status %d", name, code)
+		}
+	}
+
+	dataset := filepath.Join(dir, "other.csv")
+	os.WriteFile(dataset, []byte("data\nx\n"), 0o644)
+	checkpoint := filepath.Join(dir, "checkpoint.json")
+	os.WriteFile(checkpoint, []byte(`{"dataset":"/elsewhere.csv","nextRow":2}`), 0o644)
+	if code, _, _ := runCLI(t, "--address", "0xabcdef", "cert", "import", "--checkpoint", checkpoint, dataset); code != exitUsage {
+		t.Errorf("Expected a usage error for another dataset's checkpoint, got exit status %d", code)
+	}
+}
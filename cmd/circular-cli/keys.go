@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/keystore"
+)
+
+// passwordEnv names the environment variable the keystore password may be given in.
+const passwordEnv = "CIRCULAR_KEYSTORE_PASSWORD"
+
+// password returns the keystore password from file, $CIRCULAR_KEYSTORE_PASSWORD, or a
+// prompt on the terminal, which is asked twice when confirm is set.
+func (e *env) password(file string, confirm bool) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password := os.Getenv(passwordEnv); password != "" {
+		return password, nil
+	}
+	if e.input == nil {
+		e.input = bufio.NewReader(os.Stdin)
+	}
+	password, err := e.prompt("Keystore password: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := e.prompt("Repeat password: ")
+		if err != nil {
+			return "", err
+		}
+		if again != password {
+			return "", usagef("passwords do not match")
+		}
+	}
+	return password, nil
+}
+
+// prompt writes msg to standard error and reads a line of input.
+func (e *env) prompt(msg string) (string, error) {
+	fmt.Fprint(e.stderr, msg)
+	line, err := e.input.ReadString('\n')
+	if err != nil && line == "" {
+		return "", usagef("no password given; use --password-file or set %s", passwordEnv)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func setupKeysNew(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	passwordFile := fs.String("password-file", "", "read the password from a file")
+	iterations := fs.Int("kdf-iterations", 0, "PBKDF2 iterations (default: the keystore's default)")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected the keystore file to create")
+		}
+		if _, err := os.Stat(args[0]); err == nil {
+			return nil, usagef("%s already exists", args[0])
+		}
+		password, err := env.password(*passwordFile, true)
+		if err != nil {
+			return nil, err
+		}
+		key, err := circular.GeneratePrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		ks, err := keystore.Encrypt(key, password, *iterations)
+		if err != nil {
+			return nil, usagef("%v", err)
+		}
+		if err := keystore.Save(args[0], ks); err != nil {
+			return nil, err
+		}
+		return &result{Value: map[string]interface{}{
+			"Address":   ks.Address,
+			"PublicKey": ks.PublicKey,
+			"Keystore":  args[0],
+		}}, nil
+	}
+}
+
+func setupKeysInspect(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	verify := fs.Bool("verify", false, "check the keystore password")
+	passwordFile := fs.String("password-file", "", "read the password from a file")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a keystore file")
+		}
+		ks, err := keystore.Load(args[0])
+		if err != nil {
+			return nil, err
+		}
+		info := map[string]interface{}{
+			"Address":       ks.Address,
+			"PublicKey":     ks.PublicKey,
+			"KDF":           ks.KDF.Name,
+			"KDFIterations": ks.KDF.Iterations,
+			"Cipher":        ks.Cipher,
+		}
+		if !*verify {
+			return &result{Value: info}, nil
+		}
+		password, err := env.password(*passwordFile, false)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ks.Decrypt(password)
+		if err != nil {
+			info["PasswordValid"] = false
+			return &result{Value: info}, err
+		}
+		if signer.PublicKey() != ks.PublicKey {
+			return nil, fmt.Errorf("keystore %s holds a key for %s, not %s", args[0], signer.PublicKey(), ks.PublicKey)
+		}
+		info["PasswordValid"] = true
+		return &result{Value: info}, nil
+	}
+}
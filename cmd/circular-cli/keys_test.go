@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular/keystore"
+)
+
+func TestKeysNewAndInspect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.json")
+	passwordFile := filepath.Join(dir, "password")
+	os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600)
+
+	code, stdout, stderr := runCLI(t, "--output", "json", "keys", "new", "--password-file", passwordFile, "--kdf-iterations", "1000", path)
+	if code != exitOK {
+		t.Fatalf("Expected exit status 0, got %d: %s", code, stderr)
+	}
+	var created map[string]string
+	json.Unmarshal([]byte(stdout), &created)
+	ks, err := keystore.Load(path)
+	if err != nil || created["Address"] != ks.Address || len(ks.Address) != 64 {
+		t.Fatalf("Expected the new keystore's address, got %v (%v)", created, err)
+	}
+	if code, _, _ := runCLI(t, "keys", "new", "--password-file", passwordFile, path); code != exitUsage {
+		t.Errorf("Expected keys new to refuse an existing file, got exit status %d", code)
+	}
+
+	code, stdout, _ = runCLI(t, "--output", "yaml", "keys", "inspect", path)
+	if code != exitOK || !strings.Contains(stdout, "Address: "+ks.Address) || strings.Contains(stdout, "PasswordValid") {
+		t.Errorf("Expected the keystore's identity without a password check, got %d %q", code, stdout)
+	}
+
+	t.Setenv(passwordEnv, "s3cret")
+	if code, stdout, _ := runCLI(t, "--output", "yaml", "keys", "inspect", "--verify", path); code != exitOK || !strings.Contains(stdout, "PasswordValid: true") {
+		t.Errorf("Expected the password to verify, got %d %q", code, stdout)
+	}
+	t.Setenv(passwordEnv, "wrong")
+	if code, stdout, _ := runCLI(t, "--output", "yaml", "keys", "inspect", "--verify", path); code != exitFailure || !strings.Contains(stdout, "PasswordValid: false") {
+		t.Errorf("Expected a wrong password to fail, got %d %q", code, stdout)
+	}
+}
+
+func TestSubmitWithKeystore(t *testing.T) {
+	server := newGateway(t)
+	path := filepath.Join(t.TempDir(), "key.json")
+	ks, _ := keystore.Encrypt(testPrivateKey, "s3cret", 1000)
+	keystore.Save(path, ks)
+	t.Setenv(passwordEnv, "s3cret")
+
+	code, stdout, stderr := runCLI(t, "--quiet", "--nag", server.URL+"/?cep=", "--keystore", path, "cert", "submit", "hello")
+	if code != exitOK || len(strings.TrimSpace(stdout)) != 64 {
+		t.Errorf("Expected a submission signed from the keystore, got %d %q: %s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,69 @@
+// Command circular-cli submits certificates to and queries the Circular Protocol from
+// the shell.
+//
+// Usage:
+//
+//	circular-cli [global flags] <command> [flags] [args]
+//
+// Results are written to standard output as JSON, YAML or a table (--output), or as the
+// bare transaction ID (--quiet); diagnostics go to standard error. The account is read
+// from CIRCULAR_ADDRESS and its key from CIRCULAR_PRIVATE_KEY, either of which may be set
+// in a .env file. Global flags used together can be saved as a named profile with
+// "config set-profile" and applied with --profile. The exit status tells scripts how a
+// command failed:
+//
+//	0  success
+//	1  any other failure
+//	2  invalid command line
+//	3  timeout
+//	4  rejected by the gateway
+//	5  network failure or gateway error
+//	6  verification failed (cert verify)
+//
+// Error messages end with the SDK's error code in brackets, e.g. [CIRC-2003]; see
+// circular.ErrorCodeOf.
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// A missing .env file is fine; the environment may be set directly.
+	godotenv.Load()
+
+	// Results go to the real standard output; anything the SDK prints goes to
+	// standard error so that it cannot corrupt output meant for other programs.
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args, writing results to stdout and errors to stderr.
+//
+// Parameters:
+//   - ctx: Controls cancellation of the command.
+//   - args: The command line arguments, without the program name.
+//   - stdout: Receives the command's result.
+//   - stderr: Receives usage and error messages.
+//
+// Returns:
+//
+//	The process exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	err := execute(ctx, args, stdout, stderr)
+	if err != nil {
+		printError(stderr, err)
+	}
+	return exitCode(err)
+}
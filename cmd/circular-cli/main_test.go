@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/testsupport"
+)
+
+const testPrivateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+// newGateway starts a mock gateway that accepts submissions and reports txs as
+// finalized. Transactions are reported under the ID "abc123".
+func newGateway(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			fmt.Fprint(w, `{"Result":200,"Response":{"ID":"abc123","Status":"Executed","BlockID":"b1"}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCLI runs the CLI with args and returns its exit status and output.
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCertSubmitQuiet(t *testing.T) {
+	server := newGateway(t)
+	t.Setenv("CIRCULAR_PRIVATE_KEY", testPrivateKey)
+
+	code, stdout, stderr := runCLI(t, "--quiet", "--nag", server.URL+"/?cep=", "cert", "submit", "--address", "0xabcdef", "hello")
+	if code != exitOK {
+		t.Fatalf("Expected exit status 0, got %d: %s", code, stderr)
+	}
+	txID := strings.TrimSpace(stdout)
+	if len(txID) != 64 || strings.Count(stdout, "\n") != 1 {
+		t.Errorf("Expected only the transaction ID, got %q", stdout)
+	}
+}
+
+func TestCertSubmitNonceStore(t *testing.T) {
+	recorder := testsupport.NewRecorder(newGateway(t).Config.Handler)
+	gateway := httptest.NewServer(recorder)
+	defer gateway.Close()
+	t.Setenv("CIRCULAR_PRIVATE_KEY", testPrivateKey)
+	args := []string{"--quiet", "--nag", gateway.URL + "/?cep=", "--nonce-store", t.TempDir(), "cert", "submit", "--address", "0xabcdef"}
+
+	for _, data := range []string{"first", "second"} {
+		if code, _, stderr := runCLI(t, append(args, data)...); code != exitOK {
+			t.Fatalf("Expected exit status 0, got %d: %s", code, stderr)
+		}
+	}
+	var nonces []string
+	for _, call := range recorder.CallsTo("Circular_AddTransaction_") {
+		nonces = append(nonces, call.Payload["Nonce"].(string))
+	}
+	if fetches := len(recorder.CallsTo("Circular_GetWalletNonce_")); fetches != 1 || strings.Join(nonces, ",") != "5,6" {
+		t.Errorf("Expected the second run to reuse the persisted nonce, got %d fetches and nonces %v", fetches, nonces)
+	}
+}
+
+func TestMainnetGuard(t *testing.T) {
+	server := newGateway(t)
+	t.Setenv("CIRCULAR_PRIVATE_KEY", testPrivateKey)
+	args := []string{"--quiet", "--network", "mainnet", "--nag", server.URL + "/?cep=", "cert", "submit", "--address", "0xabcdef", "hello"}
+
+	code, _, stderr := runCLI(t, args...)
+	if code != exitFailure || !strings.Contains(stderr, "mainnet") {
+		t.Errorf("Expected the mainnet write to be refused, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, append([]string{"--allow-mainnet"}, args...)...); code != exitOK {
+		t.Errorf("Expected --allow-mainnet to permit the write, got %d: %s", code, stderr)
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	server := newGateway(t)
+	nag := server.URL + "/?cep="
+
+	code, stdout, _ := runCLI(t, "--output", "json", "--nag", nag, "tx", "get", "abc123")
+	var decoded map[string]interface{}
+	if code != exitOK || json.Unmarshal([]byte(stdout), &decoded) != nil || decoded["Status"] != "Executed" {
+		t.Errorf("Expected the transaction as JSON, got %d %q", code, stdout)
+	}
+
+	_, stdout, _ = runCLI(t, "--output", "yaml", "--nag", nag, "tx", "get", "abc123")
+	if !strings.Contains(stdout, "Status: Executed\n") || !strings.Contains(stdout, "ID: abc123\n") {
+		t.Errorf("Expected the transaction as YAML, got %q", stdout)
+	}
+
+	_, stdout, _ = runCLI(t, "--nag", nag, "tx", "get", "abc123")
+	if !strings.HasPrefix(stdout, "KEY") || !strings.Contains(stdout, "Executed") {
+		t.Errorf("Expected the transaction as a table, got %q", stdout)
+	}
+
+	if code, _, _ := runCLI(t, "--output", "xml", "--nag", nag, "tx", "get", "abc123"); code != exitUsage {
+		t.Errorf("Expected exit status %d for an unknown format, got %d", exitUsage, code)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	value, _ := normalize(map[string]interface{}{
+		"Empty":  map[string]interface{}{},
+		"List":   []interface{}{map[string]interface{}{"A": 1, "B": "x"}, "true"},
+		"Nested": map[string]interface{}{"Number": "42"},
+		"Nil":    nil,
+	})
+	var b strings.Builder
+	writeYAML(&b, value, 0)
+	want := "Empty: {}\nList:\n  -\n    A: 1\n    B: x\n  - \"true\"\nNested:\n  Number: \"42\"\nNil: null\n"
+	if b.String() != want {
+		t.Errorf("Unexpected YAML:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    int
+	}{
+		{"rejected", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"Result":108,"Response":"Invalid Signature"}`)
+		}, exitRejected},
+		{"gateway failure", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}, exitNetwork},
+		{"timeout", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}, exitTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			code, _, stderr := runCLI(t, "--timeout", "200ms", "--nag", server.URL+"/?cep=", "account", "nonce", "--address", "0xabcdef")
+			if code != tt.want {
+				t.Errorf("Expected exit status %d, got %d: %s", tt.want, code, stderr)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	if code, _, _ := runCLI(t, "--nag", server.URL+"/?cep=", "tx", "get", "abc123"); code != exitNetwork {
+		t.Errorf("Expected exit status %d for an unreachable gateway, got %d", exitNetwork, code)
+	}
+	if code, _, _ := runCLI(t, "tx", "frobnicate"); code != exitUsage {
+		t.Errorf("Expected exit status %d for an unknown command, got %d", exitUsage, code)
+	}
+	if code := exitCode(fmt.Errorf("waiting: %w", circular.ErrTransactionExpired)); code != exitTimeout {
+		t.Errorf("Expected an expired transaction to be a timeout, got %d", code)
+	}
+}
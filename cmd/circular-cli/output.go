@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats accepted by --output.
+const (
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+)
+
+// isFormat reports whether format is a supported output format.
+func isFormat(format string) bool {
+	return format == formatJSON || format == formatYAML || format == formatTable
+}
+
+// result is what a command prints.
+type result struct {
+	TxID  string      // The transaction the command acted on, printed alone with --quiet.
+	TxIDs []string    // The transactions a batch command acted on, printed one per line with --quiet.
+	Value interface{} // The full result, printed in the selected format.
+	Text  string      // Printed verbatim instead of Value, whatever the format.
+}
+
+// printResult writes res to w as selected by opts.
+func printResult(w io.Writer, opts *options, res *result) error {
+	if res.Text != "" {
+		_, err := io.WriteString(w, res.Text)
+		return err
+	}
+	if opts.quiet {
+		txIDs := res.TxIDs
+		if res.TxID != "" {
+			txIDs = append([]string{res.TxID}, txIDs...)
+		}
+		for _, txID := range txIDs {
+			if _, err := fmt.Fprintln(w, txID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch opts.output {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(res.Value)
+	case formatYAML:
+		value, err := normalize(res.Value)
+		if err != nil {
+			return err
+		}
+		var b strings.Builder
+		writeYAML(&b, value, 0)
+		_, err = io.WriteString(w, b.String())
+		return err
+	default:
+		value, err := normalize(res.Value)
+		if err != nil {
+			return err
+		}
+		return writeTable(w, value)
+	}
+}
+
+// normalize converts v to the generic form encoding/json decodes into, so that structs
+// and maps print alike. Numbers are kept as json.Number to preserve their digits.
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return value, nil
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeYAML appends the YAML block representation of a normalized value to b, indented
+// by indent spaces.
+func writeYAML(b *strings.Builder, value interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			b.WriteString(pad + "{}\n")
+			return
+		}
+		for _, key := range sortedKeys(v) {
+			b.WriteString(pad + yamlScalar(key) + ":")
+			writeYAMLChild(b, v[key], indent)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			b.WriteString(pad + "-")
+			writeYAMLChild(b, item, indent)
+		}
+	default:
+		b.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLChild completes a line ending in a key or sequence dash with value: inline
+// when it is a scalar or empty, otherwise as an indented block.
+func writeYAMLChild(b *strings.Builder, value interface{}, indent int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			b.WriteString("\n")
+			writeYAML(b, v, indent+2)
+			return
+		}
+		b.WriteString(" {}\n")
+	case []interface{}:
+		if len(v) > 0 {
+			b.WriteString("\n")
+			writeYAML(b, v, indent+2)
+			return
+		}
+		b.WriteString(" []\n")
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+// yamlScalar formats a scalar, quoting strings that YAML would otherwise read as
+// another type or misparse.
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if needsQuotes(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// needsQuotes reports whether s must be quoted to read back as the same string.
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #")
+}
+
+// writeTable writes a normalized value to w as aligned columns: an object as KEY/VALUE
+// rows, and a list of objects as one row per object. Nested values are shown as compact
+// JSON.
+func writeTable(w io.Writer, value interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, key := range sortedKeys(v) {
+			fmt.Fprintf(tw, "%s\t%s\n", key, tableCell(v[key]))
+		}
+	case []interface{}:
+		columns := tableColumns(v)
+		if columns == nil {
+			for _, item := range v {
+				fmt.Fprintln(tw, tableCell(item))
+			}
+			break
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, item := range v {
+			row := item.(map[string]interface{})
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				if cell, ok := row[column]; ok {
+					cells[i] = tableCell(cell)
+				}
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	default:
+		fmt.Fprintln(tw, tableCell(v))
+	}
+	return tw.Flush()
+}
+
+// tableColumns returns the union of the keys of items, or nil if any item is not an
+// object.
+func tableColumns(items []interface{}) []string {
+	seen := map[string]interface{}{}
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for key := range row {
+			seen[key] = nil
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// tableCell formats a normalized value for a single table cell.
+func tableCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return yamlScalar(v)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// profileGroups lists the global flags a profile can hold. The flags of a group are
+// taken together: giving any of them on the command line overrides the whole group in
+// the profile, so that e.g. --network never combines with a profile's --nag and sends
+// requests to the profile's gateway anyway.
+var profileGroups = [][]string{
+	{"network", "nag"},
+	{"address", "keystore"},
+	{"chain"},
+	{"nonce-store"},
+	{"output"},
+}
+
+// profilePaths are the profile flags naming files, which are stored as absolute paths
+// so that the profile works from any directory.
+var profilePaths = []string{"keystore", "nonce-store"}
+
+// profileName matches valid profile names.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// profile is a named set of global flag values, by flag name.
+type profile map[string]string
+
+// profileDir returns the directory profiles are stored in: circular/profiles under
+// $XDG_CONFIG_HOME, or under ~/.config when it is not set.
+func profileDir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate the profile directory: %w", err)
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "circular", "profiles"), nil
+}
+
+// profilePath returns the file profile name is stored in.
+func profilePath(name string) (string, error) {
+	if !profileName.MatchString(name) {
+		return "", usagef("invalid profile name %q; use letters, digits, '.', '_' and '-'", name)
+	}
+	dir, err := profileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// loadProfile reads profile name.
+//
+// Returns:
+//
+//	The profile, or an error wrapping os.ErrNotExist if there is no such profile.
+func loadProfile(name string) (profile, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// saveProfile writes profile name, readable by the user alone.
+func saveProfile(name string, p profile) error {
+	path, err := profilePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// applyProfile sets the global flags of fs that the command line left unset, group by
+// group, to the values in the profile selected with --profile.
+func applyProfile(fs *flag.FlagSet, opts *options) error {
+	p, err := loadProfile(opts.profile)
+	if errors.Is(err, os.ErrNotExist) {
+		return usagef("no profile %q; create it with config set-profile", opts.profile)
+	}
+	if err != nil {
+		return err
+	}
+	for _, group := range profileGroups {
+		if slices.ContainsFunc(group, func(flagName string) bool { return opts.set[flagName] }) {
+			continue
+		}
+		for _, name := range group {
+			if value, ok := p[name]; ok {
+				if err := fs.Set(name, value); err != nil {
+					return fmt.Errorf("invalid %s in profile %q: %w", name, opts.profile, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// explicitFlags returns the names of the flags set on the command line, in any of sets.
+func explicitFlags(sets ...*flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	for _, fs := range sets {
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	}
+	return set
+}
+
+func setupConfigSetProfile(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) > 1 && strings.HasPrefix(args[1], "-") {
+			return nil, usagef("flags must come before the profile name")
+		}
+		if len(args) != 1 {
+			return nil, usagef("expected the profile name")
+		}
+		name := args[0]
+		p, err := loadProfile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			p, err = profile{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		changed := false
+		for _, group := range profileGroups {
+			if !slices.ContainsFunc(group, func(flagName string) bool { return env.opts.set[flagName] }) {
+				continue
+			}
+			changed = true
+			for _, flagName := range group {
+				delete(p, flagName)
+				if !env.opts.set[flagName] {
+					continue
+				}
+				value := fs.Lookup(flagName).Value.String()
+				if slices.Contains(profilePaths, flagName) && value != "" {
+					if value, err = filepath.Abs(value); err != nil {
+						return nil, err
+					}
+				}
+				p[flagName] = value
+			}
+		}
+		if !changed {
+			return nil, usagef("no settings given; use flags such as --network, --nag, --keystore or --chain")
+		}
+		if err := saveProfile(name, p); err != nil {
+			return nil, err
+		}
+		return &result{Value: map[string]interface{}{"Profile": name, "Settings": p}}, nil
+	}
+}
+
+func setupConfigProfiles(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 0 {
+			return nil, usagef("unexpected arguments: %s", strings.Join(args, " "))
+		}
+		dir, err := profileDir()
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		profiles := make(map[string]interface{})
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".json")
+			if !ok || entry.IsDir() || !profileName.MatchString(name) {
+				continue
+			}
+			p, err := loadProfile(name)
+			if err != nil {
+				return nil, err
+			}
+			profiles[name] = p
+		}
+		return &result{Value: profiles}, nil
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := newGateway(t)
+	nag := server.URL + "/?cep="
+
+	code, _, stderr := runCLI(t, "config", "set-profile", "--nag", nag, "--address", "0xabcdef", "--nonce-store", "nonces", "staging")
+	if code != exitOK {
+		t.Fatalf("Expected the profile to be saved, got %d: %s", code, stderr)
+	}
+	// Settings given later are merged in.
+	if code, _, stderr := runCLI(t, "--output", "json", "config", "set-profile", "staging"); code != exitOK {
+		t.Fatalf("Expected the profile to be updated, got %d: %s", code, stderr)
+	}
+	p, err := loadProfile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p["nag"] != nag || p["address"] != "0xabcdef" || p["output"] != "json" || !filepath.IsAbs(p["nonce-store"]) {
+		t.Errorf("Unexpected profile %v", p)
+	}
+	if info, err := os.Stat(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "circular", "profiles", "staging.json")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the profile to be private to the user, got %v", err)
+	}
+
+	code, stdout, stderr := runCLI(t, "--profile", "staging", "account", "nonce")
+	var decoded map[string]interface{}
+	if code != exitOK || json.Unmarshal([]byte(stdout), &decoded) != nil || decoded["Address"] != "0xabcdef" {
+		t.Errorf("Expected the profile's gateway, address and format to be used, got %d %q: %s", code, stdout, stderr)
+	}
+
+	// A network given on the command line replaces the profile's gateway too.
+	opts := defaultOptions()
+	opts.profile, opts.set = "staging", map[string]bool{"network": true}
+	fs := flag.NewFlagSet("circular-cli", flag.ContinueOnError)
+	opts.register(fs)
+	if err := applyProfile(fs, opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.nag != "" || opts.address != "0xabcdef" {
+		t.Errorf("Expected only the profile's gateway to be skipped, got %+v", opts)
+	}
+
+	code, stdout, _ = runCLI(t, "--output", "json", "config", "profiles")
+	if code != exitOK || !strings.Contains(stdout, `"staging"`) {
+		t.Errorf("Expected the profile to be listed, got %d %q", code, stdout)
+	}
+	if code, _, _ := runCLI(t, "--profile", "prod", "account", "nonce"); code != exitUsage {
+		t.Errorf("Expected an unknown profile to be a usage error, got %d", code)
+	}
+	if code, _, _ := runCLI(t, "config", "set-profile", "--chain", "c0", "../escape"); code != exitUsage {
+		t.Errorf("Expected an invalid profile name to be refused, got %d", code)
+	}
+	if code, _, stderr := runCLI(t, "config", "set-profile", "prod", "--network", "mainnet"); code != exitUsage || !strings.Contains(stderr, "before the profile name") {
+		t.Errorf("Expected flags after the name to be refused with a hint, got %d: %s", code, stderr)
+	}
+	if code, _, _ := runCLI(t, "config", "set-profile", "empty"); code != exitUsage {
+		t.Errorf("Expected a profile without settings to be refused, got %d", code)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
+)
+
+// verifyCheck is the result of one of the checks of cert verify.
+type verifyCheck struct {
+	Check  string // What was checked: "ID", "Signature" or "Data".
+	OK     bool
+	Detail string // How the check passed or why it failed.
+}
+
+// verificationError reports the checks of cert verify that failed.
+type verificationError struct {
+	txID   string
+	failed []string
+}
+
+func (e *verificationError) Error() string {
+	return fmt.Sprintf("transaction %s failed verification: %s", e.txID, strings.Join(e.failed, ", "))
+}
+
+func setupCertVerify(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	txID := fs.String("tx", "", "the transaction to verify (required)")
+	file := fs.String("file", "", "also check that the certificate certifies this file, by its contents or SHA-256 digest")
+	registered := fs.Bool("registered-key", false, "verify the signature against the sender's key registered on chain instead of the record's own")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 0 || *txID == "" {
+			return nil, usagef("expected --tx <transaction ID> and no arguments")
+		}
+		acc, err := env.account()
+		if err != nil {
+			return nil, err
+		}
+		batch, err := acc.GetTransactions(ctx, []string{*txID})
+		if err != nil {
+			return nil, batch.Failed[0].Err
+		}
+		record, ok := transaction(batch.Succeeded[0].Value).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("transaction %s: the gateway returned no transaction record", *txID)
+		}
+
+		checks := []verifyCheck{checkTransactionID(*txID, record)}
+		var signature *circular.SignatureReport
+		if *registered {
+			signature, err = acc.VerifyTransactionSignatureOnChain(ctx, record)
+		} else {
+			signature, err = circular.VerifyTransactionSignature(record)
+		}
+		checks = append(checks, checkSignature(signature, err))
+		if *file != "" {
+			check, err := checkFile(record, *file)
+			if err != nil {
+				return nil, err
+			}
+			checks = append(checks, check)
+		}
+
+		id := helpers.HexFix(*txID)
+		var failed []string
+		for _, check := range checks {
+			if !check.OK {
+				failed = append(failed, check.Check)
+			}
+		}
+		status, _ := jsonx.GetString(record, "Status")
+		res := &result{TxID: id, Value: map[string]interface{}{"TxID": id, "Status": status, "Verified": len(failed) == 0, "Checks": checks}}
+		if len(failed) > 0 {
+			return res, &verificationError{txID: id, failed: failed}
+		}
+		return res, nil
+	}
+}
+
+// checkTransactionID checks that the record is that of txID and that its ID derives from
+// its fields as circular.ComputeTransactionID does.
+func checkTransactionID(txID string, record map[string]interface{}) verifyCheck {
+	check := verifyCheck{Check: "ID"}
+	recorded, _ := jsonx.GetString(record, "ID")
+	if helpers.HexFix(recorded) != helpers.HexFix(txID) {
+		check.Detail = fmt.Sprintf("the gateway returned transaction %q", recorded)
+		return check
+	}
+	var fields []string
+	for _, name := range []string{"Blockchain", "From", "To", "Payload", "Nonce", "Timestamp"} {
+		value, ok := recordField(record, name)
+		if !ok {
+			check.Detail = fmt.Sprintf("the record has no %s to derive the ID from", name)
+			return check
+		}
+		fields = append(fields, value)
+	}
+	derived := circular.ComputeTransactionID(fields[0], fields[1], fields[2], fields[3], fields[4], fields[5])
+	if derived != helpers.HexFix(recorded) {
+		check.Detail = fmt.Sprintf("the record's fields derive ID %s", derived)
+		return check
+	}
+	check.OK, check.Detail = true, "derived from the record's fields"
+	return check
+}
+
+// recordField returns a field of a transaction record as the string hashed into its ID;
+// gateways may return the nonce as a number.
+func recordField(record map[string]interface{}, name string) (string, bool) {
+	if s, ok := jsonx.GetString(record, name); ok {
+		return s, true
+	}
+	if n, ok := jsonx.GetInt(record, name); ok {
+		return strconv.FormatInt(n, 10), true
+	}
+	return "", false
+}
+
+// checkSignature turns the outcome of a signature verification into a check.
+func checkSignature(report *circular.SignatureReport, err error) verifyCheck {
+	check := verifyCheck{Check: "Signature"}
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+	case !report.Valid:
+		check.Detail = fmt.Sprintf("does not verify against the %s key %s", report.KeySource, report.PublicKey)
+	case report.RecordKeyMismatch:
+		check.Detail = "verifies against the registered key, but the record carries a different key"
+	default:
+		check.OK, check.Detail = true, fmt.Sprintf("signed by %s with the %s key", report.Signer, report.KeySource)
+	}
+	return check
+}
+
+// checkFile checks that the record certifies the file at path, by its contents or its
+// SHA-256 digest.
+func checkFile(record map[string]interface{}, path string) (verifyCheck, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return verifyCheck{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return verifyCheck{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	check := verifyCheck{Check: "Data"}
+	report, err := circular.VerifyOutcomeDigest(record, hex.EncodeToString(h.Sum(nil)))
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+	case !report.OK():
+		var mismatches []string
+		for _, m := range report.Mismatches {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %s, expected %s", m.Field, m.Actual, m.Expected))
+		}
+		check.Detail = strings.Join(mismatches, "; ")
+	case report.MatchedBy == circular.MatchedBySHA256:
+		check.OK, check.Detail = true, fmt.Sprintf("certifies the SHA-256 digest of %s", path)
+	default:
+		check.OK, check.Detail = true, fmt.Sprintf("certifies the contents of %s", path)
+	}
+	return check, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+)
+
+func TestCertVerify(t *testing.T) {
+	signer, _ := circular.NewPrivateKeySigner(testPrivateKey)
+	var mu sync.Mutex
+	var record map[string]interface{}
+	// The gateway records the submitted transaction and returns it on lookups.
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(r.URL.String(), "Circular_GetWalletNonce_"):
+			fmt.Fprint(w, `{"Result":200,"Response":{"Nonce":4}}`)
+		case strings.Contains(r.URL.String(), "Circular_AddTransaction_"):
+			json.NewDecoder(r.Body).Decode(&record)
+			record["PublicKey"], record["Status"] = signer.PublicKey(), "Executed"
+			fmt.Fprint(w, `{"Result":200,"Response":"Transaction Added"}`)
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"Result": 200, "Response": record})
+		}
+	}))
+	defer gateway.Close()
+	t.Setenv("CIRCULAR_PRIVATE_KEY", testPrivateKey)
+	nag := gateway.URL + "/?cep="
+
+	code, stdout, stderr := runCLI(t, "--quiet", "--nag", nag, "cert", "submit", "--address", "0xabcdef", "hello")
+	if code != exitOK {
+		t.Fatalf("Expected the certificate to be submitted, got %d: %s", code, stderr)
+	}
+	txID := strings.TrimSpace(stdout)
+	dir := t.TempDir()
+	original, other := filepath.Join(dir, "original.txt"), filepath.Join(dir, "other.txt")
+	os.WriteFile(original, []byte("hello"), 0o600)
+	os.WriteFile(other, []byte("goodbye"), 0o600)
+
+	verify := func(args ...string) (int, map[string]interface{}) {
+		t.Helper()
+		code, stdout, stderr := runCLI(t, append([]string{"--nag", nag, "--output", "json", "cert", "verify", "--tx", txID}, args...)...)
+		var report map[string]interface{}
+		if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+			t.Fatalf("Expected a JSON report, got %q: %s", stdout, stderr)
+		}
+		return code, report
+	}
+	if code, report := verify("--file", original); code != exitOK || report["Verified"] != true || len(report["Checks"].([]interface{})) != 3 {
+		t.Errorf("Expected the certificate to verify, got %d: %v", code, report)
+	}
+	code, report := verify("--file", other)
+	if code != exitMismatch || report["Verified"] != false {
+		t.Errorf("Expected a different file to fail verification, got %d: %v", code, report)
+	}
+	if data := report["Checks"].([]interface{})[2].(map[string]interface{}); data["Check"] != "Data" || data["OK"] != false {
+		t.Errorf("Expected the data check to fail, got %v", data)
+	}
+
+	mu.Lock()
+	record["Nonce"] = "6"
+	mu.Unlock()
+	code, report = verify()
+	if checks := report["Checks"].([]interface{}); code != exitMismatch || checks[0].(map[string]interface{})["OK"] != false || checks[1].(map[string]interface{})["OK"] != true {
+		t.Errorf("Expected only the ID check to fail for a tampered nonce, got %d: %v", code, report)
+	}
+
+	if code, _, _ := runCLI(t, "--nag", nag, "cert", "verify"); code != exitUsage {
+		t.Errorf("Expected a missing --tx to be a usage error, got %d", code)
+	}
+}
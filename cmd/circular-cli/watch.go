@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+	"github.com/lessuselesss/go-enterprise-apis/circular/webhook"
+)
+
+// webhookSecretEnv names the environment variable holding the secret webhook posts are
+// signed with; see the webhook package.
+const webhookSecretEnv = "CIRCULAR_WEBHOOK_SECRET"
+
+// watcher resumes and tracks the pending transactions of a receipt store.
+type watcher struct {
+	acc     *circular.CEPAccount
+	out     io.Writer // Receives events as JSON lines.
+	stderr  io.Writer
+	webhook string
+	secret  string // Signs webhook posts, if set.
+	client  *http.Client
+
+	mu       sync.Mutex
+	tracked  map[string]bool // Transactions tracked since start, including finished ones.
+	active   int             // Transactions currently being tracked.
+	lastScan time.Time
+	scanErr  error
+	wg       sync.WaitGroup
+}
+
+func setupWatch(fs *flag.FlagSet) func(context.Context, *env, []string) (*result, error) {
+	from := fs.String("from", "", "directory of the receipt store to resume tracking from (required)")
+	webhookURL := fs.String("webhook", "", "POST each event as JSON to this URL; set "+webhookSecretEnv+" to sign the posts")
+	listen := fs.String("listen", "127.0.0.1:8080", "address of the /healthz endpoint; empty disables it")
+	rescan := fs.Duration("rescan", 10*time.Second, "how often to look for new pending receipts")
+	return func(ctx context.Context, env *env, args []string) (*result, error) {
+		if len(args) != 0 || *from == "" {
+			return nil, usagef("expected --from <receipt store directory> and no arguments")
+		}
+		if *rescan <= 0 {
+			return nil, usagef("--rescan must be positive")
+		}
+		kv, err := storage.NewFile(*from)
+		if err != nil {
+			return nil, err
+		}
+		acc, err := env.account()
+		if err != nil {
+			return nil, err
+		}
+		// A transaction is tracked until it leaves the pending state or its receipt
+		// expires, however long that takes: the daemon's context has no deadline, and
+		// the default outcome deadline would report slow transactions as failed.
+		deadlines := acc.Deadlines()
+		deadlines.OutcomeTotal = -1
+		acc.SetDeadlines(deadlines)
+		acc.SetReceiptStore(circular.NewDocumentReceiptStore(storage.NewDocumentStore(kv)))
+
+		w := &watcher{
+			acc:     acc,
+			out:     env.stdout,
+			stderr:  env.stderr,
+			webhook: *webhookURL,
+			secret:  os.Getenv(webhookSecretEnv),
+			client:  &http.Client{Timeout: 10 * time.Second},
+			tracked: make(map[string]bool),
+		}
+		if *listen != "" {
+			listener, err := net.Listen("tcp", *listen)
+			if err != nil {
+				return nil, err
+			}
+			server := &http.Server{Handler: w.healthHandler(), ReadHeaderTimeout: 5 * time.Second}
+			go server.Serve(listener)
+			defer server.Close()
+		}
+		w.run(ctx, *rescan)
+		return nil, nil
+	}
+}
+
+// run scans for pending receipts every interval and tracks each new one until ctx is
+// done, then waits for the trackers to stop.
+func (w *watcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.scan(ctx)
+		select {
+		case <-ctx.Done():
+			w.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan starts tracking the pending receipts not tracked yet.
+func (w *watcher) scan(ctx context.Context) {
+	pending, err := w.acc.PendingReceipts()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastScan, w.scanErr = time.Now(), err
+	if err != nil {
+		fmt.Fprintf(w.stderr, "circular-cli: watch: %v\n", err)
+		return
+	}
+	for _, receipt := range pending {
+		txID := receipt.Transaction.ID
+		if w.tracked[txID] {
+			continue
+		}
+		w.tracked[txID] = true
+		w.active++
+		w.wg.Add(1)
+		go w.track(ctx, txID)
+	}
+}
+
+// track waits for the outcome of txID and emits the resulting state change. Tracking
+// that stops because the watcher is shutting down emits nothing; the receipt is still
+// pending and is resumed on the next start.
+func (w *watcher) track(ctx context.Context, txID string) {
+	defer w.wg.Done()
+	defer func() {
+		w.mu.Lock()
+		w.active--
+		w.mu.Unlock()
+	}()
+
+	outcomes, err := w.acc.WaitForOutcomes(ctx, []string{txID})
+	if ctx.Err() != nil {
+		return
+	}
+	event := webhook.Event{Time: time.Now(), TxID: txID, Status: string(circular.ReceiptFinalized)}
+	if err == nil {
+		event.FinalStatus, _ = outcomes.Succeeded[0].Value["Status"].(string)
+	} else {
+		cause := outcomes.Failed[0].Err
+		switch {
+		case errors.Is(cause, circular.ErrTransactionExpired):
+			event.Status = string(circular.ReceiptExpired)
+		case errors.Is(cause, circular.ErrTransactionAbandoned):
+			event.Status = string(circular.ReceiptAbandoned)
+		default:
+			event.Status = statusFailed
+		}
+		event.Error = cause.Error()
+		event.Code = string(circular.ErrorCodeOf(cause))
+	}
+	w.emit(event)
+}
+
+// emit writes event to the output and posts it to the webhook, if any.
+// Posts are signed when a secret is set.
+func (w *watcher) emit(event webhook.Event) {
+	data, _ := json.Marshal(event)
+	w.mu.Lock()
+	fmt.Fprintf(w.out, "%s\n", data)
+	w.mu.Unlock()
+
+	if w.webhook == "" {
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, w.webhook, bytes.NewReader(data))
+	if err != nil {
+		fmt.Fprintf(w.stderr, "circular-cli: watch: webhook failed for %s: %v\n", event.TxID, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(data, w.secret, time.Now()))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		fmt.Fprintf(w.stderr, "circular-cli: watch: webhook failed for %s: %v\n", event.TxID, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		fmt.Fprintf(w.stderr, "circular-cli: watch: webhook returned %s for %s\n", resp.Status, event.TxID)
+	}
+}
+
+// healthHandler serves /healthz: 200 with the watcher's state while scans succeed, and
+// 503 when the last scan of the receipt store failed.
+func (w *watcher) healthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) {
+		w.mu.Lock()
+		health := map[string]interface{}{
+			"Status":   "ok",
+			"Tracking": w.active,
+			"LastScan": w.lastScan,
+		}
+		code := http.StatusOK
+		if w.scanErr != nil {
+			health["Status"] = "unhealthy"
+			health["Error"] = w.scanErr.Error()
+			code = http.StatusServiceUnavailable
+		}
+		w.mu.Unlock()
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(code)
+		json.NewEncoder(rw).Encode(health)
+	})
+	return mux
+}
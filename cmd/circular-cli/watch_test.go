@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/storage"
+	"github.com/lessuselesss/go-enterprise-apis/circular/webhook"
+)
+
+func TestWatch(t *testing.T) {
+	t.Setenv(webhookSecretEnv, "whsec")
+	gateway := newGateway(t)
+	events := make(chan webhook.Event, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := webhook.ReadEvent(r, "whsec")
+		if err != nil {
+			t.Errorf("Expected a signed event, got %v", err)
+			return
+		}
+		events <- *event
+	}))
+	defer receiver.Close()
+
+	dir := t.TempDir()
+	kv, _ := storage.NewFile(dir)
+	store := circular.NewDocumentReceiptStore(storage.NewDocumentStore(kv))
+	store.SaveReceipt(&circular.Receipt{Transaction: circular.Transaction{ID: "aa11"}, SubmittedAt: time.Now(), Status: circular.ReceiptPending})
+	store.SaveReceipt(&circular.Receipt{Transaction: circular.Transaction{ID: "bb22"}, SubmittedAt: time.Now(), Status: circular.ReceiptFinalized})
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"--nag", gateway.URL + "/?cep=", "watch", "--from", dir, "--webhook", receiver.URL,
+			"--listen", addr, "--rescan", "20ms"}, io.Discard, io.Discard)
+	}()
+
+	next := func() webhook.Event {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for an event")
+			return webhook.Event{}
+		}
+	}
+	if event := next(); event.TxID != "aa11" || event.Status != "Finalized" || event.FinalStatus != "Executed" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	store.SaveReceipt(&circular.Receipt{Transaction: circular.Transaction{ID: "cc33"}, SubmittedAt: time.Now(), Status: circular.ReceiptPending})
+	if event := next(); event.TxID != "cc33" {
+		t.Errorf("Expected a receipt added later to be picked up, got %+v", event)
+	}
+
+	resp, err := http.Get("http://" + addr + "/healthz")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected a healthy /healthz, got %v, %v", resp, err)
+	} else {
+		resp.Body.Close()
+	}
+
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Errorf("Expected exit status 0 on shutdown, got %d", code)
+	}
+	if receipt, _ := store.LoadReceipt("aa11"); receipt.Status != circular.ReceiptFinalized {
+		t.Errorf("Expected the receipt to be finalized, got %s", receipt.Status)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML decodes the subset of YAML used by CLI input files: block mappings and
+// sequences, plain and quoted scalars, comments, and the empty flow collections {} and
+// []. Every scalar is decoded as a string. Input that starts with '{' or '[' is decoded
+// as JSON, which YAML also accepts.
+//
+// Parameters:
+//   - data: The document to decode.
+//
+// Returns:
+//
+//	The document as map[string]interface{}, []interface{} and string values, or an
+//	error naming the line of the first construct outside the subset.
+func parseYAML(data []byte) (interface{}, error) {
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return value, nil
+	}
+
+	p := &yamlParser{}
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(stripComment(raw), " \r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content == "---" {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{number: i + 1, indent: len(line) - len(content), text: content})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	value, err := p.block(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// yamlLine is a non-blank line of a YAML document, without comments.
+type yamlLine struct {
+	number int    // The 1-based line number, for errors.
+	indent int    // The number of leading spaces.
+	text   string // The line without indentation.
+}
+
+// yamlParser decodes a document line by line.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block decodes the mapping or sequence whose lines start at indent.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+// sequence decodes the "- " items at indent.
+func (p *yamlParser) sequence(indent int) ([]interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent || !isSequenceItem(line.text) {
+			break
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		switch {
+		case rest == "":
+			p.pos++
+			value, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		case isMappingEntry(rest):
+			// "- key: value" starts a mapping indented to the key.
+			offset := len(line.text) - len(rest)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + offset, text: rest}
+			value, err := p.mapping(indent + offset)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		default:
+			value, err := yamlValue(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+// mapping decodes the "key: value" entries at indent.
+func (p *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	entries := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+		if isSequenceItem(line.text) {
+			break
+		}
+		key, rest, ok := splitMappingEntry(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		name, err := yamlKey(key, line.number)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := entries[name]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, name)
+		}
+		p.pos++
+		if rest != "" {
+			if entries[name], err = yamlValue(rest, line.number); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// A sequence may be indented at the same level as its key.
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+			if entries[name], err = p.sequence(indent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if entries[name], err = p.nested(indent); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// nested decodes the block indented deeper than parent, or returns nil if there is none.
+func (p *yamlParser) nested(parent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+// isSequenceItem reports whether text starts a sequence item.
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isMappingEntry reports whether text is a "key: value" or "key:" entry.
+func isMappingEntry(text string) bool {
+	_, _, ok := splitMappingEntry(text)
+	return ok
+}
+
+// splitMappingEntry splits "key: value" at the first colon outside quotes that ends the
+// line or is followed by a space.
+func splitMappingEntry(text string) (key, rest string, ok bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 {
+				quote = c
+			}
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), i > 0
+		}
+	}
+	return "", "", false
+}
+
+// yamlKey decodes a mapping key.
+func yamlKey(key string, line int) (string, error) {
+	value, err := yamlValue(key, line)
+	if err != nil {
+		return "", err
+	}
+	name, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("line %d: keys must be scalars", line)
+	}
+	return name, nil
+}
+
+// yamlValue decodes an inline value.
+func yamlValue(text string, line int) (interface{}, error) {
+	switch {
+	case text == "{}":
+		return map[string]interface{}{}, nil
+	case text == "[]":
+		return []interface{}{}, nil
+	case text == "~" || text == "null":
+		return nil, nil
+	case strings.HasPrefix(text, "\""):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", line, text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", line, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case strings.ContainsAny(text[:1], "{[|>&*!"):
+		return nil, fmt.Errorf("line %d: %s is not supported; use block style and plain or quoted scalars", line, text)
+	default:
+		return text, nil
+	}
+}
+
+// stripComment removes a "#" comment that starts the line or follows a space, outside
+// quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || line[i-1] == ' ' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	doc := `
+# A manifest.
+defaults:
+  metadata:
+    owner: "ops # team"
+items:
+- file: a.txt   # relative
+  metadata: {}
+-   id: 'it''s'
+    data: "line\nbreak"
+- plain
+tags: []
+`
+	got, err := parseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"defaults": map[string]interface{}{"metadata": map[string]interface{}{"owner": "ops # team"}},
+		"items": []interface{}{
+			map[string]interface{}{"file": "a.txt", "metadata": map[string]interface{}{}},
+			map[string]interface{}{"id": "it's", "data": "line\nbreak"},
+			"plain",
+		},
+		"tags": []interface{}{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseYAML() = %#v, want %#v", got, want)
+	}
+
+	if got, err := parseYAML([]byte(`{"items": [{"data": "x"}]}`)); err != nil || got.(map[string]interface{})["items"] == nil {
+		t.Errorf("Expected JSON input to be accepted, got %v, %v", got, err)
+	}
+	for _, bad := range []string{"key: [a, b]", "a: 1\n    b: 2", "text: |\n  block", "just text"} {
+		if _, err := parseYAML([]byte(bad)); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
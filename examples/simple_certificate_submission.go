@@ -5,7 +5,8 @@ import (
 	"log"
 	"os"
 
-	circular_enterprise_apis "circular_enterprise_apis/pkg"
+	"github.com/lessuselesss/go-enterprise-apis/circular"
+	"github.com/lessuselesss/go-enterprise-apis/circular/jsonx"
 
 	"github.com/joho/godotenv"
 )
@@ -25,18 +26,12 @@ func main() {
 		log.Fatal("Please set CIRCULAR_PRIVATE_KEY and CIRCULAR_ADDRESS in your .env file")
 	}
 
-	// Initialize CEPAccount
-	account := circular_enterprise_apis.NewCEPAccount()
-	if !account.Open(address) {
-		log.Fatalf("Failed to open account: %s", account.LastError)
-	}
-
-	// Set network (e.g., "testnet")
-	nagURL := account.SetNetwork("testnet")
-	if nagURL == "" {
-		log.Fatalf("Failed to set network: %s", account.LastError)
+	// Create an account opened for the address on a network (e.g., "testnet")
+	account, err := circular.NewAccount(circular.WithAddress(address), circular.WithNetwork("testnet"))
+	if err != nil {
+		log.Fatalf("Failed to create account: %v", err)
 	}
-	fmt.Printf("Connected to NAG: %s\n", nagURL)
+	fmt.Printf("Connected to NAG: %s\n", account.NAGURL)
 
 	// Update account nonce
 	if !account.UpdateAccount() {
@@ -60,6 +55,14 @@ func main() {
 	}
 	fmt.Printf("Transaction Outcome: %+v\n", outcome)
 
+	// Navigate the raw outcome without type assertions that panic on unexpected shapes
+	if status, ok := jsonx.GetString(outcome, "Status"); ok {
+		fmt.Printf("Transaction Status: %s\n", status)
+	}
+	if blockID, ok := jsonx.GetString(outcome, "BlockID"); ok {
+		fmt.Printf("Recorded in Block: %s\n", blockID)
+	}
+
 	// Close the account
 	account.Close()
 	fmt.Println("Account closed.")
@@ -1,4 +1,4 @@
-module circular_enterprise_apis
+module github.com/lessuselesss/go-enterprise-apis
 
 go 1.24.3
 
@@ -0,0 +1,52 @@
+// Package defaults holds the test overrides of the circular package's default
+// endpoints. It is internal so that only circulartest can set them.
+package defaults
+
+import "sync/atomic"
+
+var networkURL, nagURL, chain atomic.Pointer[string]
+
+// NetworkURL returns the overriding network discovery URL, and false if none is set.
+func NetworkURL() (string, bool) {
+	return load(&networkURL)
+}
+
+// SetNetworkURL overrides the network discovery URL and returns a function restoring
+// the previous override.
+func SetNetworkURL(url string) (restore func()) {
+	return store(&networkURL, url)
+}
+
+// NAGURL returns the overriding default NAG URL, and false if none is set.
+func NAGURL() (string, bool) {
+	return load(&nagURL)
+}
+
+// SetNAGURL overrides the default NAG URL and returns a function restoring the
+// previous override.
+func SetNAGURL(url string) (restore func()) {
+	return store(&nagURL, url)
+}
+
+// Chain returns the overriding default blockchain, and false if none is set.
+func Chain() (string, bool) {
+	return load(&chain)
+}
+
+// SetChain overrides the default blockchain and returns a function restoring the
+// previous override.
+func SetChain(id string) (restore func()) {
+	return store(&chain, id)
+}
+
+func load(p *atomic.Pointer[string]) (string, bool) {
+	if value := p.Load(); value != nil {
+		return *value, true
+	}
+	return "", false
+}
+
+func store(p *atomic.Pointer[string], value string) func() {
+	previous := p.Swap(&value)
+	return func() { p.Store(previous) }
+}
@@ -0,0 +1,29 @@
+// Package certtemplate is the former import path of the certificate template package
+// and is kept for one release so existing code continues to compile.
+//
+// Deprecated: import github.com/lessuselesss/go-enterprise-apis/circular/certtemplate instead.
+package certtemplate
+
+import "github.com/lessuselesss/go-enterprise-apis/circular/certtemplate"
+
+const (
+	String  = certtemplate.String
+	Number  = certtemplate.Number
+	Boolean = certtemplate.Boolean
+	Object  = certtemplate.Object
+	Array   = certtemplate.Array
+)
+
+type (
+	Document        = certtemplate.Document
+	Field           = certtemplate.Field
+	FieldType       = certtemplate.FieldType
+	Registry        = certtemplate.Registry
+	Template        = certtemplate.Template
+	ValidationError = certtemplate.ValidationError
+)
+
+var (
+	NewRegistry = certtemplate.NewRegistry
+	Parse       = certtemplate.Parse
+)
@@ -0,0 +1,73 @@
+// Package circular_enterprise_apis is the former import path of the Circular Enterprise
+// APIs and is kept for one release so existing code continues to compile.
+//
+// Deprecated: import github.com/lessuselesss/go-enterprise-apis/circular instead. Every
+// identifier here is an alias of, or forwards to, its counterpart in that package.
+package circular_enterprise_apis
+
+import "github.com/lessuselesss/go-enterprise-apis/circular"
+
+const (
+	LibVersion   = circular.LibVersion
+	DefaultChain = circular.DefaultChain
+	DefaultNAG   = circular.DefaultNAG
+
+	// NetworkURL is the network discovery endpoint. It was a variable that tests
+	// reassigned; use circulartest.OverrideNetworkDiscoveryURL instead.
+	NetworkURL = circular.DefaultNetworkURL
+
+	ClientNameHeader = circular.ClientNameHeader
+	KeyRotationType  = circular.KeyRotationType
+	ManifestType     = circular.ManifestType
+	RequestIDHeader  = circular.RequestIDHeader
+)
+
+type (
+	APIError               = circular.APIError
+	AccountPermissions     = circular.AccountPermissions
+	AdaptivePolling        = circular.AdaptivePolling
+	Backpressure           = circular.Backpressure
+	CCertificate           = circular.CCertificate
+	CEPAccount             = circular.CEPAccount
+	KeyRotation            = circular.KeyRotation
+	Manifest               = circular.Manifest
+	ManifestEntry          = circular.ManifestEntry
+	ManifestFile           = circular.ManifestFile
+	ManifestMismatch       = circular.ManifestMismatch
+	ManifestReport         = circular.ManifestReport
+	OutcomeStats           = circular.OutcomeStats
+	PermissionError        = circular.PermissionError
+	PollingStats           = circular.PollingStats
+	PrecomputedTransaction = circular.PrecomputedTransaction
+	PrivateKeySigner       = circular.PrivateKeySigner
+	RetryPolicy            = circular.RetryPolicy
+	SchemaRegistry         = circular.SchemaRegistry
+	SchemaValidationError  = circular.SchemaValidationError
+	SchemaViolation        = circular.SchemaViolation
+	Signer                 = circular.Signer
+	SubmitOption           = circular.SubmitOption
+	Transaction            = circular.Transaction
+)
+
+var (
+	BuildManifest            = circular.BuildManifest
+	BuildManifestCertificate = circular.BuildManifestCertificate
+	BuildManifestFromPaths   = circular.BuildManifestFromPaths
+	CanonicalJSON            = circular.CanonicalJSON
+	CanonicalizeJSON         = circular.CanonicalizeJSON
+	ComputeTransactionID     = circular.ComputeTransactionID
+	DefaultRetryPolicy       = circular.DefaultRetryPolicy
+	DefaultUserAgent         = circular.DefaultUserAgent
+	GetNAG                   = circular.GetNAG
+	NewCCertificate          = circular.NewCCertificate
+	NewCEPAccount            = circular.NewCEPAccount
+	NewPrivateKeySigner      = circular.NewPrivateKeySigner
+	NewSchemaRegistry        = circular.NewSchemaRegistry
+	ParseKeyRotation         = circular.ParseKeyRotation
+	ParseManifest            = circular.ParseManifest
+	RequestIDFromContext     = circular.RequestIDFromContext
+	VerifyManifest           = circular.VerifyManifest
+	VerifySignature          = circular.VerifySignature
+	WithRecipient            = circular.WithRecipient
+	WithRequestID            = circular.WithRequestID
+)
@@ -0,0 +1,15 @@
+// Package utils is the former import path of the library's hex and timestamp helpers
+// and is kept for one release so existing code continues to compile.
+//
+// Deprecated: import github.com/lessuselesss/go-enterprise-apis/circular/helpers instead.
+package utils
+
+import "github.com/lessuselesss/go-enterprise-apis/circular/helpers"
+
+var (
+	GetFormattedTimestamp = helpers.GetFormattedTimestamp
+	HexFix                = helpers.HexFix
+	HexToString           = helpers.HexToString
+	PadNumber             = helpers.PadNumber
+	StringToHex           = helpers.StringToHex
+)
@@ -8,7 +8,7 @@ import (
 	"testing"
 	"time"
 
-	cep "circular_enterprise_apis/pkg"
+	cep "github.com/lessuselesss/go-enterprise-apis/circular"
 
 	"github.com/joho/godotenv"
 )